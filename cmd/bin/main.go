@@ -20,6 +20,8 @@ func main() {
 	consumerCmd := flag.NewFlagSet("consumer", flag.ExitOnError)
 	wsCmd := flag.NewFlagSet("ws", flag.ExitOnError)
 	cronjobCmd := flag.NewFlagSet("cronjob", flag.ExitOnError)
+	workerCmd := flag.NewFlagSet("worker", flag.ExitOnError)
+	reportingCmd := flag.NewFlagSet("reporting", flag.ExitOnError)
 
 	if len(os.Args) < 2 {
 		log.Info().Msg("No command provided, defaulting to 'server'")
@@ -38,6 +40,10 @@ func main() {
 		cmd.RunCronjob(cronjobCmd, os.Args[2:])
 	case "ws":
 		cmd.RunWebsocket(wsCmd, os.Args[2:])
+	case "worker":
+		cmd.RunWorker(workerCmd, os.Args[2:])
+	case "reporting":
+		cmd.RunReporting(reportingCmd, os.Args[2:])
 	default:
 		log.Info().Msg("Invalid command provided, defaulting to 'server' with provided flags")
 		if os.Args[1][0] == '-' { // check if the first argument is a flag
@@ -28,6 +28,8 @@ func RunConsumer(cmd *flag.FlagSet, args []string) {
 	)
 
 	adapter.Adapters.Sync(
+		// the report emails complete their deliveries
+		adapter.WithDigihubPostgres(),
 		adapter.WithEmailConsumerNats(emailConsumerCtx),
 	)
 
@@ -40,6 +42,8 @@ func RunConsumer(cmd *flag.FlagSet, args []string) {
 			EmailVerificationHandler(msg)
 		case "email.forgot-password":
 			ForgotPasswordHandler(msg)
+		case "crowners.email.report":
+			ReportEmailHandler(msg)
 		default:
 		}
 	})
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"codebase-app/internal/infrastructure/config"
+	subscriptionEntity "codebase-app/internal/module/subscription/entity"
+	subscriptionRepository "codebase-app/internal/module/subscription/repository"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/gomail.v2"
+)
+
+func ReportEmailHandler(msg jetstream.Msg) {
+	payload := &subscriptionEntity.ReportEmailPayload{}
+
+	err := json.Unmarshal(msg.Data(), payload)
+	if err != nil {
+		log.Error().Err(err).Msg("consumer::ReportEmailHandler Error while unmarshalling payload")
+		if err := msg.Ack(); err != nil {
+			log.Error().Err(err).Msg("consumer::ReportEmailHandler Error while rejecting message")
+		}
+		return
+	}
+
+	err = sendReport(payload)
+	if err != nil {
+		log.Error().Err(err).Str("delivery_id", payload.DeliveryId).Strs("to", payload.To).Msg("consumer::ReportEmailHandler Error while sending email")
+
+		// retried later until the attempts run out
+		if meta, errMeta := msg.Metadata(); errMeta == nil && meta.NumDelivered < subscriptionEntity.ReportEmailMaxAttempts {
+			if err := msg.NakWithDelay(subscriptionEntity.ReportEmailRetryDelay); err != nil {
+				log.Error().Err(err).Str("delivery_id", payload.DeliveryId).Msg("consumer::ReportEmailHandler Error while rejecting message")
+			}
+			return
+		}
+
+		reason := err.Error()
+		completeReportDelivery(payload.DeliveryId, subscriptionEntity.DeliveryFailed, &reason)
+		if err := msg.Ack(); err != nil {
+			log.Error().Err(err).Str("delivery_id", payload.DeliveryId).Msg("consumer::ReportEmailHandler Error while rejecting message")
+		}
+		return
+	}
+
+	completeReportDelivery(payload.DeliveryId, subscriptionEntity.DeliverySent, nil)
+	if err := msg.Ack(); err != nil {
+		log.Error().Err(err).Str("delivery_id", payload.DeliveryId).Msg("consumer::ReportEmailHandler Error while acknowledging message")
+	}
+}
+
+// completeReportDelivery records the outcome of the email in the delivery log,
+// a failure is only logged as the email is handled either way.
+func completeReportDelivery(deliveryId, status string, reason *string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	repo := subscriptionRepository.NewSubscriptionRepository()
+	if err := repo.CompleteDelivery(ctx, deliveryId, status, reason); err != nil {
+		log.Error().Err(err).Str("delivery_id", deliveryId).Str("status", status).Msg("consumer::completeReportDelivery Error while completing delivery")
+	}
+}
+
+func sendReport(payload *subscriptionEntity.ReportEmailPayload) error {
+	var (
+		mail       = config.Envs.Mail
+		attachment = payload.Attachment
+	)
+
+	mailer := gomail.NewMessage()
+	mailer.SetHeader("From", "Crowners <"+mail.Username+">")
+	mailer.SetHeader("To", payload.To...)
+	mailer.SetHeader("Subject", payload.Subject)
+	mailer.SetBody("text/html", payload.Body)
+	mailer.Attach(attachment.Filename,
+		gomail.SetHeader(map[string][]string{"Content-Type": {attachment.ContentType}}),
+		gomail.SetCopyFunc(func(w io.Writer) error {
+			_, err := w.Write(attachment.Content)
+			return err
+		}),
+	)
+
+	dialer := gomail.NewDialer(mail.Host, mail.Port, mail.Username, mail.Password)
+	err := dialer.DialAndSend(mailer)
+	if err != nil {
+		log.Error().Err(err).Msg("consumer::sendReport Error while sending email")
+		return err
+	}
+
+	return nil
+}
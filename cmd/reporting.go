@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/reporting/entity"
+	"codebase-app/internal/module/reporting/repository"
+	"codebase-app/internal/module/reporting/service"
+	"codebase-app/pkg/period"
+	"context"
+	"flag"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RunReporting maintains the dashboard fact tables.
+//
+//	reporting -action=backfill -from=2024-01-01 -to=2024-10-31
+//	reporting -action=check -from=2024-10-01
+//	reporting -action=refresh
+func RunReporting(cmd *flag.FlagSet, args []string) {
+	var (
+		action = cmd.String("action", "refresh", "backfill, check or refresh")
+		from   = cmd.String("from", "", "First day (2006-01-02), defaults to the first day of the current month")
+		to     = cmd.String("to", "", "Last day (2006-01-02), defaults to today")
+	)
+
+	if err := cmd.Parse(args); err != nil {
+		log.Fatal().Err(err).Msg("Error while parsing flags")
+	}
+
+	loc, _ := time.LoadLocation(period.DefaultTimezone)
+	now := time.Now().In(loc)
+
+	fromDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	toDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	if *from != "" {
+		d, err := time.ParseInLocation(entity.DateFormat, *from, loc)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid -from")
+		}
+		fromDate = d
+	}
+
+	if *to != "" {
+		d, err := time.ParseInLocation(entity.DateFormat, *to, loc)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid -to")
+		}
+		toDate = d
+	}
+
+	if toDate.Before(fromDate) {
+		log.Fatal().Msg("-to must not be before -from")
+	}
+
+	adapter.Adapters.Sync(
+		adapter.WithDigihubPostgres(),
+	)
+	defer func() {
+		if err := adapter.Adapters.Unsync(); err != nil {
+			log.Error().Err(err).Msg("Error while closing database connection")
+		}
+	}()
+
+	var (
+		ctx       = context.Background()
+		reporting = service.NewReportingService(repository.NewReportingRepository())
+	)
+
+	switch *action {
+	case "backfill":
+		if err := reporting.Backfill(ctx, fromDate, toDate); err != nil {
+			log.Fatal().Err(err).Msg("Failed to backfill facts")
+		}
+		log.Info().Time("from", fromDate).Time("to", toDate).Msg("Facts backfilled")
+	case "refresh":
+		if err := reporting.Refresh(ctx); err != nil {
+			log.Fatal().Err(err).Msg("Failed to refresh facts")
+		}
+		log.Info().Msg("Facts refreshed")
+	case "check":
+		mismatches, err := reporting.Check(ctx, fromDate, toDate)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to check facts")
+		}
+
+		for _, m := range mismatches {
+			log.Warn().Str("day", m.Day).Str("field", m.Field).Str("raw", m.Raw).Str("fact", m.Fact).Msg("Fact mismatch")
+		}
+
+		if len(mismatches) > 0 {
+			log.Error().Int("mismatches", len(mismatches)).Msg("Facts are not consistent with the raw tables, run the backfill for the listed days")
+			// deferred Unsync is skipped on purpose, the process is exiting
+			os.Exit(1)
+		}
+
+		log.Info().Msg("Facts are consistent with the raw tables")
+	default:
+		log.Fatal().Str("action", *action).Msg("Unknown action")
+	}
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/infrastructure"
+	"codebase-app/internal/infrastructure/config"
+	integlocalstorage "codebase-app/internal/integration/localstorage"
+	exportRepository "codebase-app/internal/module/export/repository"
+	exportService "codebase-app/internal/module/export/service"
+	reportingRepository "codebase-app/internal/module/reporting/repository"
+	reportingService "codebase-app/internal/module/reporting/service"
+	subscriptionRepository "codebase-app/internal/module/subscription/repository"
+	subscriptionService "codebase-app/internal/module/subscription/service"
+	tierRepository "codebase-app/internal/module/tier/repository"
+	tierService "codebase-app/internal/module/tier/service"
+	trashRepository "codebase-app/internal/module/trash/repository"
+	trashService "codebase-app/internal/module/trash/service"
+	"codebase-app/pkg/period"
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// RunWorker runs the background job processors (export jobs, report
+// subscriptions, advisor tier snapshots, the dashboard facts refresh and the
+// trash purge).
+func RunWorker(cmd *flag.FlagSet, args []string) {
+	var (
+		envs            = config.Envs
+		flagConcurrency = cmd.Int("concurrency", 2, "Number of export jobs processed in parallel")
+		flagInterval    = cmd.Duration("interval", 5*time.Second, "Polling interval when there is no pending job")
+		flagTierEvery   = cmd.Duration("tier-interval", time.Hour, "Interval between advisor tier snapshots")
+		flagFactsEvery  = cmd.Duration("facts-interval", 5*time.Minute, "Interval between dashboard facts refreshes")
+		flagSubsEvery   = cmd.Duration("subscription-interval", time.Minute, "Polling interval of the due report subscriptions")
+		flagPurgeEvery  = cmd.Duration("purge-interval", 24*time.Hour, "Interval between purges of the expired trash")
+	)
+
+	logLevel, err := zerolog.ParseLevel(envs.App.LogLevel)
+	if err != nil {
+		logLevel = zerolog.InfoLevel
+	}
+
+	if err := cmd.Parse(args); err != nil {
+		log.Fatal().Err(err).Msg("Error while parsing flags")
+	}
+
+	infrastructure.InitializeLogger(envs.App.Environtment, "worker.log", logLevel)
+
+	adapter.Adapters.Sync(
+		adapter.WithDigihubPostgres(),
+		adapter.WithEmailNatsPublisher(),
+	)
+
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		wg          sync.WaitGroup
+		exports     = exportService.NewExportService(exportRepository.NewExportRepository())
+		tiers       = tierService.NewTierService(tierRepository.NewTierRepository())
+		reporting   = reportingService.NewReportingService(reportingRepository.NewReportingRepository())
+		subs        = subscriptionService.NewSubscriptionService(
+			subscriptionRepository.NewSubscriptionRepository(),
+			exports,
+			adapter.Adapters.EmailPublisher,
+		)
+		trash = trashService.NewTrashService(
+			trashRepository.NewTrashRepository(),
+			integlocalstorage.NewLocalStorageIntegration(),
+		)
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for {
+			if err := reporting.Refresh(ctx); err != nil {
+				log.Error().Err(err).Msg("worker::RunWorker - failed to refresh dashboard facts")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(*flagFactsEvery):
+			}
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		var (
+			last   time.Time
+			loc, _ = time.LoadLocation(period.DefaultTimezone)
+		)
+
+		for {
+			now := time.Now().In(loc)
+
+			// snapshot the previous run's period once more after midnight so
+			// the history of a finished period holds its final revenue.
+			if !last.IsZero() && last.YearDay() != now.YearDay() {
+				if err := tiers.SnapshotAdvisorTiers(ctx, last); err != nil {
+					log.Error().Err(err).Msg("worker::RunWorker - failed to snapshot advisor tiers")
+				}
+			}
+
+			if err := tiers.SnapshotAdvisorTiers(ctx, now); err != nil {
+				log.Error().Err(err).Msg("worker::RunWorker - failed to snapshot advisor tiers")
+			}
+			last = now
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(*flagTierEvery):
+			}
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for {
+			processed, err := subs.ProcessNextSubscription(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("worker::RunWorker - failed to process report subscription")
+			}
+
+			if processed {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(*flagSubsEvery):
+			}
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for {
+			total, err := trash.PurgeExpired(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("worker::RunWorker - failed to purge trash")
+			} else if total > 0 {
+				log.Info().Int("total", total).Msg("worker::RunWorker - trash purged")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(*flagPurgeEvery):
+			}
+		}
+	}()
+
+	for i := 0; i < *flagConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				processed, err := exports.ProcessNextExport(ctx)
+				if err != nil {
+					log.Error().Err(err).Msg("worker::RunWorker - failed to process export")
+				}
+
+				if processed {
+					continue
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(*flagInterval):
+				}
+			}
+		}()
+	}
+
+	log.Info().Int("concurrency", *flagConcurrency).Msg("Worker is running")
+
+	quit := make(chan os.Signal, 1)
+
+	shutdownSignals := []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGINT}
+	if runtime.GOOS == "windows" {
+		shutdownSignals = []os.Signal{os.Interrupt}
+	}
+
+	signal.Notify(quit, shutdownSignals...)
+	<-quit
+	log.Info().Msg("Worker is shutting down ...")
+
+	cancel()
+	wg.Wait()
+
+	if err := adapter.Adapters.Unsync(); err != nil {
+		log.Error().Err(err).Msg("Error while closing adapters")
+	}
+
+	log.Info().Msg("Worker gracefully stopped")
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS export_jobs;
+DROP TYPE IF EXISTS export_status;
@@ -0,0 +1,22 @@
+CREATE TYPE export_status AS ENUM ('pending', 'processing', 'completed', 'failed');
+
+CREATE TABLE IF NOT EXISTS export_jobs (
+    id CHAR(26) PRIMARY KEY,
+    user_id CHAR(26) NOT NULL,
+    report VARCHAR(50) NOT NULL,
+    params JSONB NOT NULL DEFAULT '{}',
+    status export_status NOT NULL DEFAULT 'pending',
+    total_rows INT NOT NULL DEFAULT 0,
+    processed_rows INT NOT NULL DEFAULT 0,
+    path TEXT,
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS export_jobs_status_created_at_idx ON export_jobs (status, created_at);
+CREATE INDEX IF NOT EXISTS export_jobs_user_id_idx ON export_jobs (user_id);
@@ -0,0 +1,3 @@
+ALTER TABLE export_jobs
+    DROP COLUMN IF EXISTS format,
+    DROP COLUMN IF EXISTS locale;
@@ -0,0 +1,3 @@
+ALTER TABLE export_jobs
+    ADD COLUMN IF NOT EXISTS format VARCHAR(10) NOT NULL DEFAULT 'xlsx',
+    ADD COLUMN IF NOT EXISTS locale VARCHAR(5) NOT NULL DEFAULT 'id';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS advisor_tier_histories;
+DROP TABLE IF EXISTS tiers;
+DROP TYPE IF EXISTS tier_period;
@@ -0,0 +1,51 @@
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'tier_period') THEN
+        CREATE TYPE tier_period AS ENUM ('monthly', 'quarterly', 'yearly');
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS tiers (
+    id CHAR(26) PRIMARY KEY,
+    branch_id CHAR(26), -- NULL means the tier applies to every branch without its own tiers
+    key VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    threshold DECIMAL(19, 4) NOT NULL DEFAULT 0.0000,
+    period tier_period NOT NULL DEFAULT 'yearly',
+    effective_from DATE NOT NULL,
+    effective_to DATE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+
+    FOREIGN KEY (branch_id) REFERENCES branches (id),
+    CHECK (effective_to IS NULL OR effective_to >= effective_from)
+);
+
+CREATE INDEX IF NOT EXISTS tiers_branch_id_idx ON tiers (branch_id);
+
+-- the programme that used to be hard-coded
+INSERT INTO tiers (id, branch_id, key, name, threshold, period, effective_from) VALUES
+    ('01J9Z00000000000000000000P', NULL, 'platinum', 'Platinum', 1500000, 'yearly', '2024-01-01'),
+    ('01J9Z00000000000000000000G', NULL, 'gold', 'Gold', 1000000, 'yearly', '2024-01-01'),
+    ('01J9Z00000000000000000000S', NULL, 'silver', 'Silver', 500000, 'yearly', '2024-01-01')
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS advisor_tier_histories (
+    id CHAR(26) PRIMARY KEY,
+    user_id CHAR(26) NOT NULL,
+    branch_id CHAR(26),
+    tier_id CHAR(26),
+    tier_key VARCHAR(50) NOT NULL,
+    period tier_period NOT NULL,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    revenue DECIMAL(19, 4) NOT NULL DEFAULT 0.0000,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (user_id) REFERENCES users (id),
+    FOREIGN KEY (branch_id) REFERENCES branches (id),
+    FOREIGN KEY (tier_id) REFERENCES tiers (id),
+    UNIQUE (user_id, period, period_start)
+);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS walk_around_check_conditions_updated_at_idx;
+DROP INDEX IF EXISTS walk_around_checks_updated_at_idx;
+
+DROP TABLE IF EXISTS reporting_watermarks;
+DROP TABLE IF EXISTS fact_wac_condition_daily;
+DROP TABLE IF EXISTS fact_wac_daily;
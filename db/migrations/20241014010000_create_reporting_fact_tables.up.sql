@@ -0,0 +1,52 @@
+-- daily facts behind the dashboards, days are in the reporting timezone
+-- (Asia/Makassar) and rebuilt by the worker from walk_around_checks
+CREATE TABLE IF NOT EXISTS fact_wac_daily (
+    day DATE NOT NULL,
+    branch_id CHAR(26) NOT NULL,
+    user_id CHAR(26) NOT NULL,
+    status wac_status NOT NULL,
+    is_used_car BOOLEAN NOT NULL,
+    is_needs_follow_up BOOLEAN NOT NULL,
+
+    total_wacs INT DEFAULT 0 NOT NULL,
+    total_followed_up INT DEFAULT 0 NOT NULL,
+    revenue DECIMAL(19, 4) DEFAULT 0.0000 NOT NULL,
+
+    refreshed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (day, branch_id, user_id, status, is_used_car, is_needs_follow_up)
+);
+
+CREATE INDEX IF NOT EXISTS fact_wac_daily_user_id_day_idx ON fact_wac_daily (user_id, day);
+CREATE INDEX IF NOT EXISTS fact_wac_daily_branch_id_day_idx ON fact_wac_daily (branch_id, day);
+
+CREATE TABLE IF NOT EXISTS fact_wac_condition_daily (
+    day DATE NOT NULL,
+    branch_id CHAR(26) NOT NULL,
+    user_id CHAR(26) NOT NULL,
+    potency_id CHAR(26) NOT NULL,
+    area_id CHAR(26) NOT NULL,
+    status wac_status NOT NULL,
+    is_used_car BOOLEAN NOT NULL,
+    is_needs_follow_up BOOLEAN NOT NULL,
+
+    total_conditions INT DEFAULT 0 NOT NULL,
+    total_interested INT DEFAULT 0 NOT NULL,
+    revenue DECIMAL(19, 4) DEFAULT 0.0000 NOT NULL,
+
+    refreshed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (day, branch_id, user_id, potency_id, area_id, status, is_used_car, is_needs_follow_up)
+);
+
+CREATE INDEX IF NOT EXISTS fact_wac_condition_daily_user_id_day_idx ON fact_wac_condition_daily (user_id, day);
+CREATE INDEX IF NOT EXISTS fact_wac_condition_daily_branch_id_day_idx ON fact_wac_condition_daily (branch_id, day);
+CREATE INDEX IF NOT EXISTS fact_wac_condition_daily_day_idx ON fact_wac_condition_daily (day);
+
+CREATE TABLE IF NOT EXISTS reporting_watermarks (
+    name VARCHAR(50) PRIMARY KEY,
+    refreshed_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS walk_around_checks_updated_at_idx ON walk_around_checks (updated_at);
+CREATE INDEX IF NOT EXISTS walk_around_check_conditions_updated_at_idx ON walk_around_check_conditions (updated_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS report_deliveries;
+DROP TYPE IF EXISTS report_delivery_status;
+DROP TABLE IF EXISTS report_subscriptions;
@@ -0,0 +1,49 @@
+CREATE TABLE IF NOT EXISTS report_subscriptions (
+    id CHAR(26) PRIMARY KEY,
+    user_id CHAR(26) NOT NULL, -- the report is rendered with the access of this user
+    name VARCHAR(255) NOT NULL,
+    report VARCHAR(50) NOT NULL,
+    format VARCHAR(10) NOT NULL DEFAULT 'xlsx',
+    locale VARCHAR(5) NOT NULL DEFAULT 'id',
+    params JSONB NOT NULL DEFAULT '{}',
+    schedule VARCHAR(100) NOT NULL, -- cron expression evaluated in timezone
+    timezone VARCHAR(50) NOT NULL DEFAULT 'Asia/Makassar',
+    recipients TEXT[] NOT NULL,
+    is_active BOOLEAN DEFAULT TRUE NOT NULL,
+    next_run_at TIMESTAMP WITH TIME ZONE,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+
+    FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS report_subscriptions_next_run_at_idx ON report_subscriptions (next_run_at)
+    WHERE is_active = TRUE AND deleted_at IS NULL;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'report_delivery_status') THEN
+        CREATE TYPE report_delivery_status AS ENUM ('processing', 'sent', 'failed');
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS report_deliveries (
+    id CHAR(26) PRIMARY KEY,
+    subscription_id CHAR(26) NOT NULL,
+    status report_delivery_status NOT NULL DEFAULT 'processing',
+    scheduled_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    recipients TEXT[] NOT NULL,
+    params JSONB NOT NULL DEFAULT '{}', -- the resolved params of the run
+    filename VARCHAR(255),
+    size_bytes INT,
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (subscription_id) REFERENCES report_subscriptions (id)
+);
+
+CREATE INDEX IF NOT EXISTS report_deliveries_subscription_id_created_at_idx ON report_deliveries (subscription_id, created_at);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS users_deleted_at_idx;
+DROP INDEX IF EXISTS promotions_deleted_at_idx;
+DROP INDEX IF EXISTS clients_deleted_at_idx;
+DROP INDEX IF EXISTS walk_around_checks_deleted_at_idx;
+
+ALTER TABLE promotions DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE promotions DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,35 @@
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS vehicle_license_number VARCHAR(255);
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS vehicle_type_id CHAR(26) REFERENCES vehicle_types (id);
+
+-- a client gets back the vehicle of its last walk around check, else the
+-- last vehicle it owns
+UPDATE clients c
+SET
+    vehicle_license_number = v.license_number,
+    vehicle_type_id = v.vehicle_type_id
+FROM (
+    SELECT DISTINCT ON (client_id) client_id, license_number, vehicle_type_id
+    FROM (
+        SELECT wac.client_id, v.license_number, v.vehicle_type_id, wac.created_at
+        FROM walk_around_checks wac
+        JOIN vehicles v ON v.id = wac.vehicle_id
+        UNION ALL
+        SELECT v.client_id, v.license_number, v.vehicle_type_id, v.created_at - INTERVAL '100 years'
+        FROM vehicles v
+    ) candidates
+    ORDER BY client_id, created_at DESC
+) v
+WHERE v.client_id = c.id;
+
+UPDATE clients SET vehicle_license_number = '' WHERE vehicle_license_number IS NULL;
+UPDATE clients SET vehicle_type_id = (SELECT id FROM vehicle_types ORDER BY id LIMIT 1) WHERE vehicle_type_id IS NULL;
+
+ALTER TABLE clients ALTER COLUMN vehicle_license_number SET NOT NULL;
+ALTER TABLE clients ALTER COLUMN vehicle_type_id SET NOT NULL;
+ALTER TABLE clients ADD CONSTRAINT clients_vehicle_license_number_name_key UNIQUE (vehicle_license_number, name);
+
+DROP INDEX IF EXISTS walk_around_checks_vehicle_id_idx;
+ALTER TABLE walk_around_checks DROP COLUMN IF EXISTS mileage;
+ALTER TABLE walk_around_checks DROP COLUMN IF EXISTS vehicle_id;
+
+DROP TABLE IF EXISTS vehicles;
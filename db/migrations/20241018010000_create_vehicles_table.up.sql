@@ -0,0 +1,64 @@
+CREATE TABLE IF NOT EXISTS vehicles (
+    id CHAR(26) PRIMARY KEY,
+    client_id CHAR(26) NOT NULL, -- the current owner
+    vehicle_type_id CHAR(26) NOT NULL,
+    license_number VARCHAR(255) NOT NULL,
+    vin VARCHAR(17),
+    year INT,
+    colour VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+
+    FOREIGN KEY (client_id) REFERENCES clients (id),
+    FOREIGN KEY (vehicle_type_id) REFERENCES vehicle_types (id)
+);
+
+-- a plate is looked up without its spaces and case, "DD 1234 AB" is "dd1234ab"
+CREATE UNIQUE INDEX IF NOT EXISTS vehicles_license_number_key ON vehicles (UPPER(REPLACE(license_number, ' ', '')))
+    WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS vehicles_vin_key ON vehicles (UPPER(vin))
+    WHERE vin IS NOT NULL AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS vehicles_client_id_idx ON vehicles (client_id);
+
+ALTER TABLE walk_around_checks ADD COLUMN IF NOT EXISTS vehicle_id CHAR(26) REFERENCES vehicles (id);
+ALTER TABLE walk_around_checks ADD COLUMN IF NOT EXISTS mileage INT;
+
+-- one vehicle per plate, owned by the client that brought it last, the id of
+-- that client is reused as the id of the vehicle, a vehicle of a client in the
+-- trash comes back with its client
+INSERT INTO vehicles (id, client_id, vehicle_type_id, license_number, created_at, updated_at)
+SELECT
+    ranked.id,
+    ranked.id,
+    ranked.vehicle_type_id,
+    ranked.vehicle_license_number,
+    ranked.created_at,
+    ranked.updated_at
+FROM (
+    SELECT
+        c.*,
+        ROW_NUMBER() OVER (
+            PARTITION BY UPPER(REPLACE(c.vehicle_license_number, ' ', ''))
+            ORDER BY (c.deleted_at IS NULL) DESC, w.last_wac_at DESC NULLS LAST, c.updated_at DESC
+        ) AS rn
+    FROM clients c
+    LEFT JOIN LATERAL (
+        SELECT MAX(created_at) AS last_wac_at FROM walk_around_checks WHERE client_id = c.id
+    ) w ON TRUE
+) ranked
+WHERE ranked.rn = 1
+ON CONFLICT (id) DO NOTHING;
+
+UPDATE walk_around_checks wac
+SET vehicle_id = v.id
+FROM clients c, vehicles v
+WHERE c.id = wac.client_id
+    AND UPPER(REPLACE(v.license_number, ' ', '')) = UPPER(REPLACE(c.vehicle_license_number, ' ', ''))
+    AND wac.vehicle_id IS NULL;
+
+ALTER TABLE walk_around_checks ALTER COLUMN vehicle_id SET NOT NULL;
+CREATE INDEX IF NOT EXISTS walk_around_checks_vehicle_id_idx ON walk_around_checks (vehicle_id);
+
+ALTER TABLE clients DROP COLUMN IF EXISTS vehicle_license_number;
+ALTER TABLE clients DROP COLUMN IF EXISTS vehicle_type_id;
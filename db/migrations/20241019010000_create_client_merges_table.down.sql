@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS client_merges;
+
+DROP INDEX IF EXISTS vehicles_license_number_trgm_idx;
+DROP INDEX IF EXISTS clients_phone_idx;
+DROP INDEX IF EXISTS clients_name_trgm_idx;
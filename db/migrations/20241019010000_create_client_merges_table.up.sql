@@ -0,0 +1,39 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- the names, plates and phones are written the way the API normalizes them,
+-- "b  1234 xy" is "B 1234 XY" and "0812-3456-789" is "+628123456789"
+UPDATE clients SET name = TRIM(REGEXP_REPLACE(name, '\s+', ' ', 'g'));
+UPDATE vehicles SET license_number = UPPER(TRIM(REGEXP_REPLACE(license_number, '\s+', ' ', 'g')));
+UPDATE clients
+SET phone = CASE
+    WHEN digits LIKE '62%' THEN '+' || digits
+    WHEN digits LIKE '0%' AND phone NOT LIKE '+%' THEN '+62' || SUBSTRING(digits FROM 2)
+    WHEN digits LIKE '8%' AND phone NOT LIKE '+%' THEN '+62' || digits
+    WHEN phone LIKE '+%' THEN '+' || digits
+    ELSE digits
+END
+FROM (SELECT id AS digits_id, REGEXP_REPLACE(phone, '\D', '', 'g') AS digits FROM clients) d
+WHERE d.digits_id = clients.id AND d.digits <> '';
+
+CREATE INDEX IF NOT EXISTS clients_name_trgm_idx ON clients USING GIN (LOWER(name) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS clients_phone_idx ON clients (phone);
+CREATE INDEX IF NOT EXISTS vehicles_license_number_trgm_idx ON vehicles
+    USING GIN (UPPER(REPLACE(license_number, ' ', '')) gin_trgm_ops);
+
+-- the merged client is kept in the trash, its ids are not foreign keys so the
+-- trail outlives the purge of either client
+CREATE TABLE IF NOT EXISTS client_merges (
+    id CHAR(26) PRIMARY KEY,
+    target_client_id CHAR(26) NOT NULL,
+    source_client_id CHAR(26) NOT NULL,
+    user_id CHAR(26) NOT NULL,
+    source_snapshot JSONB NOT NULL, -- the merged client as it was
+    moved_wacs INT NOT NULL,
+    moved_vehicles INT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS client_merges_target_client_id_idx ON client_merges (target_client_id);
+CREATE INDEX IF NOT EXISTS client_merges_source_client_id_idx ON client_merges (source_client_id);
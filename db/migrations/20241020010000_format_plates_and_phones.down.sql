@@ -0,0 +1,2 @@
+-- the plates and phones are not written back the way they were, both formats
+-- are read the same, the merged vehicles are not split again
@@ -0,0 +1,72 @@
+-- plates that only differ by their separators, "B-1234-XY" and "B 1234 XY",
+-- become the same plate, the vehicles are merged first into the one with the
+-- latest WAC so vehicles_license_number_key holds, the WACs move to it and it
+-- takes the vin, year and colour it lacks
+CREATE TEMP TABLE plate_merges AS
+SELECT id, survivor_id, vin, year, colour
+FROM (
+    SELECT
+        v.id,
+        FIRST_VALUE(v.id) OVER (
+            PARTITION BY v.plate
+            ORDER BY w.last_wac_at DESC NULLS LAST, v.updated_at DESC, v.id
+        ) AS survivor_id,
+        v.vin,
+        v.year,
+        v.colour
+    FROM (
+        SELECT *, UPPER(REGEXP_REPLACE(license_number, '[\s.-]', '', 'g')) AS plate
+        FROM vehicles
+        WHERE deleted_at IS NULL
+    ) v
+    LEFT JOIN LATERAL (
+        SELECT MAX(created_at) AS last_wac_at FROM walk_around_checks WHERE vehicle_id = v.id
+    ) w ON TRUE
+    WHERE v.plate ~ '^[A-Z]{1,2}[1-9][0-9]{0,3}[A-Z]{0,3}$'
+) ranked
+WHERE id <> survivor_id;
+
+UPDATE walk_around_checks wac
+SET vehicle_id = m.survivor_id
+FROM plate_merges m
+WHERE wac.vehicle_id = m.id;
+
+DELETE FROM vehicles WHERE id IN (SELECT id FROM plate_merges);
+
+UPDATE vehicles v
+SET
+    vin = COALESCE(v.vin, m.vin),
+    year = COALESCE(v.year, m.year),
+    colour = COALESCE(v.colour, m.colour),
+    updated_at = NOW()
+FROM (
+    SELECT survivor_id, MAX(vin) AS vin, MAX(year) AS year, MAX(colour) AS colour
+    FROM plate_merges
+    GROUP BY survivor_id
+) m
+WHERE v.id = m.survivor_id;
+
+DROP TABLE plate_merges;
+
+-- plates that follow the region, number and suffix pattern are written the
+-- canonical way, "b-1234-xyz" is "B 1234 XYZ", the others are kept
+UPDATE vehicles
+SET license_number = RTRIM(REGEXP_REPLACE(
+    UPPER(REGEXP_REPLACE(license_number, '[\s.-]', '', 'g')),
+    '^([A-Z]{1,2})([1-9][0-9]{0,3})([A-Z]{0,3})$',
+    '\1 \2 \3'
+))
+WHERE UPPER(REGEXP_REPLACE(license_number, '[\s.-]', '', 'g')) ~ '^[A-Z]{1,2}[1-9][0-9]{0,3}[A-Z]{0,3}$';
+
+-- the WhatsApp numbers of the employees are written in E.164 like the phones
+-- of the clients
+UPDATE users
+SET whatsapp_number = CASE
+    WHEN digits LIKE '62%' THEN '+' || digits
+    WHEN digits LIKE '0%' AND whatsapp_number NOT LIKE '+%' THEN '+62' || SUBSTRING(digits FROM 2)
+    WHEN digits LIKE '8%' AND whatsapp_number NOT LIKE '+%' THEN '+62' || digits
+    WHEN whatsapp_number LIKE '+%' THEN '+' || digits
+    ELSE digits
+END
+FROM (SELECT id AS digits_id, REGEXP_REPLACE(whatsapp_number, '\D', '', 'g') AS digits FROM users) d
+WHERE d.digits_id = users.id AND d.digits <> '';
@@ -0,0 +1,16 @@
+-- only the prices of the list in effect are kept
+DELETE FROM trade_in_trends
+WHERE price_list_id IS DISTINCT FROM (
+    SELECT id
+    FROM trade_in_price_lists
+    WHERE published_at IS NOT NULL AND effective_from <= CURRENT_DATE
+    ORDER BY effective_from DESC
+    LIMIT 1
+);
+
+ALTER TABLE trade_in_trends DROP CONSTRAINT IF EXISTS trade_in_trends_price_list_id_brand_model_type_year_key;
+ALTER TABLE trade_in_trends ADD CONSTRAINT trade_in_trends_brand_model_type_year_key UNIQUE (brand, model, type, year);
+ALTER TABLE trade_in_trends DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE trade_in_trends DROP COLUMN IF EXISTS price_list_id;
+
+DROP TABLE IF EXISTS trade_in_price_lists;
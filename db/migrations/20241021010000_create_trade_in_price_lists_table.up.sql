@@ -0,0 +1,32 @@
+-- the trade in prices are grouped in price lists, a draft is edited and then
+-- published with the date it takes effect, a published list is never changed
+-- so a past quote can be priced again
+CREATE TABLE IF NOT EXISTS trade_in_price_lists (
+    id CHAR(26) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    effective_from DATE NOT NULL,
+    created_by CHAR(26),
+    published_at TIMESTAMP WITH TIME ZONE, -- null while draft
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (created_by) REFERENCES users (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS trade_in_price_lists_effective_from_key ON trade_in_price_lists (effective_from)
+    WHERE published_at IS NOT NULL;
+
+-- the seeded prices become the first published list
+INSERT INTO trade_in_price_lists (id, name, effective_from, published_at)
+VALUES ('01JAF0000000000000000TRADE', 'Initial', '2024-01-01', NOW())
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE trade_in_trends ADD COLUMN IF NOT EXISTS price_list_id CHAR(26) REFERENCES trade_in_price_lists (id);
+ALTER TABLE trade_in_trends ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL;
+
+UPDATE trade_in_trends SET price_list_id = '01JAF0000000000000000TRADE' WHERE price_list_id IS NULL;
+
+ALTER TABLE trade_in_trends ALTER COLUMN price_list_id SET NOT NULL;
+ALTER TABLE trade_in_trends DROP CONSTRAINT IF EXISTS trade_in_trends_brand_model_type_year_key;
+ALTER TABLE trade_in_trends ADD CONSTRAINT trade_in_trends_price_list_id_brand_model_type_year_key
+    UNIQUE (price_list_id, brand, model, type, year);
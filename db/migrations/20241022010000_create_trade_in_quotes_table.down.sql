@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS trade_in_quote_adjustments;
+DROP TABLE IF EXISTS trade_in_quotes;
@@ -0,0 +1,43 @@
+-- the trade in quote of a used-car walk around check, the price range is copied
+-- with the price list it came from so the quote can be priced again
+CREATE TABLE IF NOT EXISTS trade_in_quotes (
+    id CHAR(26) PRIMARY KEY,
+    walk_around_check_id CHAR(26) NOT NULL UNIQUE,
+    price_list_id CHAR(26) NOT NULL,
+    user_id CHAR(26) NOT NULL,
+    brand VARCHAR(255) NOT NULL,
+    model VARCHAR(255) NOT NULL,
+    type VARCHAR(255) NOT NULL,
+    year INT NOT NULL,
+    mileage INT,
+    min_purchase INT NOT NULL,
+    max_purchase INT NOT NULL,
+    base_price INT NOT NULL,
+    mileage_adjustment INT NOT NULL,
+    condition_adjustment INT NOT NULL,
+    suggested_price INT NOT NULL,
+    offered_price INT NOT NULL,
+    valid_until DATE NOT NULL,
+    notes VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (walk_around_check_id) REFERENCES walk_around_checks (id) ON DELETE CASCADE,
+    FOREIGN KEY (price_list_id) REFERENCES trade_in_price_lists (id),
+    FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+-- the deductions for the damages shown by the condition photos
+CREATE TABLE IF NOT EXISTS trade_in_quote_adjustments (
+    id CHAR(26) PRIMARY KEY,
+    trade_in_quote_id CHAR(26) NOT NULL,
+    walk_around_check_condition_id CHAR(26) NOT NULL,
+    deduction INT NOT NULL,
+    notes VARCHAR(255),
+
+    FOREIGN KEY (trade_in_quote_id) REFERENCES trade_in_quotes (id) ON DELETE CASCADE,
+    FOREIGN KEY (walk_around_check_condition_id) REFERENCES walk_around_check_conditions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS trade_in_quote_adjustments_trade_in_quote_id_idx ON trade_in_quote_adjustments (trade_in_quote_id);
+CREATE INDEX IF NOT EXISTS trade_in_quotes_price_list_id_idx ON trade_in_quotes (price_list_id);
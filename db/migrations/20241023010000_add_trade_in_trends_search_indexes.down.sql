@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS trade_in_trends_search_trgm_idx;
+DROP INDEX IF EXISTS trade_in_trends_search_fts_idx;
@@ -0,0 +1,7 @@
+-- the free text search of the trade in catalogue matches the words on the full
+-- text index and the misspelled ones on the trigram index
+CREATE INDEX IF NOT EXISTS trade_in_trends_search_fts_idx ON trade_in_trends
+    USING GIN (to_tsvector('simple', brand || ' ' || model || ' ' || type));
+
+CREATE INDEX IF NOT EXISTS trade_in_trends_search_trgm_idx ON trade_in_trends
+    USING GIN (LOWER(brand || ' ' || model || ' ' || type) gin_trgm_ops);
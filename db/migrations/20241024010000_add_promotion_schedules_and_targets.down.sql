@@ -0,0 +1,12 @@
+ALTER TABLE walk_around_checks DROP COLUMN IF EXISTS promotion_id;
+
+DROP INDEX IF EXISTS promotions_display_order_idx;
+DROP TABLE IF EXISTS promotion_potencies;
+DROP TABLE IF EXISTS promotion_branches;
+
+ALTER TABLE promotions
+    DROP CONSTRAINT IF EXISTS promotions_end_date_check,
+    DROP COLUMN IF EXISTS display_order,
+    DROP COLUMN IF EXISTS is_active,
+    DROP COLUMN IF EXISTS end_date,
+    DROP COLUMN IF EXISTS start_date;
@@ -0,0 +1,36 @@
+-- a promotion runs from start_date until end_date (open ended when NULL) while
+-- it is active, the apps show the running ones by display_order
+ALTER TABLE promotions
+    ADD COLUMN IF NOT EXISTS start_date DATE NOT NULL DEFAULT CURRENT_DATE,
+    ADD COLUMN IF NOT EXISTS end_date DATE,
+    ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS display_order INT NOT NULL DEFAULT 0,
+    ADD CONSTRAINT promotions_end_date_check CHECK (end_date IS NULL OR end_date >= start_date);
+
+-- the existing promotions have been running since they were created
+UPDATE promotions SET start_date = created_at::date;
+
+-- a promotion without target branches or potencies targets all of them
+CREATE TABLE IF NOT EXISTS promotion_branches (
+    promotion_id CHAR(26) NOT NULL,
+    branch_id CHAR(26) NOT NULL,
+
+    PRIMARY KEY (promotion_id, branch_id),
+    FOREIGN KEY (promotion_id) REFERENCES promotions (id) ON DELETE CASCADE,
+    FOREIGN KEY (branch_id) REFERENCES branches (id)
+);
+
+CREATE TABLE IF NOT EXISTS promotion_potencies (
+    promotion_id CHAR(26) NOT NULL,
+    potency_id CHAR(26) NOT NULL,
+
+    PRIMARY KEY (promotion_id, potency_id),
+    FOREIGN KEY (promotion_id) REFERENCES promotions (id) ON DELETE CASCADE,
+    FOREIGN KEY (potency_id) REFERENCES potencies (id)
+);
+
+CREATE INDEX IF NOT EXISTS promotions_display_order_idx ON promotions (display_order) WHERE deleted_at IS NULL;
+
+-- the promotion the advisor attached to the offering of the WAC
+ALTER TABLE walk_around_checks
+    ADD COLUMN IF NOT EXISTS promotion_id CHAR(26) REFERENCES promotions (id) ON DELETE SET NULL;
@@ -0,0 +1,18 @@
+-- the impressions and clicks of the promotions, a user counts once per
+-- promotion, type and day in Asia/Makassar, branch_id is the branch of the user
+-- at the time
+CREATE TABLE IF NOT EXISTS promotion_events (
+    promotion_id CHAR(26) NOT NULL,
+    user_id CHAR(26) NOT NULL,
+    type VARCHAR(16) NOT NULL CHECK (type IN ('impression', 'click')),
+    day DATE NOT NULL,
+    branch_id CHAR(26),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (promotion_id, user_id, type, day),
+    FOREIGN KEY (promotion_id) REFERENCES promotions (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (branch_id) REFERENCES branches (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS promotion_events_day_idx ON promotion_events (day);
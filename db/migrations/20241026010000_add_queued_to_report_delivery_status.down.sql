@@ -0,0 +1,3 @@
+-- an enum value can not be dropped, the queued deliveries are only counted as
+-- sent the way they were before
+UPDATE report_deliveries SET status = 'sent', finished_at = COALESCE(finished_at, NOW()) WHERE status = 'queued';
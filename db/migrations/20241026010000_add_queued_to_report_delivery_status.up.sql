@@ -0,0 +1,3 @@
+-- a delivery is queued once its email job is published, the email consumer
+-- marks it sent or failed
+ALTER TYPE report_delivery_status ADD VALUE IF NOT EXISTS 'queued' AFTER 'processing';
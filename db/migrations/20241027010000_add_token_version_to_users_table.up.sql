@@ -0,0 +1,7 @@
+-- the tokens carry the version of their user, a password change bumps it and
+-- revokes the tokens issued before, even within the same second
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INT NOT NULL DEFAULT 0;
+
+-- the tokens last 12 hours and the ones issued so far have no version, those
+-- of a recent password change are revoked and their users log in again
+UPDATE users SET token_version = 1 WHERE password_changed_at > NOW() - INTERVAL '12 hours';
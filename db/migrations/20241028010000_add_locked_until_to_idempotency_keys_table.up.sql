@@ -0,0 +1,3 @@
+-- a key is leased while its first request is processed, a key whose lease
+-- passed without a stored response (the process died) can be reserved again
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;
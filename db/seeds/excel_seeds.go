@@ -1,6 +1,7 @@
 package seeds
 
 import (
+	tradeInEntity "codebase-app/internal/module/tradein/entity"
 	"strconv"
 	"strings"
 
@@ -692,7 +693,23 @@ func (s *excelSeed) SeedUsers(tx *sqlx.Tx) error {
 	return nil
 }
 
+// SeedTradeInTrends fills the price list in effect today while it has no
+// trends, the initial one of a new database. A published list is never
+// changed, new prices are uploaded to a draft list and published.
 func (s *excelSeed) SeedTradeInTrends(tx *sqlx.Tx) error {
+	var seeded bool
+
+	err := tx.Get(&seeded, `SELECT EXISTS (SELECT 1 FROM trade_in_trends WHERE price_list_id = `+tradeInEntity.CurrentPriceList+`)`)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check the current price list")
+		return err
+	}
+
+	if seeded {
+		log.Info().Msg("hi tread in trends already seeded, the current price list is left as it is")
+		return nil
+	}
+
 	rows, err := s.file.GetRows("hi_trade_in")
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get rows from excel")
@@ -729,12 +746,13 @@ func (s *excelSeed) SeedTradeInTrends(tx *sqlx.Tx) error {
 			return err
 		}
 
-		// on conflict do update min_purchase and max_purchase
+		// the seed goes to the price list in effect today, a row repeated in
+		// the sheet updates min_purchase and max_purchase
 		query := `
-			INSERT INTO trade_in_trends (brand, model, type, year, min_purchase, max_purchase)
-			VALUES (?, ?, ?, ?, ?, ?)
-			ON CONFLICT (brand, model, type, year)
-			DO UPDATE SET min_purchase = ?, max_purchase = ?, created_at = NOW()
+			INSERT INTO trade_in_trends (price_list_id, brand, model, type, year, min_purchase, max_purchase)
+			VALUES (` + tradeInEntity.CurrentPriceList + `, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (price_list_id, brand, model, type, year)
+			DO UPDATE SET min_purchase = ?, max_purchase = ?, updated_at = NOW()
 			`
 		_, err = tx.Exec(s.db.Rebind(query),
 			brand, model, type_, year, minPurchase, maxPurchase,
@@ -3,6 +3,7 @@ module codebase-app
 go 1.22.0
 
 require (
+	github.com/DATA-DOG/go-sqlmock v1.5.2
 	github.com/LukaGiorgadze/gonull v1.2.0
 	github.com/brianvoe/gofakeit/v7 v7.0.2
 	github.com/dropbox/dropbox-sdk-go-unofficial/v6 v6.0.5
@@ -31,6 +31,13 @@ type Config struct {
 		MaxIdleCons       int `env:"DB_MAX_IdLE_CONS" env-default:"20" env-description:"database max idle conn in seconds"`
 		ConnMaxLifetime   int `env:"DB_CONN_MAX_LIFETIME" env-default:"0" env-description:"database conn max lifetime in seconds"`
 	}
+	Idempotency struct {
+		TTLHours    int `env:"IDEMPOTENCY_TTL_HOURS" env-default:"24" env-description:"hours an idempotency key replays its response"`
+		LockSeconds int `env:"IDEMPOTENCY_LOCK_SECONDS" env-default:"60" env-description:"seconds an idempotency key stays reserved for a request in progress"`
+	}
+	Trash struct {
+		RetentionDays int `env:"TRASH_RETENTION_DAYS" env-default:"30" env-description:"days a soft deleted row is kept before it is purged"`
+	}
 	Guard struct {
 		JwtPrivateKey   string `env:"JWT_PRIVATE_KEY"`
 		JwtPrivateKeyWs string `env:"JWT_PRIVATE_KEY_WS"`
@@ -0,0 +1,47 @@
+package integration
+
+import (
+	"codebase-app/internal/integration/oauth2google/entity"
+	"context"
+	"errors"
+	"net/url"
+
+	"golang.org/x/oauth2"
+)
+
+var ErrFakeInvalidCode = errors.New("fake oauth2google: invalid authorization code")
+
+var _ Oauth2googleContract = &fakeOauth2google{}
+
+// fakeOauth2google is an offline stand-in for Google used in tests and local
+// development. Every code listed in users is accepted and resolves to its user info.
+type fakeOauth2google struct {
+	users map[string]entity.UserInfoResponse
+}
+
+func NewFakeOauth2googleIntegration(users map[string]entity.UserInfoResponse) Oauth2googleContract {
+	return &fakeOauth2google{
+		users: users,
+	}
+}
+
+func (f *fakeOauth2google) GetUrl(state string, opts ...oauth2.AuthCodeOption) string {
+	return "https://accounts.google.test/o/oauth2/auth?state=" + url.QueryEscape(state)
+}
+
+func (f *fakeOauth2google) Exchange(ctx context.Context, code string) (*oauth2.Token, error) {
+	if _, ok := f.users[code]; !ok {
+		return nil, ErrFakeInvalidCode
+	}
+
+	return &oauth2.Token{AccessToken: code, TokenType: "Bearer"}, nil
+}
+
+func (f *fakeOauth2google) GetUserInfo(ctx context.Context, token *oauth2.Token) (entity.UserInfoResponse, error) {
+	info, ok := f.users[token.AccessToken]
+	if !ok {
+		return info, ErrFakeInvalidCode
+	}
+
+	return info, nil
+}
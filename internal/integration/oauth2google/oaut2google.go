@@ -34,7 +34,7 @@ func NewOauth2googleIntegration(clientID, clientSecret, redirectURL string) Oaut
 }
 
 func (o *ouath2google) GetUrl(state string, opts ...oauth2.AuthCodeOption) string {
-	return o.cfg.AuthCodeURL("state", opts...)
+	return o.cfg.AuthCodeURL(state, opts...)
 }
 
 func (o *ouath2google) Exchange(ctx context.Context, code string) (*oauth2.Token, error) {
@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"codebase-app/internal/adapter"
 	"codebase-app/pkg/jwthandler"
+	"context"
+	"database/sql"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog/log"
@@ -32,9 +35,47 @@ func AuthBearer(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusUnauthorized).JSON(unauthorizedResponse)
 	}
 
+	if isTokenRevoked(c.Context(), claims) {
+		log.Warn().Str("user_id", claims.UserId).Msg("middleware::AuthMiddleware - Token has been revoked")
+		return c.Status(fiber.StatusUnauthorized).JSON(unauthorizedResponse)
+	}
+
 	c.Locals("user_id", claims.UserId)
 	c.Locals("role", claims.Role)
 
 	// If the token is valid, pass the request to the next handler
 	return c.Next()
 }
+
+// AuthBearerOptional authenticates the request like AuthBearer when it has the
+// Authorization header, the visitors without one pass through without locals.
+func AuthBearerOptional(c *fiber.Ctx) error {
+	if c.Get("Authorization") == "" {
+		return c.Next()
+	}
+
+	return AuthBearer(c)
+}
+
+// isTokenRevoked reports whether the token was issued before the user's last
+// password change or role move, its version is then behind the user's, or the
+// user no longer exists. The version is read on every request so a revoked
+// token is refused at once.
+func isTokenRevoked(ctx context.Context, claims *jwthandler.CustomClaims) bool {
+	var (
+		db      = adapter.Adapters.DigihubPostgres
+		version int
+	)
+
+	query := `SELECT token_version FROM users WHERE id = ? AND deleted_at IS NULL`
+
+	err := db.GetContext(ctx, &version, db.Rebind(query), claims.UserId)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Error().Err(err).Str("user_id", claims.UserId).Msg("middleware::isTokenRevoked - failed to get user")
+		}
+		return true
+	}
+
+	return claims.TokenVersion < version
+}
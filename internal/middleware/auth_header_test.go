@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/infrastructure/config"
+	"codebase-app/pkg/jwthandler"
+	"database/sql"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAuthApp(t *testing.T) (*fiber.App, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	adapter.Adapters = &adapter.Adapter{DigihubPostgres: sqlx.NewDb(db, "postgres")}
+	config.Envs = &config.Config{}
+	config.Envs.Guard.JwtPrivateKey = "secret"
+
+	app := fiber.New()
+	app.Get("/me", AuthBearer, func(c *fiber.Ctx) error {
+		return c.SendString(c.Locals("user_id").(string))
+	})
+
+	return app, mock
+}
+
+func bearer(t *testing.T, version int) string {
+	t.Helper()
+
+	token, err := jwthandler.GenerateTokenString(jwthandler.CostumClaimsPayload{
+		UserId:          "user",
+		Role:            "admin",
+		TokenVersion:    version,
+		TokenExpiration: time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return "Bearer " + token
+}
+
+func expectTokenVersion(mock sqlmock.Sqlmock, version int) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT token_version FROM users WHERE id = $1 AND deleted_at IS NULL")).
+		WithArgs("user").
+		WillReturnRows(sqlmock.NewRows([]string{"token_version"}).AddRow(version))
+}
+
+func getMe(t *testing.T, app *fiber.App, version int) int {
+	t.Helper()
+
+	req := httptest.NewRequest(fiber.MethodGet, "/me", nil)
+	req.Header.Set(fiber.HeaderAuthorization, bearer(t, version))
+
+	res, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return res.StatusCode
+}
+
+func TestAuthBearerCurrentVersion(t *testing.T) {
+	app, mock := newAuthApp(t)
+	expectTokenVersion(mock, 2)
+
+	assert.Equal(t, fiber.StatusOK, getMe(t, app, 2))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthBearerRejectsOldTokenAfterBump(t *testing.T) {
+	app, mock := newAuthApp(t)
+
+	// the version is read on every request, the bump between them refuses
+	// the old token on the very next one
+	expectTokenVersion(mock, 1)
+	expectTokenVersion(mock, 2)
+	expectTokenVersion(mock, 2)
+
+	assert.Equal(t, fiber.StatusOK, getMe(t, app, 1))
+	assert.Equal(t, fiber.StatusUnauthorized, getMe(t, app, 1))
+	assert.Equal(t, fiber.StatusOK, getMe(t, app, 2))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthBearerDeletedUser(t *testing.T) {
+	app, mock := newAuthApp(t)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT token_version FROM users")).
+		WithArgs("user").
+		WillReturnError(sql.ErrNoRows)
+
+	assert.Equal(t, fiber.StatusUnauthorized, getMe(t, app, 1))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/infrastructure/config"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotencyReplayed = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+type idempotencyRecord struct {
+	RequestHash  string  `db:"request_hash"`
+	StatusCode   *int    `db:"status_code"`
+	ContentType  *string `db:"content_type"`
+	ResponseBody []byte  `db:"response_body"`
+}
+
+// Idempotency replays the stored response of a retried request that carries
+// the same Idempotency-Key header, a key is scoped to the user and must run
+// after AuthBearer. A request without the header is processed as usual.
+//
+//   - the key is reserved before the handler runs, a concurrent retry gets 409
+//   - the reservation is a lease of IDEMPOTENCY_LOCK_SECONDS, when it passed
+//     without a stored response (the process died) a retry reserves the key
+//     again, the lease must outlast the slowest request
+//   - the same key with another method, path or body gets 409
+//   - a 5xx response is not stored so the request can be retried
+func Idempotency(c *fiber.Ctx) error {
+	key := c.Get(HeaderIdempotencyKey)
+	if key == "" {
+		return c.Next()
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Idempotency-Key maksimal 255 karakter",
+			"success": false,
+		})
+	}
+
+	var (
+		ctx    = c.Context()
+		db     = adapter.Adapters.DigihubPostgres
+		userId = GetLocals(c).UserId
+		ttl    = time.Duration(config.Envs.Idempotency.TTLHours) * time.Hour
+		lease  = time.Duration(config.Envs.Idempotency.LockSeconds) * time.Second
+	)
+
+	hash := sha256.New()
+	hash.Write([]byte(c.Method() + " " + c.Path() + "\n"))
+	hash.Write(c.Body())
+	requestHash := hex.EncodeToString(hash.Sum(nil))
+
+	// expired keys of the user are dropped so their keys can be reused
+	query := `DELETE FROM idempotency_keys WHERE user_id = ? AND expires_at < NOW()`
+	if _, err := db.ExecContext(ctx, db.Rebind(query), userId); err != nil {
+		log.Error().Err(err).Str("key", key).Msg("middleware::Idempotency - failed to delete expired keys")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Internal server error",
+			"success": false,
+		})
+	}
+
+	// the lease tells this reservation apart from a later one, the key is
+	// stored or released only while the reservation still holds it
+	lockedUntil := time.Now().Add(lease).Truncate(time.Microsecond)
+
+	query = `
+		INSERT INTO idempotency_keys (user_id, key, request_hash, expires_at, locked_until)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, key) DO UPDATE
+		SET expires_at = EXCLUDED.expires_at, locked_until = EXCLUDED.locked_until
+		WHERE
+			idempotency_keys.status_code IS NULL
+			AND idempotency_keys.request_hash = EXCLUDED.request_hash
+			AND (idempotency_keys.locked_until IS NULL OR idempotency_keys.locked_until < NOW())
+	`
+	result, err := db.ExecContext(ctx, db.Rebind(query), userId, key, requestHash, time.Now().Add(ttl), lockedUntil)
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("middleware::Idempotency - failed to reserve key")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Internal server error",
+			"success": false,
+		})
+	}
+
+	if reserved, _ := result.RowsAffected(); reserved == 0 {
+		return replayIdempotent(c, userId, key, requestHash)
+	}
+
+	// a failed or panicking request releases the key
+	stored := false
+	defer func() {
+		if stored {
+			return
+		}
+
+		query := `
+			DELETE FROM idempotency_keys
+			WHERE user_id = ? AND key = ? AND status_code IS NULL AND locked_until = ?
+		`
+		if _, errDelete := db.ExecContext(ctx, db.Rebind(query), userId, key, lockedUntil); errDelete != nil {
+			log.Error().Err(errDelete).Str("key", key).Msg("middleware::Idempotency - failed to release key")
+		}
+	}()
+
+	if err = c.Next(); err != nil {
+		return err
+	}
+
+	status := c.Response().StatusCode()
+	if status >= fiber.StatusInternalServerError {
+		return nil
+	}
+
+	query = `
+		UPDATE idempotency_keys
+		SET status_code = ?, content_type = ?, response_body = ?, locked_until = NULL
+		WHERE user_id = ? AND key = ? AND locked_until = ?
+	`
+	_, err = db.ExecContext(ctx, db.Rebind(query),
+		status, string(c.Response().Header.ContentType()), c.Response().Body(), userId, key, lockedUntil)
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("middleware::Idempotency - failed to store response")
+		return nil
+	}
+
+	stored = true
+	return nil
+}
+
+func replayIdempotent(c *fiber.Ctx, userId, key, requestHash string) error {
+	var (
+		db     = adapter.Adapters.DigihubPostgres
+		record idempotencyRecord
+	)
+
+	query := `
+		SELECT request_hash, status_code, content_type, response_body
+		FROM idempotency_keys
+		WHERE user_id = ? AND key = ?
+	`
+	if err := db.GetContext(c.Context(), &record, db.Rebind(query), userId, key); err != nil {
+		log.Error().Err(err).Str("key", key).Msg("middleware::Idempotency - failed to get key")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Internal server error",
+			"success": false,
+		})
+	}
+
+	if record.RequestHash != requestHash {
+		log.Warn().Str("key", key).Str("path", c.Path()).Msg("middleware::Idempotency - key reused with another request")
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": "Idempotency-Key sudah digunakan untuk permintaan lain",
+			"success": false,
+		})
+	}
+
+	if record.StatusCode == nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"message": "Permintaan dengan Idempotency-Key yang sama sedang diproses",
+			"success": false,
+		})
+	}
+
+	if record.ContentType != nil {
+		c.Set(fiber.HeaderContentType, *record.ContentType)
+	}
+	c.Set(HeaderIdempotencyReplayed, "true")
+
+	return c.Status(*record.StatusCode).Send(record.ResponseBody)
+}
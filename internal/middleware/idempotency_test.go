@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/infrastructure/config"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+const idempotencyTestBody = `{"name":"Budi"}`
+
+func newIdempotencyApp(t *testing.T, handler fiber.Handler) (*fiber.App, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	adapter.Adapters = &adapter.Adapter{DigihubPostgres: sqlx.NewDb(db, "postgres")}
+	config.Envs = &config.Config{}
+	config.Envs.Idempotency.TTLHours = 24
+	config.Envs.Idempotency.LockSeconds = 60
+
+	app := fiber.New()
+	app.Post("/wacs", func(c *fiber.Ctx) error {
+		c.Locals("user_id", "user")
+		return c.Next()
+	}, Idempotency, handler)
+
+	return app, mock
+}
+
+func newIdempotentRequest(body string) *http.Request {
+	req := httptest.NewRequest(fiber.MethodPost, "/wacs", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	req.Header.Set(HeaderIdempotencyKey, "key")
+
+	return req
+}
+
+func requestHash(body string) string {
+	sum := sha256.Sum256([]byte("POST /wacs\n" + body))
+	return hex.EncodeToString(sum[:])
+}
+
+func expectReserve(mock sqlmock.Sqlmock, reserved int64) {
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM idempotency_keys WHERE user_id = $1 AND expires_at < NOW()")).
+		WithArgs("user").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO idempotency_keys")).
+		WithArgs("user", "key", requestHash(idempotencyTestBody), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, reserved))
+}
+
+func expectRecord(mock sqlmock.Sqlmock, hash string, status any) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT request_hash, status_code, content_type, response_body")).
+		WithArgs("user", "key").
+		WillReturnRows(sqlmock.NewRows([]string{"request_hash", "status_code", "content_type", "response_body"}).
+			AddRow(hash, status, fiber.MIMEApplicationJSON, []byte(`{"success":true}`)))
+}
+
+func created(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"success": true})
+}
+
+func TestIdempotencyStoresResponse(t *testing.T) {
+	app, mock := newIdempotencyApp(t, created)
+	expectReserve(mock, 1)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE idempotency_keys")).
+		WithArgs(fiber.StatusCreated, fiber.MIMEApplicationJSON, sqlmock.AnyArg(), "user", "key", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	res, err := app.Test(newIdempotentRequest(idempotencyTestBody))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, res.StatusCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	called := false
+	app, mock := newIdempotencyApp(t, func(c *fiber.Ctx) error {
+		called = true
+		return created(c)
+	})
+	expectReserve(mock, 0)
+	expectRecord(mock, requestHash(idempotencyTestBody), fiber.StatusCreated)
+
+	res, err := app.Test(newIdempotentRequest(idempotencyTestBody))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, res.StatusCode)
+	assert.Equal(t, "true", res.Header.Get(HeaderIdempotencyReplayed))
+	assert.False(t, called)
+
+	body, _ := io.ReadAll(res.Body)
+	assert.Equal(t, `{"success":true}`, string(body))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyMismatchedBody(t *testing.T) {
+	app, mock := newIdempotencyApp(t, created)
+	expectReserve(mock, 0)
+	expectRecord(mock, requestHash(`{"name":"Andi"}`), fiber.StatusCreated)
+
+	res, err := app.Test(newIdempotentRequest(idempotencyTestBody))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusConflict, res.StatusCode)
+	assert.Empty(t, res.Header.Get(HeaderIdempotencyReplayed))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyReleasesKeyOnServerError(t *testing.T) {
+	app, mock := newIdempotencyApp(t, func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"success": false})
+	})
+	expectReserve(mock, 1)
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM idempotency_keys")).
+		WithArgs("user", "key", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	res, err := app.Test(newIdempotentRequest(idempotencyTestBody))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusInternalServerError, res.StatusCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyConcurrentReservation(t *testing.T) {
+	var (
+		entered = make(chan struct{})
+		release = make(chan struct{})
+		done    = make(chan int)
+	)
+
+	app, mock := newIdempotencyApp(t, func(c *fiber.Ctx) error {
+		close(entered)
+		<-release
+		return created(c)
+	})
+
+	// the first request holds the key while the second one arrives
+	expectReserve(mock, 1)
+	expectReserve(mock, 0)
+	expectRecord(mock, requestHash(idempotencyTestBody), nil)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE idempotency_keys")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	go func() {
+		res, err := app.Test(newIdempotentRequest(idempotencyTestBody), -1)
+		if err != nil {
+			done <- 0
+			return
+		}
+		done <- res.StatusCode
+	}()
+
+	<-entered
+	res, err := app.Test(newIdempotentRequest(idempotencyTestBody), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusConflict, res.StatusCode)
+
+	close(release)
+	assert.Equal(t, fiber.StatusCreated, <-done)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,6 +1,15 @@
 package entity
 
-import "codebase-app/pkg/types"
+import (
+	"codebase-app/pkg"
+	"codebase-app/pkg/types"
+	"codebase-app/pkg/validator"
+	"time"
+
+	sqlxtypes "github.com/jmoiron/sqlx/types"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+)
 
 type GetClientsRequest struct {
 	Search   string `query:"search" validate:"omitempty,min=3"`
@@ -24,9 +33,279 @@ type GetClientsResponse struct {
 }
 
 type Client struct {
-	Id          string `json:"id" db:"id"`
-	Name        string `json:"name" db:"name"`
-	VLicense    string `json:"vehicle_license_number" db:"vehicle_license_number"`
-	VehicleType string `json:"vehicle_type" db:"vehicle_type"`
-	Phone       string `json:"phone" db:"phone"`
+	Id                    string         `json:"id" db:"id"`
+	Name                  string         `json:"name" db:"name"`
+	VehicleLicenseNumbers pq.StringArray `json:"vehicle_license_numbers" db:"vehicle_license_numbers"`
+	Phone                 string         `json:"phone" db:"phone"`
+}
+
+// DeleteClientRequest moves a client to the trash, a client with walk around
+// checks is kept.
+type DeleteClientRequest struct {
+	Id string `params:"id" validate:"ulid"`
+}
+
+type CreateClientRequest struct {
+	Name     string           `json:"name" validate:"required,max=255"`
+	Phone    string           `json:"phone" validate:"required,id_phone"`
+	Vehicles []VehicleRequest `json:"vehicles" validate:"omitempty,max=20,dive"`
+}
+
+func (r *CreateClientRequest) Normalize() {
+	r.Name = pkg.NormalizeName(r.Name)
+	r.Phone = validator.FormatPhone(r.Phone)
+
+	for i := range r.Vehicles {
+		r.Vehicles[i].Normalize()
+	}
+}
+
+// VehicleRequest is a vehicle of a customer, a plate belongs to one vehicle
+// only.
+type VehicleRequest struct {
+	LicenseNumber string  `json:"license_number" validate:"required,id_plate"`
+	VehicleTypeId string  `json:"vehicle_type_id" validate:"required,ulid,exist=vehicle_types.id"`
+	Vin           *string `json:"vin" validate:"omitempty,len=17,alphanum"`
+	Year          *int    `json:"year" validate:"omitempty,min=1900,max=2100"`
+	Colour        *string `json:"colour" validate:"omitempty,max=50"`
+}
+
+func (r *VehicleRequest) Normalize() {
+	r.LicenseNumber = validator.FormatPlate(r.LicenseNumber)
+}
+
+type AddVehicleRequest struct {
+	ClientId string `params:"id" validate:"ulid"`
+
+	VehicleRequest
+}
+
+// UpdateVehicleRequest corrects a vehicle, client_id moves the vehicle to
+// its new owner, nil fields are left untouched.
+type UpdateVehicleRequest struct {
+	ClientId  string `params:"id" validate:"ulid"`
+	VehicleId string `params:"vehicle_id" validate:"ulid"`
+
+	NewClientId   *string `json:"client_id" validate:"omitempty,ulid,exist=clients.id"`
+	LicenseNumber *string `json:"license_number" validate:"omitempty,id_plate"`
+	VehicleTypeId *string `json:"vehicle_type_id" validate:"omitempty,ulid,exist=vehicle_types.id"`
+	Vin           *string `json:"vin" validate:"omitempty,len=17,alphanum"`
+	Year          *int    `json:"year" validate:"omitempty,min=1900,max=2100"`
+	Colour        *string `json:"colour" validate:"omitempty,max=50"`
+}
+
+func (r *UpdateVehicleRequest) Normalize() {
+	if r.LicenseNumber != nil {
+		plate := validator.FormatPlate(*r.LicenseNumber)
+		r.LicenseNumber = &plate
+	}
+}
+
+// UpdateClientRequest corrects the customer data, nil fields are left
+// untouched.
+type UpdateClientRequest struct {
+	Id string `params:"id" validate:"ulid"`
+
+	Name  *string `json:"name" validate:"omitempty,min=1,max=255"`
+	Phone *string `json:"phone" validate:"omitempty,id_phone"`
+}
+
+func (r *UpdateClientRequest) Normalize() {
+	if r.Name != nil {
+		name := pkg.NormalizeName(*r.Name)
+		r.Name = &name
+	}
+
+	if r.Phone != nil {
+		phone := validator.FormatPhone(*r.Phone)
+		r.Phone = &phone
+	}
+}
+
+/*
+Client 360 - Start
+  - The customer with the vehicles it owns and every walk around check that is
+    not deleted, the most recent first, a walk around check may be of a
+    vehicle the customer no longer owns
+  - Offered conditions are all the conditions of the potency, accepted the
+    ones the customer is interested in
+  - Revenue is the revenue of the completed walk around checks, follow up
+    attempts are the entries of the follow up logs
+  - Last contact is the latest walk around check or follow up
+*/
+type GetClientRequest struct {
+	Id string `params:"id" validate:"ulid"`
+}
+
+type GetClientResponse struct {
+	Id        string    `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Phone     string    `json:"phone" db:"phone"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	Summary   ClientSummary   `json:"summary" db:"-"`
+	Vehicles  []ClientVehicle `json:"vehicles" db:"-"`
+	Potencies []ClientPotency `json:"potencies" db:"-"`
+	WACs      []ClientWAC     `json:"wacs" db:"-"`
+}
+
+type ClientSummary struct {
+	TotalWACs          int             `json:"total_wacs" db:"total_wacs"`
+	TotalCompletedWACs int             `json:"total_completed_wacs" db:"total_completed_wacs"`
+	TotalRevenue       decimal.Decimal `json:"total_revenue" db:"total_revenue"`
+	FollowUpAttempts   int             `json:"follow_up_attempts" db:"follow_up_attempts"`
+	LastContactAt      *time.Time      `json:"last_contact_at" db:"last_contact_at"`
+}
+
+type ClientVehicle struct {
+	Id            string  `json:"id" db:"id"`
+	LicenseNumber string  `json:"license_number" db:"license_number"`
+	VehicleTypeId string  `json:"vehicle_type_id" db:"vehicle_type_id"`
+	VehicleType   string  `json:"vehicle_type" db:"vehicle_type"`
+	Vin           *string `json:"vin" db:"vin"`
+	Year          *int    `json:"year" db:"year"`
+	Colour        *string `json:"colour" db:"colour"`
+	LastMileage   *int    `json:"last_mileage" db:"last_mileage"`
+}
+
+type ClientPotency struct {
+	PotencyId     string          `json:"potency_id" db:"potency_id"`
+	PotencyName   string          `json:"potency_name" db:"potency_name"`
+	TotalOffered  int             `json:"total_offered" db:"total_offered"`
+	TotalAccepted int             `json:"total_accepted" db:"total_accepted"`
+	Revenue       decimal.Decimal `json:"revenue" db:"revenue"`
+}
+
+type ClientWAC struct {
+	Id                   string          `json:"id" db:"id"`
+	Status               string          `json:"status" db:"status"`
+	VehicleId            string          `json:"vehicle_id" db:"vehicle_id"`
+	VehicleLicenseNumber string          `json:"vehicle_license_number" db:"vehicle_license_number"`
+	Mileage              *int            `json:"mileage" db:"mileage"`
+	BranchName           string          `json:"branch_name" db:"branch_name"`
+	ServiceAdvisorName   string          `json:"service_advisor_name" db:"service_advisor_name"`
+	IsUsedCar            bool            `json:"is_used_car" db:"is_used_car"`
+	TotalPotentialLeads  int             `json:"total_potential_leads" db:"total_potential_leads"`
+	TotalLeads           int             `json:"total_leads" db:"total_leads"`
+	TotalLeadsCompleted  int             `json:"total_leads_completed" db:"total_leads_completed"`
+	FollowUpAttempts     int             `json:"follow_up_attempts" db:"follow_up_attempts"`
+	Revenue              decimal.Decimal `json:"revenue" db:"revenue"`
+	FollowUpAt           *time.Time      `json:"follow_up_at" db:"follow_up_at"`
+	CreatedAt            time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// SummarizeClient totals the walk around checks of a customer, the last
+// contact is the latest walk around check or follow up.
+func SummarizeClient(wacs []ClientWAC, lastFollowUpAt *time.Time) ClientSummary {
+	summary := ClientSummary{
+		TotalWACs:     len(wacs),
+		TotalRevenue:  decimal.Zero,
+		LastContactAt: lastFollowUpAt,
+	}
+
+	for _, w := range wacs {
+		if w.Status == "completed" {
+			summary.TotalCompletedWACs++
+		}
+
+		summary.TotalRevenue = summary.TotalRevenue.Add(w.Revenue)
+		summary.FollowUpAttempts += w.FollowUpAttempts
+
+		if summary.LastContactAt == nil || w.CreatedAt.After(*summary.LastContactAt) {
+			createdAt := w.CreatedAt
+			summary.LastContactAt = &createdAt
+		}
+	}
+
+	return summary
+}
+
+/*
+Duplicate Clients - Start
+  - Pairs of customers that may be the same person, a pair is listed when the
+    names or two of their plates are similar (trigram similarity of at least
+    min_similarity) or when the phones are the same
+  - Plates are compared without spaces, name case is ignored
+  - score is the average of the name similarity, the best plate similarity
+    and 1 for the same phone, the most likely duplicates first
+*/
+type GetDuplicatesRequest struct {
+	MinSimilarity float64 `query:"min_similarity" validate:"min=0.1,max=1"`
+	Page          int     `query:"page" validate:"required"`
+	Paginate      int     `query:"paginate" validate:"required,max=100"`
+}
+
+func (r *GetDuplicatesRequest) SetDefault() {
+	if r.MinSimilarity == 0 {
+		r.MinSimilarity = 0.5
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+
+	if r.Page < 1 {
+		r.Page = 1
+	}
+}
+
+type GetDuplicatesResponse struct {
+	Items []DuplicateCandidate `json:"items"`
+	Meta  types.Meta           `json:"meta"`
+}
+
+type DuplicateCandidate struct {
+	Client          Client  `json:"client"`
+	Duplicate       Client  `json:"duplicate"`
+	NameSimilarity  float64 `json:"name_similarity"`
+	PlateSimilarity float64 `json:"plate_similarity"`
+	SamePhone       bool    `json:"same_phone"`
+	Score           float64 `json:"score"`
+}
+
+/*
+Merge Clients - Start
+  - Moves every walk around check and vehicle of client_id to the client of
+    the path, client_id is then moved to the trash
+  - Each merge is kept in the audit trail with the merged client as it was
+*/
+type MergeClientRequest struct {
+	UserId   string
+	Id       string `params:"id" validate:"ulid"`
+	SourceId string `json:"client_id" validate:"required,ulid,nefield=Id"`
+}
+
+type GetMergesRequest struct {
+	ClientId string `query:"client_id" validate:"omitempty,ulid"`
+	Page     int    `query:"page" validate:"required"`
+	Paginate int    `query:"paginate" validate:"required,max=100"`
+}
+
+func (r *GetMergesRequest) SetDefault() {
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+
+	if r.Page < 1 {
+		r.Page = 1
+	}
+}
+
+type GetMergesResponse struct {
+	Items []ClientMerge `json:"items"`
+	Meta  types.Meta    `json:"meta"`
+}
+
+type ClientMerge struct {
+	Id             string             `json:"id" db:"id"`
+	TargetClientId string             `json:"target_client_id" db:"target_client_id"`
+	SourceClientId string             `json:"source_client_id" db:"source_client_id"`
+	SourceSnapshot sqlxtypes.JSONText `json:"source_snapshot" db:"source_snapshot"`
+	MovedWACs      int                `json:"moved_wacs" db:"moved_wacs"`
+	MovedVehicles  int                `json:"moved_vehicles" db:"moved_vehicles"`
+	UserId         string             `json:"user_id" db:"user_id"`
+	UserName       string             `json:"user_name" db:"user_name"`
+	CreatedAt      time.Time          `json:"created_at" db:"created_at"`
 }
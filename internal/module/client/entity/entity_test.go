@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeClient(t *testing.T) {
+	var (
+		first  = time.Date(2024, 10, 1, 9, 0, 0, 0, time.UTC)
+		second = time.Date(2024, 10, 10, 9, 0, 0, 0, time.UTC)
+		follow = time.Date(2024, 10, 12, 9, 0, 0, 0, time.UTC)
+	)
+
+	wacs := []ClientWAC{
+		{Status: "completed", Revenue: decimal.RequireFromString("150000.50"), FollowUpAttempts: 2, CreatedAt: second},
+		{Status: "offered", Revenue: decimal.Zero, CreatedAt: first},
+	}
+
+	summary := SummarizeClient(wacs, &follow)
+	assert.Equal(t, 2, summary.TotalWACs)
+	assert.Equal(t, 1, summary.TotalCompletedWACs)
+	assert.Equal(t, "150000.5", summary.TotalRevenue.String())
+	assert.Equal(t, 2, summary.FollowUpAttempts)
+	assert.True(t, follow.Equal(*summary.LastContactAt))
+
+	summary = SummarizeClient(wacs, nil)
+	assert.True(t, second.Equal(*summary.LastContactAt))
+
+	summary = SummarizeClient(nil, nil)
+	assert.Nil(t, summary.LastContactAt)
+	assert.True(t, summary.TotalRevenue.IsZero())
+}
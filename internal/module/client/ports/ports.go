@@ -7,8 +7,28 @@ import (
 
 type ClientRepository interface {
 	GetClients(ctx context.Context, req *entity.GetClientsRequest) (entity.GetClientsResponse, error)
+	DeleteClient(ctx context.Context, req *entity.DeleteClientRequest) error
+	GetClient(ctx context.Context, req *entity.GetClientRequest) (entity.GetClientResponse, error)
+	CreateClient(ctx context.Context, req *entity.CreateClientRequest) (string, error)
+	UpdateClient(ctx context.Context, req *entity.UpdateClientRequest) error
+	AddVehicle(ctx context.Context, req *entity.AddVehicleRequest) error
+	UpdateVehicle(ctx context.Context, req *entity.UpdateVehicleRequest) error
+	GetDuplicates(ctx context.Context, req *entity.GetDuplicatesRequest) (entity.GetDuplicatesResponse, error)
+	MergeClient(ctx context.Context, req *entity.MergeClientRequest) error
+	GetMerges(ctx context.Context, req *entity.GetMergesRequest) (entity.GetMergesResponse, error)
 }
 
 type ClientService interface {
 	GetClients(ctx context.Context, req *entity.GetClientsRequest) (entity.GetClientsResponse, error)
+	DeleteClient(ctx context.Context, req *entity.DeleteClientRequest) error
+	GetClient(ctx context.Context, req *entity.GetClientRequest) (entity.GetClientResponse, error)
+	CreateClient(ctx context.Context, req *entity.CreateClientRequest) (entity.GetClientResponse, error)
+	UpdateClient(ctx context.Context, req *entity.UpdateClientRequest) (entity.GetClientResponse, error)
+	AddVehicle(ctx context.Context, req *entity.AddVehicleRequest) (entity.GetClientResponse, error)
+	// UpdateVehicle returns the owner of the vehicle after the update.
+	UpdateVehicle(ctx context.Context, req *entity.UpdateVehicleRequest) (entity.GetClientResponse, error)
+	GetDuplicates(ctx context.Context, req *entity.GetDuplicatesRequest) (entity.GetDuplicatesResponse, error)
+	// MergeClient returns the surviving client after the merge.
+	MergeClient(ctx context.Context, req *entity.MergeClientRequest) (entity.GetClientResponse, error)
+	GetMerges(ctx context.Context, req *entity.GetMergesRequest) (entity.GetMergesResponse, error)
 }
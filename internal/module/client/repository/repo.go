@@ -4,9 +4,16 @@ import (
 	"codebase-app/internal/adapter"
 	"codebase-app/internal/module/client/entity"
 	"codebase-app/internal/module/client/ports"
+	wacEntity "codebase-app/internal/module/wac/entity"
+	"codebase-app/pkg/errmsg"
 	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/oklog/ulid/v2"
 	"github.com/rs/zerolog/log"
 )
 
@@ -40,18 +47,28 @@ func (r *clientRepository) GetClients(ctx context.Context, req *entity.GetClient
 			COUNT(*) OVER() AS total_data,
 			c.id,
 			c.name,
-			c.vehicle_license_number,
-			vt.name AS vehicle_type,
+			ARRAY(
+				SELECT v.license_number
+				FROM vehicles v
+				WHERE v.client_id = c.id AND v.deleted_at IS NULL
+				ORDER BY v.created_at
+			) AS vehicle_license_numbers,
 			c.phone
 		FROM clients c
-		LEFT JOIN
-			vehicle_types vt ON vt.id = c.vehicle_type_id
 		WHERE
-			1 = 1
+			c.deleted_at IS NULL
 	`
 
 	if req.Search != "" {
-		query += ` AND (c.name ILIKE ? OR c.vehicle_license_number ILIKE ? OR c.phone ILIKE ?) `
+		query += ` AND (
+			c.name ILIKE ?
+			OR EXISTS (
+				SELECT 1
+				FROM vehicles v
+				WHERE v.client_id = c.id AND v.deleted_at IS NULL AND v.license_number ILIKE ?
+			)
+			OR c.phone ILIKE ?
+		) `
 		args = append(args, "%"+req.Search+"%", "%"+req.Search+"%", "%"+req.Search+"%")
 	}
 
@@ -74,3 +91,472 @@ func (r *clientRepository) GetClients(ctx context.Context, req *entity.GetClient
 	res.Meta.CountTotalPage(req.Page, req.Paginate, res.Meta.TotalData)
 	return res, nil
 }
+
+// DeleteClient soft deletes the client, a client with walk around checks that
+// are not deleted is kept.
+func (r *clientRepository) DeleteClient(ctx context.Context, req *entity.DeleteClientRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteClient - failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::DeleteClient - failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::DeleteClient - failed to commit transaction")
+		}
+	}()
+
+	query := `
+		UPDATE clients
+		SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = ? AND deleted_at IS NULL
+	`
+	result, err := tx.ExecContext(ctx, r.db.Rebind(query), req.Id)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteClient - failed to delete client")
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteClient - failed to get affected rows")
+		return err
+	}
+
+	if affected == 0 {
+		log.Warn().Any("payload", req).Msg("repo::DeleteClient - client not found")
+		err = errmsg.NewCustomErrors(404, errmsg.WithMessage("Klien tidak ditemukan"))
+		return err
+	}
+
+	// the update locks the client, a new walk around check waits for it
+	var hasWACs bool
+	query = `SELECT EXISTS (SELECT 1 FROM walk_around_checks WHERE client_id = ? AND deleted_at IS NULL)`
+	if err = tx.GetContext(ctx, &hasWACs, r.db.Rebind(query), req.Id); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteClient - failed to check walk around checks")
+		return err
+	}
+
+	if hasWACs {
+		log.Warn().Any("payload", req).Msg("repo::DeleteClient - client has walk around checks")
+		err = errmsg.NewCustomErrors(400, errmsg.WithMessage("Klien masih memiliki walk around check"))
+		return err
+	}
+
+	return nil
+}
+
+func (r *clientRepository) GetClient(ctx context.Context, req *entity.GetClientRequest) (entity.GetClientResponse, error) {
+	var res entity.GetClientResponse
+
+	query := `
+		SELECT
+			c.id,
+			c.name,
+			c.phone,
+			c.created_at,
+			c.updated_at
+		FROM clients c
+		WHERE
+			c.id = ? AND c.deleted_at IS NULL
+	`
+	if err := r.db.GetContext(ctx, &res, r.db.Rebind(query), req.Id); err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn().Any("payload", req).Msg("repo::GetClient - client not found")
+			return res, errmsg.NewCustomErrors(404, errmsg.WithMessage("Klien tidak ditemukan"))
+		}
+
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetClient - failed to get client")
+		return res, err
+	}
+
+	res.Vehicles = make([]entity.ClientVehicle, 0)
+	query = `
+		SELECT
+			v.id,
+			v.license_number,
+			v.vehicle_type_id,
+			COALESCE(vt.name, '') AS vehicle_type,
+			v.vin,
+			v.year,
+			v.colour,
+			(
+				SELECT wac.mileage
+				FROM walk_around_checks wac
+				WHERE wac.vehicle_id = v.id AND wac.deleted_at IS NULL AND wac.mileage IS NOT NULL
+				ORDER BY wac.created_at DESC
+				LIMIT 1
+			) AS last_mileage
+		FROM vehicles v
+		LEFT JOIN
+			vehicle_types vt ON vt.id = v.vehicle_type_id
+		WHERE
+			v.client_id = ? AND v.deleted_at IS NULL
+		ORDER BY v.created_at
+	`
+	if err := r.db.SelectContext(ctx, &res.Vehicles, r.db.Rebind(query), req.Id); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetClient - failed to get vehicles")
+		return res, err
+	}
+
+	res.WACs = make([]entity.ClientWAC, 0)
+	query = `
+		SELECT
+			wac.id,
+			wac.status,
+			wac.vehicle_id,
+			v.license_number AS vehicle_license_number,
+			wac.mileage,
+			COALESCE(b.name, '') AS branch_name,
+			COALESCE(u.name, '') AS service_advisor_name,
+			wac.is_used_car,
+			wac.total_potential_leads,
+			wac.total_leads,
+			wac.total_leads_completed,
+			(
+				SELECT COUNT(*)
+				FROM wac_follow_up_logs fl
+				WHERE fl.walk_around_check_id = wac.id AND fl.deleted_at IS NULL
+			) AS follow_up_attempts,
+			CASE WHEN wac.status = 'completed' THEN wac.revenue ELSE 0 END AS revenue,
+			wac.follow_up_at,
+			wac.created_at,
+			wac.updated_at
+		FROM walk_around_checks wac
+		JOIN
+			vehicles v ON v.id = wac.vehicle_id
+		LEFT JOIN
+			branches b ON b.id = wac.branch_id
+		LEFT JOIN
+			users u ON u.id = wac.user_id
+		WHERE
+			wac.client_id = ? AND wac.deleted_at IS NULL
+		ORDER BY wac.created_at DESC
+	`
+	if err := r.db.SelectContext(ctx, &res.WACs, r.db.Rebind(query), req.Id); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetClient - failed to get walk around checks")
+		return res, err
+	}
+
+	res.Potencies = make([]entity.ClientPotency, 0)
+	query = `
+		SELECT
+			p.id AS potency_id,
+			p.name AS potency_name,
+			COUNT(*) AS total_offered,
+			COUNT(*) FILTER (WHERE wacc.is_interested = TRUE) AS total_accepted,
+			COALESCE(SUM(wacc.revenue) FILTER (
+				WHERE wac.status = 'completed' AND wacc.invoice_number IS NOT NULL
+			), 0) AS revenue
+		FROM walk_around_check_conditions wacc
+		JOIN
+			walk_around_checks wac ON wac.id = wacc.walk_around_check_id
+		JOIN
+			potencies p ON p.id = wacc.potency_id
+		WHERE
+			wac.client_id = ?
+			AND wac.deleted_at IS NULL
+			AND wacc.deleted_at IS NULL
+		GROUP BY p.id, p.name
+		ORDER BY p.name
+	`
+	if err := r.db.SelectContext(ctx, &res.Potencies, r.db.Rebind(query), req.Id); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetClient - failed to get potencies")
+		return res, err
+	}
+
+	var lastFollowUpAt *time.Time
+	query = `
+		SELECT MAX(fl.created_at)
+		FROM wac_follow_up_logs fl
+		JOIN
+			walk_around_checks wac ON wac.id = fl.walk_around_check_id
+		WHERE
+			wac.client_id = ?
+			AND wac.deleted_at IS NULL
+			AND fl.deleted_at IS NULL
+	`
+	if err := r.db.GetContext(ctx, &lastFollowUpAt, r.db.Rebind(query), req.Id); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetClient - failed to get last follow up")
+		return res, err
+	}
+
+	res.Summary = entity.SummarizeClient(res.WACs, lastFollowUpAt)
+
+	return res, nil
+}
+
+// CreateClient creates a customer with its vehicles, a plate that belongs to
+// another vehicle is rejected.
+func (r *clientRepository) CreateClient(ctx context.Context, req *entity.CreateClientRequest) (id string, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::CreateClient - failed to begin transaction")
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::CreateClient - failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::CreateClient - failed to commit transaction")
+		}
+	}()
+
+	id = ulid.Make().String()
+	query := `INSERT INTO clients (id, name, phone) VALUES (?, ?, ?)`
+	if _, err = tx.ExecContext(ctx, r.db.Rebind(query), id, req.Name, req.Phone); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::CreateClient - failed to create client")
+		return "", err
+	}
+
+	errs := errmsg.NewCustomErrors(409, errmsg.WithMessage("Nomor polisi sudah terdaftar"))
+	for i, v := range req.Vehicles {
+		taken, errTaken := r.isPlateTaken(ctx, tx, v.LicenseNumber, "")
+		if errTaken != nil {
+			err = errTaken
+			log.Error().Err(err).Any("payload", req).Msg("repo::CreateClient - failed to check plate")
+			return "", err
+		}
+
+		if taken || isPlateRepeated(req.Vehicles[:i], v.LicenseNumber) {
+			errs.Add(fmt.Sprintf("vehicles[%d].license_number", i), "Nomor polisi sudah terdaftar")
+		}
+	}
+
+	if errs.HasErrors() {
+		log.Warn().Any("payload", req).Msg("repo::CreateClient - plate already registered")
+		err = errs
+		return "", err
+	}
+
+	for _, v := range req.Vehicles {
+		if err = r.insertVehicle(ctx, tx, id, &v); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::CreateClient - failed to create vehicle")
+			return "", err
+		}
+	}
+
+	return id, nil
+}
+
+func (r *clientRepository) UpdateClient(ctx context.Context, req *entity.UpdateClientRequest) error {
+	query := `
+		UPDATE clients
+		SET
+			name = COALESCE(?, name),
+			phone = COALESCE(?, phone),
+			updated_at = NOW()
+		WHERE id = ? AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), req.Name, req.Phone, req.Id)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateClient - failed to update client")
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateClient - failed to get affected rows")
+		return err
+	}
+
+	if affected == 0 {
+		log.Warn().Any("payload", req).Msg("repo::UpdateClient - client not found")
+		return errmsg.NewCustomErrors(404, errmsg.WithMessage("Klien tidak ditemukan"))
+	}
+
+	return nil
+}
+
+func (r *clientRepository) AddVehicle(ctx context.Context, req *entity.AddVehicleRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::AddVehicle - failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::AddVehicle - failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::AddVehicle - failed to commit transaction")
+		}
+	}()
+
+	if err = r.lockClient(ctx, tx, req.ClientId); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("repo::AddVehicle - failed to lock client")
+		return err
+	}
+
+	taken, err := r.isPlateTaken(ctx, tx, req.LicenseNumber, "")
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::AddVehicle - failed to check plate")
+		return err
+	}
+
+	if taken {
+		log.Warn().Any("payload", req).Msg("repo::AddVehicle - plate already registered")
+		err = errmsg.NewCustomErrors(409, errmsg.WithMessage("Nomor polisi sudah terdaftar"))
+		return err
+	}
+
+	if err = r.insertVehicle(ctx, tx, req.ClientId, &req.VehicleRequest); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::AddVehicle - failed to create vehicle")
+		return err
+	}
+
+	return nil
+}
+
+// UpdateVehicle corrects a vehicle of the client, the walk around checks of
+// the vehicle stay with the client they were made for.
+func (r *clientRepository) UpdateVehicle(ctx context.Context, req *entity.UpdateVehicleRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateVehicle - failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::UpdateVehicle - failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::UpdateVehicle - failed to commit transaction")
+		}
+	}()
+
+	if req.LicenseNumber != nil {
+		taken, errTaken := r.isPlateTaken(ctx, tx, *req.LicenseNumber, req.VehicleId)
+		if errTaken != nil {
+			err = errTaken
+			log.Error().Err(err).Any("payload", req).Msg("repo::UpdateVehicle - failed to check plate")
+			return err
+		}
+
+		if taken {
+			log.Warn().Any("payload", req).Msg("repo::UpdateVehicle - plate already registered")
+			err = errmsg.NewCustomErrors(409, errmsg.WithMessage("Nomor polisi sudah terdaftar"))
+			return err
+		}
+	}
+
+	if req.NewClientId != nil {
+		if err = r.lockClient(ctx, tx, *req.NewClientId); err != nil {
+			log.Warn().Err(err).Any("payload", req).Msg("repo::UpdateVehicle - failed to lock new client")
+			return err
+		}
+	}
+
+	query := `
+		UPDATE vehicles
+		SET
+			client_id = COALESCE(?, client_id),
+			license_number = COALESCE(?, license_number),
+			vehicle_type_id = COALESCE(?, vehicle_type_id),
+			vin = COALESCE(?, vin),
+			year = COALESCE(?, year),
+			colour = COALESCE(?, colour),
+			updated_at = NOW()
+		WHERE
+			id = ?
+			AND client_id = ?
+			AND deleted_at IS NULL
+			AND EXISTS (SELECT 1 FROM clients c WHERE c.id = ? AND c.deleted_at IS NULL)
+	`
+	result, err := tx.ExecContext(ctx, r.db.Rebind(query),
+		req.NewClientId, req.LicenseNumber, req.VehicleTypeId, req.Vin, req.Year, req.Colour,
+		req.VehicleId, req.ClientId, req.ClientId)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateVehicle - failed to update vehicle")
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateVehicle - failed to get affected rows")
+		return err
+	}
+
+	if affected == 0 {
+		log.Warn().Any("payload", req).Msg("repo::UpdateVehicle - vehicle not found")
+		err = errmsg.NewCustomErrors(404, errmsg.WithMessage("Kendaraan tidak ditemukan"))
+		return err
+	}
+
+	return nil
+}
+
+// lockClient locks a client that is not deleted so it is not deleted while
+// a vehicle is given to it.
+func (r *clientRepository) lockClient(ctx context.Context, tx *sqlx.Tx, id string) error {
+	var locked []string
+	query := `SELECT id FROM clients WHERE id = ? AND deleted_at IS NULL FOR UPDATE`
+	if err := tx.SelectContext(ctx, &locked, r.db.Rebind(query), id); err != nil {
+		return err
+	}
+
+	if len(locked) == 0 {
+		return errmsg.NewCustomErrors(404, errmsg.WithMessage("Klien tidak ditemukan"))
+	}
+
+	return nil
+}
+
+// isPlateTaken reports whether another vehicle than exceptId has the plate.
+func (r *clientRepository) isPlateTaken(ctx context.Context, tx *sqlx.Tx, plate, exceptId string) (bool, error) {
+	var taken bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM vehicles v
+			WHERE ` + wacEntity.PlateMatch + ` AND v.deleted_at IS NULL AND v.id <> ?
+		)
+	`
+	err := tx.GetContext(ctx, &taken, r.db.Rebind(query), plate, exceptId)
+	return taken, err
+}
+
+func (r *clientRepository) insertVehicle(ctx context.Context, tx *sqlx.Tx, clientId string, v *entity.VehicleRequest) error {
+	query := `
+		INSERT INTO vehicles (id, client_id, vehicle_type_id, license_number, vin, year, colour)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := tx.ExecContext(ctx, r.db.Rebind(query),
+		ulid.Make().String(), clientId, v.VehicleTypeId, v.LicenseNumber, v.Vin, v.Year, v.Colour)
+	return err
+}
+
+// isPlateRepeated reports whether the plate is already among the vehicles.
+func isPlateRepeated(vehicles []entity.VehicleRequest, plate string) bool {
+	normalize := func(s string) string {
+		return strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+	}
+
+	for _, v := range vehicles {
+		if normalize(v.LicenseNumber) == normalize(plate) {
+			return true
+		}
+	}
+
+	return false
+}
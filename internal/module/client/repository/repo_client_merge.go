@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"codebase-app/internal/module/client/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/lib/pq"
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// normalizedPlate is the plate of the vehicle v without spaces, the way the
+// trigram index of the vehicles compares it.
+const normalizedPlate = `UPPER(REPLACE(v.license_number, ' ', ''))`
+
+func (r *clientRepository) GetDuplicates(ctx context.Context, req *entity.GetDuplicatesRequest) (res entity.GetDuplicatesResponse, err error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+
+		ClientId     string         `db:"client_id"`
+		ClientName   string         `db:"client_name"`
+		ClientPhone  string         `db:"client_phone"`
+		ClientPlates pq.StringArray `db:"client_plates"`
+
+		DuplicateId     string         `db:"duplicate_id"`
+		DuplicateName   string         `db:"duplicate_name"`
+		DuplicatePhone  string         `db:"duplicate_phone"`
+		DuplicatePlates pq.StringArray `db:"duplicate_plates"`
+
+		NameSimilarity  float64 `db:"name_similarity"`
+		PlateSimilarity float64 `db:"plate_similarity"`
+		SamePhone       bool    `db:"same_phone"`
+		Score           float64 `db:"score"`
+	}
+
+	var data = make([]dao, 0)
+	res.Items = make([]entity.DuplicateCandidate, 0)
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetDuplicates - failed to begin transaction")
+		return res, err
+	}
+	defer func() {
+		if errRollback := tx.Rollback(); errRollback != nil && errRollback != sql.ErrTxDone {
+			log.Error().Err(errRollback).Any("payload", req).Msg("repo::GetDuplicates - failed to rollback transaction")
+		}
+	}()
+
+	// the % operator compares with the threshold, it is reset with the transaction
+	query := `SELECT set_config('pg_trgm.similarity_threshold', ?, true)`
+	threshold := strconv.FormatFloat(req.MinSimilarity, 'f', 2, 64)
+	if _, err = tx.ExecContext(ctx, r.db.Rebind(query), threshold); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetDuplicates - failed to set similarity threshold")
+		return res, err
+	}
+
+	query = `
+		WITH pairs AS (
+			SELECT a.id AS a_id, b.id AS b_id
+			FROM clients a
+			JOIN
+				clients b ON a.id < b.id AND LOWER(a.name) % LOWER(b.name)
+			WHERE a.deleted_at IS NULL AND b.deleted_at IS NULL
+			UNION
+			SELECT a.id, b.id
+			FROM clients a
+			JOIN
+				clients b ON a.id < b.id AND a.phone = b.phone
+			WHERE a.deleted_at IS NULL AND b.deleted_at IS NULL AND a.phone <> ''
+			UNION
+			SELECT LEAST(v.client_id, w.client_id), GREATEST(v.client_id, w.client_id)
+			FROM vehicles v
+			JOIN
+				vehicles w ON w.client_id <> v.client_id
+				AND ` + normalizedPlate + ` % UPPER(REPLACE(w.license_number, ' ', ''))
+			WHERE v.deleted_at IS NULL AND w.deleted_at IS NULL
+		), scored AS (
+			SELECT
+				a.id AS client_id,
+				a.name AS client_name,
+				a.phone AS client_phone,
+				b.id AS duplicate_id,
+				b.name AS duplicate_name,
+				b.phone AS duplicate_phone,
+				similarity(LOWER(a.name), LOWER(b.name)) AS name_similarity,
+				COALESCE((
+					SELECT MAX(similarity(` + normalizedPlate + `, UPPER(REPLACE(w.license_number, ' ', ''))))
+					FROM vehicles v
+					JOIN
+						vehicles w ON w.client_id = b.id AND w.deleted_at IS NULL
+					WHERE v.client_id = a.id AND v.deleted_at IS NULL
+				), 0) AS plate_similarity,
+				a.phone = b.phone AS same_phone
+			FROM pairs p
+			JOIN
+				clients a ON a.id = p.a_id AND a.deleted_at IS NULL
+			JOIN
+				clients b ON b.id = p.b_id AND b.deleted_at IS NULL
+		)
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			s.*,
+			(s.name_similarity + s.plate_similarity + CASE WHEN s.same_phone THEN 1 ELSE 0 END) / 3 AS score,
+			ARRAY(
+				SELECT v.license_number FROM vehicles v
+				WHERE v.client_id = s.client_id AND v.deleted_at IS NULL
+				ORDER BY v.created_at
+			) AS client_plates,
+			ARRAY(
+				SELECT v.license_number FROM vehicles v
+				WHERE v.client_id = s.duplicate_id AND v.deleted_at IS NULL
+				ORDER BY v.created_at
+			) AS duplicate_plates
+		FROM scored s
+		ORDER BY score DESC, s.client_id, s.duplicate_id
+		LIMIT ? OFFSET ?
+	`
+	err = tx.SelectContext(ctx, &data, r.db.Rebind(query), req.Paginate, (req.Page-1)*req.Paginate)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetDuplicates - failed to get duplicates")
+		return res, err
+	}
+
+	for _, d := range data {
+		res.Items = append(res.Items, entity.DuplicateCandidate{
+			Client: entity.Client{
+				Id:                    d.ClientId,
+				Name:                  d.ClientName,
+				Phone:                 d.ClientPhone,
+				VehicleLicenseNumbers: d.ClientPlates,
+			},
+			Duplicate: entity.Client{
+				Id:                    d.DuplicateId,
+				Name:                  d.DuplicateName,
+				Phone:                 d.DuplicatePhone,
+				VehicleLicenseNumbers: d.DuplicatePlates,
+			},
+			NameSimilarity:  d.NameSimilarity,
+			PlateSimilarity: d.PlateSimilarity,
+			SamePhone:       d.SamePhone,
+			Score:           d.Score,
+		})
+	}
+
+	if len(res.Items) > 0 {
+		res.Meta.TotalData = data[0].TotalData
+	}
+
+	res.Meta.CountTotalPage(req.Page, req.Paginate, res.Meta.TotalData)
+	return res, nil
+}
+
+// MergeClient moves the walk around checks and vehicles of the source client
+// to the client of the request, trashes the source and records the merge.
+func (r *clientRepository) MergeClient(ctx context.Context, req *entity.MergeClientRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::MergeClient - failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::MergeClient - failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::MergeClient - failed to commit transaction")
+		}
+	}()
+
+	// both are locked in the same order so two opposite merges do not deadlock
+	var locked []string
+	query := `
+		SELECT id
+		FROM clients
+		WHERE id IN (?, ?) AND deleted_at IS NULL
+		ORDER BY id
+		FOR UPDATE
+	`
+	if err = tx.SelectContext(ctx, &locked, r.db.Rebind(query), req.Id, req.SourceId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::MergeClient - failed to lock clients")
+		return err
+	}
+
+	if len(locked) < 2 {
+		log.Warn().Any("payload", req).Msg("repo::MergeClient - client not found")
+		err = errmsg.NewCustomErrors(404, errmsg.WithMessage("Klien tidak ditemukan"))
+		return err
+	}
+
+	var snapshot string
+	query = `
+		SELECT JSON_BUILD_OBJECT(
+			'id', c.id,
+			'name', c.name,
+			'phone', c.phone,
+			'created_at', c.created_at,
+			'vehicle_ids', ARRAY(SELECT v.id FROM vehicles v WHERE v.client_id = c.id),
+			'wac_ids', ARRAY(SELECT wac.id FROM walk_around_checks wac WHERE wac.client_id = c.id)
+		)
+		FROM clients c
+		WHERE c.id = ?
+	`
+	if err = tx.GetContext(ctx, &snapshot, r.db.Rebind(query), req.SourceId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::MergeClient - failed to get source client")
+		return err
+	}
+
+	// deleted walk around checks and vehicles move too so a restore finds them
+	// with the surviving client
+	moved := make([]int64, 0, 2)
+	for _, q := range []string{
+		`UPDATE walk_around_checks SET client_id = ?, updated_at = NOW() WHERE client_id = ?`,
+		`UPDATE vehicles SET client_id = ?, updated_at = NOW() WHERE client_id = ?`,
+	} {
+		result, errUpdate := tx.ExecContext(ctx, r.db.Rebind(q), req.Id, req.SourceId)
+		if errUpdate != nil {
+			err = errUpdate
+			log.Error().Err(err).Any("payload", req).Msg("repo::MergeClient - failed to move to target client")
+			return err
+		}
+
+		affected, errAffected := result.RowsAffected()
+		if errAffected != nil {
+			err = errAffected
+			log.Error().Err(err).Any("payload", req).Msg("repo::MergeClient - failed to get affected rows")
+			return err
+		}
+
+		moved = append(moved, affected)
+	}
+
+	query = `UPDATE clients SET deleted_at = NOW(), updated_at = NOW() WHERE id = ?`
+	if _, err = tx.ExecContext(ctx, r.db.Rebind(query), req.SourceId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::MergeClient - failed to delete source client")
+		return err
+	}
+
+	query = `
+		INSERT INTO client_merges
+			(id, target_client_id, source_client_id, user_id, source_snapshot, moved_wacs, moved_vehicles)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.ExecContext(ctx, r.db.Rebind(query),
+		ulid.Make().String(), req.Id, req.SourceId, req.UserId, snapshot, moved[0], moved[1])
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::MergeClient - failed to record merge")
+		return err
+	}
+
+	return nil
+}
+
+func (r *clientRepository) GetMerges(ctx context.Context, req *entity.GetMergesRequest) (entity.GetMergesResponse, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.ClientMerge
+	}
+
+	var (
+		data = make([]dao, 0)
+		args = make([]any, 0, 4)
+		res  = entity.GetMergesResponse{}
+	)
+	res.Items = make([]entity.ClientMerge, 0)
+
+	query := `
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			cm.id,
+			cm.target_client_id,
+			cm.source_client_id,
+			cm.source_snapshot,
+			cm.moved_wacs,
+			cm.moved_vehicles,
+			cm.user_id,
+			COALESCE(u.name, '') AS user_name,
+			cm.created_at
+		FROM client_merges cm
+		LEFT JOIN
+			users u ON u.id = cm.user_id
+		WHERE 1 = 1
+	`
+
+	if req.ClientId != "" {
+		query += ` AND (cm.target_client_id = ? OR cm.source_client_id = ?)`
+		args = append(args, req.ClientId, req.ClientId)
+	}
+
+	query += ` ORDER BY cm.created_at DESC, cm.id DESC LIMIT ? OFFSET ?`
+	args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetMerges - failed to get merges")
+		return res, err
+	}
+
+	for _, d := range data {
+		res.Items = append(res.Items, d.ClientMerge)
+	}
+
+	if len(res.Items) > 0 {
+		res.Meta.TotalData = data[0].TotalData
+	}
+
+	res.Meta.CountTotalPage(req.Page, req.Paginate, res.Meta.TotalData)
+	return res, nil
+}
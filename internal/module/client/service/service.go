@@ -21,3 +21,65 @@ func NewClientService(repo ports.ClientRepository) *clientService {
 func (s *clientService) GetClients(ctx context.Context, req *entity.GetClientsRequest) (entity.GetClientsResponse, error) {
 	return s.repo.GetClients(ctx, req)
 }
+
+func (s *clientService) DeleteClient(ctx context.Context, req *entity.DeleteClientRequest) error {
+	return s.repo.DeleteClient(ctx, req)
+}
+
+func (s *clientService) GetClient(ctx context.Context, req *entity.GetClientRequest) (entity.GetClientResponse, error) {
+	return s.repo.GetClient(ctx, req)
+}
+
+func (s *clientService) CreateClient(ctx context.Context, req *entity.CreateClientRequest) (entity.GetClientResponse, error) {
+	id, err := s.repo.CreateClient(ctx, req)
+	if err != nil {
+		return entity.GetClientResponse{}, err
+	}
+
+	return s.repo.GetClient(ctx, &entity.GetClientRequest{Id: id})
+}
+
+func (s *clientService) UpdateClient(ctx context.Context, req *entity.UpdateClientRequest) (entity.GetClientResponse, error) {
+	if err := s.repo.UpdateClient(ctx, req); err != nil {
+		return entity.GetClientResponse{}, err
+	}
+
+	return s.repo.GetClient(ctx, &entity.GetClientRequest{Id: req.Id})
+}
+
+func (s *clientService) AddVehicle(ctx context.Context, req *entity.AddVehicleRequest) (entity.GetClientResponse, error) {
+	if err := s.repo.AddVehicle(ctx, req); err != nil {
+		return entity.GetClientResponse{}, err
+	}
+
+	return s.repo.GetClient(ctx, &entity.GetClientRequest{Id: req.ClientId})
+}
+
+func (s *clientService) UpdateVehicle(ctx context.Context, req *entity.UpdateVehicleRequest) (entity.GetClientResponse, error) {
+	if err := s.repo.UpdateVehicle(ctx, req); err != nil {
+		return entity.GetClientResponse{}, err
+	}
+
+	ownerId := req.ClientId
+	if req.NewClientId != nil {
+		ownerId = *req.NewClientId
+	}
+
+	return s.repo.GetClient(ctx, &entity.GetClientRequest{Id: ownerId})
+}
+
+func (s *clientService) GetDuplicates(ctx context.Context, req *entity.GetDuplicatesRequest) (entity.GetDuplicatesResponse, error) {
+	return s.repo.GetDuplicates(ctx, req)
+}
+
+func (s *clientService) MergeClient(ctx context.Context, req *entity.MergeClientRequest) (entity.GetClientResponse, error) {
+	if err := s.repo.MergeClient(ctx, req); err != nil {
+		return entity.GetClientResponse{}, err
+	}
+
+	return s.repo.GetClient(ctx, &entity.GetClientRequest{Id: req.Id})
+}
+
+func (s *clientService) GetMerges(ctx context.Context, req *entity.GetMergesRequest) (entity.GetMergesResponse, error) {
+	return s.repo.GetMerges(ctx, req)
+}
@@ -4,4 +4,5 @@ type Tier struct {
 	Key       string `json:"key"`
 	Name      string `json:"name"`
 	Threshold int    `json:"threshold"`
+	Period    string `json:"period"`
 }
@@ -1,5 +1,13 @@
 package entity
 
+import (
+	tradeInEntity "codebase-app/internal/module/tradein/entity"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
 type GetHTIBrandsRequest struct {
 }
 
@@ -45,3 +53,62 @@ type GetHTIPurchaseResponse struct {
 	MinPurchase int `db:"min_purchase" json:"min_purchase"`
 	MaxPurchase int `db:"max_purchase" json:"max_purchase"`
 }
+
+/*
+Trade In Search - Start
+  - One free text search over the brand, model, type and year of the price
+    list in effect, e.g. "avanza 1.3 g 2019"
+  - The words are matched as prefixes with the full text index, a misspelled
+    word still matches on trigram similarity, the best matches come first
+  - A word that is a model year filters the year instead
+*/
+type SearchHTIRequest struct {
+	Keyword  string `query:"keyword" validate:"required,min=2,max=100"`
+	Page     int    `query:"page" validate:"required"`
+	Paginate int    `query:"paginate" validate:"required,max=50"`
+}
+
+func (r *SearchHTIRequest) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+// Tokens splits the keyword into the words to match and the model years, a
+// word without a letter nor a digit is dropped.
+func (r *SearchHTIRequest) Tokens(now time.Time) (words []string, years []int) {
+	for _, token := range strings.Fields(strings.ToLower(r.Keyword)) {
+		if !strings.ContainsFunc(token, func(c rune) bool { return unicode.IsLetter(c) || unicode.IsDigit(c) }) {
+			continue
+		}
+
+		if year, err := strconv.Atoi(token); err == nil && len(token) == 4 &&
+			year >= tradeInEntity.MinYear && year <= now.Year()+1 {
+			years = append(years, year)
+			continue
+		}
+
+		words = append(words, token)
+	}
+
+	return words, years
+}
+
+type SearchHTIResponse struct {
+	Items []HTISearchItem `json:"items"`
+	Meta  Meta            `json:"meta"`
+}
+
+type HTISearchItem struct {
+	Brand       string  `json:"brand" db:"brand"`
+	Model       string  `json:"model" db:"model"`
+	Type        string  `json:"type" db:"type"`
+	Year        int     `json:"year" db:"year"`
+	MinPurchase int     `json:"min_purchase" db:"min_purchase"`
+	MaxPurchase int     `json:"max_purchase" db:"max_purchase"`
+	Score       float64 `json:"score" db:"score"`
+}
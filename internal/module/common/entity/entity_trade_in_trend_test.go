@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchHTIRequestTokens(t *testing.T) {
+	now := time.Date(2024, 10, 23, 0, 0, 0, 0, time.UTC)
+
+	req := SearchHTIRequest{Keyword: "  Avanza 1.3  G - 2019 2030 1234"}
+	words, years := req.Tokens(now)
+	assert.Equal(t, []string{"avanza", "1.3", "g", "2030", "1234"}, words)
+	assert.Equal(t, []int{2019}, years)
+
+	req.Keyword = "2024 2025"
+	words, years = req.Tokens(now)
+	assert.Empty(t, words)
+	assert.Equal(t, []int{2024, 2025}, years)
+}
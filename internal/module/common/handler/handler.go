@@ -7,6 +7,10 @@ import (
 	"codebase-app/internal/module/common/ports"
 	"codebase-app/internal/module/common/repository"
 	"codebase-app/internal/module/common/service"
+	tierEntity "codebase-app/internal/module/tier/entity"
+	tierPorts "codebase-app/internal/module/tier/ports"
+	tierRepository "codebase-app/internal/module/tier/repository"
+	tierService "codebase-app/internal/module/tier/service"
 	"codebase-app/pkg/errmsg"
 	"codebase-app/pkg/response"
 
@@ -16,6 +20,7 @@ import (
 
 type commonHandler struct {
 	service ports.CommonService
+	tiers   tierPorts.TierService
 }
 
 func NewCommonHandler() *commonHandler {
@@ -26,6 +31,7 @@ func NewCommonHandler() *commonHandler {
 	)
 
 	handler.service = service
+	handler.tiers = tierService.NewTierService(tierRepository.NewTierRepository())
 	return handler
 }
 
@@ -46,6 +52,7 @@ func (h *commonHandler) Register(router fiber.Router) {
 	master.Get("/hi-trade-in/years", h.GetHTIYears)
 	master.Get("/hi-trade-in/purchases", h.GetHTIPurchases)
 	master.Get("/hi-trade-in/valuations", h.GetHTIvaluations)
+	master.Get("/hi-trade-in/search", h.SearchHTI)
 
 	master.Post("/branches", m.AuthRole([]string{"admin"}), h.CreateBranch)
 	master.Put("/branches/:id", m.AuthRole([]string{"admin"}), h.UpdateBranch)
@@ -306,26 +313,30 @@ func (h *commonHandler) GetRoles(c *fiber.Ctx) error {
 }
 
 func (h *commonHandler) GetTiers(c *fiber.Ctx) error {
-	var result []entity.Tier
-
-	result = append(result,
-		entity.Tier{
-			Key:       "platinum",
-			Name:      "Platinum",
-			Threshold: 1_500_000,
-		},
-		entity.Tier{
-			Key:       "gold",
-			Name:      "Gold",
-			Threshold: 1_000_000,
-		},
-		entity.Tier{
-			Key:       "silver",
-			Name:      "Silver",
-			Threshold: 500_000,
-		},
+	var (
+		req = new(tierEntity.GetActiveTiersRequest)
+		ctx = c.Context()
+		l   = m.GetLocals(c)
 	)
 
+	req.UserId = l.GetUserId()
+
+	tiers, err := h.tiers.GetActiveTiers(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	result := make([]entity.Tier, 0, len(tiers))
+	for _, t := range tiers {
+		result = append(result, entity.Tier{
+			Key:       t.Key,
+			Name:      t.Name,
+			Threshold: int(t.Threshold.IntPart()),
+			Period:    t.Period,
+		})
+	}
+
 	return c.JSON(response.Success(result, ""))
 }
 
@@ -384,3 +395,32 @@ func (h *commonHandler) UpdateBranch(c *fiber.Ctx) error {
 
 	return c.JSON(response.Success(nil, "Cabang berhasil diperbarui"))
 }
+
+func (h *commonHandler) SearchHTI(c *fiber.Ctx) error {
+	var (
+		req = new(entity.SearchHTIRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Error().Err(err).Msg("handler::SearchHTI - Failed to parse request")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("handler::SearchHTI - Invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	result, err := h.service.SearchHTI(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(result, ""))
+}
@@ -20,6 +20,7 @@ type CommonRepository interface {
 	GetHTIYears(ctx context.Context, req *entity.GetHTIYearsRequest) ([]entity.CommonResponse, error)
 	GetHTIPurchase(ctx context.Context, req *entity.GetHTIPurchaseRequest) (entity.GetHTIPurchaseResponse, error)
 	GetHTIValuations(ctx context.Context, req *entity.GetHTIValuationsRequest) (entity.GetHTIValuationsResponse, error)
+	SearchHTI(ctx context.Context, req *entity.SearchHTIRequest) (entity.SearchHTIResponse, error)
 
 	CreateBranch(ctx context.Context, req *entity.CreateBranchRequest) error
 	UpdateBranch(ctx context.Context, req *entity.UpdateBranchRequest) error
@@ -40,6 +41,7 @@ type CommonService interface {
 	GetHTIYears(ctx context.Context, req *entity.GetHTIYearsRequest) ([]entity.CommonResponse, error)
 	GetHTIPurchase(ctx context.Context, req *entity.GetHTIPurchaseRequest) (entity.GetHTIPurchaseResponse, error)
 	GetHTIValuations(ctx context.Context, req *entity.GetHTIValuationsRequest) (entity.GetHTIValuationsResponse, error)
+	SearchHTI(ctx context.Context, req *entity.SearchHTIRequest) (entity.SearchHTIResponse, error)
 
 	CreateBranch(ctx context.Context, req *entity.CreateBranchRequest) error
 	UpdateBranch(ctx context.Context, req *entity.UpdateBranchRequest) error
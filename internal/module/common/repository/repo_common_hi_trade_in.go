@@ -2,19 +2,27 @@ package repository
 
 import (
 	"codebase-app/internal/module/common/entity"
+	tradeInEntity "codebase-app/internal/module/tradein/entity"
+	"codebase-app/pkg"
 	"codebase-app/pkg/errmsg"
 	"context"
 	"database/sql"
+	"strings"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/rs/zerolog/log"
 )
 
+// The trade in catalogue of the app is the price list in effect today.
 func (r *commonRepo) GetHTIBrands(ctx context.Context) ([]entity.CommonResponse, error) {
 	query := `
 		SELECT DISTINCT
 			brand as name
 		FROM
 			trade_in_trends
+		WHERE
+			price_list_id = ` + tradeInEntity.CurrentPriceList + `
 		ORDER BY
 			brand ASC
 		`
@@ -37,7 +45,7 @@ func (r *commonRepo) GetHTIModels(ctx context.Context, req *entity.GetHTIModelsR
 		FROM
 			trade_in_trends
 		WHERE
-			brand = ?
+			price_list_id = ` + tradeInEntity.CurrentPriceList + ` AND brand = ?
 		ORDER BY
 			model ASC
 		`
@@ -60,7 +68,7 @@ func (r *commonRepo) GetHTITypes(ctx context.Context, req *entity.GetHTITypesReq
 		FROM
 			trade_in_trends
 		WHERE
-			brand = ? AND model = ?
+			price_list_id = ` + tradeInEntity.CurrentPriceList + ` AND brand = ? AND model = ?
 		ORDER BY
 			type ASC
 		`
@@ -83,7 +91,7 @@ func (r *commonRepo) GetHTIYears(ctx context.Context, req *entity.GetHTIYearsReq
 		FROM
 			trade_in_trends
 		WHERE
-			brand = ? AND model = ? AND type = ?
+			price_list_id = ` + tradeInEntity.CurrentPriceList + ` AND brand = ? AND model = ? AND type = ?
 		ORDER BY
 			CAST(year AS VARCHAR) ASC
 		`
@@ -107,7 +115,7 @@ func (r *commonRepo) GetHTIPurchase(ctx context.Context, req *entity.GetHTIPurch
 		FROM
 			trade_in_trends
 		WHERE
-			brand = ? AND model = ? AND type = ? AND year = CAST(? AS INTEGER)
+			price_list_id = ` + tradeInEntity.CurrentPriceList + ` AND brand = ? AND model = ? AND type = ? AND year = CAST(? AS INTEGER)
 		`
 
 	var data entity.GetHTIPurchaseResponse
@@ -147,7 +155,7 @@ func (r *commonRepo) GetHTIValuations(ctx context.Context, req *entity.GetHTIVal
 		FROM
 			trade_in_trends
 		WHERE
-			brand = ? AND model = ?
+			price_list_id = ` + tradeInEntity.CurrentPriceList + ` AND brand = ? AND model = ?
 		ORDER BY
 			year DESC, type ASC
 		LIMIT ? OFFSET ?
@@ -171,3 +179,91 @@ func (r *commonRepo) GetHTIValuations(ctx context.Context, req *entity.GetHTIVal
 
 	return res, nil
 }
+
+// htiDocument and htiVector are the searched text of the trade in trend t,
+// they are the expressions of the search indexes.
+const (
+	htiDocument = `LOWER(t.brand || ' ' || t.model || ' ' || t.type)`
+	htiVector   = `to_tsvector('simple', t.brand || ' ' || t.model || ' ' || t.type)`
+)
+
+func (r *commonRepo) SearchHTI(ctx context.Context, req *entity.SearchHTIRequest) (entity.SearchHTIResponse, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.HTISearchItem
+	}
+
+	var (
+		res          entity.SearchHTIResponse
+		data         = make([]dao, 0)
+		scoreArgs    = make([]any, 0, 3)
+		filter       = ``
+		filterArgs   = make([]any, 0, 3)
+		words, years = req.Tokens(time.Now())
+		score        = `0`
+	)
+	res.Items = make([]entity.HTISearchItem, 0)
+
+	if len(words) > 0 {
+		var (
+			text = strings.Join(words, " ")
+			// any word is enough to match, matching them all ranks first
+			anyWord  = pkg.PrefixQuery(text, false)
+			allWords = pkg.PrefixQuery(text, true)
+		)
+
+		score = `
+			CASE WHEN ` + htiVector + ` @@ to_tsquery('simple', ?) THEN 1 ELSE 0 END
+			+ ts_rank(` + htiVector + `, to_tsquery('simple', ?))
+			+ word_similarity(?, ` + htiDocument + `)
+		`
+		scoreArgs = append(scoreArgs, allWords, anyWord, text)
+
+		filter += ` AND (` + htiVector + ` @@ to_tsquery('simple', ?) OR ? <% ` + htiDocument + `)`
+		filterArgs = append(filterArgs, anyWord, text)
+	}
+
+	if len(years) > 0 {
+		filter += ` AND t.year = ANY(?)`
+		filterArgs = append(filterArgs, pq.Array(years))
+	}
+
+	query := `
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			t.brand,
+			t.model,
+			t.type,
+			t.year,
+			t.min_purchase,
+			t.max_purchase,
+			` + score + ` AS score
+		FROM
+			trade_in_trends t
+		WHERE
+			t.price_list_id = ` + tradeInEntity.CurrentPriceList + filter + `
+		ORDER BY
+			score DESC, t.brand, t.model, t.type, t.year DESC
+		LIMIT ? OFFSET ?
+	`
+
+	args := append(scoreArgs, filterArgs...)
+	args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::SearchHTI - Failed to search HTI")
+		return res, err
+	}
+
+	for _, d := range data {
+		res.Items = append(res.Items, d.HTISearchItem)
+	}
+
+	if len(data) > 0 {
+		res.Meta.TotalData = data[0].TotalData
+	}
+
+	res.Meta.CountTotalPage(req.Page, req.Paginate, res.Meta.TotalData)
+	return res, nil
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"codebase-app/internal/module/common/entity"
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchHTIQuotedKeyword(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &commonRepo{db: sqlx.NewDb(db, "postgres")}
+
+	// the quote splits the words like the parser does, nothing of it reaches
+	// to_tsquery
+	mock.ExpectQuery("FROM\\s+trade_in_trends t").
+		WithArgs("o:* & brien:* & ford:*", "o:* | brien:* | ford:*", "o'brien ford", "o:* | brien:* | ford:*", "o'brien ford", pq.Array([]int{2020}), 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"total_data", "brand", "model", "type", "year", "min_purchase", "max_purchase", "score"}).
+			AddRow(1, "Ford", "O'Brien", "GT", 2020, 100, 200, 1.5))
+
+	req := &entity.SearchHTIRequest{Keyword: "O'Brien Ford 2020", Page: 1, Paginate: 10}
+	res, err := repo.SearchHTI(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Len(t, res.Items, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
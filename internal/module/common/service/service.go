@@ -99,6 +99,10 @@ func (s *commonService) GetHTIValuations(ctx context.Context, req *entity.GetHTI
 	return s.repo.GetHTIValuations(ctx, req)
 }
 
+func (s *commonService) SearchHTI(ctx context.Context, req *entity.SearchHTIRequest) (entity.SearchHTIResponse, error) {
+	return s.repo.SearchHTI(ctx, req)
+}
+
 func (s *commonService) CreateBranch(ctx context.Context, req *entity.CreateBranchRequest) error {
 	return s.repo.CreateBranch(ctx, req)
 }
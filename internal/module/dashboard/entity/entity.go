@@ -1,28 +1,47 @@
 package entity
 
 import (
-	"bytes"
 	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/period"
 	"codebase-app/pkg/types"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type LeadTrendsRequest struct {
 	UserId string
+	period.Request
+
+	Period period.Period
+}
+
+// Resolve defaults to the last 12 months per month.
+func (r *LeadTrendsRequest) Resolve() (err error) {
+	r.Period, err = r.Request.Resolve(period.Default{Granularity: period.Month, Months: 11})
+	return err
 }
 
 type LeadTrendsResponse struct {
+	// label of the bucket, 2006/Jan for months
 	Month            string `json:"month" db:"month"`
+	Bucket           string `json:"bucket" db:"bucket"`
 	ReviewConditions int    `json:"review_conditions" db:"review_conditions"`
 	Leads            int    `json:"leads" db:"leads"`
+
+	Previous *LeadTrendsResponse `json:"previous,omitempty"`
 }
 
 type TechWACSummaryResponse struct {
 	Month                string         `json:"month"`
+	From                 string         `json:"from"`
+	To                   string         `json:"to"`
 	TotalWACNeedFollowUp int            `json:"total_wac_need_follow_up" db:"total_wac_need_follow_up"`
 	TotalWACFollowedUp   int            `json:"total_wac_followed_up" db:"total_wac_followed_up"`
 	TotalLeads           int            `json:"total_leads" db:"total_leads"`
 	DistributionOfLeads  []Distribution `json:"distribution_of_leads"`
+
+	Previous *TechWACSummaryResponse `json:"previous,omitempty"`
 }
 
 type GetActivitiesRequest struct {
@@ -34,6 +53,8 @@ type GetActivitiesRequest struct {
 	Timezone string `query:"timezone" validate:"omitempty,timezone"`
 	BranchId string `query:"branch_id" validate:"omitempty,ulid,exist=branches.id"`
 	Export   uint   `query:"export" validate:"omitempty,min=0,max=1"`
+	Format   string `query:"format"`
+	Lang     string `query:"lang"`
 
 	FromTime   time.Time
 	ToTime     time.Time
@@ -108,21 +129,16 @@ type GetActivitiesResponse struct {
 }
 
 type Activity struct {
-	Id                   string    `json:"id" db:"id"`
-	EmployeeName         string    `json:"employee_name" db:"employee_name"`
-	ClientName           string    `json:"client_name" db:"client_name"`
-	BranchName           string    `json:"branch_name" db:"branch_name"`
-	VehicleLicenseNumber string    `json:"vehicle_license_number" db:"vehicle_license_number"`
-	Phone                string    `json:"phone" db:"phone"`
-	VehicleTypeName      string    `json:"vehicle_type_name" db:"vehicle_type_name"`
-	Status               string    `json:"status" db:"status"`
-	TotalPotentialLeads  int       `json:"total_potential_leads" db:"total_potential_leads"`
-	TotalLeads           int       `json:"total_leads" db:"total_leads"`
-	TotalRevenue         float64   `json:"total_revenue" db:"total_revenue"`
-	CreatedAt            time.Time `json:"created_at" db:"created_at"`
-}
-
-type ExportMeta struct {
-	Filename string
-	Buf      *bytes.Buffer
+	Id                   string          `json:"id" db:"id"`
+	EmployeeName         string          `json:"employee_name" db:"employee_name"`
+	ClientName           string          `json:"client_name" db:"client_name"`
+	BranchName           string          `json:"branch_name" db:"branch_name"`
+	VehicleLicenseNumber string          `json:"vehicle_license_number" db:"vehicle_license_number"`
+	Phone                string          `json:"phone" db:"phone"`
+	VehicleTypeName      string          `json:"vehicle_type_name" db:"vehicle_type_name"`
+	Status               string          `json:"status" db:"status"`
+	TotalPotentialLeads  int             `json:"total_potential_leads" db:"total_potential_leads"`
+	TotalLeads           int             `json:"total_leads" db:"total_leads"`
+	TotalRevenue         decimal.Decimal `json:"total_revenue" db:"total_revenue"`
+	CreatedAt            time.Time       `json:"created_at" db:"created_at"`
 }
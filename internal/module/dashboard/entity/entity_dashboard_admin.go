@@ -1,59 +1,42 @@
 package entity
 
 import (
-	"codebase-app/pkg/errmsg"
-	"time"
+	"codebase-app/pkg/period"
 
-	"github.com/rs/zerolog/log"
+	"github.com/shopspring/decimal"
 )
 
 /*
 Admin WAC Line Chart - Start
 */
 type GetWACLineChartRequest struct {
-	From string `query:"from" validate:"datetime=2006-01-02"`
-	To   string `query:"to" validate:"datetime=2006-01-02"`
-	Tz   string `query:"timezone" validate:"timezone"`
-}
+	period.Request
 
-func (r *GetWACLineChartRequest) SetDefault() {
-	if r.Tz == "" {
-		r.Tz = "Asia/Makassar"
-	}
+	Period period.Period
 }
 
-func (r *GetWACLineChartRequest) Validate() error {
-	fromDate, err := time.Parse("2006-01-02", r.From)
-	if err != nil {
-		log.Error().Err(err).Str("from", r.From).Msg("failed to parse from date")
-		return err
-	}
-
-	toDate, err := time.Parse("2006-01-02", r.To)
-	if err != nil {
-		log.Error().Err(err).Str("to", r.To).Msg("failed to parse to date")
-		return err
-	}
-
-	if fromDate.After(toDate) {
-		log.Warn().Str("from", r.From).Str("to", r.To).Msg("from date is after to date")
-		return errmsg.NewCustomErrors(400).
-			Add("from", "tanggal awal tidak boleh lebih besar dari tanggal akhir").
-			Add("to", "tanggal akhir tidak boleh lebih kecil dari tanggal awal")
-	}
-
-	return nil
+// Resolve defaults to the last 30 days per day.
+func (r *GetWACLineChartRequest) Resolve() (err error) {
+	r.Period, err = r.Request.Resolve(period.Default{Granularity: period.Day, Days: 29})
+	return err
 }
 
 type GetWACLineChartResponse struct {
+	From       string      `json:"from"`
+	To         string      `json:"to"`
 	TotalWAC   int         `json:"total_wac" db:"total_wac"`
 	ChartItems []ChartItem `json:"chart_items"`
+
+	PreviousTotalWAC *int `json:"previous_total_wac,omitempty"`
 }
 type ChartItem struct {
+	// start of the bucket
 	Date           string `json:"date" db:"date"`
 	PotentialLeads int    `json:"total_potential_leads" db:"total_potential_leads"`
 	Leads          int    `json:"total_leads" db:"total_leads"`
 	Completed      int    `json:"total_completed" db:"total_completed_leads"`
+
+	Previous *ChartItem `json:"previous,omitempty"`
 }
 
 /*
@@ -67,26 +50,30 @@ Admin Summary per month - Start
 - Distribution of leads based on area
 */
 type GetSummaryPerMonthRequest struct {
-	Month    string `query:"month" validate:"datetime=2006-01"`
-	Timezone string `query:"timezone" validate:"omitempty,timezone"`
-}
+	// month format 2021-01, from/to take precedence when given
+	Month string `query:"month" validate:"omitempty,datetime=2006-01"`
+	// the summary reads the daily facts dated in the reporting timezone
+	// (Asia/Makassar), another timezone is aggregated from the raw tables
+	period.Request
 
-func (r *GetSummaryPerMonthRequest) SetDefault() {
-	if r.Month == "" {
-		r.Month = time.Now().Format("2006-01")
-	}
+	Period period.Period
+}
 
-	if r.Timezone == "" {
-		r.Timezone = "Asia/Makassar"
-	}
+func (r *GetSummaryPerMonthRequest) Resolve() (err error) {
+	r.Period, err = resolveSummaryPeriod(r.Month, r.Request)
+	return err
 }
 
 type GetSummaryPerMonthResponse struct {
+	From              string              `json:"from"`
+	To                string              `json:"to"`
 	SASummary         []Summary           `json:"sa_summary"`  // based on area
 	MRASummary        MRASummary          `json:"mra_summary"` //based on follow up
 	SADistribution    []Distribution      `json:"sa_distribution"`
 	MRADistribution   []Distribution      `json:"mra_distribution"`
 	AreaServiceTrends []AreaServiceTrends `json:"area_service_trends"`
+
+	Previous *GetSummaryPerMonthResponse `json:"previous,omitempty"`
 }
 
 type MRASummary struct {
@@ -107,11 +94,11 @@ type GetSALatestActivityResponse struct {
 }
 
 type SALatestActivity struct {
-	Name                string `json:"name" db:"name"`
-	Status              string `json:"status" db:"status"`
-	TotalPotentialLeads int    `json:"total_potential_leads" db:"total_potential_leads"`
-	TotalLeads          int    `json:"total_leads" db:"total_leads"`
-	TotalRevenue        int    `json:"total_revenue" db:"total_revenue"`
+	Name                string          `json:"name" db:"name"`
+	Status              string          `json:"status" db:"status"`
+	TotalPotentialLeads int             `json:"total_potential_leads" db:"total_potential_leads"`
+	TotalLeads          int             `json:"total_leads" db:"total_leads"`
+	TotalRevenue        decimal.Decimal `json:"total_revenue" db:"total_revenue"`
 }
 
 /*
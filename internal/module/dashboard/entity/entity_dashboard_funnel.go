@@ -0,0 +1,216 @@
+package entity
+
+import (
+	"codebase-app/pkg"
+	"codebase-app/pkg/period"
+	"fmt"
+	"strings"
+)
+
+const (
+	FunnelByBranch  = "branch"
+	FunnelByAdvisor = "advisor"
+	FunnelByPotency = "potency"
+	FunnelByArea    = "area"
+
+	AgingWIP      = "wip"
+	AgingFollowUp = "follow_up"
+)
+
+/*
+Admin Funnel - Start
+- WAC stages: offered -> wip -> completed
+- Lead stages: potential leads -> leads -> completed leads
+- Time between the WAC stages from wac_activities
+- Aging of the WACs stuck in wip or awaiting follow-up
+*/
+type GetFunnelRequest struct {
+	GroupBy  string `query:"group_by" validate:"omitempty,oneof=branch advisor potency area"`
+	BranchId string `query:"branch_id" validate:"omitempty,ulid"`
+	UserId   string `query:"user_id" validate:"omitempty,ulid"`
+	period.Request
+
+	Period period.Period
+}
+
+// Resolve defaults to the current month grouped per branch, the funnel
+// follows the WACs created in the period.
+func (r *GetFunnelRequest) Resolve() (err error) {
+	if r.GroupBy == "" {
+		r.GroupBy = FunnelByBranch
+	}
+
+	r.Period, err = r.Request.Resolve(period.Default{Granularity: period.Month})
+	return err
+}
+
+type GetFunnelResponse struct {
+	From    string       `json:"from"`
+	To      string       `json:"to"`
+	GroupBy string       `json:"group_by"`
+	Total   FunnelItem   `json:"total"`
+	Items   []FunnelItem `json:"items"`
+}
+
+type FunnelItem struct {
+	Id         string           `json:"id"`
+	Name       string           `json:"name"`
+	WACStages  []FunnelStage    `json:"wac_stages"`
+	LeadStages []FunnelStage    `json:"lead_stages"`
+	Durations  []StageDuration  `json:"durations"`
+	Aging      []FunnelAgingSet `json:"aging"`
+}
+
+type FunnelStage struct {
+	Stage string `json:"stage"`
+	Count int    `json:"count"`
+	// percentage of the previous stage, the first stage is 100
+	ConversionRate float64 `json:"conversion_rate"`
+	// percentage of the first stage
+	OverallRate float64 `json:"overall_rate"`
+}
+
+type StageDuration struct {
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	Samples int      `json:"samples"`
+	Median  *float64 `json:"median_hours"`
+	P90     *float64 `json:"p90_hours"`
+}
+
+type FunnelAgingSet struct {
+	State   string        `json:"state"`
+	Total   int           `json:"total"`
+	Buckets []AgingBucket `json:"buckets"`
+}
+
+type AgingBucket struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// AgingRange is a bucket of days, MaxDays 0 has no upper bound.
+type AgingRange struct {
+	Label   string
+	MinDays int
+	MaxDays int
+}
+
+var AgingRanges = []AgingRange{
+	{Label: "0-3", MinDays: 0, MaxDays: 3},
+	{Label: "4-7", MinDays: 4, MaxDays: 7},
+	{Label: "8-14", MinDays: 8, MaxDays: 14},
+	{Label: "15-30", MinDays: 15, MaxDays: 30},
+	{Label: ">30", MinDays: 31},
+}
+
+// AgingCase returns a sql CASE labelling the age in whole days with the
+// aging ranges.
+func AgingCase(days string) string {
+	var b strings.Builder
+
+	b.WriteString("CASE")
+	for _, r := range AgingRanges {
+		if r.MaxDays == 0 {
+			fmt.Fprintf(&b, " WHEN %s >= %d THEN '%s'", days, r.MinDays, r.Label)
+			continue
+		}
+		fmt.Fprintf(&b, " WHEN %s BETWEEN %d AND %d THEN '%s'", days, r.MinDays, r.MaxDays, r.Label)
+	}
+	fmt.Fprintf(&b, " ELSE '%s' END", AgingRanges[0].Label)
+
+	return b.String()
+}
+
+// FunnelRow is the aggregate of a group as read from the database.
+type FunnelRow struct {
+	Id                  string   `db:"id"`
+	Name                string   `db:"name"`
+	TotalWACs           int      `db:"total_wacs"`
+	TotalWIP            int      `db:"total_wip"`
+	TotalCompleted      int      `db:"total_completed"`
+	TotalPotentialLeads int      `db:"total_potential_leads"`
+	TotalLeads          int      `db:"total_leads"`
+	TotalLeadsCompleted int      `db:"total_leads_completed"`
+	OfferedToWIPSamples int      `db:"offered_to_wip_samples"`
+	OfferedToWIPMedian  *float64 `db:"offered_to_wip_median"`
+	OfferedToWIPP90     *float64 `db:"offered_to_wip_p90"`
+	WIPToDoneSamples    int      `db:"wip_to_completed_samples"`
+	WIPToDoneMedian     *float64 `db:"wip_to_completed_median"`
+	WIPToDoneP90        *float64 `db:"wip_to_completed_p90"`
+}
+
+type FunnelAgingRow struct {
+	Id    string `db:"id"`
+	Name  string `db:"name"`
+	State string `db:"state"`
+	Label string `db:"label"`
+	Count int    `db:"count"`
+}
+
+// NewFunnelItem builds the stages of a group, durations are in hours.
+func NewFunnelItem(row FunnelRow, aging []FunnelAgingRow) FunnelItem {
+	return FunnelItem{
+		Id:   row.Id,
+		Name: row.Name,
+		WACStages: funnelStages(
+			[]string{"offered", "wip", "completed"},
+			[]int{row.TotalWACs, row.TotalWIP, row.TotalCompleted},
+		),
+		LeadStages: funnelStages(
+			[]string{"potential_leads", "leads", "completed_leads"},
+			[]int{row.TotalPotentialLeads, row.TotalLeads, row.TotalLeadsCompleted},
+		),
+		Durations: []StageDuration{
+			{From: "offered", To: "wip", Samples: row.OfferedToWIPSamples, Median: row.OfferedToWIPMedian, P90: row.OfferedToWIPP90},
+			{From: "wip", To: "completed", Samples: row.WIPToDoneSamples, Median: row.WIPToDoneMedian, P90: row.WIPToDoneP90},
+		},
+		Aging: agingSets(aging),
+	}
+}
+
+func funnelStages(names []string, counts []int) []FunnelStage {
+	stages := make([]FunnelStage, 0, len(names))
+
+	for i, name := range names {
+		stage := FunnelStage{Stage: name, Count: counts[i]}
+		if i == 0 {
+			if counts[0] > 0 {
+				stage.ConversionRate = 100
+				stage.OverallRate = 100
+			}
+		} else {
+			stage.ConversionRate = pkg.Percentage(counts[i], counts[i-1])
+			stage.OverallRate = pkg.Percentage(counts[i], counts[0])
+		}
+
+		stages = append(stages, stage)
+	}
+
+	return stages
+}
+
+// agingSets zero-fills the aging buckets of both states.
+func agingSets(rows []FunnelAgingRow) []FunnelAgingSet {
+	sets := make([]FunnelAgingSet, 0, 2)
+
+	for _, state := range []string{AgingWIP, AgingFollowUp} {
+		set := FunnelAgingSet{State: state, Buckets: make([]AgingBucket, 0, len(AgingRanges))}
+
+		for _, r := range AgingRanges {
+			bucket := AgingBucket{Label: r.Label}
+			for _, row := range rows {
+				if row.State == state && row.Label == r.Label {
+					bucket.Count += row.Count
+				}
+			}
+
+			set.Total += bucket.Count
+			set.Buckets = append(set.Buckets, bucket)
+		}
+
+		sets = append(sets, set)
+	}
+
+	return sets
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFunnelItem(t *testing.T) {
+	item := NewFunnelItem(FunnelRow{
+		Id:                  "b1",
+		TotalWACs:           8,
+		TotalWIP:            6,
+		TotalCompleted:      2,
+		TotalPotentialLeads: 0,
+	}, []FunnelAgingRow{
+		{Id: "b1", State: AgingWIP, Label: "0-3", Count: 3},
+		{Id: "b1", State: AgingWIP, Label: ">30", Count: 1},
+	})
+
+	assert.Equal(t, []FunnelStage{
+		{Stage: "offered", Count: 8, ConversionRate: 100, OverallRate: 100},
+		{Stage: "wip", Count: 6, ConversionRate: 75, OverallRate: 75},
+		{Stage: "completed", Count: 2, ConversionRate: 33.33, OverallRate: 25},
+	}, item.WACStages)
+
+	for _, stage := range item.LeadStages {
+		assert.Zero(t, stage.ConversionRate)
+	}
+
+	assert.Equal(t, 4, item.Aging[0].Total)
+	assert.Len(t, item.Aging[0].Buckets, len(AgingRanges))
+	assert.Equal(t, 1, item.Aging[0].Buckets[len(AgingRanges)-1].Count)
+	assert.Equal(t, 0, item.Aging[1].Total)
+}
+
+func TestAgingCase(t *testing.T) {
+	assert.Equal(t,
+		"CASE WHEN d BETWEEN 0 AND 3 THEN '0-3' WHEN d BETWEEN 4 AND 7 THEN '4-7' WHEN d BETWEEN 8 AND 14 THEN '8-14' WHEN d BETWEEN 15 AND 30 THEN '15-30' WHEN d >= 31 THEN '>30' ELSE '0-3' END",
+		AgingCase("d"),
+	)
+}
+
+func TestFunnelTable(t *testing.T) {
+	row := FunnelRow{Name: "Makassar", TotalWACs: 8, TotalWIP: 6, TotalCompleted: 2}
+	res := GetFunnelResponse{
+		Items: []FunnelItem{NewFunnelItem(row, nil)},
+		Total: NewFunnelItem(FunnelRow{Name: "Total", TotalWACs: 8}, nil),
+	}
+
+	table := res.Table()
+	assert.Len(t, table.Rows, 2)
+	for _, r := range table.Rows {
+		assert.Len(t, r, len(table.Columns))
+	}
+	assert.Equal(t, []any{"Makassar", 8, 6, 2}, table.Rows[0][:4])
+	assert.Equal(t, "Total", table.Rows[1][0])
+}
@@ -0,0 +1,174 @@
+package entity
+
+import (
+	"codebase-app/pkg"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/period"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	LeaderboardBranch  = "branch"
+	LeaderboardAdvisor = "advisor"
+
+	SortByWACs       = "wacs"
+	SortByConversion = "conversion"
+	SortByRevenue    = "revenue"
+	SortByFollowUp   = "follow_up"
+	SortByTier       = "tier"
+)
+
+/*
+Admin Leaderboard - Start
+- Branches or service advisors ranked by one metric for a period
+- Equal values share a rank (1, 2, 2, 4)
+- Below the minimum WACs an item is listed unranked after the ranked ones
+- Rank delta against the previous period of the same length
+*/
+type GetLeaderboardRequest struct {
+	Scope    string `query:"scope" validate:"omitempty,oneof=branch advisor"`
+	SortBy   string `query:"sort_by" validate:"omitempty,oneof=wacs conversion revenue follow_up tier"`
+	MinWACs  int    `query:"min_wacs" validate:"omitempty,min=0"`
+	BranchId string `query:"branch_id" validate:"omitempty,ulid"`
+	period.Request
+
+	Period period.Period
+}
+
+// Resolve defaults to branches ranked by revenue over the current month.
+func (r *GetLeaderboardRequest) Resolve() (err error) {
+	if r.Scope == "" {
+		r.Scope = LeaderboardBranch
+	}
+
+	if r.SortBy == "" {
+		r.SortBy = SortByRevenue
+	}
+
+	if r.SortBy == SortByTier && r.Scope != LeaderboardAdvisor {
+		return errmsg.NewCustomErrors(400).Add("sort_by", "tier hanya tersedia untuk scope advisor")
+	}
+
+	r.Period, err = r.Request.Resolve(period.Default{Granularity: period.Month})
+	return err
+}
+
+type GetLeaderboardResponse struct {
+	From         string            `json:"from"`
+	To           string            `json:"to"`
+	PreviousFrom string            `json:"previous_from"`
+	PreviousTo   string            `json:"previous_to"`
+	Scope        string            `json:"scope"`
+	SortBy       string            `json:"sort_by"`
+	MinWACs      int               `json:"min_wacs"`
+	Items        []LeaderboardItem `json:"items"`
+}
+
+type LeaderboardItem struct {
+	// nil when the item is below the minimum WACs
+	Rank         *int `json:"rank"`
+	PreviousRank *int `json:"previous_rank"`
+	// positive when the item moved up since the previous period
+	RankDelta *int `json:"rank_delta"`
+
+	Id         string  `json:"id" db:"id"`
+	Name       string  `json:"name" db:"name"`
+	BranchId   *string `json:"branch_id,omitempty" db:"branch_id"`
+	BranchName *string `json:"branch_name,omitempty" db:"branch_name"`
+
+	TotalWACs      int `json:"total_wacs" db:"total_wacs"`
+	TotalCompleted int `json:"total_completed" db:"total_completed"`
+	// completed WACs out of the WACs created
+	ConversionRate   float64         `json:"conversion_rate"`
+	Revenue          decimal.Decimal `json:"revenue" db:"revenue"`
+	TotalFollowUpDue int             `json:"total_follow_up_due" db:"total_follow_up_due"`
+	TotalFollowedUp  int             `json:"total_followed_up" db:"total_followed_up"`
+	FollowUpRate     float64         `json:"follow_up_rate"`
+
+	// advisors only, evaluated at the end of the period
+	Tier *LeaderboardTier `json:"tier,omitempty"`
+}
+
+type LeaderboardTier struct {
+	Key       string          `json:"key"`
+	Name      string          `json:"name"`
+	Threshold decimal.Decimal `json:"threshold"`
+}
+
+func (i *LeaderboardItem) metric(sortBy string) decimal.Decimal {
+	switch sortBy {
+	case SortByWACs:
+		return decimal.NewFromInt(int64(i.TotalWACs))
+	case SortByConversion:
+		return decimal.NewFromFloat(i.ConversionRate)
+	case SortByFollowUp:
+		return decimal.NewFromFloat(i.FollowUpRate)
+	case SortByTier:
+		if i.Tier == nil {
+			return decimal.NewFromInt(-1)
+		}
+		return i.Tier.Threshold
+	default:
+		return i.Revenue
+	}
+}
+
+// RankLeaderboard fills the rates, sorts the items and ranks the ones with
+// at least minWACs WACs.
+func RankLeaderboard(items []LeaderboardItem, sortBy string, minWACs int) {
+	for i := range items {
+		items[i].ConversionRate = pkg.Percentage(items[i].TotalCompleted, items[i].TotalWACs)
+		items[i].FollowUpRate = pkg.Percentage(items[i].TotalFollowedUp, items[i].TotalFollowUpDue)
+		items[i].Rank = nil
+	}
+
+	sort.SliceStable(items, func(a, b int) bool {
+		rankedA, rankedB := items[a].TotalWACs >= minWACs, items[b].TotalWACs >= minWACs
+		if rankedA != rankedB {
+			return rankedA
+		}
+
+		if c := items[a].metric(sortBy).Cmp(items[b].metric(sortBy)); c != 0 {
+			return c > 0
+		}
+
+		return items[a].Name < items[b].Name
+	})
+
+	for i := range items {
+		if items[i].TotalWACs < minWACs {
+			break
+		}
+
+		rank := i + 1
+		if i > 0 && items[i].metric(sortBy).Equal(items[i-1].metric(sortBy)) {
+			rank = *items[i-1].Rank
+		}
+		items[i].Rank = &rank
+	}
+}
+
+// ApplyRankDeltas compares the ranks with the ranked previous items.
+func ApplyRankDeltas(items, previous []LeaderboardItem) {
+	ranks := make(map[string]int, len(previous))
+	for _, p := range previous {
+		if p.Rank != nil {
+			ranks[p.Id] = *p.Rank
+		}
+	}
+
+	for i := range items {
+		prev, ok := ranks[items[i].Id]
+		if !ok {
+			continue
+		}
+
+		items[i].PreviousRank = &prev
+		if items[i].Rank != nil {
+			delta := prev - *items[i].Rank
+			items[i].RankDelta = &delta
+		}
+	}
+}
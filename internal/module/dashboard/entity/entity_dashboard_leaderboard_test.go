@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankLeaderboard(t *testing.T) {
+	items := []LeaderboardItem{
+		{Id: "a", Name: "A", TotalWACs: 10, Revenue: decimal.NewFromInt(500)},
+		{Id: "b", Name: "B", TotalWACs: 2, Revenue: decimal.NewFromInt(900)},
+		{Id: "c", Name: "C", TotalWACs: 8, Revenue: decimal.NewFromInt(700)},
+		{Id: "d", Name: "D", TotalWACs: 5, Revenue: decimal.RequireFromString("700.00")},
+	}
+
+	RankLeaderboard(items, SortByRevenue, 3)
+
+	var ids []string
+	var ranks []any
+	for _, item := range items {
+		ids = append(ids, item.Id)
+		if item.Rank == nil {
+			ranks = append(ranks, nil)
+			continue
+		}
+		ranks = append(ranks, *item.Rank)
+	}
+
+	assert.Equal(t, []string{"c", "d", "a", "b"}, ids)
+	assert.Equal(t, []any{1, 1, 3, nil}, ranks)
+
+	previous := []LeaderboardItem{
+		{Id: "a", TotalWACs: 4, Revenue: decimal.NewFromInt(100)},
+		{Id: "c", TotalWACs: 4, Revenue: decimal.NewFromInt(50)},
+	}
+	RankLeaderboard(previous, SortByRevenue, 3)
+	ApplyRankDeltas(items, previous)
+
+	assert.Equal(t, 1, *items[0].RankDelta)
+	assert.Nil(t, items[1].PreviousRank)
+	assert.Equal(t, -2, *items[2].RankDelta)
+}
@@ -0,0 +1,104 @@
+package entity
+
+import (
+	"codebase-app/pkg/period"
+
+	"github.com/shopspring/decimal"
+)
+
+/*
+Admin Revenue - Start
+  - Revenue of the completed WACs created in the period, the same revenue the
+    tiers are evaluated on: conditions plus used-car WACs
+  - Breakdown per potency, area, area type, vehicle type, branch and bucket
+  - Used-car revenue is recorded on the WAC, it has no potency nor area
+  - trade_in sums up the trade in quotes of the used-car WACs created in the
+    period, whatever their status
+*/
+type GetRevenueRequest struct {
+	BranchId string `query:"branch_id" validate:"omitempty,ulid"`
+	period.Request
+
+	Period period.Period
+}
+
+// Resolve defaults to the last 12 months per month.
+func (r *GetRevenueRequest) Resolve() (err error) {
+	r.Period, err = r.Request.Resolve(period.Default{Granularity: period.Month, Months: 11})
+	return err
+}
+
+type GetRevenueResponse struct {
+	From          string             `json:"from"`
+	To            string             `json:"to"`
+	Total         RevenueBreakdown   `json:"total"`
+	UsedCar       RevenueBreakdown   `json:"used_car"`
+	TradeIn       TradeInSummary     `json:"trade_in"`
+	ByPotency     []RevenueBreakdown `json:"by_potency"`
+	ByArea        []RevenueBreakdown `json:"by_area"`
+	ByAreaType    []RevenueBreakdown `json:"by_area_type"`
+	ByVehicleType []RevenueBreakdown `json:"by_vehicle_type"`
+	ByBranch      []RevenueBreakdown `json:"by_branch"`
+	// one zero-filled item per bucket of the period
+	Trend []RevenueBreakdown `json:"trend"`
+
+	Previous *GetRevenueResponse `json:"previous,omitempty"`
+}
+
+type RevenueBreakdown struct {
+	Id             string          `json:"id" db:"id"`
+	Name           string          `json:"name" db:"name"`
+	Revenue        decimal.Decimal `json:"revenue" db:"revenue"`
+	TotalWACs      int             `json:"total_wacs" db:"total_wacs"`
+	ConvertedLeads int             `json:"converted_leads" db:"converted_leads"`
+	// revenue per completed WAC and per lead with an invoice
+	AvgPerWAC           decimal.Decimal `json:"avg_per_wac"`
+	AvgPerConvertedLead decimal.Decimal `json:"avg_per_converted_lead"`
+	// percentage of the total revenue
+	Share decimal.Decimal `json:"share"`
+}
+
+type TradeInSummary struct {
+	UsedCarWACs         int             `json:"used_car_wacs" db:"used_car_wacs"`
+	QuotedWACs          int             `json:"quoted_wacs" db:"quoted_wacs"`
+	CompletedQuotedWACs int             `json:"completed_quoted_wacs" db:"completed_quoted_wacs"`
+	ExpiredQuotes       int             `json:"expired_quotes" db:"expired_quotes"`
+	TotalOfferedPrice   decimal.Decimal `json:"total_offered_price" db:"total_offered_price"`
+	AvgOfferedPrice     decimal.Decimal `json:"avg_offered_price" db:"avg_offered_price"`
+	// average offered price as a percentage of max_purchase
+	AvgOfferedToMax decimal.Decimal `json:"avg_offered_to_max" db:"avg_offered_to_max"`
+	// percentage of the used-car WACs with a quote
+	QuoteRate decimal.Decimal `json:"quote_rate"`
+}
+
+// Complete fills the quote rate and rounds the averages.
+func (s *TradeInSummary) Complete() {
+	s.AvgOfferedPrice = s.AvgOfferedPrice.Round(4)
+	s.AvgOfferedToMax = s.AvgOfferedToMax.Round(2)
+
+	s.QuoteRate = decimal.Zero
+	if s.UsedCarWACs > 0 {
+		s.QuoteRate = decimal.NewFromInt(int64(s.QuotedWACs)).Mul(hundred).Div(decimal.NewFromInt(int64(s.UsedCarWACs))).Round(2)
+	}
+}
+
+var hundred = decimal.NewFromInt(100)
+
+// Complete fills the averages and the share of total, money keeps the 4
+// decimals of the revenue columns.
+func (b *RevenueBreakdown) Complete(total decimal.Decimal) {
+	b.AvgPerWAC = decimal.Zero
+	if b.TotalWACs > 0 {
+		b.AvgPerWAC = b.Revenue.Div(decimal.NewFromInt(int64(b.TotalWACs))).Round(4)
+	}
+
+	b.AvgPerConvertedLead = decimal.Zero
+	if b.ConvertedLeads > 0 {
+		b.AvgPerConvertedLead = b.Revenue.Div(decimal.NewFromInt(int64(b.ConvertedLeads))).Round(4)
+	}
+
+	b.Share = decimal.Zero
+	if total.IsPositive() {
+		b.Share = b.Revenue.Mul(hundred).Div(total).Round(2)
+	}
+}
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevenueBreakdownComplete(t *testing.T) {
+	b := RevenueBreakdown{
+		Revenue:        decimal.RequireFromString("1000000.10"),
+		TotalWACs:      3,
+		ConvertedLeads: 4,
+	}
+
+	b.Complete(decimal.RequireFromString("3000000.30"))
+
+	assert.Equal(t, "333333.3667", b.AvgPerWAC.String())
+	assert.Equal(t, "250000.025", b.AvgPerConvertedLead.String())
+	assert.Equal(t, "33.33", b.Share.String())
+
+	empty := RevenueBreakdown{}
+	empty.Complete(decimal.Zero)
+	assert.True(t, empty.AvgPerWAC.IsZero())
+	assert.True(t, empty.Share.IsZero())
+}
@@ -1,14 +1,53 @@
 package entity
 
+import (
+	"codebase-app/pkg/period"
+
+	"github.com/shopspring/decimal"
+)
+
 type WACSummaryRequest struct {
 	UserId   string
 	UserRole string
-	// month format 2021-01
-	Month string `query:"month" validate:"required,datetime=2006-01"`
+	// month format 2021-01, from/to take precedence when given
+	Month string `query:"month" validate:"omitempty,datetime=2006-01"`
+	period.Request
+
+	Period period.Period
+}
+
+func (r *WACSummaryRequest) Resolve() (err error) {
+	r.Period, err = resolveSummaryPeriod(r.Month, r.Request)
+	return err
+}
+
+// resolveSummaryPeriod resolves the period of the summary endpoints, they
+// used to take a single month so the month is still accepted.
+func resolveSummaryPeriod(month string, req period.Request) (period.Period, error) {
+	def := period.Default{Granularity: period.Month}
+
+	if req.From == "" && req.To == "" && month != "" {
+		p, err := req.Resolve(def)
+		if err != nil {
+			return p, err
+		}
+
+		monthly, err := period.MonthOf(month, p.Location)
+		if err != nil {
+			return p, err
+		}
+
+		monthly.Compare = p.Compare
+		return monthly, nil
+	}
+
+	return req.Resolve(def)
 }
 
 type WACSummaryResponse struct {
 	Month                  string         `json:"month"`
+	From                   string         `json:"from"`
+	To                     string         `json:"to"`
 	WACCounts              int            `json:"wac_counts" db:"wac_counts"`
 	TotalWACOnOffered      int            `json:"total_wac_on_offered" db:"total_wac_on_offered"`
 	TotalLeadDistributions int            `json:"total_lead_distributions" db:"total_lead_distributions"`
@@ -17,12 +56,14 @@ type WACSummaryResponse struct {
 	ServiceTrends          []Trend        `json:"service_trends"`
 	Tiers                  Tier           `json:"tiers"`
 	Promotions             []Promotion    `json:"promotions"`
+
+	Previous *WACSummaryResponse `json:"previous,omitempty"`
 }
 
 type Tier struct {
-	Current string  `json:"current_tier"`
-	Next    *string `json:"next_tier"`
-	Revenue float64 `json:"revenue"`
+	Current string          `json:"current_tier"`
+	Next    *string         `json:"next_tier"`
+	Revenue decimal.Decimal `json:"revenue"`
 }
 
 type Promotion struct {
@@ -0,0 +1,156 @@
+package entity
+
+import "codebase-app/pkg/tabular"
+
+// The report endpoints answer with a table instead of json when a tabular
+// format is requested, the tables flatten the items of the response.
+
+func header(id, en string) map[string]string {
+	return map[string]string{tabular.LocaleID: id, tabular.LocaleEN: en}
+}
+
+var (
+	colName  = tabular.Column{Key: "name", Headers: header("Nama", "Name")}
+	colWACs  = tabular.Column{Key: "total_wacs", Headers: header("Jumlah WAC", "WACs"), Width: 15}
+	colLeads = tabular.Column{Key: "total_leads", Headers: header("Leads", "Leads"), Width: 15}
+)
+
+var funnelColumns = []tabular.Column{
+	colName,
+	colWACs,
+	{Key: "total_wip", Headers: header("Pengerjaan", "In Progress"), Width: 15},
+	{Key: "total_completed", Headers: header("Selesai", "Completed"), Width: 15},
+	{Key: "total_potential_leads", Headers: header("Potensi Leads", "Potential Leads"), Width: 15},
+	colLeads,
+	{Key: "total_leads_completed", Headers: header("Leads Selesai", "Completed Leads"), Width: 15},
+	{Key: "offered_to_wip_median_hours", Headers: header("Median Penawaran ke Pengerjaan (Jam)", "Median Offered to In Progress (Hours)")},
+	{Key: "wip_to_completed_median_hours", Headers: header("Median Pengerjaan ke Selesai (Jam)", "Median In Progress to Completed (Hours)")},
+}
+
+// Table lists the groups of the funnel followed by their total.
+func (r GetFunnelResponse) Table() tabular.Table {
+	rows := make([][]any, 0, len(r.Items)+1)
+	for _, item := range r.Items {
+		rows = append(rows, item.row())
+	}
+	rows = append(rows, r.Total.row())
+
+	return tabular.Table{Slug: "funnel", Columns: funnelColumns, Rows: rows}
+}
+
+func (i FunnelItem) row() []any {
+	row := []any{i.Name}
+	for _, stage := range i.WACStages {
+		row = append(row, stage.Count)
+	}
+	for _, stage := range i.LeadStages {
+		row = append(row, stage.Count)
+	}
+	for _, duration := range i.Durations {
+		row = append(row, duration.Median)
+	}
+
+	return row
+}
+
+var leaderboardColumns = []tabular.Column{
+	{Key: "rank", Headers: header("Peringkat", "Rank"), Width: 10},
+	colName,
+	{Key: "branch_name", Headers: header("Cabang", "Branch")},
+	colWACs,
+	{Key: "total_completed", Headers: header("WAC Selesai", "Completed WACs"), Width: 15},
+	{Key: "conversion_rate", Headers: header("Konversi (%)", "Conversion (%)"), Width: 15},
+	{Key: "revenue", Headers: header("Pendapatan", "Revenue")},
+	{Key: "total_follow_up_due", Headers: header("Jadwal Follow Up", "Follow Ups Due"), Width: 15},
+	{Key: "total_followed_up", Headers: header("Sudah Follow Up", "Followed Up"), Width: 15},
+	{Key: "follow_up_rate", Headers: header("Follow Up (%)", "Follow Up (%)"), Width: 15},
+	{Key: "tier", Headers: header("Tier", "Tier")},
+}
+
+func (r GetLeaderboardResponse) Table() tabular.Table {
+	rows := make([][]any, 0, len(r.Items))
+	for _, item := range r.Items {
+		var tier *string
+		if item.Tier != nil {
+			tier = &item.Tier.Name
+		}
+
+		rows = append(rows, []any{
+			item.Rank, item.Name, item.BranchName, item.TotalWACs, item.TotalCompleted, item.ConversionRate,
+			item.Revenue, item.TotalFollowUpDue, item.TotalFollowedUp, item.FollowUpRate, tier,
+		})
+	}
+
+	return tabular.Table{Slug: "peringkat", Columns: leaderboardColumns, Rows: rows}
+}
+
+var revenueBreakdowns = tabular.Labels(map[string]map[string]string{
+	"total":        header("Total", "Total"),
+	"used_car":     header("Mobil Bekas", "Used Car"),
+	"potency":      header("Potensi", "Potency"),
+	"area":         header("Area", "Area"),
+	"area_type":    header("Tipe Area", "Area Type"),
+	"vehicle_type": header("Tipe Kendaraan", "Vehicle Type"),
+	"branch":       header("Cabang", "Branch"),
+	"trend":        header("Tren", "Trend"),
+})
+
+var revenueColumns = []tabular.Column{
+	{Key: "breakdown", Headers: header("Rincian", "Breakdown"), Format: revenueBreakdowns},
+	colName,
+	{Key: "revenue", Headers: header("Pendapatan", "Revenue")},
+	colWACs,
+	{Key: "converted_leads", Headers: header("Leads Terkonversi", "Converted Leads"), Width: 15},
+	{Key: "avg_per_wac", Headers: header("Rata-rata per WAC", "Average per WAC")},
+	{Key: "avg_per_converted_lead", Headers: header("Rata-rata per Lead", "Average per Lead")},
+	{Key: "share", Headers: header("Porsi (%)", "Share (%)"), Width: 15},
+}
+
+// Table lists every breakdown of the revenue, the trend items are named
+// after the start of their bucket.
+func (r GetRevenueResponse) Table() tabular.Table {
+	var (
+		rows       = make([][]any, 0)
+		breakdowns = []struct {
+			key   string
+			items []RevenueBreakdown
+		}{
+			{"total", []RevenueBreakdown{r.Total}},
+			{"used_car", []RevenueBreakdown{r.UsedCar}},
+			{"potency", r.ByPotency},
+			{"area", r.ByArea},
+			{"area_type", r.ByAreaType},
+			{"vehicle_type", r.ByVehicleType},
+			{"branch", r.ByBranch},
+			{"trend", r.Trend},
+		}
+	)
+
+	for _, b := range breakdowns {
+		for _, item := range b.items {
+			rows = append(rows, []any{
+				b.key, item.Name, item.Revenue, item.TotalWACs, item.ConvertedLeads,
+				item.AvgPerWAC, item.AvgPerConvertedLead, item.Share,
+			})
+		}
+	}
+
+	return tabular.Table{Slug: "pendapatan", Columns: revenueColumns, Rows: rows}
+}
+
+var summaryColumns = []tabular.Column{
+	{Key: "title", Headers: header("Area", "Area")},
+	{Key: "total_potential_leads", Headers: header("Potensi Leads", "Potential Leads"), Width: 15},
+	colLeads,
+	{Key: "total_wo_do", Headers: header("WO/DO", "WO/DO"), Width: 15},
+}
+
+// Table lists the service advisor summary per area.
+func (r GetSummaryPerMonthResponse) Table() tabular.Table {
+	rows := make([][]any, 0, len(r.SASummary))
+	for _, s := range r.SASummary {
+		rows = append(rows, []any{s.Title, s.TotalPotencialLeads, s.TotalLeads, s.TotalWoDo})
+	}
+
+	return tabular.Table{Slug: "ringkasan-admin", Columns: summaryColumns, Rows: rows}
+}
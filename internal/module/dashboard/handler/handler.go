@@ -1,14 +1,24 @@
 package handler
 
 import (
+	"bufio"
 	"codebase-app/internal/adapter"
 	m "codebase-app/internal/middleware"
 	"codebase-app/internal/module/dashboard/entity"
 	"codebase-app/internal/module/dashboard/ports"
 	"codebase-app/internal/module/dashboard/repository"
 	"codebase-app/internal/module/dashboard/service"
+	exportEntity "codebase-app/internal/module/export/entity"
+	exportPorts "codebase-app/internal/module/export/ports"
+	exportRepository "codebase-app/internal/module/export/repository"
+	exportService "codebase-app/internal/module/export/service"
+	tierRepository "codebase-app/internal/module/tier/repository"
+	tierService "codebase-app/internal/module/tier/service"
 	"codebase-app/pkg/errmsg"
 	"codebase-app/pkg/response"
+	"codebase-app/pkg/tabular"
+	"context"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog/log"
@@ -16,14 +26,17 @@ import (
 
 type dashboardHandler struct {
 	service ports.DashboardService
+	exports exportPorts.ExportService
 }
 
 func NewDashboardHandler() *dashboardHandler {
 	handler := new(dashboardHandler)
 	repo := repository.NewDashboardRepository()
-	service := service.NewDashboardService(repo)
+	tiers := tierService.NewTierService(tierRepository.NewTierRepository())
+	service := service.NewDashboardService(repo, tiers)
 
 	handler.service = service
+	handler.exports = exportService.NewExportService(exportRepository.NewExportRepository())
 	return handler
 }
 
@@ -34,6 +47,9 @@ func (h *dashboardHandler) Register(router fiber.Router) {
 	dashboard.Get("/lead-trends", h.GetLeadsTrends)
 	dashboard.Get("/admin/summaries", m.AuthRole([]string{"admin"}), h.GetAdminWACSummaries)
 	dashboard.Get("/admin/wac-line-chart", m.AuthRole([]string{"admin"}), h.GetWACLineChart)
+	dashboard.Get("/admin/funnel", m.AuthRole([]string{"admin"}), h.GetFunnel)
+	dashboard.Get("/admin/leaderboard", m.AuthRole([]string{"admin"}), h.GetLeaderboard)
+	dashboard.Get("/admin/revenue", m.AuthRole([]string{"admin"}), h.GetRevenue)
 	dashboard.Get("/admin/activities",
 		m.AuthRole([]string{"admin"}),
 		h.GetActivities,
@@ -44,11 +60,28 @@ func (h *dashboardHandler) GetLeadsTrends(c *fiber.Ctx) error {
 	var (
 		req = new(entity.LeadTrendsRequest)
 		ctx = c.Context()
+		v   = adapter.Adapters.Validator
 		l   = m.GetLocals(c)
 	)
 
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetLeadsTrends - failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err.Error()))
+	}
+
 	req.UserId = l.GetUserId()
 
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::GetLeadsTrends - failed to validate request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := req.Resolve(); err != nil {
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
 	res, err := h.service.GetLeadsTrends(ctx, req)
 	if err != nil {
 		code, errs := errmsg.Errors[error](err)
@@ -80,6 +113,11 @@ func (h *dashboardHandler) GetWACSummaries(c *fiber.Ctx) error {
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
+	if err := req.Resolve(); err != nil {
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
 	if l.GetRole() == "technician" {
 		res, err := h.service.GetWACSummaryTechnician(ctx, req)
 		if err != nil {
@@ -115,15 +153,13 @@ func (h *dashboardHandler) GetWACLineChart(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err.Error()))
 	}
 
-	req.SetDefault()
-
 	if err := v.Validate(req); err != nil {
 		log.Warn().Err(err).Any("payload", req).Msg("handler::GetWACLineChart - failed to validate request")
 		code, errs := errmsg.Errors(err, req)
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
-	if err := req.Validate(); err != nil {
+	if err := req.Resolve(); err != nil {
 		code, errs := errmsg.Errors(err, req)
 		return c.Status(code).JSON(response.Error(errs))
 	}
@@ -165,25 +201,90 @@ func (h *dashboardHandler) GetActivities(c *fiber.Ctx) error {
 	}
 
 	if req.Export == 1 {
-		resp, err := h.service.GetActivitiesExported(ctx, req)
-		if err != nil {
-			code, errs := errmsg.Errors[error](err)
-			return c.Status(code).JSON(response.Error(errs))
-		}
+		return h.exportActivities(c, req)
+	}
 
-		c.Set("Content-Disposition", "attachment; filename=\""+resp.Filename+"\"")
-		c.Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	res, err := h.service.GetActivities(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
 
-		return c.SendStream(resp.Buf)
-	} else {
-		res, err := h.service.GetActivities(ctx, req)
-		if err != nil {
-			code, errs := errmsg.Errors[error](err)
-			return c.Status(code).JSON(response.Error(errs))
+	return c.JSON(response.Success(res, ""))
+}
+
+// exportActivities renders the activities through the shared export layer,
+// the format is negotiated from the format query parameter or Accept header.
+func (h *dashboardHandler) exportActivities(c *fiber.Ctx, req *entity.GetActivitiesRequest) error {
+	var (
+		l   = m.GetLocals(c)
+		err error
+	)
+
+	exportReq := &exportEntity.CreateExportRequest{
+		UserId:   l.GetUserId(),
+		UserRole: l.GetRole(),
+		Report:   exportEntity.ReportActivities,
+		Locale:   tabular.NegotiateLocale(req.Lang, c.Get(fiber.HeaderAcceptLanguage)),
+		Params: exportEntity.ExportParams{
+			From:     req.From,
+			To:       req.To,
+			Timezone: req.Timezone,
+			BranchId: req.BranchId,
+			Search:   req.Search,
+		},
+	}
+
+	exportReq.Format, err = tabular.Negotiate(req.Format, c.Get(fiber.HeaderAccept))
+	if err != nil {
+		return c.Status(fiber.StatusNotAcceptable).JSON(response.Error("Format tidak didukung, gunakan xlsx, csv atau ndjson"))
+	}
+
+	// the rows are streamed after the handler returns, the request context is
+	// released by then
+	filename, render := h.exports.RenderExport(context.Background(), exportReq)
+
+	c.Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	c.Set("Content-Type", tabular.ContentType(exportReq.Format))
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := render(w); err != nil {
+			log.Error().Err(err).Any("payload", exportReq).Msg("handler::exportActivities - failed to stream export")
 		}
+	})
 
-		return c.JSON(response.Success(res, ""))
+	return nil
+}
+
+// sendTable streams the report as a table when a tabular format is requested
+// through the format query parameter or the Accept header, ok is false when
+// the json response is expected.
+func sendTable(c *fiber.Ctx, table tabular.Table, loc *time.Location) (ok bool, err error) {
+	if !tabular.Requested(c.Query("format"), c.Get(fiber.HeaderAccept)) {
+		return false, nil
 	}
+
+	format, err := tabular.Negotiate(c.Query("format"), c.Get(fiber.HeaderAccept))
+	if err != nil {
+		return true, c.Status(fiber.StatusNotAcceptable).JSON(response.Error("Format tidak didukung, gunakan xlsx, csv atau ndjson"))
+	}
+
+	opt := tabular.Options{
+		Locale:   tabular.NegotiateLocale(c.Query("lang"), c.Get(fiber.HeaderAcceptLanguage)),
+		Location: loc,
+	}
+	filename := table.Slug + "-" + time.Now().In(loc).Format("20060102150405") + "." + tabular.Extension(format)
+
+	c.Set(fiber.HeaderContentDisposition, "attachment; filename=\""+filename+"\"")
+	c.Set(fiber.HeaderContentType, tabular.ContentType(format))
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := table.Write(w, format, opt); err != nil {
+			log.Error().Err(err).Str("report", table.Slug).Msg("handler::sendTable - failed to stream table")
+		}
+	})
+
+	return true, nil
 }
 
 func (h *dashboardHandler) GetAdminWACSummaries(c *fiber.Ctx) error {
@@ -198,19 +299,134 @@ func (h *dashboardHandler) GetAdminWACSummaries(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err.Error()))
 	}
 
-	req.SetDefault()
-
 	if err := v.Validate(req); err != nil {
 		log.Warn().Err(err).Any("payload", req).Msg("handler::GetAdminWACSummaries - failed to validate request")
 		code, errs := errmsg.Errors(err, req)
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
+	if err := req.Resolve(); err != nil {
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
 	res, err := h.service.GetAdminSummary(ctx, req)
 	if err != nil {
 		code, errs := errmsg.Errors[error](err)
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
+	if ok, err := sendTable(c, res.Table(), req.Period.Location); ok {
+		return err
+	}
+
+	return c.JSON(response.Success(res, ""))
+}
+
+func (h *dashboardHandler) GetFunnel(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetFunnelRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetFunnel - failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err.Error()))
+	}
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::GetFunnel - failed to validate request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := req.Resolve(); err != nil {
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetFunnel(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if ok, err := sendTable(c, res.Table(), req.Period.Location); ok {
+		return err
+	}
+
+	return c.JSON(response.Success(res, ""))
+}
+
+func (h *dashboardHandler) GetLeaderboard(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetLeaderboardRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetLeaderboard - failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err.Error()))
+	}
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::GetLeaderboard - failed to validate request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := req.Resolve(); err != nil {
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetLeaderboard(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if ok, err := sendTable(c, res.Table(), req.Period.Location); ok {
+		return err
+	}
+
+	return c.JSON(response.Success(res, ""))
+}
+
+func (h *dashboardHandler) GetRevenue(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetRevenueRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetRevenue - failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err.Error()))
+	}
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::GetRevenue - failed to validate request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := req.Resolve(); err != nil {
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetRevenue(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if ok, err := sendTable(c, res.Table(), req.Period.Location); ok {
+		return err
+	}
+
 	return c.JSON(response.Success(res, ""))
 }
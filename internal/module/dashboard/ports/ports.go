@@ -13,6 +13,9 @@ type DashboardRepository interface {
 	GetWACLineChart(ctx context.Context, request *entity.GetWACLineChartRequest) (entity.GetWACLineChartResponse, error)
 	GetActivities(ctx context.Context, request *entity.GetActivitiesRequest) (entity.GetActivitiesResponse, error)
 	GetAdminSummary(ctx context.Context, request *entity.GetSummaryPerMonthRequest) (entity.GetSummaryPerMonthResponse, error)
+	GetFunnel(ctx context.Context, request *entity.GetFunnelRequest) (entity.GetFunnelResponse, error)
+	GetLeaderboard(ctx context.Context, request *entity.GetLeaderboardRequest) ([]entity.LeaderboardItem, error)
+	GetRevenue(ctx context.Context, request *entity.GetRevenueRequest) (entity.GetRevenueResponse, error)
 }
 
 type DashboardService interface {
@@ -23,6 +26,7 @@ type DashboardService interface {
 	GetWACLineChart(ctx context.Context, request *entity.GetWACLineChartRequest) (entity.GetWACLineChartResponse, error)
 	GetActivities(ctx context.Context, request *entity.GetActivitiesRequest) (entity.GetActivitiesResponse, error)
 	GetAdminSummary(ctx context.Context, request *entity.GetSummaryPerMonthRequest) (entity.GetSummaryPerMonthResponse, error)
-
-	GetActivitiesExported(ctx context.Context, request *entity.GetActivitiesRequest) (*entity.ExportMeta, error)
+	GetFunnel(ctx context.Context, request *entity.GetFunnelRequest) (entity.GetFunnelResponse, error)
+	GetLeaderboard(ctx context.Context, request *entity.GetLeaderboardRequest) (entity.GetLeaderboardResponse, error)
+	GetRevenue(ctx context.Context, request *entity.GetRevenueRequest) (entity.GetRevenueResponse, error)
 }
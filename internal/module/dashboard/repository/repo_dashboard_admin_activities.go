@@ -27,7 +27,7 @@ func (r *dashboardRepository) GetActivities(ctx context.Context, req *entity.Get
 			u.name AS employee_name,
 			c.name AS client_name,
 			b.name AS branch_name,
-			c.vehicle_license_number,
+			v.license_number AS vehicle_license_number,
 			vt.name AS vehicle_type_name,
 			c.phone,
 			waca.status,
@@ -49,11 +49,14 @@ func (r *dashboardRepository) GetActivities(ctx context.Context, req *entity.Get
 		LEFT JOIN
 			clients c
 			ON wac.client_id = c.id
+		LEFT JOIN
+			vehicles v
+			ON wac.vehicle_id = v.id
 		LEFT JOIN
 			vehicle_types vt
-			ON c.vehicle_type_id = vt.id
+			ON v.vehicle_type_id = vt.id
 		WHERE
-			1 = 1
+			wac.deleted_at IS NULL
 	`
 
 	if req.Search != "" {
@@ -80,15 +83,8 @@ func (r *dashboardRepository) GetActivities(ctx context.Context, req *entity.Get
 		}
 	}
 
-	query += ` ORDER BY waca.created_at DESC`
-
-	// convert int to bool
-	isExport := req.Export == 1
-
-	if !isExport {
-		query += ` LIMIT ? OFFSET ?`
-		args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
-	}
+	query += ` ORDER BY waca.created_at DESC LIMIT ? OFFSET ?`
+	args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
 
 	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...)
 	if err != nil {
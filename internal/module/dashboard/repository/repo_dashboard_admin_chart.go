@@ -2,22 +2,74 @@ package repository
 
 import (
 	"codebase-app/internal/module/dashboard/entity"
+	"codebase-app/pkg/period"
 	"context"
-	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
 func (r *dashboardRepository) GetWACLineChart(ctx context.Context, req *entity.GetWACLineChartRequest) (entity.GetWACLineChartResponse, error) {
 	var (
-		res        entity.GetWACLineChartResponse
-		chartItems = make([]entity.ChartItem, 0)
+		res entity.GetWACLineChartResponse
+		p   = req.Period
+	)
+	res.From = p.FromDate()
+	res.To = p.To.Format(period.DateFormat)
+
+	items, err := r.lineChartItems(ctx, p)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetWACLineChart - failed to get wac line chart")
+		return res, err
+	}
+
+	res.TotalWAC, err = r.countWACs(ctx, p)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetWACLineChart - failed to get total wac")
+		return res, err
+	}
+
+	res.ChartItems = items
+
+	if !p.Compare {
+		return res, nil
+	}
+
+	prev := p.Previous()
+
+	prevItems, err := r.lineChartItems(ctx, prev)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetWACLineChart - failed to get previous wac line chart")
+		return res, err
+	}
+
+	prevTotal, err := r.countWACs(ctx, prev)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetWACLineChart - failed to get previous total wac")
+		return res, err
+	}
+
+	// buckets are compared by position, a longer period has no previous
+	// value for its last buckets
+	for i := range res.ChartItems {
+		if i < len(prevItems) {
+			res.ChartItems[i].Previous = &prevItems[i]
+		}
+	}
+	res.PreviousTotalWAC = &prevTotal
+
+	return res, nil
+}
+
+// lineChartItems returns one zero-filled item per bucket of the period.
+func (r *dashboardRepository) lineChartItems(ctx context.Context, p period.Period) ([]entity.ChartItem, error) {
+	var (
+		data = make([]entity.ChartItem, 0)
+		tz   = p.Location.String()
 	)
-	res.ChartItems = chartItems
 
 	query := `
 		SELECT
-			TO_CHAR(waca.created_at AT TIME ZONE '` + req.Tz + `', 'YYYY-MM-DD') AS date,
+			TO_CHAR(date_trunc(?::text, waca.created_at AT TIME ZONE ?), 'YYYY-MM-DD') AS date,
 			COALESCE(SUM(
 				CASE
 					WHEN
@@ -38,91 +90,59 @@ func (r *dashboardRepository) GetWACLineChart(ctx context.Context, req *entity.G
 			), 0) AS total_completed_leads
 		FROM
 			wac_activities waca
+		JOIN
+			walk_around_checks wac
+			ON wac.id = waca.wac_id
 		WHERE
-			waca.created_at AT TIME ZONE '` + req.Tz + `'
-			BETWEEN (TO_TIMESTAMP(?, 'YYYY-MM-DD') AT TIME ZONE 'UTC')
-			AND (TO_TIMESTAMP(?, 'YYYY-MM-DD') AT TIME ZONE 'UTC' + time '23:59:59.999999')
+			wac.deleted_at IS NULL
+			AND waca.created_at >= ?
+			AND waca.created_at < ?
 		GROUP BY
-			TO_CHAR(waca.created_at AT TIME ZONE '` + req.Tz + `', 'YYYY-MM-DD')
-		`
-
-	err := r.db.SelectContext(ctx, &chartItems, r.db.Rebind(query), req.From, req.To)
-	if err != nil {
-		log.Error().Err(err).Any("payload", req).Msg("repo::GetWACLineChart - failed to get wac line chart")
-		return res, err
-	}
-
-	// count expected days
-	expectedDays := 0
-	fromDate, err := time.Parse("2006-01-02", req.From)
-	if err != nil {
-		log.Error().Err(err).Any("from", req.From).Msg("failed to parse from date")
-		return res, err
-	}
+			1
+	`
 
-	toDate, err := time.Parse("2006-01-02", req.To)
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), p.Granularity, tz, p.From, p.End())
 	if err != nil {
-		log.Error().Err(err).Any("to", req.To).Msg("failed to parse to date")
-		return res, err
+		return nil, err
 	}
 
-	listOfDates := make(map[string]bool)
-	for fromDate.Before(toDate.AddDate(0, 0, 1)) { // add 1 day to toDate to include the last date
-		expectedDays++
-		listOfDates[fromDate.Format("2006-01-02")] = false
-		fromDate = fromDate.AddDate(0, 0, 1)
+	byDate := make(map[string]entity.ChartItem, len(data))
+	for _, d := range data {
+		byDate[d.Date] = d
 	}
 
-	res.ChartItems = chartItems
-
-	// fill the data if blank for the expected days
-	length := len(res.ChartItems)
-	if length < expectedDays {
-		newRes := make([]entity.ChartItem, 0)
-		loc, _ := time.LoadLocation("Asia/Makassar")
-
-		for i := 0; i < expectedDays; i++ {
-			date := toDate.In(loc).AddDate(0, 0, -i).Format("2006-01-02")
-			found := false
-			for j := 0; j < length; j++ {
-				if res.ChartItems[j].Date == date {
-					newRes = append(newRes, res.ChartItems[j])
-					listOfDates[date] = true
-					found = true
-					break
-				}
-			}
-			if !found {
-				newRes = append(newRes, entity.ChartItem{Date: date})
-			}
+	buckets := p.Buckets()
+	items := make([]entity.ChartItem, 0, len(buckets))
+	for _, b := range buckets {
+		item, ok := byDate[b.Key]
+		if !ok {
+			item = entity.ChartItem{Date: b.Key}
 		}
 
-		res.ChartItems = newRes
+		items = append(items, item)
 	}
 
-	// reverse the data
-	for i, j := 0, len(res.ChartItems)-1; i < j; i, j = i+1, j-1 {
-		res.ChartItems[i], res.ChartItems[j] = res.ChartItems[j], res.ChartItems[i]
-	}
+	return items, nil
+}
+
+func (r *dashboardRepository) countWACs(ctx context.Context, p period.Period) (int, error) {
+	var total int
 
-	queryWACCount := `
+	query := `
 		SELECT
 			COUNT(*) AS total_wac
 		FROM
 			walk_around_checks wac
 		WHERE
-			wac.created_at AT TIME ZONE '` + req.Tz + `'
-			BETWEEN
-				(TO_TIMESTAMP(?, 'YYYY-MM-DD') AT TIME ZONE 'UTC')
-			AND
-				(TO_TIMESTAMP(?, 'YYYY-MM-DD') AT TIME ZONE 'UTC' + time '23:59:59.999999')
+			wac.deleted_at IS NULL
+			AND wac.created_at >= ?
+			AND wac.created_at < ?
 	`
 
-	err = r.db.GetContext(ctx, &res.TotalWAC, r.db.Rebind(queryWACCount), req.From, req.To)
+	err := r.db.GetContext(ctx, &total, r.db.Rebind(query), p.From, p.End())
 	if err != nil {
-		log.Error().Err(err).Any("payload", req).Msg("repo::GetWACLineChart - failed to get total wac")
-		return res, err
+		return 0, err
 	}
 
-	return res, nil
+	return total, nil
 }
@@ -0,0 +1,316 @@
+package repository
+
+import (
+	"codebase-app/internal/module/dashboard/entity"
+	"codebase-app/pkg/period"
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// funnelGroup describes how the WACs are grouped, potencies and areas come
+// from the vehicle conditions so a WAC may belong to several groups.
+type funnelGroup struct {
+	key        string
+	names      string
+	conditions bool
+}
+
+var funnelGroups = map[string]funnelGroup{
+	entity.FunnelByBranch:  {key: "w.branch_id", names: "branches"},
+	entity.FunnelByAdvisor: {key: "w.user_id", names: "users"},
+	entity.FunnelByPotency: {key: "wacc.potency_id", names: "potencies", conditions: true},
+	entity.FunnelByArea:    {key: "wacc.area_id", names: "areas", conditions: true},
+}
+
+// funnelTotal groups every WAC together.
+var funnelTotal = funnelGroup{key: "''"}
+
+func (r *dashboardRepository) GetFunnel(ctx context.Context, req *entity.GetFunnelRequest) (entity.GetFunnelResponse, error) {
+	var (
+		res   entity.GetFunnelResponse
+		group = funnelGroups[req.GroupBy]
+	)
+	res.From = req.Period.FromDate()
+	res.To = req.Period.To.Format(period.DateFormat)
+	res.GroupBy = req.GroupBy
+	res.Items = make([]entity.FunnelItem, 0)
+
+	totals, err := r.funnelRows(ctx, req, funnelTotal)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetFunnel - failed to get funnel total")
+		return res, err
+	}
+
+	totalAging, err := r.funnelAging(ctx, req, funnelTotal)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetFunnel - failed to get aging total")
+		return res, err
+	}
+
+	total := entity.FunnelRow{}
+	if len(totals) > 0 {
+		total = totals[0]
+	}
+	res.Total = entity.NewFunnelItem(total, totalAging)
+
+	rows, err := r.funnelRows(ctx, req, group)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetFunnel - failed to get funnel")
+		return res, err
+	}
+
+	aging, err := r.funnelAging(ctx, req, group)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetFunnel - failed to get aging")
+		return res, err
+	}
+
+	agingById := make(map[string][]entity.FunnelAgingRow)
+	for _, a := range aging {
+		agingById[a.Id] = append(agingById[a.Id], a)
+	}
+
+	for _, row := range rows {
+		res.Items = append(res.Items, entity.NewFunnelItem(row, agingById[row.Id]))
+		delete(agingById, row.Id)
+	}
+
+	// groups without WACs in the period may still have stuck WACs
+	for _, a := range aging {
+		if stuck, ok := agingById[a.Id]; ok {
+			res.Items = append(res.Items, entity.NewFunnelItem(entity.FunnelRow{Id: a.Id, Name: a.Name}, stuck))
+			delete(agingById, a.Id)
+		}
+	}
+
+	return res, nil
+}
+
+func funnelFilter(req *entity.GetFunnelRequest, alias string) (string, []any) {
+	var (
+		query string
+		args  []any
+	)
+
+	if req.BranchId != "" {
+		query += ` AND ` + alias + `.branch_id = ?`
+		args = append(args, req.BranchId)
+	}
+
+	if req.UserId != "" {
+		query += ` AND ` + alias + `.user_id = ?`
+		args = append(args, req.UserId)
+	}
+
+	return query, args
+}
+
+// funnelNames returns the name column of the group and its join on id.
+func funnelNames(group funnelGroup, id string) (string, string) {
+	if group.names == "" {
+		return `''`, ``
+	}
+
+	return `COALESCE(n.name, '')`, `LEFT JOIN ` + group.names + ` n ON n.id = ` + id
+}
+
+// funnelRows counts the WACs created in the period per stage, the time
+// between stages is the first wac_activities row of each status.
+func (r *dashboardRepository) funnelRows(ctx context.Context, req *entity.GetFunnelRequest, group funnelGroup) ([]entity.FunnelRow, error) {
+	var (
+		data = make([]entity.FunnelRow, 0)
+		p    = req.Period
+		args = []any{p.From, p.End()}
+	)
+
+	filter, filterArgs := funnelFilter(req, "wac")
+	args = append(args, filterArgs...)
+
+	members := `
+		SELECT
+			` + group.key + ` AS id,
+			w.id AS wac_id,
+			w.total_potential_leads AS potential_leads,
+			w.total_leads AS leads,
+			w.total_leads_completed AS leads_completed
+		FROM
+			wacs w
+	`
+	if group.conditions {
+		// used car leads are every condition of the WAC once offered
+		members = `
+			SELECT
+				` + group.key + ` AS id,
+				w.id AS wac_id,
+				COUNT(*) AS potential_leads,
+				COUNT(*) FILTER (
+					WHERE wacc.is_interested = TRUE
+					OR (w.is_used_car = TRUE AND w.status <> 'offered')
+				) AS leads,
+				COUNT(*) FILTER (
+					WHERE (wacc.is_interested = TRUE AND wacc.invoice_number IS NOT NULL)
+					OR (w.is_used_car = TRUE AND w.status = 'completed')
+				) AS leads_completed
+			FROM
+				wacs w
+			JOIN
+				walk_around_check_conditions wacc
+				ON wacc.walk_around_check_id = w.id
+				AND wacc.deleted_at IS NULL
+			GROUP BY
+				1, 2
+		`
+	}
+
+	name, names := funnelNames(group, "m.id")
+
+	query := `
+		WITH wacs AS (
+			SELECT
+				wac.id,
+				wac.branch_id,
+				wac.user_id,
+				wac.status,
+				wac.is_used_car,
+				wac.total_potential_leads,
+				wac.total_leads,
+				wac.total_leads_completed,
+				wac.created_at,
+				wip.created_at AS wip_at,
+				done.created_at AS completed_at
+			FROM
+				walk_around_checks wac
+			LEFT JOIN LATERAL (
+				SELECT MIN(created_at) AS created_at
+				FROM wac_activities
+				WHERE wac_id = wac.id AND status = 'wip'
+			) wip ON TRUE
+			LEFT JOIN LATERAL (
+				SELECT MIN(created_at) AS created_at
+				FROM wac_activities
+				WHERE wac_id = wac.id AND status = 'completed'
+			) done ON TRUE
+			WHERE
+				wac.deleted_at IS NULL
+				AND wac.created_at >= ?
+				AND wac.created_at < ?` + filter + `
+		), members AS (` + members + `)
+		SELECT
+			m.id,
+			` + name + ` AS name,
+			COUNT(*) AS total_wacs,
+			COUNT(*) FILTER (WHERE w.status <> 'offered') AS total_wip,
+			COUNT(*) FILTER (WHERE w.status = 'completed') AS total_completed,
+			COALESCE(SUM(m.potential_leads), 0) AS total_potential_leads,
+			COALESCE(SUM(m.leads), 0) AS total_leads,
+			COALESCE(SUM(m.leads_completed), 0) AS total_leads_completed,
+			COUNT(w.wip_at) AS offered_to_wip_samples,
+			ROUND((PERCENTILE_CONT(0.5) WITHIN GROUP (
+				ORDER BY EXTRACT(EPOCH FROM w.wip_at - w.created_at) / 3600
+			))::numeric, 2)::float8 AS offered_to_wip_median,
+			ROUND((PERCENTILE_CONT(0.9) WITHIN GROUP (
+				ORDER BY EXTRACT(EPOCH FROM w.wip_at - w.created_at) / 3600
+			))::numeric, 2)::float8 AS offered_to_wip_p90,
+			COUNT(*) FILTER (WHERE w.wip_at IS NOT NULL AND w.completed_at IS NOT NULL) AS wip_to_completed_samples,
+			ROUND((PERCENTILE_CONT(0.5) WITHIN GROUP (
+				ORDER BY EXTRACT(EPOCH FROM w.completed_at - w.wip_at) / 3600
+			))::numeric, 2)::float8 AS wip_to_completed_median,
+			ROUND((PERCENTILE_CONT(0.9) WITHIN GROUP (
+				ORDER BY EXTRACT(EPOCH FROM w.completed_at - w.wip_at) / 3600
+			))::numeric, 2)::float8 AS wip_to_completed_p90
+		FROM
+			members m
+		JOIN
+			wacs w
+			ON w.id = m.wac_id
+		` + names + `
+		GROUP BY
+			1, 2
+		ORDER BY
+			total_wacs DESC,
+			name ASC
+	`
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// funnelAging counts the WACs stuck right now, it does not follow the
+// period. WIP ages from the first wip activity, follow-ups from the day they
+// became due.
+func (r *dashboardRepository) funnelAging(ctx context.Context, req *entity.GetFunnelRequest, group funnelGroup) ([]entity.FunnelAgingRow, error) {
+	var data = make([]entity.FunnelAgingRow, 0)
+
+	filter, filterArgs := funnelFilter(req, "wac")
+	args := append(append([]any{}, filterArgs...), filterArgs...)
+
+	name, names := funnelNames(group, group.key)
+
+	conditions := ``
+	if group.conditions {
+		conditions = `
+			JOIN
+				walk_around_check_conditions wacc
+				ON wacc.walk_around_check_id = w.id
+				AND wacc.deleted_at IS NULL
+		`
+	}
+
+	query := `
+		WITH stuck AS (
+			SELECT
+				wac.id,
+				wac.branch_id,
+				wac.user_id,
+				'` + entity.AgingWIP + `' AS state,
+				FLOOR(EXTRACT(EPOCH FROM NOW() - COALESCE(wip.created_at, wac.updated_at)) / 86400)::int AS days
+			FROM
+				walk_around_checks wac
+			LEFT JOIN LATERAL (
+				SELECT MIN(created_at) AS created_at
+				FROM wac_activities
+				WHERE wac_id = wac.id AND status = 'wip'
+			) wip ON TRUE
+			WHERE
+				wac.deleted_at IS NULL
+				AND wac.status = 'wip'` + filter + `
+			UNION ALL
+			SELECT
+				wac.id,
+				wac.branch_id,
+				wac.user_id,
+				'` + entity.AgingFollowUp + `' AS state,
+				FLOOR(EXTRACT(EPOCH FROM NOW() - wac.follow_up_at) / 86400)::int AS days
+			FROM
+				walk_around_checks wac
+			WHERE
+				wac.deleted_at IS NULL
+				AND wac.is_needs_follow_up = TRUE
+				AND wac.follow_up_at <= NOW()` + filter + `
+		)
+		SELECT
+			` + group.key + ` AS id,
+			` + name + ` AS name,
+			w.state,
+			` + entity.AgingCase("w.days") + ` AS label,
+			COUNT(DISTINCT w.id) AS count
+		FROM
+			stuck w
+		` + conditions + `
+		` + names + `
+		GROUP BY
+			1, 2, 3, 4
+	`
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"codebase-app/internal/module/dashboard/entity"
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// GetLeaderboard returns the unranked metrics of every branch or service
+// advisor for the WACs created in the period, revenue follows the tier
+// revenue: completed conditions plus used-car WACs.
+func (r *dashboardRepository) GetLeaderboard(ctx context.Context, req *entity.GetLeaderboardRequest) ([]entity.LeaderboardItem, error) {
+	var (
+		data = make([]entity.LeaderboardItem, 0)
+		p    = req.Period
+		args = []any{p.From, p.End()}
+		key  = "w.branch_id"
+	)
+
+	if req.Scope == entity.LeaderboardAdvisor {
+		key = "w.user_id"
+	}
+
+	query := `
+		WITH wacs AS (
+			SELECT
+				wac.id,
+				wac.branch_id,
+				wac.user_id,
+				wac.status,
+				wac.is_used_car,
+				wac.revenue,
+				wac.is_needs_follow_up,
+				wac.is_followed_up,
+				wac.follow_up_at
+			FROM
+				walk_around_checks wac
+			WHERE
+				wac.deleted_at IS NULL
+				AND wac.created_at >= ?
+				AND wac.created_at < ?
+		), condition_revenues AS (
+			SELECT
+				wacc.walk_around_check_id AS wac_id,
+				SUM(wacc.revenue) AS revenue
+			FROM
+				walk_around_check_conditions wacc
+			JOIN
+				wacs w
+				ON w.id = wacc.walk_around_check_id
+			WHERE
+				wacc.deleted_at IS NULL
+				AND w.status = 'completed'
+			GROUP BY
+				1
+		), stats AS (
+			SELECT
+				` + key + ` AS id,
+				COUNT(*) AS total_wacs,
+				COUNT(*) FILTER (WHERE w.status = 'completed') AS total_completed,
+				COALESCE(SUM(cr.revenue), 0)
+					+ COALESCE(SUM(w.revenue) FILTER (WHERE w.status = 'completed' AND w.is_used_car = TRUE), 0) AS revenue,
+				COUNT(*) FILTER (
+					WHERE w.is_followed_up = TRUE
+					OR (w.is_needs_follow_up = TRUE AND w.follow_up_at <= NOW())
+				) AS total_follow_up_due,
+				COUNT(*) FILTER (WHERE w.is_followed_up = TRUE) AS total_followed_up
+			FROM
+				wacs w
+			LEFT JOIN
+				condition_revenues cr
+				ON cr.wac_id = w.id
+			GROUP BY
+				1
+		)
+	`
+
+	if req.Scope == entity.LeaderboardAdvisor {
+		query += `
+			SELECT
+				u.id,
+				u.name,
+				u.branch_id,
+				b.name AS branch_name,
+				COALESCE(s.total_wacs, 0) AS total_wacs,
+				COALESCE(s.total_completed, 0) AS total_completed,
+				COALESCE(s.revenue, 0) AS revenue,
+				COALESCE(s.total_follow_up_due, 0) AS total_follow_up_due,
+				COALESCE(s.total_followed_up, 0) AS total_followed_up
+			FROM
+				users u
+			JOIN
+				roles ro
+				ON ro.id = u.role_id
+			LEFT JOIN
+				branches b
+				ON b.id = u.branch_id
+			LEFT JOIN
+				stats s
+				ON s.id = u.id
+			WHERE
+				ro.name = 'service_advisor'
+				AND u.deleted_at IS NULL
+		`
+
+		if req.BranchId != "" {
+			query += ` AND u.branch_id = ?`
+			args = append(args, req.BranchId)
+		}
+	} else {
+		query += `
+			SELECT
+				b.id,
+				b.name,
+				COALESCE(s.total_wacs, 0) AS total_wacs,
+				COALESCE(s.total_completed, 0) AS total_completed,
+				COALESCE(s.revenue, 0) AS revenue,
+				COALESCE(s.total_follow_up_due, 0) AS total_follow_up_due,
+				COALESCE(s.total_followed_up, 0) AS total_followed_up
+			FROM
+				branches b
+			LEFT JOIN
+				stats s
+				ON s.id = b.id
+			WHERE
+				b.deleted_at IS NULL
+		`
+
+		if req.BranchId != "" {
+			query += ` AND b.id = ?`
+			args = append(args, req.BranchId)
+		}
+	}
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetLeaderboard - failed to get leaderboard")
+		return nil, err
+	}
+
+	return data, nil
+}
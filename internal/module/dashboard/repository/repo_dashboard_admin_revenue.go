@@ -0,0 +1,310 @@
+package repository
+
+import (
+	"codebase-app/internal/module/dashboard/entity"
+	"codebase-app/pkg/period"
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/shopspring/decimal"
+)
+
+// revenueDimension is a breakdown of the revenue lines, filter excludes the
+// lines the dimension does not apply to.
+type revenueDimension struct {
+	key    string
+	name   string
+	join   string
+	filter string
+}
+
+var (
+	revenueTotal     = revenueDimension{key: `''`, name: `''`}
+	revenueUsedCar   = revenueDimension{key: `''`, name: `''`, filter: `l.potency_id IS NULL`}
+	revenueByPotency = revenueDimension{
+		key:    `l.potency_id`,
+		name:   `COALESCE(p.name, '')`,
+		join:   `LEFT JOIN potencies p ON p.id = l.potency_id`,
+		filter: `l.potency_id IS NOT NULL`,
+	}
+	revenueByArea = revenueDimension{
+		key:    `l.area_id`,
+		name:   `COALESCE(a.name, '')`,
+		join:   `LEFT JOIN areas a ON a.id = l.area_id`,
+		filter: `l.area_id IS NOT NULL`,
+	}
+	revenueByAreaType = revenueDimension{
+		key:    `a.type::text`,
+		name:   `a.type::text`,
+		join:   `JOIN areas a ON a.id = l.area_id`,
+		filter: `l.area_id IS NOT NULL`,
+	}
+	revenueByVehicleType = revenueDimension{
+		key:  `COALESCE(l.vehicle_type_id, '')`,
+		name: `COALESCE(vt.name, '')`,
+		join: `LEFT JOIN vehicle_types vt ON vt.id = l.vehicle_type_id`,
+	}
+	revenueByBranch = revenueDimension{
+		key:  `l.branch_id`,
+		name: `COALESCE(b.name, '')`,
+		join: `LEFT JOIN branches b ON b.id = l.branch_id`,
+	}
+)
+
+func (r *dashboardRepository) GetRevenue(ctx context.Context, req *entity.GetRevenueRequest) (entity.GetRevenueResponse, error) {
+	var (
+		res entity.GetRevenueResponse
+		p   = req.Period
+	)
+	res.From = p.FromDate()
+	res.To = p.To.Format(period.DateFormat)
+
+	totals, err := r.revenueBreakdown(ctx, req, revenueTotal)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetRevenue - failed to get total revenue")
+		return res, err
+	}
+	if len(totals) > 0 {
+		res.Total = totals[0]
+	}
+	total := res.Total.Revenue
+	res.Total.Complete(total)
+
+	usedCar, err := r.revenueBreakdown(ctx, req, revenueUsedCar)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetRevenue - failed to get used car revenue")
+		return res, err
+	}
+	if len(usedCar) > 0 {
+		res.UsedCar = usedCar[0]
+	}
+	res.UsedCar.Complete(total)
+
+	res.TradeIn, err = r.tradeInSummary(ctx, req)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetRevenue - failed to get trade in summary")
+		return res, err
+	}
+	res.TradeIn.Complete()
+
+	breakdowns := []struct {
+		dimension revenueDimension
+		dest      *[]entity.RevenueBreakdown
+	}{
+		{revenueByPotency, &res.ByPotency},
+		{revenueByArea, &res.ByArea},
+		{revenueByAreaType, &res.ByAreaType},
+		{revenueByVehicleType, &res.ByVehicleType},
+		{revenueByBranch, &res.ByBranch},
+	}
+
+	for _, b := range breakdowns {
+		items, err := r.revenueBreakdown(ctx, req, b.dimension)
+		if err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::GetRevenue - failed to get revenue breakdown")
+			return res, err
+		}
+
+		for i := range items {
+			items[i].Complete(total)
+		}
+		*b.dest = items
+	}
+
+	res.Trend, err = r.revenueTrend(ctx, req, total)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetRevenue - failed to get revenue trend")
+		return res, err
+	}
+
+	return res, nil
+}
+
+// revenueLines is one line per condition of the completed WACs plus one per
+// completed used-car WAC, the used-car lines have no potency nor area.
+func revenueLines(req *entity.GetRevenueRequest) (string, []any) {
+	var (
+		p      = req.Period
+		filter = `
+			wac.status = 'completed'
+			AND wac.deleted_at IS NULL
+			AND wac.created_at >= ?
+			AND wac.created_at < ?`
+		args = []any{p.From, p.End()}
+	)
+
+	if req.BranchId != "" {
+		filter += ` AND wac.branch_id = ?`
+		args = append(args, req.BranchId)
+	}
+
+	query := `
+		WITH lines AS (
+			SELECT
+				wac.id AS wac_id,
+				wac.branch_id,
+				v.vehicle_type_id,
+				wac.created_at,
+				wacc.potency_id,
+				wacc.area_id,
+				wacc.revenue,
+				CASE WHEN wacc.invoice_number IS NOT NULL THEN 1 ELSE 0 END AS converted_leads
+			FROM
+				walk_around_check_conditions wacc
+			JOIN
+				walk_around_checks wac
+				ON wac.id = wacc.walk_around_check_id
+			LEFT JOIN
+				vehicles v
+				ON v.id = wac.vehicle_id
+			WHERE
+				wacc.deleted_at IS NULL
+				AND ` + filter + `
+			UNION ALL
+			SELECT
+				wac.id AS wac_id,
+				wac.branch_id,
+				v.vehicle_type_id,
+				wac.created_at,
+				NULL AS potency_id,
+				NULL AS area_id,
+				wac.revenue,
+				wac.total_leads_completed AS converted_leads
+			FROM
+				walk_around_checks wac
+			LEFT JOIN
+				vehicles v
+				ON v.id = wac.vehicle_id
+			WHERE
+				wac.is_used_car = TRUE
+				AND ` + filter + `
+		)
+	`
+
+	return query, append(args, args...)
+}
+
+// tradeInSummary sums up the quotes of the used-car WACs created in the period.
+func (r *dashboardRepository) tradeInSummary(ctx context.Context, req *entity.GetRevenueRequest) (entity.TradeInSummary, error) {
+	var (
+		data entity.TradeInSummary
+		p    = req.Period
+		args = []any{p.From, p.End()}
+	)
+
+	query := `
+		SELECT
+			COUNT(*) AS used_car_wacs,
+			COUNT(q.id) AS quoted_wacs,
+			COUNT(q.id) FILTER (WHERE wac.status = 'completed') AS completed_quoted_wacs,
+			COUNT(q.id) FILTER (WHERE wac.status <> 'completed' AND q.valid_until < ` + period.Today + `) AS expired_quotes,
+			COALESCE(SUM(q.offered_price), 0) AS total_offered_price,
+			COALESCE(AVG(q.offered_price), 0) AS avg_offered_price,
+			COALESCE(AVG(q.offered_price * 100.0 / NULLIF(q.max_purchase, 0)), 0) AS avg_offered_to_max
+		FROM
+			walk_around_checks wac
+		LEFT JOIN
+			trade_in_quotes q
+			ON q.walk_around_check_id = wac.id
+		WHERE
+			wac.is_used_car = TRUE
+			AND wac.deleted_at IS NULL
+			AND wac.created_at >= ?
+			AND wac.created_at < ?
+	`
+
+	if req.BranchId != "" {
+		query += ` AND wac.branch_id = ?`
+		args = append(args, req.BranchId)
+	}
+
+	if err := r.db.GetContext(ctx, &data, r.db.Rebind(query), args...); err != nil {
+		return data, err
+	}
+
+	return data, nil
+}
+
+func (r *dashboardRepository) revenueBreakdown(ctx context.Context, req *entity.GetRevenueRequest, d revenueDimension) ([]entity.RevenueBreakdown, error) {
+	var data = make([]entity.RevenueBreakdown, 0)
+
+	query, args := revenueLines(req)
+
+	where := ``
+	if d.filter != "" {
+		where = `WHERE ` + d.filter
+	}
+
+	query += `
+		SELECT
+			` + d.key + ` AS id,
+			` + d.name + ` AS name,
+			COALESCE(SUM(l.revenue), 0) AS revenue,
+			COUNT(DISTINCT l.wac_id) AS total_wacs,
+			COALESCE(SUM(l.converted_leads), 0) AS converted_leads
+		FROM
+			lines l
+		` + d.join + `
+		` + where + `
+		GROUP BY
+			1, 2
+		ORDER BY
+			revenue DESC,
+			name ASC
+	`
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// revenueTrend returns one zero-filled item per bucket of the period, the id
+// is the start of the bucket.
+func (r *dashboardRepository) revenueTrend(ctx context.Context, req *entity.GetRevenueRequest, total decimal.Decimal) ([]entity.RevenueBreakdown, error) {
+	var (
+		data = make([]entity.RevenueBreakdown, 0)
+		p    = req.Period
+	)
+
+	query, args := revenueLines(req)
+	query += `
+		SELECT
+			TO_CHAR(date_trunc(?::text, l.created_at AT TIME ZONE ?), 'YYYY-MM-DD') AS id,
+			COALESCE(SUM(l.revenue), 0) AS revenue,
+			COUNT(DISTINCT l.wac_id) AS total_wacs,
+			COALESCE(SUM(l.converted_leads), 0) AS converted_leads
+		FROM
+			lines l
+		GROUP BY
+			1
+	`
+	args = append(args, p.Granularity, p.Location.String())
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	byBucket := make(map[string]entity.RevenueBreakdown, len(data))
+	for _, d := range data {
+		byBucket[d.Id] = d
+	}
+
+	buckets := p.Buckets()
+	items := make([]entity.RevenueBreakdown, 0, len(buckets))
+	for _, b := range buckets {
+		item, ok := byBucket[b.Key]
+		if !ok {
+			item = entity.RevenueBreakdown{Id: b.Key, Revenue: decimal.Zero}
+		}
+		item.Name = b.Key
+		item.Complete(total)
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"codebase-app/internal/module/dashboard/entity"
+	"codebase-app/pkg/period"
 	"context"
 
 	"github.com/rs/zerolog/log"
@@ -9,6 +10,8 @@ import (
 
 func (r *dashboardRepository) GetAdminSummary(ctx context.Context, req *entity.GetSummaryPerMonthRequest) (entity.GetSummaryPerMonthResponse, error) {
 	var res entity.GetSummaryPerMonthResponse
+	res.From = req.Period.FromDate()
+	res.To = req.Period.To.Format(period.DateFormat)
 	res.SADistribution = make([]entity.Distribution, 0, 4)
 	res.MRADistribution = make([]entity.Distribution, 0, 2)
 	res.AreaServiceTrends = make([]entity.AreaServiceTrends, 0, 4)
@@ -42,168 +45,31 @@ func (r *dashboardRepository) GetAdminSummary(ctx context.Context, req *entity.G
 }
 
 func (r *dashboardRepository) getSASummary(ctx context.Context, req *entity.GetSummaryPerMonthRequest, res *entity.GetSummaryPerMonthResponse) error {
-	type daoPotency struct {
-		Id   string `db:"id"`
-		Name string `db:"name"`
-	}
-
-	var potencies = make([]daoPotency, 0, 4)
-	res.SASummary = make([]entity.Summary, 0, 4)
-
-	query := `
-		SELECT
-			id,
-			name
-		FROM
-			potencies
-		WHERE
-			name != 'Used-car'
-	`
-	err := r.db.SelectContext(ctx, &potencies, r.db.Rebind(query))
-	if err != nil {
-		log.Error().Err(err).Any("payload", req).Msg("repo::getSASummary - failed to get wac summary")
-		return err
-	}
-
-	for _, potency := range potencies {
-		query = `
-			SELECT
-				'` + potency.Name + `' AS title,
-				COALESCE(SUM(1), 0) AS total_potencial_leads,
-				COALESCE(SUM(CASE WHEN wacc.is_interested = TRUE AND wac.status != 'offered' THEN 1 ELSE 0 END), 0) AS total_leads,
-				COALESCE(SUM(CASE WHEN wacc.is_interested = TRUE AND wac.status = 'completed' THEN 1 ELSE 0 END), 0) AS total_wo_do
-			FROM
-				walk_around_check_conditions wacc
-			LEFT JOIN
-				walk_around_checks wac
-				ON wac.id = wacc.walk_around_check_id
-			WHERE
-				wacc.potency_id = ?
-				AND TO_CHAR(wac.created_at AT TIME ZONE '` + req.Timezone + `', 'YYYY-MM') = ?
-		`
-
-		var summary entity.Summary
-		err = r.db.QueryRowxContext(ctx, r.db.Rebind(query), potency.Id, req.Month).StructScan(&summary)
-		if err != nil {
-			log.Error().Err(err).Any("payload", req).Msg("repo::getSASummary - failed to get wac summary")
-			return err
-		}
-
-		res.SASummary = append(res.SASummary, summary)
-	}
-
-	// for used-car, the wo/do is based on w ac that status is completed (invoice_number column is not null)
-	query = `
-		WITH total_wo_do_alt AS (
-			SELECT
-				COUNT(wac.id) AS total_wo_do
-			FROM
-				walk_around_checks wac
-			WHERE
-				wac.status = 'completed'
-				AND Wac.is_used_car = TRUE
-				AND TO_CHAR(wac.created_at AT TIME ZONE '` + req.Timezone + `', 'YYYY-MM') = ?
-		),
-		total_leads_alt AS (
-			SELECT
-				COUNT(wacc.id) AS total_leads
-			FROM
-				walk_around_check_conditions wacc
-			LEFT JOIN
-				walk_around_checks wac
-				ON wac.id = wacc.walk_around_check_id
-			WHERE
-				wac.is_used_car = TRUE
-				AND TO_CHAR(wac.created_at AT TIME ZONE '` + req.Timezone + `', 'YYYY-MM') = ?
-		)
-		SELECT
-			'Used-car' AS title,
-			(SELECT total_leads FROM total_leads_alt) AS total_potencial_leads,
-			(SELECT total_leads FROM total_leads_alt) AS total_leads,
-			(SELECT total_wo_do FROM total_wo_do_alt) AS total_wo_do
-	`
-
-	var summary entity.Summary
-	err = r.db.QueryRowxContext(ctx, r.db.Rebind(query), req.Month, req.Month).StructScan(&summary)
+	summaries, err := r.potencySummaries(ctx, factScope{}, req.Period, true)
 	if err != nil {
 		log.Error().Err(err).Any("payload", req).Msg("repo::getSASummary - failed to get wac summary")
 		return err
 	}
 
-	res.SASummary = append(res.SASummary, summary)
+	res.SASummary = summaries
 
 	return nil
 }
 
 func (r *dashboardRepository) getMRASummary(ctx context.Context, req *entity.GetSummaryPerMonthRequest, res *entity.GetSummaryPerMonthResponse) error {
-	query := `
-	WITH total_leads_wac_need_follow_up AS (
-		SELECT
-			COALESCE(SUM(1), 0) AS total_leads
-		FROM
-			walk_around_check_conditions wacc
-		LEFT JOIN
-			walk_around_checks wac
-			ON wac.id = wacc.walk_around_check_id
-		WHERE
-			wacc.is_interested = TRUE
-			AND wac.status = 'completed'
-			AND wac.is_needs_follow_up = TRUE
-			AND TO_CHAR(wac.created_at AT TIME ZONE '` + req.Timezone + `', 'YYYY-MM') = ?
-	)
-	SELECT
-		COALESCE(SUM(CASE WHEN wac.is_needs_follow_up = TRUE THEN 1 ELSE 0 END), 0) AS total_wac_need_follow_up,
-		COALESCE(SUM(CASE WHEN wac.is_followed_up = TRUE THEN 1 ELSE 0 END), 0) AS total_wac_followed_up,
-		(SELECT total_leads FROM total_leads_wac_need_follow_up) AS total_leads
-	FROM
-		walk_around_checks wac
-	WHERE
-		wac.status = 'completed'
-		AND TO_CHAR(wac.created_at AT TIME ZONE '` + req.Timezone + `', 'YYYY-MM') = ?
-`
-
-	err := r.db.QueryRowxContext(ctx, r.db.Rebind(query), req.Month, req.Month).StructScan(&res.MRASummary)
+	summary, err := r.followUpSummary(ctx, factScope{}, req.Period)
 	if err != nil {
-		log.Error().Err(err).Any("payload", req).Msg("repo::GetWACSummaryTechnician - failed to get wac summary")
+		log.Error().Err(err).Any("payload", req).Msg("repo::getMRASummary - failed to get wac summary")
 		return err
 	}
 
+	res.MRASummary = summary
+
 	return nil
 }
 
 func (r *dashboardRepository) getSADistribution(ctx context.Context, req *entity.GetSummaryPerMonthRequest, res *entity.GetSummaryPerMonthResponse) error {
-	query := `
-		SELECT
-			potencies.name AS title,
-			COALESCE(SUM(
-				CASE
-					WHEN
-						wacc.is_interested = TRUE
-						AND wac.status != 'offered'
-						AND TO_CHAR(wac.created_at AT TIME ZONE '` + req.Timezone + `', 'YYYY-MM') = ?
-					THEN 1
-					ELSE 0 END
-				), 0) AS total
-		FROM
-			potencies
-		LEFT JOIN
-			walk_around_check_conditions wacc
-			ON potencies.id = wacc.potency_id
-		LEFT JOIN
-			walk_around_checks wac
-			ON wac.id = wacc.walk_around_check_id
-		GROUP BY
-			potencies.name
-	`
-
-	type daoDistribution struct {
-		Title string `db:"title"`
-		Total int    `db:"total"`
-	}
-
-	var distributions = make([]daoDistribution, 0, 4)
-
-	err := r.db.SelectContext(ctx, &distributions, r.db.Rebind(query), req.Month)
+	distributions, err := r.leadsPerPotency(ctx, factScope{}, req.Period)
 	if err != nil {
 		log.Error().Err(err).Any("payload", req).Msg("repo::getSADistribution - failed to get wac summary")
 		return err
@@ -212,7 +78,7 @@ func (r *dashboardRepository) getSADistribution(ctx context.Context, req *entity
 	var totalLeads int // total leads from all potencies
 	for _, distribution := range distributions {
 		res.SADistribution = append(res.SADistribution, entity.Distribution{
-			Title: distribution.Title,
+			Title: distribution.Name,
 			Total: distribution.Total,
 		})
 
@@ -249,33 +115,7 @@ func (r *dashboardRepository) getSADistribution(ctx context.Context, req *entity
 }
 
 func (r *dashboardRepository) getMRADistribution(ctx context.Context, req *entity.GetSummaryPerMonthRequest, res *entity.GetSummaryPerMonthResponse) error {
-	query := `
-	SELECT
-		id,
-		name,
-		(
-			SELECT
-				COALESCE(SUM(1), 0)
-			FROM
-				walk_around_check_conditions wacc
-			LEFT JOIN
-				walk_around_checks wac
-				ON wac.id = wacc.walk_around_check_id
-			WHERE
-				wacc.potency_id = potencies.id
-				AND wac.status = 'completed'
-				AND wac.is_needs_follow_up = TRUE
-				AND wacc.is_interested = TRUE
-				AND TO_CHAR(wac.created_at AT TIME ZONE '` + req.Timezone + `', 'YYYY-MM') = ?
-		) AS total
-	FROM
-		potencies
-`
-
-	potencies := make([]daoTotalLeadsPerPotency, 0, 4)
-	err := r.db.SelectContext(ctx, &potencies, r.db.Rebind(query),
-		req.Month,
-	)
+	potencies, err := r.followUpLeadsPerPotency(ctx, factScope{}, req.Period)
 	if err != nil {
 		log.Error().Err(err).Any("payload", req).Msg("repo::getMRADistribution - failed to get wac summary")
 		return err
@@ -321,30 +161,7 @@ func (r *dashboardRepository) getMRADistribution(ctx context.Context, req *entit
 }
 
 func (r *dashboardRepository) getAreaServiceTrends(ctx context.Context, req *entity.GetSummaryPerMonthRequest, res *entity.GetSummaryPerMonthResponse) error {
-	query := `
-	SELECT
-		a.name AS area,
-		a.type,
-		COALESCE(SUM(CASE WHEN wacc.is_interested = TRUE AND wac.status != 'offered' THEN 1 ELSE 0 END), 0) AS leads
-	FROM
-		areas a
-	LEFT JOIN
-		walk_around_check_conditions wacc
-		ON a.id = wacc.area_id
-	LEFT JOIN
-		walk_around_checks wac
-		ON wac.id = wacc.walk_around_check_id
-		AND TO_CHAR(wac.created_at AT TIME ZONE '` + req.Timezone + `', 'YYYY-MM') = ?
-	GROUP BY
-		a.name,
-		a.type
-	ORDER BY
-		leads DESC,
-		a.type, a.name ASC
-	`
-
-	var areaServiceTrends = make([]entity.AreaServiceTrends, 0, 15)
-	err := r.db.SelectContext(ctx, &areaServiceTrends, r.db.Rebind(query), req.Month)
+	areaServiceTrends, err := r.areaLeads(ctx, factScope{}, req.Period)
 	if err != nil {
 		log.Error().Err(err).Any("payload", req).Msg("repo::getAreaServiceTrends - failed to get wac summary")
 		return err
@@ -0,0 +1,329 @@
+package repository
+
+import (
+	"codebase-app/internal/module/dashboard/entity"
+	"codebase-app/pkg/period"
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// The dashboards read the daily fact tables maintained by the reporting
+// module, days there are bucketed in period.DefaultTimezone. A period in
+// another timezone reads the same columns aggregated from the raw tables.
+
+const (
+	factWAC          = "fact_wac_daily"
+	factWACCondition = "fact_wac_condition_daily"
+)
+
+// rawFacts aggregate the raw tables like the reporting module refreshes the
+// fact tables, the day in the timezone of the period.
+var rawFacts = map[string]string{
+	factWAC: `(
+		SELECT
+			(wac.created_at AT TIME ZONE ?)::date AS day,
+			wac.branch_id,
+			wac.user_id,
+			wac.status,
+			wac.is_used_car,
+			wac.is_needs_follow_up,
+			COUNT(*) AS total_wacs,
+			COUNT(*) FILTER (WHERE wac.is_followed_up = TRUE) AS total_followed_up,
+			COALESCE(SUM(wac.revenue), 0) AS revenue
+		FROM
+			walk_around_checks wac
+		WHERE
+			wac.deleted_at IS NULL
+			AND wac.created_at >= ?
+			AND wac.created_at < ?
+		GROUP BY 1, 2, 3, 4, 5, 6
+	)`,
+	factWACCondition: `(
+		SELECT
+			(wac.created_at AT TIME ZONE ?)::date AS day,
+			wac.branch_id,
+			wac.user_id,
+			wacc.potency_id,
+			wacc.area_id,
+			wac.status,
+			wac.is_used_car,
+			wac.is_needs_follow_up,
+			COUNT(*) AS total_conditions,
+			COUNT(*) FILTER (WHERE wacc.is_interested = TRUE) AS total_interested,
+			COALESCE(SUM(wacc.revenue), 0) AS revenue
+		FROM
+			walk_around_check_conditions wacc
+		JOIN
+			walk_around_checks wac
+			ON wac.id = wacc.walk_around_check_id
+		WHERE
+			wacc.deleted_at IS NULL
+			AND wac.deleted_at IS NULL
+			AND wac.created_at >= ?
+			AND wac.created_at < ?
+		GROUP BY 1, 2, 3, 4, 5, 6, 7, 8
+	)`,
+}
+
+// factSource returns what to read the facts of table from for the period
+// and the arguments it binds, placed where the source is in the query.
+func factSource(table string, p period.Period) (string, []any) {
+	if p.Location == nil || p.Location.String() == period.DefaultTimezone {
+		return table, nil
+	}
+
+	return rawFacts[table], []any{p.Location.String(), p.From, p.End()}
+}
+
+// factScope narrows the facts to a user or to the branch of a user, the zero
+// value covers every branch.
+type factScope struct {
+	userId       string
+	branchUserId string
+}
+
+func (s factScope) filter() (string, []any) {
+	switch {
+	case s.userId != "":
+		return ` AND f.user_id = ?`, []any{s.userId}
+	case s.branchUserId != "":
+		return ` AND f.branch_id = (SELECT branch_id FROM users WHERE id = ?)`, []any{s.branchUserId}
+	default:
+		return "", nil
+	}
+}
+
+// factDayRange filters the facts on [from, to)
+const factDayRange = ` AND f.day >= ?::date AND f.day < ?::date`
+
+// potencySummaries returns the leads per potency followed by the used-car
+// row, whose wo/do is the number of completed used-car WACs.
+func (r *dashboardRepository) potencySummaries(ctx context.Context, scope factScope, p period.Period, usedCarOfferedLeads bool) ([]entity.Summary, error) {
+	var (
+		res                 = make([]entity.Summary, 0, 5)
+		from, to            = p.FromDate(), p.EndDate()
+		cond, arg           = scope.filter()
+		wacs, wacsArg       = factSource(factWAC, p)
+		conditions, condArg = factSource(factWACCondition, p)
+	)
+
+	query := `
+		SELECT
+			p.name AS title,
+			COALESCE(SUM(f.total_conditions), 0) AS total_potencial_leads,
+			COALESCE(SUM(f.total_interested) FILTER (WHERE f.status != 'offered'), 0) AS total_leads,
+			COALESCE(SUM(f.total_interested) FILTER (WHERE f.status = 'completed'), 0) AS total_wo_do
+		FROM
+			potencies p
+		LEFT JOIN
+			` + conditions + ` f
+			ON f.potency_id = p.id` + factDayRange + cond + `
+		WHERE
+			p.name != 'Used-car'
+		GROUP BY
+			p.id, p.name, p.created_at
+		ORDER BY
+			p.created_at, p.name
+	`
+
+	args := append(append(condArg, from, to), arg...)
+
+	err := r.db.SelectContext(ctx, &res, r.db.Rebind(query), args...)
+	if err != nil {
+		log.Error().Err(err).Str("from", from).Str("to", to).Msg("repo::potencySummaries - failed to get summary per potency")
+		return nil, err
+	}
+
+	leadsStatus := ` AND f.status != 'offered'`
+	if usedCarOfferedLeads {
+		leadsStatus = ""
+	}
+
+	query = `
+		WITH total_leads_alt AS (
+			SELECT
+				COALESCE(SUM(f.total_conditions), 0) AS total_leads
+			FROM
+				` + conditions + ` f
+			WHERE
+				f.is_used_car = TRUE` + leadsStatus + factDayRange + cond + `
+		),
+		total_wo_do_alt AS (
+			SELECT
+				COALESCE(SUM(f.total_wacs), 0) AS total_wo_do
+			FROM
+				` + wacs + ` f
+			WHERE
+				f.is_used_car = TRUE
+				AND f.status = 'completed'` + factDayRange + cond + `
+		)
+		SELECT
+			'Used-car' AS title,
+			(SELECT total_leads FROM total_leads_alt) AS total_potencial_leads,
+			(SELECT total_leads FROM total_leads_alt) AS total_leads,
+			(SELECT total_wo_do FROM total_wo_do_alt) AS total_wo_do
+	`
+
+	args = append(append(condArg, from, to), arg...)
+	args = append(append(append(args, wacsArg...), from, to), arg...)
+
+	var summary entity.Summary
+	err = r.db.QueryRowxContext(ctx, r.db.Rebind(query), args...).StructScan(&summary)
+	if err != nil {
+		log.Error().Err(err).Str("from", from).Str("to", to).Msg("repo::potencySummaries - failed to get used-car summary")
+		return nil, err
+	}
+
+	return append(res, summary), nil
+}
+
+// followUpSummary counts the completed WACs that need (or had) a follow up
+// and their interested conditions.
+func (r *dashboardRepository) followUpSummary(ctx context.Context, scope factScope, p period.Period) (entity.MRASummary, error) {
+	var (
+		res                 entity.MRASummary
+		from, to            = p.FromDate(), p.EndDate()
+		cond, arg           = scope.filter()
+		wacs, wacsArg       = factSource(factWAC, p)
+		conditions, condArg = factSource(factWACCondition, p)
+	)
+
+	query := `
+		SELECT
+			COALESCE(SUM(f.total_wacs) FILTER (WHERE f.is_needs_follow_up = TRUE), 0) AS total_wac_need_follow_up,
+			COALESCE(SUM(f.total_followed_up), 0) AS total_wac_followed_up,
+			(
+				SELECT
+					COALESCE(SUM(f.total_interested), 0)
+				FROM
+					` + conditions + ` f
+				WHERE
+					f.status = 'completed'
+					AND f.is_needs_follow_up = TRUE` + factDayRange + cond + `
+			) AS total_leads
+		FROM
+			` + wacs + ` f
+		WHERE
+			f.status = 'completed'` + factDayRange + cond + `
+	`
+
+	args := append(append(condArg, from, to), arg...)
+	args = append(append(append(args, wacsArg...), from, to), arg...)
+
+	err := r.db.QueryRowxContext(ctx, r.db.Rebind(query), args...).StructScan(&res)
+	if err != nil {
+		log.Error().Err(err).Str("from", from).Str("to", to).Msg("repo::followUpSummary - failed to get follow up summary")
+		return res, err
+	}
+
+	return res, nil
+}
+
+// followUpLeadsPerPotency counts the interested conditions of completed WACs
+// that need a follow up, per potency.
+func (r *dashboardRepository) followUpLeadsPerPotency(ctx context.Context, scope factScope, p period.Period) ([]daoTotalLeadsPerPotency, error) {
+	var (
+		res                 = make([]daoTotalLeadsPerPotency, 0, 5)
+		from, to            = p.FromDate(), p.EndDate()
+		cond, arg           = scope.filter()
+		conditions, condArg = factSource(factWACCondition, p)
+	)
+
+	query := `
+		SELECT
+			p.id,
+			p.name,
+			COALESCE(SUM(f.total_interested), 0) AS total
+		FROM
+			potencies p
+		LEFT JOIN
+			` + conditions + ` f
+			ON f.potency_id = p.id
+			AND f.status = 'completed'
+			AND f.is_needs_follow_up = TRUE` + factDayRange + cond + `
+		GROUP BY
+			p.id, p.name, p.created_at
+		ORDER BY
+			p.created_at, p.name
+	`
+
+	err := r.db.SelectContext(ctx, &res, r.db.Rebind(query), append(append(condArg, from, to), arg...)...)
+	if err != nil {
+		log.Error().Err(err).Str("from", from).Str("to", to).Msg("repo::followUpLeadsPerPotency - failed to get leads per potency")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// leadsPerPotency counts the interested conditions of offered-out WACs
+// (status wip or completed), per potency.
+func (r *dashboardRepository) leadsPerPotency(ctx context.Context, scope factScope, p period.Period) ([]daoTotalLeadsPerPotency, error) {
+	var (
+		res                 = make([]daoTotalLeadsPerPotency, 0, 5)
+		from, to            = p.FromDate(), p.EndDate()
+		cond, arg           = scope.filter()
+		conditions, condArg = factSource(factWACCondition, p)
+	)
+
+	query := `
+		SELECT
+			p.id,
+			p.name,
+			COALESCE(SUM(f.total_interested), 0) AS total
+		FROM
+			potencies p
+		LEFT JOIN
+			` + conditions + ` f
+			ON f.potency_id = p.id
+			AND f.status != 'offered'` + factDayRange + cond + `
+		GROUP BY
+			p.id, p.name, p.created_at
+		ORDER BY
+			p.created_at, p.name
+	`
+
+	err := r.db.SelectContext(ctx, &res, r.db.Rebind(query), append(append(condArg, from, to), arg...)...)
+	if err != nil {
+		log.Error().Err(err).Str("from", from).Str("to", to).Msg("repo::leadsPerPotency - failed to get leads per potency")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// areaLeads counts the interested conditions of offered-out WACs per area.
+func (r *dashboardRepository) areaLeads(ctx context.Context, scope factScope, p period.Period) ([]entity.AreaServiceTrends, error) {
+	var (
+		res                 = make([]entity.AreaServiceTrends, 0, 15)
+		from, to            = p.FromDate(), p.EndDate()
+		cond, arg           = scope.filter()
+		conditions, condArg = factSource(factWACCondition, p)
+	)
+
+	query := `
+		SELECT
+			a.name AS area,
+			a.type,
+			COALESCE(SUM(f.total_interested), 0) AS leads
+		FROM
+			areas a
+		LEFT JOIN
+			` + conditions + ` f
+			ON f.area_id = a.id
+			AND f.status != 'offered'` + factDayRange + cond + `
+		GROUP BY
+			a.type, a.name
+		ORDER BY
+			leads DESC, a.type, a.name ASC
+	`
+
+	err := r.db.SelectContext(ctx, &res, r.db.Rebind(query), append(append(condArg, from, to), arg...)...)
+	if err != nil {
+		log.Error().Err(err).Str("from", from).Str("to", to).Msg("repo::areaLeads - failed to get leads per area")
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"codebase-app/pkg/period"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFactSource(t *testing.T) {
+	p, err := period.Request{From: "2024-10-01", To: "2024-10-31"}.Resolve(period.Default{})
+	assert.NoError(t, err)
+
+	source, args := factSource(factWAC, p)
+	assert.Equal(t, factWAC, source)
+	assert.Empty(t, args)
+
+	p, err = period.Request{From: "2024-10-01", To: "2024-10-31", Timezone: "Asia/Jakarta"}.Resolve(period.Default{})
+	assert.NoError(t, err)
+
+	source, args = factSource(factWACCondition, p)
+	assert.True(t, strings.Contains(source, "walk_around_check_conditions"))
+	assert.Equal(t, []any{"Asia/Jakarta", p.From, p.End()}, args)
+	assert.Equal(t, 3, strings.Count(source, "?"))
+}
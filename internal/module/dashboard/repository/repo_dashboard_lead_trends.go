@@ -2,83 +2,98 @@ package repository
 
 import (
 	"codebase-app/internal/module/dashboard/entity"
+	"codebase-app/pkg/period"
 	"context"
-	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
 func (r *dashboardRepository) GetLeadsTrends(ctx context.Context, req *entity.LeadTrendsRequest) ([]entity.LeadTrendsResponse, error) {
-	var res = make([]entity.LeadTrendsResponse, 0)
+	res, err := r.leadTrends(ctx, req.UserId, req.Period)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetLeadsTrends - failed to get leads trends")
+		return nil, err
+	}
+
+	if req.Period.Compare {
+		prev, err := r.leadTrends(ctx, req.UserId, req.Period.Previous())
+		if err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::GetLeadsTrends - failed to get previous leads trends")
+			return nil, err
+		}
+
+		for i := range res {
+			if i < len(prev) {
+				res[i].Previous = &prev[i]
+			}
+		}
+	}
+
+	// the latest bucket comes first
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+
+	return res, nil
+}
+
+// leadTrends returns one zero-filled item per bucket of the period in
+// ascending order.
+func (r *dashboardRepository) leadTrends(ctx context.Context, userId string, p period.Period) ([]entity.LeadTrendsResponse, error) {
+	var (
+		data            = make([]entity.LeadTrendsResponse, 0)
+		conditions, arg = factSource(factWACCondition, p)
+	)
 
 	query := `
 		SELECT
-			TO_CHAR(wacc.created_at, 'YYYY/Mon') AS month,
-			SUM(
-				CASE
-					WHEN
-						wac.user_id = ?
-					THEN 1 ELSE 0 END
-			) AS review_conditions,
-			SUM(
-				CASE
-					WHEN
-						wac.user_id = ?
-						AND wacc.is_interested = TRUE
-						AND wac.status != 'offered'
-					THEN 1 ELSE 0 END
-			) AS leads
+			TO_CHAR(date_trunc(?::text, f.day), 'YYYY-MM-DD') AS bucket,
+			COALESCE(SUM(f.total_conditions), 0) AS review_conditions,
+			COALESCE(SUM(f.total_interested) FILTER (WHERE f.status != 'offered'), 0) AS leads
 		FROM
-			walk_around_check_conditions wacc
-		LEFT JOIN
-			walk_around_checks wac
-			ON wac.id = wacc.walk_around_check_id
+			` + conditions + ` f
 		WHERE
-			wacc.created_at >= NOW() - INTERVAL '11 months'
+			f.user_id = ?` + factDayRange + `
 		GROUP BY
-			TO_CHAR(wacc.created_at, 'YYYY/Mon')
-		ORDER BY
-			TO_CHAR(wacc.created_at, 'YYYY/Mon') DESC
+			1
 	`
 
-	err := r.db.SelectContext(ctx, &res, r.db.Rebind(query), req.UserId, req.UserId)
+	args := append(append([]any{p.Granularity}, arg...), userId, p.FromDate(), p.EndDate())
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...)
 	if err != nil {
-		log.Error().Err(err).Any("payload", req).Msg("repo::GetLeadsTrends - failed to get leads trends")
 		return nil, err
 	}
 
-	// feel the data if blank for 12 months
-
-	length := len(res)
-
-	if length < 12 {
-		newRes := make([]entity.LeadTrendsResponse, 0)
-		for i := 0; i < 12; i++ {
-			month := time.Now().AddDate(0, -i, 0).Format("2006/Jan")
-			found := false
-			for j := 0; j < length; j++ {
-				if res[j].Month == month {
-					newRes = append(newRes, res[j])
-					found = true
-					break
-				}
-			}
-			if !found {
-				newRes = append(newRes, entity.LeadTrendsResponse{
-					Month:            month,
-					ReviewConditions: 0,
-					Leads:            0,
-				})
-			}
+	byBucket := make(map[string]entity.LeadTrendsResponse, len(data))
+	for _, d := range data {
+		byBucket[d.Bucket] = d
+	}
+
+	buckets := p.Buckets()
+	res := make([]entity.LeadTrendsResponse, 0, len(buckets))
+	for _, b := range buckets {
+		item, ok := byBucket[b.Key]
+		if !ok {
+			item = entity.LeadTrendsResponse{Bucket: b.Key}
 		}
 
-		res = newRes
+		item.Month = bucketLabel(p.Granularity, b)
+		res = append(res, item)
 	}
 
-	// reverse the data
-	// for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
-	// 	res[i], res[j] = res[j], res[i]
-	// }
-
 	return res, nil
 }
+
+// bucketLabel keeps the 2006/Jan label the lead trends always returned for
+// months.
+func bucketLabel(granularity string, b period.Bucket) string {
+	switch granularity {
+	case period.Month:
+		return b.Start.Format("2006/Jan")
+	case period.Quarter:
+		return b.Start.Format("2006") + "/Q" + string(rune('1'+(b.Start.Month()-1)/3))
+	default:
+		return b.Key
+	}
+}
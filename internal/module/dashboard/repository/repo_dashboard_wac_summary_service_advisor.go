@@ -3,6 +3,7 @@ package repository
 import (
 	"codebase-app/internal/infrastructure/config"
 	"codebase-app/internal/module/dashboard/entity"
+	"codebase-app/pkg/period"
 	"context"
 	"strings"
 
@@ -15,7 +16,9 @@ func (r *dashboardRepository) GetWACSummary(ctx context.Context, req *entity.WAC
 	)
 	res.Summaries = make([]entity.Summary, 0, 4)
 	res.DistributionOfLeads = make([]entity.Distribution, 0, 4)
-	res.Month = req.Month // 2006-01
+	res.Month = req.Period.From.Format("2006-01")
+	res.From = req.Period.FromDate()
+	res.To = req.Period.To.Format(period.DateFormat)
 
 	// counting walk around checks based on user id and month
 	err := r.summaryWACCount(ctx, req, &res)
@@ -29,11 +32,6 @@ func (r *dashboardRepository) GetWACSummary(ctx context.Context, req *entity.WAC
 		return res, err
 	}
 
-	err = r.summaryTiers(ctx, req, &res)
-	if err != nil {
-		return res, err
-	}
-
 	err = r.summaryPromotions(ctx, req, &res)
 	if err != nil {
 		return res, err
@@ -52,19 +50,24 @@ func (r *dashboardRepository) GetWACSummary(ctx context.Context, req *entity.WAC
 }
 
 func (r *dashboardRepository) summaryWACCount(ctx context.Context, req *entity.WACSummaryRequest, res *entity.WACSummaryResponse) error {
+	var (
+		from, to  = req.Period.FromDate(), req.Period.EndDate()
+		wacs, arg = factSource(factWAC, req.Period)
+	)
+
 	query := `
 		SELECT
-			COUNT(wac.id) AS wac_counts,
-			COALESCE(SUM(CASE WHEN wac.status = 'offered' THEN 1 ELSE 0 END), 0)
-				AS total_wac_on_offered
+			COALESCE(SUM(f.total_wacs), 0) AS wac_counts,
+			COALESCE(SUM(f.total_wacs) FILTER (WHERE f.status = 'offered'), 0) AS total_wac_on_offered
 		FROM
-			walk_around_checks wac
+			` + wacs + ` f
 		WHERE
-			wac.user_id = ?
-			AND TO_CHAR(wac.created_at AT TIME ZONE 'Asia/Makassar', 'YYYY-MM') = ?
+			f.user_id = ?` + factDayRange + `
 	`
 
-	err := r.db.QueryRowxContext(ctx, r.db.Rebind(query), req.UserId, req.Month).Scan(&res.WACCounts, &res.TotalWACOnOffered)
+	args := append(arg, req.UserId, from, to)
+
+	err := r.db.QueryRowxContext(ctx, r.db.Rebind(query), args...).Scan(&res.WACCounts, &res.TotalWACOnOffered)
 	if err != nil {
 		log.Error().Err(err).Any("payload", req).Msg("repo::GetWACSummary - failed to get wac summary")
 		return err
@@ -74,105 +77,16 @@ func (r *dashboardRepository) summaryWACCount(ctx context.Context, req *entity.W
 }
 
 func (r *dashboardRepository) summaryPerPotency(ctx context.Context, req *entity.WACSummaryRequest, res *entity.WACSummaryResponse) error {
-	type daoPotency struct {
-		Id   string `db:"id"`
-		Name string `db:"name"`
-	}
-
-	var potencies = make([]daoPotency, 0, 4)
-	res.Summaries = make([]entity.Summary, 0, 4)
-
-	query := `
-		SELECT
-			id,
-			name
-		FROM
-			potencies
-		WHERE
-			name != 'Used-car'
-	`
-	err := r.db.SelectContext(ctx, &potencies, r.db.Rebind(query))
+	summaries, err := r.potencySummaries(ctx, factScope{userId: req.UserId}, req.Period, false)
 	if err != nil {
-		log.Error().Err(err).Any("payload", req).Msg("repo::GetWACSummaryPerPotency - failed to get wac summary")
 		return err
 	}
 
-	for _, potency := range potencies {
-		query = `
-			SELECT
-				'` + potency.Name + `' AS title,
-				COALESCE(SUM(1), 0) AS total_potencial_leads,
-				COALESCE(SUM(CASE WHEN wacc.is_interested = TRUE AND wac.status != 'offered' THEN 1 ELSE 0 END), 0) AS total_leads,
-				COALESCE(SUM(CASE WHEN wacc.is_interested = TRUE AND wac.status = 'completed' THEN 1 ELSE 0 END), 0) AS total_wo_do
-			FROM
-				walk_around_check_conditions wacc
-			LEFT JOIN
-				walk_around_checks wac
-				ON wac.id = wacc.walk_around_check_id
-			WHERE
-				wac.user_id = ?
-				AND wacc.potency_id = ?
-				AND TO_CHAR(wac.created_at AT TIME ZONE 'Asia/Makassar', 'YYYY-MM') = ?
-		`
-
-		var summary entity.Summary
-		err = r.db.QueryRowxContext(ctx, r.db.Rebind(query), req.UserId, potency.Id, req.Month).StructScan(&summary)
-		if err != nil {
-			log.Error().Err(err).Any("payload", req).Msg("repo::GetWACSummaryPerPotency - failed to get wac summary")
-			return err
-		}
-
+	res.Summaries = summaries
+	for _, summary := range summaries {
 		res.TotalLeadDistributions += summary.TotalLeads
-		res.Summaries = append(res.Summaries, summary)
-	}
-
-	// for used-car, the wo/do is based on w ac that status is completed (invoice_number column is not null)
-	query = `
-		WITH total_wo_do_alt AS (
-			SELECT
-				COUNT(wac.id) AS total_wo_do
-			FROM
-				walk_around_checks wac
-			WHERE
-				wac.status = 'completed'
-				AND Wac.is_used_car = TRUE
-				AND wac.user_id = ?
-				AND TO_CHAR(wac.created_at AT TIME ZONE 'Asia/Makassar', 'YYYY-MM') = ?
-		),
-		total_leads_alt AS (
-			SELECT
-				COUNT(wacc.id) AS total_leads
-			FROM
-				walk_around_check_conditions wacc
-			LEFT JOIN
-				walk_around_checks wac
-				ON wac.id = wacc.walk_around_check_id
-			WHERE
-				wac.is_used_car = TRUE
-				AND wac.status != 'offered'
-				AND wac.user_id = ?
-				AND TO_CHAR(wac.created_at AT TIME ZONE 'Asia/Makassar', 'YYYY-MM') = ?
-		)
-		SELECT
-			'Used-car' AS title,
-			(SELECT total_leads FROM total_leads_alt) AS total_potencial_leads,
-			(SELECT total_leads FROM total_leads_alt) AS total_leads,
-			(SELECT total_wo_do FROM total_wo_do_alt) AS total_wo_do
-	`
-
-	var summary entity.Summary
-	err = r.db.QueryRowxContext(ctx, r.db.Rebind(query),
-		req.UserId, req.Month,
-		req.UserId, req.Month,
-	).StructScan(&summary)
-	if err != nil {
-		log.Error().Err(err).Any("payload", req).Msg("repo::GetWACSummaryPerPotency - failed to get wac summary")
-		return err
 	}
 
-	res.Summaries = append(res.Summaries, summary)
-	res.TotalLeadDistributions += summary.TotalLeads
-
 	return nil
 }
 
@@ -226,105 +140,18 @@ func (r *dashboardRepository) summaryLeadsDistribution(res *entity.WACSummaryRes
 }
 
 func (r *dashboardRepository) summaryWACArea(ctx context.Context, req *entity.WACSummaryRequest, res *entity.WACSummaryResponse) error {
-	query := `
-		SELECT
-			a.name AS area,
-			a.type,
-			COALESCE(SUM(CASE WHEN wacc.area_id = a.id AND wacc.is_interested = TRUE THEN 1 ELSE 0 END), 0) AS leads
-		FROM
-			areas a
-		LEFT JOIN
-			walk_around_check_conditions wacc
-			ON wacc.area_id = a.id
-		LEFT JOIN
-			walk_around_checks wac
-			ON wac.id = wacc.walk_around_check_id
-			AND wac.user_id = ?
-			AND wac.status != 'offered'
-			AND TO_CHAR(wac.created_at AT TIME ZONE 'Asia/Makassar', 'YYYY-MM') = ?
-		GROUP BY
-			a.type, a.name
-		ORDER BY
-			leads DESC, a.type, a.name ASC
-		`
-
-	err := r.db.SelectContext(ctx, &res.ServiceTrends, r.db.Rebind(query), req.UserId, req.Month)
+	areas, err := r.areaLeads(ctx, factScope{userId: req.UserId}, req.Period)
 	if err != nil {
-		log.Error().Err(err).Any("payload", req).Msg("repo::GetWACSummary - failed to get wac summary")
 		return err
 	}
 
-	return nil
-}
-
-func (r *dashboardRepository) summaryTiers(ctx context.Context, req *entity.WACSummaryRequest, res *entity.WACSummaryResponse) error {
-	var (
-		currentYear            = res.Month[:4]
-		totalRevenueNotUsedCar float64
-		totalRevenueUsedCar    float64
-		currentTier            string
-		nextTier               *string
-	)
-
-	query := `
-		SELECT
-			COALESCE(SUM(CASE WHEN wac.status = 'completed' THEN wacc.revenue ELSE 0 END), 0) AS revenue
-		FROM
-			walk_around_check_conditions wacc
-		LEFT JOIN
-			walk_around_checks wac
-			ON wac.id = wacc.walk_around_check_id
-		WHERE
-			wac.user_id = ?
-			AND TO_CHAR(wac.created_at AT TIME ZONE 'Asia/Makassar', 'YYYY') = ?
-	`
-
-	err := r.db.QueryRowxContext(ctx, r.db.Rebind(query), req.UserId, currentYear).Scan(&totalRevenueNotUsedCar)
-	if err != nil {
-		log.Error().Err(err).Any("payload", req).Msg("repo::GetWACSummary - failed to get wac summary")
-		return err
-	}
-
-	query = `
-		SELECT
-			COALESCE(
-				SUM(
-					CASE
-						WHEN
-							wac.status = 'completed'
-							AND is_used_car = TRUE
-						THEN wac.revenue
-						ELSE 0
-					END),
-			0) AS revenue
-		FROM
-			walk_around_checks wac
-		WHERE
-			wac.user_id = ?
-			AND TO_CHAR(wac.created_at AT TIME ZONE 'Asia/Makassar', 'YYYY') = ?
-	`
-
-	err = r.db.QueryRowxContext(ctx, r.db.Rebind(query), req.UserId, currentYear).Scan(&totalRevenueUsedCar)
-	if err != nil {
-		log.Error().Err(err).Any("payload", req).Msg("repo::GetWACSummary - failed to get wac summary")
-		return err
-	}
-
-	totalRevenue := totalRevenueNotUsedCar + totalRevenueUsedCar
-	if totalRevenue >= 1500000 {
-		currentTier = "platinum"
-	} else if totalRevenue >= 1000000 {
-		currentTier = "gold"
-		nextTier = stringPointer("platinum")
-	} else {
-		currentTier = "silver"
-		nextTier = stringPointer("gold")
-	}
-
-	res.Tiers = entity.Tier{
-		Current: currentTier,
-		Next:    nextTier,
-		Revenue: totalRevenue,
+	res.ServiceTrends = make([]entity.Trend, 0, len(areas))
+	for _, a := range areas {
+		res.ServiceTrends = append(res.ServiceTrends, entity.Trend{
+			Types: a.Type,
+			Area:  a.Area,
+			Leads: a.Leads,
+		})
 	}
 
 	return nil
@@ -363,7 +190,3 @@ func (r *dashboardRepository) summaryPromotions(ctx context.Context, req *entity
 
 	return nil
 }
-
-func stringPointer(s string) *string {
-	return &s
-}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"codebase-app/internal/module/dashboard/entity"
+	"codebase-app/pkg/period"
 	"context"
 
 	"github.com/rs/zerolog/log"
@@ -11,7 +12,9 @@ func (r *dashboardRepository) GetWACSummaryTechnician(ctx context.Context, req *
 	var (
 		res entity.TechWACSummaryResponse
 	)
-	res.Month = req.Month
+	res.Month = req.Period.From.Format("2006-01")
+	res.From = req.Period.FromDate()
+	res.To = req.Period.To.Format(period.DateFormat)
 
 	// get walk around check summary that needs follow up
 	err := r.summaryTechnicianNeedFollowUp(ctx, req, &res)
@@ -33,46 +36,18 @@ func (r *dashboardRepository) GetWACSummaryTechnician(ctx context.Context, req *
 
 // this function will get the total leads from walk around check conditions that needs follow up
 // also counting walk around checks that needs follow up and already followed up
-// based on the user branch, month in Asia/Makassar timezone
-
+// based on the user branch and month
 func (r *dashboardRepository) summaryTechnicianNeedFollowUp(ctx context.Context, req *entity.WACSummaryRequest, res *entity.TechWACSummaryResponse) error {
-	query := `
-		WITH total_leads_wac_need_follow_up AS (
-			SELECT
-				COALESCE(SUM(1), 0) AS total_leads
-			FROM
-				walk_around_check_conditions wacc
-			LEFT JOIN
-				walk_around_checks wac
-				ON wac.id = wacc.walk_around_check_id
-			WHERE
-				wacc.is_interested = TRUE
-				AND wac.status = 'completed'
-				AND wac.is_needs_follow_up = TRUE
-				AND wac.branch_id = (SELECT branch_id FROM users WHERE id = ?)
-				AND TO_CHAR(wac.created_at AT TIME ZONE 'Asia/Makassar', 'YYYY-MM') = ?
-		)
-		SELECT
-			COALESCE(SUM(CASE WHEN wac.is_needs_follow_up = TRUE THEN 1 ELSE 0 END), 0) AS total_wac_need_follow_up,
-			COALESCE(SUM(CASE WHEN wac.is_followed_up = TRUE THEN 1 ELSE 0 END), 0) AS total_wac_followed_up,
-			(SELECT total_leads FROM total_leads_wac_need_follow_up) AS total_leads
-		FROM
-			walk_around_checks wac
-		WHERE
-			wac.branch_id = (SELECT branch_id FROM users WHERE id = ?)
-			AND wac.status = 'completed'
-			AND TO_CHAR(wac.created_at AT TIME ZONE 'Asia/Makassar', 'YYYY-MM') = ?
-	`
-
-	err := r.db.QueryRowxContext(ctx, r.db.Rebind(query),
-		req.UserId, req.Month,
-		req.UserId, req.Month,
-	).StructScan(res)
+	summary, err := r.followUpSummary(ctx, factScope{branchUserId: req.UserId}, req.Period)
 	if err != nil {
 		log.Error().Err(err).Any("payload", req).Msg("repo::GetWACSummaryTechnician - failed to get wac summary")
 		return err
 	}
 
+	res.TotalWACNeedFollowUp = summary.TotalWACNeedFollowUp
+	res.TotalWACFollowedUp = summary.TotalWACFollowedUp
+	res.TotalLeads = summary.TotalLeads
+
 	return nil
 }
 
@@ -84,37 +59,10 @@ type daoTotalLeadsPerPotency struct {
 
 // this function will get the total leads per potency
 // from walk around check conditions that needs follow up
-// based on the user branch, month in Asia/Makassar timezone
+// based on the user branch and month
 // leads (condition that interested and walk around check status is completed)
 func (r *dashboardRepository) summaryTechnicianTotalLeadsPerPotency(ctx context.Context, req *entity.WACSummaryRequest) ([]daoTotalLeadsPerPotency, error) {
-	query := `
-	SELECT
-		id,
-		name,
-		(
-			SELECT
-				COALESCE(SUM(1), 0)
-			FROM
-				walk_around_check_conditions wacc
-			LEFT JOIN
-				walk_around_checks wac
-				ON wac.id = wacc.walk_around_check_id
-			WHERE
-				wacc.potency_id = potencies.id
-				AND wac.status = 'completed'
-				AND wac.is_needs_follow_up = TRUE
-				AND wacc.is_interested = TRUE
-				AND wac.branch_id = (SELECT branch_id FROM users WHERE id = ?)
-				AND TO_CHAR(wac.created_at AT TIME ZONE 'Asia/Makassar', 'YYYY-MM') = ?
-		) AS total
-	FROM
-		potencies
-`
-
-	potencies := make([]daoTotalLeadsPerPotency, 0, 4)
-	err := r.db.SelectContext(ctx, &potencies, r.db.Rebind(query),
-		req.UserId, req.Month,
-	)
+	potencies, err := r.followUpLeadsPerPotency(ctx, factScope{branchUserId: req.UserId}, req.Period)
 	if err != nil {
 		log.Error().Err(err).Any("payload", req).Msg("repo::GetWACSummaryTechnician - failed to get wac summary")
 		return potencies, err
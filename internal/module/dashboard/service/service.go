@@ -1,26 +1,26 @@
 package service
 
 import (
-	"bytes"
 	"codebase-app/internal/module/dashboard/entity"
 	"codebase-app/internal/module/dashboard/ports"
+	tierEntity "codebase-app/internal/module/tier/entity"
+	tierPorts "codebase-app/internal/module/tier/ports"
+	"codebase-app/pkg/period"
 	"context"
-	"fmt"
 	"time"
-
-	"github.com/rs/zerolog/log"
-	"github.com/xuri/excelize/v2"
 )
 
 var _ ports.DashboardService = &DashbaordService{}
 
 type DashbaordService struct {
-	repo ports.DashboardRepository
+	repo  ports.DashboardRepository
+	tiers tierPorts.TierService
 }
 
-func NewDashboardService(repo ports.DashboardRepository) *DashbaordService {
+func NewDashboardService(repo ports.DashboardRepository, tiers tierPorts.TierService) *DashbaordService {
 	return &DashbaordService{
-		repo: repo,
+		repo:  repo,
+		tiers: tiers,
 	}
 }
 
@@ -29,11 +29,80 @@ func (s *DashbaordService) GetLeadsTrends(ctx context.Context, request *entity.L
 }
 
 func (s *DashbaordService) GetWACSummary(ctx context.Context, request *entity.WACSummaryRequest) (entity.WACSummaryResponse, error) {
-	return s.repo.GetWACSummary(ctx, request)
+	res, err := s.wacSummary(ctx, request)
+	if err != nil {
+		return res, err
+	}
+
+	if request.Period.Compare {
+		prevRequest := *request
+		prevRequest.Period = request.Period.Previous()
+
+		prev, err := s.wacSummary(ctx, &prevRequest)
+		if err != nil {
+			return res, err
+		}
+		res.Previous = &prev
+	}
+
+	return res, nil
+}
+
+func (s *DashbaordService) wacSummary(ctx context.Context, request *entity.WACSummaryRequest) (entity.WACSummaryResponse, error) {
+	res, err := s.repo.GetWACSummary(ctx, request)
+	if err != nil {
+		return res, err
+	}
+
+	tier, err := s.tiers.CalculateAdvisorTier(ctx, &tierEntity.AdvisorTierRequest{
+		UserId:   request.UserId,
+		Date:     tierDate(request.Period),
+		Timezone: request.Period.Location.String(),
+	})
+	if err != nil {
+		return res, err
+	}
+
+	if tier.Current != nil {
+		res.Tiers.Current = tier.Current.Key
+	}
+	if tier.Next != nil {
+		res.Tiers.Next = &tier.Next.Key
+	}
+	res.Tiers.Revenue = tier.Revenue
+
+	return res, nil
+}
+
+// tierDate is the end of the period, or now when the period is still
+// running, tiers are evaluated as of that date.
+func tierDate(p period.Period) time.Time {
+	date := p.End().Add(-time.Nanosecond)
+	if now := time.Now(); date.After(now) {
+		date = now
+	}
+
+	return date
 }
 
 func (s *DashbaordService) GetWACSummaryTechnician(ctx context.Context, request *entity.WACSummaryRequest) (entity.TechWACSummaryResponse, error) {
-	return s.repo.GetWACSummaryTechnician(ctx, request)
+	res, err := s.repo.GetWACSummaryTechnician(ctx, request)
+	if err != nil {
+		return res, err
+	}
+
+	if request.Period.Compare {
+		prevRequest := *request
+		prevRequest.Period = request.Period.Previous()
+
+		prev, err := s.repo.GetWACSummaryTechnician(ctx, &prevRequest)
+		if err != nil {
+			return res, err
+		}
+		res.Previous = &prev
+	}
+
+	return res, nil
 }
 
 func (s *DashbaordService) GetWACLineChart(ctx context.Context, request *entity.GetWACLineChartRequest) (entity.GetWACLineChartResponse, error) {
@@ -44,108 +113,116 @@ func (s *DashbaordService) GetActivities(ctx context.Context, request *entity.Ge
 	return s.repo.GetActivities(ctx, request)
 }
 
-func (s *DashbaordService) GetActivitiesExported(ctx context.Context, request *entity.GetActivitiesRequest) (*entity.ExportMeta, error) {
+func (s *DashbaordService) GetAdminSummary(ctx context.Context, request *entity.GetSummaryPerMonthRequest) (entity.GetSummaryPerMonthResponse, error) {
+	res, err := s.repo.GetAdminSummary(ctx, request)
+	if err != nil {
+		return res, err
+	}
+
+	if request.Period.Compare {
+		prevRequest := *request
+		prevRequest.Period = request.Period.Previous()
+
+		prev, err := s.repo.GetAdminSummary(ctx, &prevRequest)
+		if err != nil {
+			return res, err
+		}
+		res.Previous = &prev
+	}
+
+	return res, nil
+}
+
+func (s *DashbaordService) GetFunnel(ctx context.Context, request *entity.GetFunnelRequest) (entity.GetFunnelResponse, error) {
+	return s.repo.GetFunnel(ctx, request)
+}
+
+func (s *DashbaordService) GetLeaderboard(ctx context.Context, request *entity.GetLeaderboardRequest) (entity.GetLeaderboardResponse, error) {
 	var (
-		loc, _ = time.LoadLocation(request.Timezone)
-		from   = request.FromTime.Format("02/01/2006")
-		to     = request.ToTime.Format("02/01/2006")
+		res = entity.GetLeaderboardResponse{
+			From:    request.Period.FromDate(),
+			To:      request.Period.To.Format(period.DateFormat),
+			Scope:   request.Scope,
+			SortBy:  request.SortBy,
+			MinWACs: request.MinWACs,
+		}
+		prevRequest = *request
 	)
+	prevRequest.Period = request.Period.Previous()
+	res.PreviousFrom = prevRequest.Period.FromDate()
+	res.PreviousTo = prevRequest.Period.To.Format(period.DateFormat)
 
-	resp, err := s.repo.GetActivities(ctx, request)
+	items, err := s.leaderboard(ctx, request, true)
 	if err != nil {
-		return nil, err
+		return res, err
 	}
 
-	f := excelize.NewFile()
+	// previous tiers only matter when they decide the previous ranks
+	prev, err := s.leaderboard(ctx, &prevRequest, request.SortBy == entity.SortByTier)
+	if err != nil {
+		return res, err
+	}
 
-	style, err := f.NewStyle(&excelize.Style{
-		Alignment: &excelize.Alignment{
-			Horizontal: "center",
-			Vertical:   "center",
-		},
-		Font: &excelize.Font{
-			Bold: true,
-		},
-	})
+	entity.ApplyRankDeltas(items, prev)
+	res.Items = items
+
+	return res, nil
+}
+
+func (s *DashbaordService) leaderboard(ctx context.Context, request *entity.GetLeaderboardRequest, withTiers bool) ([]entity.LeaderboardItem, error) {
+	items, err := s.repo.GetLeaderboard(ctx, request)
 	if err != nil {
-		log.Error().Err(err).Any("payload", request).Msg("service::GetActivitiesExported - failed to create style")
 		return nil, err
 	}
 
-	f.SetCellValue("Sheet1", "A1", "Kalla Toyota")
-	f.SetCellValue("Sheet1", "B1", fmt.Sprintf("Cabang %s", request.BranchName))
-	f.SetCellValue("Sheet1", "A2", "Periode")
-	f.SetCellValue("Sheet1", "B2", fmt.Sprintf("%s - %s", from, to))
-	f.SetCellValue("Sheet1", "A3", "Report")
-	f.SetCellValue("Sheet1", "B3", "Activity")
-
-	// Header table
-	headers := []string{"No", "Tanggal", "Nama Customer", "Cabang", "Penanggung Jawab", "Nomor WhatsApp", "Nomor Plat", "Jenis Mobil", "Status", "Potensi Leads", "Leads", "Total Revenue"}
-	for i, h := range headers {
-		col := fmt.Sprintf("%c5", 'A'+i)
-		f.SetCellValue("Sheet1", col, h)
-	}
-
-	for i, a := range resp.Items {
-		row := i + 6
-		f.SetCellValue("Sheet1", fmt.Sprintf("A%d", row), i+1)
-		c := a.CreatedAt.In(loc)
-		f.SetCellValue("Sheet1", fmt.Sprintf("B%d", row), c.Format("2006-01-02 15:04:05"))
-		f.SetCellValue("Sheet1", fmt.Sprintf("B%d", row), a.CreatedAt.In(loc).Format("2006-01-02 15:04:05"))
-		f.SetCellValue("Sheet1", fmt.Sprintf("C%d", row), a.ClientName)
-		f.SetCellValue("Sheet1", fmt.Sprintf("D%d", row), a.BranchName)
-		f.SetCellValue("Sheet1", fmt.Sprintf("E%d", row), a.EmployeeName)
-		f.SetCellValue("Sheet1", fmt.Sprintf("F%d", row), a.Phone)
-		f.SetCellValue("Sheet1", fmt.Sprintf("G%d", row), a.VehicleLicenseNumber)
-		f.SetCellValue("Sheet1", fmt.Sprintf("H%d", row), a.VehicleTypeName)
-
-		s := a.Status
-		if s == "wip" {
-			s = "Pengerjaan"
-		} else if s == "completed" {
-			s = "Selesai"
-		} else if s == "offered" {
-			s = "Penawaran"
+	if withTiers && request.Scope == entity.LeaderboardAdvisor && len(items) > 0 {
+		ids := make([]string, len(items))
+		for i := range items {
+			ids[i] = items[i].Id
 		}
 
-		f.SetCellValue("Sheet1", fmt.Sprintf("I%d", row), s)
-		f.SetCellValue("Sheet1", fmt.Sprintf("J%d", row), a.TotalPotentialLeads)
-		f.SetCellValue("Sheet1", fmt.Sprintf("K%d", row), a.TotalLeads)
-		f.SetCellValue("Sheet1", fmt.Sprintf("L%d", row), a.TotalRevenue)
-	}
-
-	// Terapkan style center alignment pada title
-	f.SetCellStyle("Sheet1", "A1", "L1", style)
-	f.SetCellStyle("Sheet1", "A2", "L2", style)
-	f.SetCellStyle("Sheet1", "A3", "L3", style)
-	f.SetCellStyle("Sheet1", "A5", "L5", style)
-
-	f.SetColWidth("Sheet1", "A", "A", 12) // No
-	f.SetColWidth("Sheet1", "B", "B", 21) // Tanggal
-	f.SetColWidth("Sheet1", "C", "C", 21) // Nama Customer
-	f.SetColWidth("Sheet1", "D", "D", 21) // Cabang
-	f.SetColWidth("Sheet1", "E", "E", 21) // Penanggung Jawab
-	f.SetColWidth("Sheet1", "F", "F", 21) // Nomor WhatsApp
-	f.SetColWidth("Sheet1", "G", "G", 15) // Nomor Plat
-	f.SetColWidth("Sheet1", "H", "H", 21) // Jenis Mobil
-	f.SetColWidth("Sheet1", "I", "I", 15) // Status
-	f.SetColWidth("Sheet1", "J", "J", 15) // Potensi Leads
-	f.SetColWidth("Sheet1", "K", "K", 15) // Leads
-	f.SetColWidth("Sheet1", "L", "L", 15) // Total Revenue
-
-	buf := new(bytes.Buffer)
-	if err := f.Write(buf); err != nil {
-		return nil, err
-	}
+		// one calculation for all the advisors, not one per advisor
+		tiers, err := s.tiers.CalculateAdvisorTiers(ctx, &tierEntity.AdvisorTiersRequest{
+			UserIds:  ids,
+			Date:     tierDate(request.Period),
+			Timezone: request.Period.Location.String(),
+		})
+		if err != nil {
+			return nil, err
+		}
 
-	meta := entity.ExportMeta{
-		Buf:      buf,
-		Filename: "aktivitas-wac-" + request.BranchName + "-" + time.Now().In(loc).Format("20060102150405") + ".xlsx",
+		for i := range items {
+			if tier, ok := tiers[items[i].Id]; ok && tier.Current != nil {
+				items[i].Tier = &entity.LeaderboardTier{
+					Key:       tier.Current.Key,
+					Name:      tier.Current.Name,
+					Threshold: tier.Current.Threshold,
+				}
+			}
+		}
 	}
 
-	return &meta, nil
+	entity.RankLeaderboard(items, request.SortBy, request.MinWACs)
+
+	return items, nil
 }
 
-func (s *DashbaordService) GetAdminSummary(ctx context.Context, request *entity.GetSummaryPerMonthRequest) (entity.GetSummaryPerMonthResponse, error) {
-	return s.repo.GetAdminSummary(ctx, request)
+func (s *DashbaordService) GetRevenue(ctx context.Context, request *entity.GetRevenueRequest) (entity.GetRevenueResponse, error) {
+	res, err := s.repo.GetRevenue(ctx, request)
+	if err != nil {
+		return res, err
+	}
+
+	if request.Period.Compare {
+		prevRequest := *request
+		prevRequest.Period = request.Period.Previous()
+
+		prev, err := s.repo.GetRevenue(ctx, &prevRequest)
+		if err != nil {
+			return res, err
+		}
+		res.Previous = &prev
+	}
+
+	return res, nil
 }
@@ -1,5 +1,7 @@
 package entity
 
+import "codebase-app/pkg/validator"
+
 type GetEmployeeRequest struct {
 	Id string `json:"id" validate:"ulid"`
 }
@@ -33,22 +35,34 @@ type UpdateEmployeeRequest struct {
 	RoleId    string `json:"role_id" validate:"ulid,exist=roles.id"`
 	Name      string `json:"name" validate:"required"`
 	Email     string `json:"email" validate:"email"`
-	WANumber  string `json:"whatsapp_number"`
+	WANumber  string `json:"whatsapp_number" validate:"omitempty,id_phone"`
 	Password  string `json:"password" validate:"omitempty,strong_password"`
 	PassConf  string `json:"password_confirmation" validate:"eqfield=Password"`
 }
 
+func (r *UpdateEmployeeRequest) Normalize() {
+	if r.WANumber != "" {
+		r.WANumber = validator.FormatPhone(r.WANumber)
+	}
+}
+
 type CreateEmployeeRequest struct {
 	BranchId  string `json:"branch_id" validate:"ulid,exist=branches.id"`
 	SectionId string `json:"section_id" validate:"ulid,exist=potencies.id"`
 	RoleId    string `json:"role_id" validate:"ulid,exist=roles.id"`
 	Name      string `json:"name" validate:"required"`
 	Email     string `json:"email" validate:"email"`
-	WANumber  string `json:"whatsapp_number"`
+	WANumber  string `json:"whatsapp_number" validate:"omitempty,id_phone"`
 	Password  string `json:"password" validate:"required,strong_password"`
 	PassConf  string `json:"password_confirmation" validate:"eqfield=Password"`
 }
 
+func (r *CreateEmployeeRequest) Normalize() {
+	if r.WANumber != "" {
+		r.WANumber = validator.FormatPhone(r.WANumber)
+	}
+}
+
 type DeleteEmployeeRequest struct {
 	Id string `json:"id" validate:"ulid"`
 }
@@ -1,7 +1,93 @@
 package entity
 
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+const (
+	// ImportSheet is the sheet read from the workbook.
+	ImportSheet = "import_users"
+	// ImportRefSheet is the hidden sheet of the template listing the values
+	// of the dropdowns.
+	ImportRefSheet = "referensi"
+
+	ImportModeInsert = "insert"
+	ImportModeUpsert = "upsert"
+
+	ImportActionCreate = "create"
+	ImportActionUpdate = "update"
+
+	MaxImportRows = 500
+)
+
+// ImportColumn is a column of the import sheet.
+type ImportColumn struct {
+	Key    string
+	Header string
+}
+
+// ImportColumns are the columns of the import sheet in order, the first one
+// only numbers the rows.
+var ImportColumns = []ImportColumn{
+	{Key: "no", Header: "No"},
+	{Key: "name", Header: "Nama"},
+	{Key: "branch_name", Header: "Cabang"},
+	{Key: "section_name", Header: "Section"},
+	{Key: "role_name", Header: "Role"},
+	{Key: "email", Header: "Email"},
+	{Key: "password", Header: "Password"},
+}
+
+// ImportColumnIndex returns the zero based index of the column, -1 when there
+// is none.
+func ImportColumnIndex(key string) int {
+	for i, c := range ImportColumns {
+		if c.Key == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// roleLabels are the names of the roles in the workbooks.
+var roleLabels = map[string]string{
+	"admin":           "ADMIN",
+	"service_advisor": "SERVICE ADVISOR",
+	"technician":      "MRA",
+}
+
+// RoleLabel returns the name of the role in the workbooks.
+func RoleLabel(role string) string {
+	if label, ok := roleLabels[role]; ok {
+		return label
+	}
+
+	return strings.ToUpper(strings.ReplaceAll(role, "_", " "))
+}
+
+/*
+Import Employees - Start
+  - The rows of the import_users sheet, see ImportColumns, blank rows are
+    skipped and short rows are checked as if the missing cells were empty
+  - insert creates the employees and rejects the emails already registered,
+    upsert updates the branch, section and role of the employees of those
+    emails, their name and password are left as they are
+  - dry_run only returns the report, otherwise nothing is imported unless
+    every row is valid
+*/
 type ImportEmployeesRequest struct {
-	File string `json:"file" validate:"required,base64"`
+	File   string `json:"file" validate:"required,base64"`
+	Mode   string `json:"mode" validate:"oneof=insert upsert"`
+	DryRun bool   `json:"dry_run"`
+}
+
+func (r *ImportEmployeesRequest) SetDefault() {
+	if r.Mode == "" {
+		r.Mode = ImportModeInsert
+	}
 }
 
 type ImportEmployeeRow struct {
@@ -10,9 +96,258 @@ type ImportEmployeeRow struct {
 	SectionName    string `json:"section_name"`
 	RoleName       string `json:"role_name"`
 	Email          string `json:"email"`
-	PasswordHashed string `json:"password"`
+	PasswordHashed string `json:"-"`
 
 	BranchId  string `json:"branch_id"`
 	SectionId string `json:"section_id"`
 	RoleId    string `json:"role_id"`
+
+	// Row is the number of the row in the sheet, Index its index without
+	// the header
+	Row      int    `json:"row"`
+	Index    int    `json:"-"`
+	Password string `json:"-"`
+	UserId   string `json:"-"`
+	Action   string `json:"action"`
+	Valid    bool   `json:"valid"`
+	// Errors are the messages per column key
+	Errors map[string][]string `json:"errors"`
+}
+
+func (r *ImportEmployeeRow) addError(column, msg string) {
+	r.Errors[column] = append(r.Errors[column], msg)
+}
+
+func (r *ImportEmployeeRow) IsValid() bool {
+	return len(r.Errors) == 0
+}
+
+// ImportRefs are the ids of the branches, sections and roles by the names
+// written in the workbooks, in upper case.
+type ImportRefs struct {
+	Branches map[string]string
+	Sections map[string]string
+	Roles    map[string]string
+}
+
+// NewImportRefs maps the names to the ids, a role is known by its label and
+// its name.
+func NewImportRefs(branches, sections, roles []Common) ImportRefs {
+	refs := ImportRefs{
+		Branches: make(map[string]string, len(branches)),
+		Sections: make(map[string]string, len(sections)),
+		Roles:    make(map[string]string, len(roles)*2),
+	}
+
+	for _, b := range branches {
+		refs.Branches[strings.ToUpper(b.Name)] = b.Id
+	}
+	for _, s := range sections {
+		refs.Sections[strings.ToUpper(s.Name)] = s.Id
+	}
+	for _, r := range roles {
+		refs.Roles[RoleLabel(r.Name)] = r.Id
+		refs.Roles[strings.ToUpper(strings.ReplaceAll(r.Name, "_", " "))] = r.Id
+	}
+
+	return refs
+}
+
+// ParseEmployeeRows checks the rows of the sheet, the first one is the header.
+// The emails already registered are checked by PlanImport.
+func ParseEmployeeRows(records [][]string, refs ImportRefs) []ImportEmployeeRow {
+	var (
+		rows   = make([]ImportEmployeeRow, 0, len(records))
+		emails = make(map[string]int)
+	)
+
+	for i, record := range records {
+		if i == 0 || isBlankRecord(record) {
+			continue
+		}
+
+		cell := func(key string) string {
+			idx := ImportColumnIndex(key)
+			if idx < len(record) {
+				return strings.TrimSpace(record[idx])
+			}
+			return ""
+		}
+
+		r := ImportEmployeeRow{
+			Row:         i + 1,
+			Index:       i,
+			Name:        cell("name"),
+			BranchName:  strings.ToUpper(cell("branch_name")),
+			SectionName: strings.ToUpper(cell("section_name")),
+			RoleName:    strings.ToUpper(cell("role_name")),
+			Email:       strings.ToLower(cell("email")),
+			Password:    cell("password"),
+			Errors:      make(map[string][]string),
+		}
+
+		if r.Name == "" {
+			r.addError("name", "nama tidak boleh kosong")
+		}
+
+		if r.RoleName == "" {
+			r.addError("role_name", "role tidak boleh kosong")
+		} else if roleId, ok := refs.Roles[r.RoleName]; !ok {
+			r.addError("role_name", "role tidak valid")
+		} else {
+			r.RoleId = roleId
+		}
+
+		// the admins have no branch nor section
+		if r.RoleName != RoleLabel("admin") {
+			if branchId, ok := refs.Branches[r.BranchName]; ok {
+				r.BranchId = branchId
+			} else {
+				r.addError("branch_name", "cabang tidak ditemukan")
+			}
+
+			if sectionId, ok := refs.Sections[r.SectionName]; ok {
+				r.SectionId = sectionId
+			} else {
+				r.addError("section_name", "section tidak ditemukan")
+			}
+		}
+
+		switch {
+		case r.Email == "":
+			r.addError("email", "email tidak boleh kosong")
+		case !isEmail(r.Email):
+			r.addError("email", "email tidak valid")
+		default:
+			if first, ok := emails[r.Email]; ok {
+				r.addError("email", fmt.Sprintf("email duplikat dengan baris %d", first))
+			} else {
+				emails[r.Email] = r.Row
+			}
+		}
+
+		if r.Password != "" && !IsImportPasswordValid(r.Password) {
+			r.addError("password", "password minimal 8 karakter dan mengandung karakter spesial")
+		}
+
+		rows = append(rows, r)
+	}
+
+	return rows
+}
+
+// ImportUser is a user registered with the email of a row.
+type ImportUser struct {
+	Id        string `db:"id"`
+	Email     string `db:"email"`
+	IsDeleted bool   `db:"is_deleted"`
+}
+
+// PlanImport sets the action of the rows from the users registered with their
+// emails, by email.
+func PlanImport(rows []ImportEmployeeRow, users map[string]ImportUser, mode string) {
+	for i := range rows {
+		r := &rows[i]
+
+		u, exists := users[r.Email]
+		switch {
+		case !exists:
+			r.Action = ImportActionCreate
+			if r.Password == "" {
+				r.addError("password", "password tidak boleh kosong")
+			}
+		case u.IsDeleted:
+			r.addError("email", "email milik pegawai yang dihapus, pulihkan dari trash")
+		case mode == ImportModeUpsert:
+			r.Action = ImportActionUpdate
+			r.UserId = u.Id
+		default:
+			r.addError("email", "email sudah terdaftar")
+		}
+
+		r.Valid = r.IsValid()
+		if !r.Valid {
+			r.Action = ""
+		}
+	}
+}
+
+type ImportEmployeesResponse struct {
+	Mode      string              `json:"mode"`
+	DryRun    bool                `json:"dry_run"`
+	Committed bool                `json:"committed"`
+	Total     int                 `json:"total"`
+	Valid     int                 `json:"valid"`
+	Invalid   int                 `json:"invalid"`
+	Create    int                 `json:"create"`
+	Update    int                 `json:"update"`
+	Rows      []ImportEmployeeRow `json:"rows"`
+}
+
+func NewImportEmployeesResponse(req *ImportEmployeesRequest, rows []ImportEmployeeRow) ImportEmployeesResponse {
+	res := ImportEmployeesResponse{
+		Mode:   req.Mode,
+		DryRun: req.DryRun,
+		Total:  len(rows),
+		Rows:   rows,
+	}
+
+	for _, r := range rows {
+		if !r.IsValid() {
+			res.Invalid++
+			continue
+		}
+
+		res.Valid++
+		switch r.Action {
+		case ImportActionCreate:
+			res.Create++
+		case ImportActionUpdate:
+			res.Update++
+		}
+	}
+
+	return res
+}
+
+// Errors returns the errors of the rows as file[index].column, the way the
+// import always reported them.
+func (r *ImportEmployeesResponse) Errors() map[string][]string {
+	errs := make(map[string][]string)
+	for _, row := range r.Rows {
+		for column, msgs := range row.Errors {
+			key := fmt.Sprintf("file[%d].%s", row.Index, column)
+			errs[key] = append(errs[key], msgs...)
+		}
+	}
+
+	return errs
+}
+
+// IsImportPasswordValid checks the passwords of the workbooks, at least 8
+// characters with a special one.
+func IsImportPasswordValid(password string) bool {
+	if len(password) < 8 {
+		return false
+	}
+
+	return strings.ContainsAny(password, "!@#$%^&*()_+{}|:<>?")
+}
+
+func isEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
+
+// isBlankRecord reports whether the row is empty, the row numbers alone do
+// not count.
+func isBlankRecord(record []string) bool {
+	no := ImportColumnIndex("no")
+	for i, v := range record {
+		if i != no && strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+
+	return true
 }
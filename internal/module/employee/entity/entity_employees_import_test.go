@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEmployeeRows(t *testing.T) {
+	refs := NewImportRefs(
+		[]Common{{Id: "b1", Name: "Makassar"}},
+		[]Common{{Id: "s1", Name: "Body Repair"}},
+		[]Common{{Id: "r1", Name: "admin"}, {Id: "r2", Name: "service_advisor"}, {Id: "r3", Name: "technician"}},
+	)
+
+	rows := ParseEmployeeRows([][]string{
+		{"No", "Nama", "Cabang", "Section", "Role", "Email", "Password"},
+		{"1", "Budi", "makassar", "body repair", "mra", "Budi@Example.com", "rahasia!123"},
+		{"2", "Admin", "", "", "ADMIN", "admin@example.com"},
+		{"3"},
+		{"4", "", "Jakarta", "Body Repair", "SERVICE ADVISOR", "budi@example.com", "short"},
+	}, refs)
+
+	assert.Len(t, rows, 3)
+
+	assert.True(t, rows[0].IsValid())
+	assert.Equal(t, 2, rows[0].Row)
+	assert.Equal(t, "b1", rows[0].BranchId)
+	assert.Equal(t, "r3", rows[0].RoleId)
+	assert.Equal(t, "budi@example.com", rows[0].Email)
+
+	// a short row is checked as if the missing cells were empty
+	assert.True(t, rows[1].IsValid())
+	assert.Empty(t, rows[1].BranchId)
+
+	assert.Equal(t, 5, rows[2].Row)
+	assert.Contains(t, rows[2].Errors, "name")
+	assert.Contains(t, rows[2].Errors, "branch_name")
+	assert.Contains(t, rows[2].Errors, "password")
+	assert.Equal(t, []string{"email duplikat dengan baris 2"}, rows[2].Errors["email"])
+}
+
+func TestPlanImport(t *testing.T) {
+	newRows := func() []ImportEmployeeRow {
+		return []ImportEmployeeRow{
+			{Index: 1, Email: "new@example.com", Errors: map[string][]string{}},
+			{Index: 2, Email: "old@example.com", Errors: map[string][]string{}},
+			{Index: 3, Email: "gone@example.com", Errors: map[string][]string{}},
+		}
+	}
+	users := map[string]ImportUser{
+		"old@example.com":  {Id: "u1"},
+		"gone@example.com": {Id: "u2", IsDeleted: true},
+	}
+
+	rows := newRows()
+	PlanImport(rows, users, ImportModeInsert)
+	assert.Contains(t, rows[0].Errors, "password")
+	assert.Contains(t, rows[1].Errors, "email")
+	assert.Contains(t, rows[2].Errors, "email")
+
+	rows = newRows()
+	rows[0].Password = "rahasia!123"
+	PlanImport(rows, users, ImportModeUpsert)
+	assert.Equal(t, ImportActionCreate, rows[0].Action)
+	assert.Equal(t, ImportActionUpdate, rows[1].Action)
+	assert.Equal(t, "u1", rows[1].UserId)
+	assert.False(t, rows[2].Valid)
+
+	res := NewImportEmployeesResponse(&ImportEmployeesRequest{Mode: ImportModeUpsert}, rows)
+	assert.Equal(t, 2, res.Valid)
+	assert.Equal(t, 1, res.Create)
+	assert.Equal(t, 1, res.Update)
+	assert.Contains(t, res.Errors(), "file[3].email")
+}
@@ -9,6 +9,7 @@ import (
 	"codebase-app/internal/module/employee/service"
 	"codebase-app/pkg/errmsg"
 	"codebase-app/pkg/response"
+	"codebase-app/pkg/tabular"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog/log"
@@ -33,6 +34,8 @@ func (h *employeeHandler) Register(router fiber.Router) {
 	employee := router.Group("/employees", middleware.AuthBearer, middleware.AuthRole([]string{"admin"}))
 
 	employee.Post("/import", h.importEmployees)
+	employee.Get("/import/template", h.getImportTemplate)
+	employee.Post("/import/errors", h.getImportErrors)
 	employee.Post("/", h.createEmployee)
 	employee.Get("/:id", h.getEmployee)
 	employee.Patch("/:id", h.updateEmployee)
@@ -77,6 +80,8 @@ func (h *employeeHandler) updateEmployee(c *fiber.Ctx) error {
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
+	req.Normalize()
+
 	if err := v.Validate(req); err != nil {
 		log.Warn().Err(err).Any("payload", req).Msg("handler::UpdateEmployee - invalid request")
 		code, errs := errmsg.Errors(err, req)
@@ -105,6 +110,8 @@ func (h *employeeHandler) createEmployee(c *fiber.Ctx) error {
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
+	req.Normalize()
+
 	if err := v.Validate(req); err != nil {
 		log.Warn().Err(err).Any("payload", req).Msg("handler::CreateEmployee - invalid request")
 		code, errs := errmsg.Errors(err, req)
@@ -152,22 +159,72 @@ func (h *employeeHandler) importEmployees(c *fiber.Ctx) error {
 	)
 
 	if err := c.BodyParser(req); err != nil {
-		log.Warn().Err(err).Any("payload", req).Msg("handler::ImportEmployees - invalid request")
+		log.Warn().Err(err).Msg("handler::ImportEmployees - invalid request")
 		code, errs := errmsg.Errors(err, req)
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
+	req.SetDefault()
+
 	if err := v.Validate(req); err != nil {
-		log.Warn().Err(err).Any("payload", req).Msg("handler::ImportEmployees - invalid request")
+		log.Warn().Err(err).Str("mode", req.Mode).Msg("handler::ImportEmployees - invalid request")
 		code, errs := errmsg.Errors(err, req)
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
-	err := h.service.ImportEmployees(ctx, req)
+	res, err := h.service.ImportEmployees(ctx, req)
 	if err != nil {
 		code, errs := errmsg.Errors[error](err)
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
-	return c.JSON(response.Success(nil, ""))
+	return c.JSON(response.Success(res, ""))
+}
+
+func (h *employeeHandler) getImportTemplate(c *fiber.Ctx) error {
+	buf, err := h.service.GetImportTemplate(c.Context())
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	c.Set(fiber.HeaderContentDisposition, "attachment; filename=\"import_users.xlsx\"")
+	c.Set(fiber.HeaderContentType, tabular.ContentType(tabular.FormatXLSX))
+
+	return c.SendStream(buf)
+}
+
+// getImportErrors takes the payload of the import and returns the workbook
+// with the invalid cells highlighted.
+func (h *employeeHandler) getImportErrors(c *fiber.Ctx) error {
+	var (
+		req = new(entity.ImportEmployeesRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::getImportErrors - invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Str("mode", req.Mode).Msg("handler::getImportErrors - invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	buf, err := h.service.GetImportErrors(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	c.Set(fiber.HeaderContentDisposition, "attachment; filename=\"import_users_errors.xlsx\"")
+	c.Set(fiber.HeaderContentType, tabular.ContentType(tabular.FormatXLSX))
+
+	return c.SendStream(buf)
 }
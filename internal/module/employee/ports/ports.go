@@ -1,6 +1,7 @@
 package ports
 
 import (
+	"bytes"
 	"codebase-app/internal/module/employee/entity"
 	"context"
 )
@@ -12,12 +13,11 @@ type EmployeeRepository interface {
 	DeleteEmployee(ctx context.Context, req *entity.DeleteEmployeeRequest) error
 
 	ImportEmployees(ctx context.Context, data []entity.ImportEmployeeRow) error
+	GetImportUsers(ctx context.Context, emails []string) (map[string]entity.ImportUser, error)
 
 	GetBranches(ctx context.Context) ([]entity.Common, error)
 	GetPotencies(ctx context.Context) ([]entity.Common, error)
 	GetRoles(ctx context.Context) ([]entity.Common, error)
-
-	IsEmailExist(ctx context.Context, email string) error
 }
 
 type EmployeeService interface {
@@ -26,5 +26,7 @@ type EmployeeService interface {
 	CreateEmployee(ctx context.Context, req *entity.CreateEmployeeRequest) error
 	DeleteEmployee(ctx context.Context, req *entity.DeleteEmployeeRequest) error
 
-	ImportEmployees(ctx context.Context, req *entity.ImportEmployeesRequest) error
+	ImportEmployees(ctx context.Context, req *entity.ImportEmployeesRequest) (entity.ImportEmployeesResponse, error)
+	GetImportTemplate(ctx context.Context) (*bytes.Buffer, error)
+	GetImportErrors(ctx context.Context, req *entity.ImportEmployeesRequest) (*bytes.Buffer, error)
 }
@@ -89,17 +89,28 @@ func (r *employeeRepo) UpdateEmployee(ctx context.Context, req *entity.UpdateEmp
 		UPDATE
 			users
 		SET
-			branch_id = ?, section_id = ?, role_id = ?, whatsapp_number = ?, name = ?, email = ?
+			branch_id = ?, section_id = ?, role_id = ?, whatsapp_number = ?, name = ?, email = ?, updated_at = NOW()
 		WHERE
-			id = ?
+			id = ? AND deleted_at IS NULL
 		`
 
-	_, err = tx.ExecContext(ctx, r.db.Rebind(query), req.BranchId, req.SectionId, req.RoleId, req.WANumber, req.Name, req.Email, req.Id)
+	result, err := tx.ExecContext(ctx, r.db.Rebind(query), req.BranchId, req.SectionId, req.RoleId, req.WANumber, req.Name, req.Email, req.Id)
 	if err != nil {
 		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateEmployee - Failed to update employee")
 		return err
 	}
 
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateEmployee - Failed to get affected rows")
+		return err
+	}
+
+	if affected == 0 {
+		log.Warn().Any("payload", req).Msg("repo::UpdateEmployee - Employee not found")
+		return errmsg.NewCustomErrors(404).SetMessage("Karyawan tidak ditemukan")
+	}
+
 	if req.Password != "" {
 		query = `
 			UPDATE
@@ -178,17 +189,28 @@ func (r *employeeRepo) DeleteEmployee(ctx context.Context, req *entity.DeleteEmp
 		UPDATE
 			users
 		SET
-			deleted_at = NOW()
+			deleted_at = NOW(), updated_at = NOW()
 		WHERE
 			id = ? AND deleted_at IS NULL
 		`
 
-	_, err = tx.ExecContext(ctx, r.db.Rebind(query), req.Id)
+	result, err := tx.ExecContext(ctx, r.db.Rebind(query), req.Id)
 	if err != nil {
 		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteEmployee - Failed to delete employee")
 		return err
 	}
 
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteEmployee - Failed to get affected rows")
+		return err
+	}
+
+	if affected == 0 {
+		log.Warn().Any("payload", req).Msg("repo::DeleteEmployee - Employee not found")
+		return errmsg.NewCustomErrors(404).SetMessage("Karyawan tidak ditemukan")
+	}
+
 	if err := tx.Commit(); err != nil {
 		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteEmployee - Failed to commit transaction")
 		return err
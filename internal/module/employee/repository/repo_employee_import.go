@@ -2,38 +2,62 @@ package repository
 
 import (
 	"codebase-app/internal/module/employee/entity"
+	"codebase-app/pkg/errmsg"
 	"context"
-	"errors"
+	"strconv"
 
+	"github.com/lib/pq"
 	"github.com/oklog/ulid/v2"
 	"github.com/rs/zerolog/log"
 )
 
-func (r *employeeRepo) ImportEmployees(ctx context.Context, rows []entity.ImportEmployeeRow) error {
+func (r *employeeRepo) ImportEmployees(ctx context.Context, rows []entity.ImportEmployeeRow) (err error) {
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		log.Error().Err(err).Any("payload", rows).Msg("repo::ImportEmployees - Failed to begin transaction")
 		return err
 	}
-	defer tx.Rollback()
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Msg("repo::ImportEmployees - Failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Any("payload", rows).Msg("repo::ImportEmployees - Failed to commit transaction")
+		}
+	}()
 
-	queryNonAdmin := `
+	queryInsert := `
 		INSERT INTO
 			users (id, branch_id, section_id, role_id, name, email, password)
 		VALUES
-			(?, ?, ?, ?, TRIM(UPPER(?)), TRIM(LOWER(?)), ?)
+			(?, NULLIF(?, ''), NULLIF(?, ''), ?, TRIM(UPPER(?)), TRIM(LOWER(?)), ?)
 		`
 
-	queryAdmin := `
-		INSERT INTO
-			users (id, role_id, name, email, password)
-		VALUES
-			(?, ?, TRIM(UPPER(?)), TRIM(LOWER(?)), ?)
+	// the admins have no branch nor section. A moved employee gets a new
+	// token version so the tokens issued for the old access are revoked.
+	queryUpdate := `
+		UPDATE users
+		SET
+			branch_id = NULLIF(?, ''),
+			section_id = NULLIF(?, ''),
+			role_id = ?,
+			token_version = token_version + CASE
+				WHEN (branch_id, section_id, role_id) IS DISTINCT FROM (NULLIF(?, ''), NULLIF(?, ''), ?) THEN 1
+				ELSE 0
+			END,
+			updated_at = NOW()
+		WHERE
+			id = ? AND deleted_at IS NULL
 		`
 
 	for _, row := range rows {
-		if row.RoleName != "ADMIN" {
-			_, err = tx.ExecContext(ctx, r.db.Rebind(queryNonAdmin),
+		switch row.Action {
+		case entity.ImportActionCreate:
+			_, err = tx.ExecContext(ctx, r.db.Rebind(queryInsert),
 				ulid.Make().String(),
 				row.BranchId,
 				row.SectionId,
@@ -42,31 +66,59 @@ func (r *employeeRepo) ImportEmployees(ctx context.Context, rows []entity.Import
 				row.Email,
 				row.PasswordHashed,
 			)
-			if err != nil {
-				log.Error().Err(err).Any("payload", row).Msg("repo::ImportEmployees - Failed to import employee")
-				return err
-			}
-		} else {
-			_, err = tx.ExecContext(ctx, r.db.Rebind(queryAdmin),
-				ulid.Make().String(),
+		case entity.ImportActionUpdate:
+			_, err = tx.ExecContext(ctx, r.db.Rebind(queryUpdate),
+				row.BranchId,
+				row.SectionId,
 				row.RoleId,
-				row.Name,
-				row.Email,
-				row.PasswordHashed,
+				row.BranchId,
+				row.SectionId,
+				row.RoleId,
+				row.UserId,
 			)
-			if err != nil {
-				log.Error().Err(err).Any("payload", row).Msg("repo::ImportEmployees - Failed to import employee")
+		}
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				log.Warn().Err(err).Any("payload", row).Msg("repo::ImportEmployees - Email registered during the import")
+				err = errmsg.NewCustomErrors(409).SetMessage("Email pada baris " + strconv.Itoa(row.Row) + " baru saja didaftarkan, ulangi import")
 				return err
 			}
+			log.Error().Err(err).Any("payload", row).Msg("repo::ImportEmployees - Failed to import employee")
+			return err
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		log.Error().Err(err).Any("payload", rows).Msg("repo::ImportEmployees - Failed to commit transaction")
-		return err
+	return nil
+}
+
+// GetImportUsers returns the users registered with the emails by email, the
+// deleted ones included as the emails stay unique.
+func (r *employeeRepo) GetImportUsers(ctx context.Context, emails []string) (map[string]entity.ImportUser, error) {
+	var (
+		users = make([]entity.ImportUser, 0)
+		res   = make(map[string]entity.ImportUser, len(emails))
+	)
+
+	query := `
+		SELECT
+			id, email, deleted_at IS NOT NULL AS is_deleted
+		FROM
+			users
+		WHERE
+			email = ANY(?)
+		`
+
+	err := r.db.SelectContext(ctx, &users, r.db.Rebind(query), pq.Array(emails))
+	if err != nil {
+		log.Error().Err(err).Msg("repo::GetImportUsers - Failed to get users")
+		return nil, err
 	}
 
-	return nil
+	for _, u := range users {
+		res[u.Email] = u
+	}
+
+	return res, nil
 }
 
 func (r *employeeRepo) GetBranches(ctx context.Context) ([]entity.Common, error) {
@@ -134,32 +186,3 @@ func (r *employeeRepo) GetRoles(ctx context.Context) ([]entity.Common, error) {
 
 	return res, nil
 }
-
-func (r *employeeRepo) IsEmailExist(ctx context.Context, email string) error {
-	query := `
-		SELECT EXISTS(
-			SELECT
-				1
-			FROM
-				users
-			WHERE
-				email = ? AND deleted_at IS NULL
-		)
-		`
-
-	var (
-		exist bool
-	)
-
-	err := r.db.GetContext(ctx, &exist, r.db.Rebind(query), email)
-	if err != nil {
-		log.Error().Err(err).Str("email", email).Msg("repo::IsEmailExist - Failed to check email")
-		return err
-	}
-
-	if exist {
-		return errors.New("email sudah terdaftar")
-	}
-
-	return nil
-}
@@ -0,0 +1,342 @@
+package service
+
+import (
+	"bytes"
+	"codebase-app/internal/module/employee/entity"
+	"codebase-app/pkg"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xuri/excelize/v2"
+)
+
+func (s *employeeService) ImportEmployees(ctx context.Context, req *entity.ImportEmployeesRequest) (entity.ImportEmployeesResponse, error) {
+	xlsx, rows, err := s.planImport(ctx, req)
+	if err != nil {
+		return entity.ImportEmployeesResponse{}, err
+	}
+	xlsx.Close()
+
+	res := entity.NewImportEmployeesResponse(req, rows)
+	if req.DryRun {
+		return res, nil
+	}
+
+	if res.Invalid > 0 {
+		errs := errmsg.NewCustomErrors(400).SetMessage("Beberapa data pegawai tidak valid")
+		for field, msgs := range res.Errors() {
+			for _, msg := range msgs {
+				errs.Add(field, msg)
+			}
+		}
+		return res, errs
+	}
+
+	for i := range rows {
+		if rows[i].Action != entity.ImportActionCreate {
+			continue
+		}
+
+		rows[i].PasswordHashed, err = pkg.HashPassword(rows[i].Password)
+		if err != nil {
+			log.Error().Err(err).Int("row", rows[i].Row).Msg("service::ImportEmployees - Failed to hash password")
+			return res, errmsg.NewCustomErrors(500).SetMessage("Gagal menghash password")
+		}
+	}
+
+	if err := s.repo.ImportEmployees(ctx, rows); err != nil {
+		return res, err
+	}
+
+	res.Committed = true
+	return res, nil
+}
+
+// GetImportErrors returns the uploaded workbook with the invalid cells
+// highlighted and commented, and the messages of each row in an extra column.
+func (s *employeeService) GetImportErrors(ctx context.Context, req *entity.ImportEmployeesRequest) (*bytes.Buffer, error) {
+	xlsx, rows, err := s.planImport(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer xlsx.Close()
+
+	styleId, err := xlsx.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFC7CE"}},
+		Font: &excelize.Font{Color: "9C0006"},
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("service::GetImportErrors - Failed to create style")
+		return nil, err
+	}
+
+	errorCol := len(entity.ImportColumns) + 1
+	cell, _ := excelize.CoordinatesToCellName(errorCol, 1)
+	if err := xlsx.SetCellStr(entity.ImportSheet, cell, "Error"); err != nil {
+		log.Error().Err(err).Msg("service::GetImportErrors - Failed to write header")
+		return nil, err
+	}
+
+	for _, r := range rows {
+		if r.Valid {
+			continue
+		}
+
+		var messages []string
+		for _, column := range entity.ImportColumns {
+			msgs, ok := r.Errors[column.Key]
+			if !ok {
+				continue
+			}
+
+			cell, _ := excelize.CoordinatesToCellName(entity.ImportColumnIndex(column.Key)+1, r.Row)
+			if err := xlsx.SetCellStyle(entity.ImportSheet, cell, cell, styleId); err != nil {
+				log.Error().Err(err).Str("cell", cell).Msg("service::GetImportErrors - Failed to highlight cell")
+				return nil, err
+			}
+
+			text := strings.Join(msgs, ", ")
+			if err := xlsx.AddComment(entity.ImportSheet, excelize.Comment{Cell: cell, Author: "Digihub", Text: text}); err != nil {
+				log.Error().Err(err).Str("cell", cell).Msg("service::GetImportErrors - Failed to comment cell")
+				return nil, err
+			}
+
+			messages = append(messages, column.Header+": "+text)
+		}
+
+		cell, _ := excelize.CoordinatesToCellName(errorCol, r.Row)
+		if err := xlsx.SetCellStr(entity.ImportSheet, cell, strings.Join(messages, "; ")); err != nil {
+			log.Error().Err(err).Str("cell", cell).Msg("service::GetImportErrors - Failed to write errors")
+			return nil, err
+		}
+	}
+
+	buf, err := xlsx.WriteToBuffer()
+	if err != nil {
+		log.Error().Err(err).Msg("service::GetImportErrors - Failed to write workbook")
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// GetImportTemplate returns an empty import workbook, the branch, section and
+// role columns have dropdowns of the current values.
+func (s *employeeService) GetImportTemplate(ctx context.Context) (*bytes.Buffer, error) {
+	refs, err := s.importRefNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	xlsx := excelize.NewFile()
+	defer xlsx.Close()
+
+	if err := xlsx.SetSheetName("Sheet1", entity.ImportSheet); err != nil {
+		log.Error().Err(err).Msg("service::GetImportTemplate - Failed to rename sheet")
+		return nil, err
+	}
+
+	header := make([]any, len(entity.ImportColumns))
+	for i, c := range entity.ImportColumns {
+		header[i] = c.Header
+	}
+	if err := xlsx.SetSheetRow(entity.ImportSheet, "A1", &header); err != nil {
+		log.Error().Err(err).Msg("service::GetImportTemplate - Failed to write header")
+		return nil, err
+	}
+
+	styleId, err := xlsx.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		log.Error().Err(err).Msg("service::GetImportTemplate - Failed to create style")
+		return nil, err
+	}
+
+	lastCol, _ := excelize.ColumnNumberToName(len(entity.ImportColumns))
+	if err := xlsx.SetCellStyle(entity.ImportSheet, "A1", lastCol+"1", styleId); err != nil {
+		log.Error().Err(err).Msg("service::GetImportTemplate - Failed to style header")
+		return nil, err
+	}
+	if err := xlsx.SetColWidth(entity.ImportSheet, "B", lastCol, 24); err != nil {
+		log.Error().Err(err).Msg("service::GetImportTemplate - Failed to set column width")
+		return nil, err
+	}
+
+	// the lists live in a hidden sheet, a list written in the validation is
+	// limited to 255 characters
+	if _, err := xlsx.NewSheet(entity.ImportRefSheet); err != nil {
+		log.Error().Err(err).Msg("service::GetImportTemplate - Failed to create reference sheet")
+		return nil, err
+	}
+
+	for i, ref := range refs {
+		col, _ := excelize.ColumnNumberToName(i + 1)
+		if err := xlsx.SetCellStr(entity.ImportRefSheet, col+"1", entity.ImportColumns[ref.column].Header); err != nil {
+			log.Error().Err(err).Msg("service::GetImportTemplate - Failed to write reference header")
+			return nil, err
+		}
+
+		for j, name := range ref.names {
+			if err := xlsx.SetCellStr(entity.ImportRefSheet, fmt.Sprintf("%s%d", col, j+2), name); err != nil {
+				log.Error().Err(err).Msg("service::GetImportTemplate - Failed to write reference")
+				return nil, err
+			}
+		}
+
+		if len(ref.names) == 0 {
+			continue
+		}
+
+		target, _ := excelize.ColumnNumberToName(ref.column + 1)
+		dv := excelize.NewDataValidation(true)
+		dv.SetSqref(fmt.Sprintf("%s2:%s%d", target, target, entity.MaxImportRows+1))
+		dv.SetSqrefDropList(fmt.Sprintf("%s!$%s$2:$%s$%d", entity.ImportRefSheet, col, col, len(ref.names)+1))
+		if err := xlsx.AddDataValidation(entity.ImportSheet, dv); err != nil {
+			log.Error().Err(err).Msg("service::GetImportTemplate - Failed to add dropdown")
+			return nil, err
+		}
+	}
+
+	if err := xlsx.SetSheetVisible(entity.ImportRefSheet, false); err != nil {
+		log.Error().Err(err).Msg("service::GetImportTemplate - Failed to hide reference sheet")
+		return nil, err
+	}
+
+	buf, err := xlsx.WriteToBuffer()
+	if err != nil {
+		log.Error().Err(err).Msg("service::GetImportTemplate - Failed to write workbook")
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+type importRefNames struct {
+	column int
+	names  []string
+}
+
+// importRefNames returns the sorted names of the branches, sections and roles
+// with the index of their column.
+func (s *employeeService) importRefNames(ctx context.Context) ([]importRefNames, error) {
+	branches, sections, roles, err := s.getImportRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := func(data []entity.Common, label func(string) string) []string {
+		res := make([]string, 0, len(data))
+		for _, d := range data {
+			res = append(res, label(d.Name))
+		}
+		sort.Strings(res)
+		return res
+	}
+
+	return []importRefNames{
+		{column: entity.ImportColumnIndex("branch_name"), names: names(branches, strings.ToUpper)},
+		{column: entity.ImportColumnIndex("section_name"), names: names(sections, strings.ToUpper)},
+		{column: entity.ImportColumnIndex("role_name"), names: names(roles, entity.RoleLabel)},
+	}, nil
+}
+
+func (s *employeeService) getImportRefs(ctx context.Context) (branches, sections, roles []entity.Common, err error) {
+	branches, err = s.repo.GetBranches(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("service::getImportRefs - Failed to get branches")
+		return nil, nil, nil, errmsg.NewCustomErrors(500).SetMessage("Gagal mendapatkan data cabang")
+	}
+
+	sections, err = s.repo.GetPotencies(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("service::getImportRefs - Failed to get potencies")
+		return nil, nil, nil, errmsg.NewCustomErrors(500).SetMessage("Gagal mendapatkan data section")
+	}
+
+	roles, err = s.repo.GetRoles(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("service::getImportRefs - Failed to get roles")
+		return nil, nil, nil, errmsg.NewCustomErrors(500).SetMessage("Gagal mendapatkan data role")
+	}
+
+	return branches, sections, roles, nil
+}
+
+// planImport reads the workbook and checks its rows against the current
+// branches, sections, roles and users, the caller closes the workbook.
+func (s *employeeService) planImport(ctx context.Context, req *entity.ImportEmployeesRequest) (*excelize.File, []entity.ImportEmployeeRow, error) {
+	branches, sections, roles, err := s.getImportRefs(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(req.File)
+	if err != nil {
+		log.Warn().Err(err).Msg("service::planImport - Failed to decode base64 string")
+		return nil, nil, errmsg.NewCustomErrors(400).SetMessage("Gagal mendecode file base64")
+	}
+
+	if err := isXLSXFile(data); err != nil {
+		log.Warn().Err(err).Msg("service::planImport - File is not a valid xlsx file")
+		return nil, nil, errmsg.NewCustomErrors(400).SetMessage("File bukan file xlsx yang valid")
+	}
+
+	xlsx, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		log.Warn().Err(err).Msg("service::planImport - Failed to open xlsx file")
+		return nil, nil, errmsg.NewCustomErrors(400).SetMessage("File bukan file xlsx yang valid")
+	}
+
+	records, err := xlsx.GetRows(entity.ImportSheet)
+	if err != nil {
+		xlsx.Close()
+		if errSheetNotExist, ok := err.(excelize.ErrSheetNotExist); ok {
+			return nil, nil, errmsg.NewCustomErrors(400).SetMessage("Sheet " + errSheetNotExist.SheetName + " tidak ditemukan")
+		}
+		log.Error().Err(err).Msg("service::planImport - Failed to get rows from xlsx file")
+		return nil, nil, errmsg.NewCustomErrors(400).SetMessage("Gagal membaca file xlsx")
+	}
+
+	rows := entity.ParseEmployeeRows(records, entity.NewImportRefs(branches, sections, roles))
+	if len(rows) == 0 {
+		xlsx.Close()
+		return nil, nil, errmsg.NewCustomErrors(400).SetMessage("File tidak memiliki data pegawai")
+	}
+	if len(rows) > entity.MaxImportRows {
+		xlsx.Close()
+		return nil, nil, errmsg.NewCustomErrors(400).SetMessage(fmt.Sprintf("Maksimal %d pegawai per import", entity.MaxImportRows))
+	}
+
+	emails := make([]string, 0, len(rows))
+	for _, r := range rows {
+		if r.Email != "" {
+			emails = append(emails, r.Email)
+		}
+	}
+
+	users, err := s.repo.GetImportUsers(ctx, emails)
+	if err != nil {
+		xlsx.Close()
+		return nil, nil, err
+	}
+
+	entity.PlanImport(rows, users, req.Mode)
+	return xlsx, rows, nil
+}
+
+func isXLSXFile(data []byte) error {
+	// Check the file header
+	if len(data) < 4 {
+		return fmt.Errorf("file is too short to determine type")
+	}
+
+	if data[0] != 0x50 || data[1] != 0x4b || data[2] != 0x03 || data[3] != 0x04 {
+		return fmt.Errorf("file is not a valid xlsx file")
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package entity
+
+import (
+	"codebase-app/pkg"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/period"
+	"codebase-app/pkg/tabular"
+	"codebase-app/pkg/types"
+	"slices"
+	"time"
+)
+
+const (
+	ReportActivities     = "activities"
+	ReportWACs           = "wacs"
+	ReportClients        = "clients"
+	ReportMRSBacklog     = "mrs_backlog"
+	ReportAdminSummaries = "admin_summaries"
+)
+
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusCompleted  = "completed"
+	StatusFailed     = "failed"
+)
+
+type ExportParams struct {
+	From     string `json:"from,omitempty" query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To       string `json:"to,omitempty" query:"to" validate:"omitempty,datetime=2006-01-02"`
+	Month    string `json:"month,omitempty" query:"month" validate:"omitempty,datetime=2006-01"`
+	Timezone string `json:"timezone,omitempty" query:"timezone" validate:"omitempty,timezone"`
+	BranchId string `json:"branch_id,omitempty" query:"branch_id" validate:"omitempty,ulid,exist=branches.id"`
+	Search   string `json:"search,omitempty" query:"search" validate:"omitempty,min=3"`
+	Status   string `json:"status,omitempty" query:"status" validate:"omitempty,oneof=offered wip completed"`
+}
+
+type CreateExportRequest struct {
+	UserId   string
+	UserRole string
+
+	Report string       `json:"report" validate:"required,oneof=activities wacs clients mrs_backlog admin_summaries"`
+	Format string       `json:"format" query:"format" validate:"omitempty,oneof=xlsx csv ndjson"`
+	Locale string       `json:"locale" query:"lang" validate:"omitempty,oneof=id en"`
+	Params ExportParams `json:"params"`
+}
+
+func (r *CreateExportRequest) SetDefault() {
+	if r.Format == "" {
+		r.Format = tabular.FormatXLSX
+	}
+
+	if r.Locale == "" {
+		r.Locale = tabular.LocaleID
+	}
+
+	if r.Params.Timezone == "" {
+		r.Params.Timezone = period.DefaultTimezone
+	}
+
+	if r.Report == ReportAdminSummaries && r.Params.Month == "" {
+		r.Params.Month = time.Now().Format("2006-01")
+	}
+}
+
+func (r *CreateExportRequest) Validate() error {
+	errs := errmsg.NewCustomErrors(400)
+
+	report, ok := Reports[r.Report]
+	if ok && !slices.Contains(report.Roles, r.UserRole) {
+		return errmsg.NewCustomErrors(403, errmsg.WithMessage("Terlarang: role anda tidak diizinkan untuk mengekspor laporan ini"))
+	}
+
+	if (r.Params.From == "") != (r.Params.To == "") {
+		errs.Add("params.from", "from dan to harus diisi bersamaan")
+		errs.Add("params.to", "from dan to harus diisi bersamaan")
+	}
+
+	if r.Params.From != "" && r.Params.To != "" && r.Params.From > r.Params.To {
+		errs.Add("params.from", "from seharusnya tidak lebih besar dari to")
+	}
+
+	if r.Report == ReportActivities && (r.Params.From == "" || r.Params.To == "") {
+		errs.Add("params.from", "from harus diisi ketika export")
+		errs.Add("params.to", "to harus diisi ketika export")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+
+	return nil
+}
+
+type ExportJob struct {
+	Id            string       `json:"id" db:"id"`
+	UserId        string       `json:"-" db:"user_id"`
+	UserRole      string       `json:"-" db:"role"`
+	Report        string       `json:"report" db:"report"`
+	Format        string       `json:"format" db:"format"`
+	Locale        string       `json:"locale" db:"locale"`
+	Params        ExportParams `json:"params" db:"-"`
+	ParamsRaw     []byte       `json:"-" db:"params"`
+	Status        string       `json:"status" db:"status"`
+	TotalRows     int          `json:"total_rows" db:"total_rows"`
+	ProcessedRows int          `json:"processed_rows" db:"processed_rows"`
+	Progress      float64      `json:"progress"`
+	Error         *string      `json:"error" db:"error"`
+	Path          *string      `json:"-" db:"path"`
+	DownloadURL   *string      `json:"download_url"`
+	StartedAt     *time.Time   `json:"started_at" db:"started_at"`
+	FinishedAt    *time.Time   `json:"finished_at" db:"finished_at"`
+	CreatedAt     time.Time    `json:"created_at" db:"created_at"`
+}
+
+// SetProgress fills the progress percentage from the processed row count.
+func (j *ExportJob) SetProgress() {
+	switch {
+	case j.Status == StatusCompleted:
+		j.Progress = 100
+	case j.TotalRows > 0:
+		j.Progress = pkg.Percentage(j.ProcessedRows, j.TotalRows)
+	default:
+		j.Progress = 0
+	}
+}
+
+type GetExportRequest struct {
+	UserId string
+	Id     string `params:"id" validate:"ulid"`
+}
+
+type GetExportsRequest struct {
+	UserId string
+
+	Page     int `query:"page" validate:"required"`
+	Paginate int `query:"paginate" validate:"required"`
+}
+
+func (r *GetExportsRequest) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type GetExportsResponse struct {
+	Items []ExportJob `json:"items"`
+	Meta  types.Meta  `json:"meta"`
+}
+
+// ExportRow is a single streamed row; Total is the number of rows the whole
+// export will produce.
+type ExportRow struct {
+	Total int
+	Cells []any
+}
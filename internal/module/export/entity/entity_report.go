@@ -0,0 +1,114 @@
+package entity
+
+import "codebase-app/pkg/tabular"
+
+// Report describes an exportable report: who may request it and the columns
+// it renders, in the order the repository streams the row values.
+type Report struct {
+	Slug    string
+	Names   map[string]string
+	Roles   []string
+	Columns []tabular.Column
+}
+
+func (r Report) Name(locale string) string {
+	if n, ok := r.Names[locale]; ok {
+		return n
+	}
+
+	return r.Names[tabular.LocaleID]
+}
+
+func header(id, en string) map[string]string {
+	return map[string]string{tabular.LocaleID: id, tabular.LocaleEN: en}
+}
+
+var wacStatusLabels = tabular.Labels(map[string]map[string]string{
+	"offered":   header("Penawaran", "Offered"),
+	"wip":       header("Pengerjaan", "In progress"),
+	"completed": header("Selesai", "Completed"),
+	"edited":    header("Diubah", "Edited"),
+})
+
+var (
+	colNo = tabular.Column{Key: "no", Headers: header("No", "No"), Width: 8}
+)
+
+var Reports = map[string]Report{
+	ReportActivities: {
+		Slug:  "aktivitas-wac",
+		Names: header("Aktivitas", "Activities"),
+		Roles: []string{"admin"},
+		Columns: []tabular.Column{
+			colNo,
+			{Key: "created_at", Headers: header("Tanggal", "Date"), Format: tabular.DateTime},
+			{Key: "client_name", Headers: header("Nama Customer", "Customer Name")},
+			{Key: "branch_name", Headers: header("Cabang", "Branch")},
+			{Key: "employee_name", Headers: header("Penanggung Jawab", "Person in Charge")},
+			{Key: "phone", Headers: header("Nomor WhatsApp", "WhatsApp Number")},
+			{Key: "vehicle_license_number", Headers: header("Nomor Plat", "License Plate"), Width: 15},
+			{Key: "vehicle_type_name", Headers: header("Jenis Mobil", "Vehicle Type")},
+			{Key: "status", Headers: header("Status", "Status"), Width: 15, Format: wacStatusLabels},
+			{Key: "total_potential_leads", Headers: header("Potensi Leads", "Potential Leads"), Width: 15},
+			{Key: "total_leads", Headers: header("Leads", "Leads"), Width: 15},
+			{Key: "total_revenue", Headers: header("Total Revenue", "Total Revenue"), Width: 15},
+		},
+	},
+	ReportWACs: {
+		Slug:  "daftar-wac",
+		Names: header("Daftar WAC", "WAC List"),
+		Roles: []string{"admin", "service_advisor"},
+		Columns: []tabular.Column{
+			colNo,
+			{Key: "created_at", Headers: header("Tanggal", "Date"), Format: tabular.DateTime},
+			{Key: "client_name", Headers: header("Nama Customer", "Customer Name")},
+			{Key: "vehicle_license_number", Headers: header("Nomor Plat", "License Plate"), Width: 15},
+			{Key: "branch_name", Headers: header("Cabang", "Branch")},
+			{Key: "service_advisor", Headers: header("Service Advisor", "Service Advisor")},
+			{Key: "status", Headers: header("Status", "Status"), Width: 15, Format: wacStatusLabels},
+			{Key: "total_potential_leads", Headers: header("Potensi Leads", "Potential Leads"), Width: 15},
+			{Key: "total_leads", Headers: header("Leads", "Leads"), Width: 15},
+			{Key: "total_leads_completed", Headers: header("Leads Selesai", "Completed Leads"), Width: 15},
+			{Key: "total_follow_ups", Headers: header("Follow Up", "Follow Ups"), Width: 15},
+		},
+	},
+	ReportClients: {
+		Slug:  "daftar-customer",
+		Names: header("Daftar Customer", "Customer List"),
+		Roles: []string{"admin"},
+		Columns: []tabular.Column{
+			colNo,
+			{Key: "name", Headers: header("Nama Customer", "Customer Name")},
+			{Key: "vehicle_license_number", Headers: header("Nomor Plat", "License Plate"), Width: 15},
+			{Key: "vehicle_type", Headers: header("Jenis Mobil", "Vehicle Type")},
+			{Key: "phone", Headers: header("Nomor WhatsApp", "WhatsApp Number")},
+		},
+	},
+	ReportMRSBacklog: {
+		Slug:  "backlog-mrs",
+		Names: header("Backlog MRS", "MRS Backlog"),
+		Roles: []string{"admin", "technician"},
+		Columns: []tabular.Column{
+			colNo,
+			{Key: "client_name", Headers: header("Nama Customer", "Customer Name")},
+			{Key: "vehicle_license_number", Headers: header("Nomor Plat", "License Plate"), Width: 15},
+			{Key: "branch_name", Headers: header("Cabang", "Branch")},
+			{Key: "service_advisor", Headers: header("Service Advisor", "Service Advisor")},
+			{Key: "follow_up_at", Headers: header("Jadwal Follow Up", "Follow Up Date"), Format: tabular.Date},
+			{Key: "total_follow_ups", Headers: header("Jumlah Follow Up", "Follow Ups"), Width: 15},
+		},
+	},
+	ReportAdminSummaries: {
+		Slug:  "ringkasan-admin",
+		Names: header("Ringkasan Admin", "Admin Summary"),
+		Roles: []string{"admin"},
+		Columns: []tabular.Column{
+			colNo,
+			{Key: "branch_name", Headers: header("Cabang", "Branch")},
+			{Key: "potency_name", Headers: header("Potensi", "Potency")},
+			{Key: "total_potential_leads", Headers: header("Potensi Leads", "Potential Leads"), Width: 15},
+			{Key: "total_leads", Headers: header("Leads", "Leads"), Width: 15},
+			{Key: "total_wo_do", Headers: header("WO/DO", "WO/DO"), Width: 15},
+		},
+	},
+}
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"bufio"
+	"codebase-app/internal/adapter"
+	m "codebase-app/internal/middleware"
+	"codebase-app/internal/module/export/entity"
+	"codebase-app/internal/module/export/ports"
+	"codebase-app/internal/module/export/repository"
+	"codebase-app/internal/module/export/service"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/response"
+	"codebase-app/pkg/tabular"
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type exportHandler struct {
+	service ports.ExportService
+}
+
+func NewExportHandler() *exportHandler {
+	var (
+		handler = new(exportHandler)
+		repo    = repository.NewExportRepository()
+		service = service.NewExportService(repo)
+	)
+
+	handler.service = service
+
+	return handler
+}
+
+func (h *exportHandler) Register(router fiber.Router) {
+	exports := router.Group("/exports", m.AuthBearer)
+
+	exports.Post("/", h.createExport)
+	exports.Get("/", h.getExports)
+	exports.Get("/reports/:report", h.renderExport)
+	exports.Get("/:id", h.getExport)
+}
+
+func (h *exportHandler) createExport(c *fiber.Ctx) error {
+	var (
+		req = new(entity.CreateExportRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = m.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::createExport - failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	if req.Format == "" {
+		req.Format = c.Query("format")
+	}
+
+	req.UserId = l.GetUserId()
+	req.UserRole = l.GetRole()
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::createExport - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := req.Validate(); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::createExport - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.CreateExport(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(response.Success(res, "Export sedang diproses"))
+}
+
+func (h *exportHandler) getExports(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetExportsRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = m.GetLocals(c)
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::getExports - failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserId = l.GetUserId()
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::getExports - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetExports(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(res, ""))
+}
+
+func (h *exportHandler) getExport(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetExportRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = m.GetLocals(c)
+	)
+
+	req.Id = c.Params("id")
+	req.UserId = l.GetUserId()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::getExport - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetExport(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(res, ""))
+}
+
+// renderExport streams a report directly in the format negotiated from the
+// format query parameter or the Accept header.
+func (h *exportHandler) renderExport(c *fiber.Ctx) error {
+	var (
+		req = new(entity.CreateExportRequest)
+		v   = adapter.Adapters.Validator
+		l   = m.GetLocals(c)
+		err error
+	)
+
+	if err := c.QueryParser(&req.Params); err != nil {
+		log.Warn().Err(err).Msg("handler::renderExport - failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.Format, err = tabular.Negotiate(c.Query("format"), c.Get(fiber.HeaderAccept))
+	if err != nil {
+		return c.Status(fiber.StatusNotAcceptable).JSON(response.Error("Format tidak didukung, gunakan xlsx, csv atau ndjson"))
+	}
+
+	req.Report = c.Params("report")
+	req.Locale = tabular.NegotiateLocale(c.Query("lang"), c.Get(fiber.HeaderAcceptLanguage))
+	req.UserId = l.GetUserId()
+	req.UserRole = l.GetRole()
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::renderExport - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := req.Validate(); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::renderExport - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	// the rows are streamed after the handler returns, the request context is
+	// released by then
+	filename, render := h.service.RenderExport(context.Background(), req)
+
+	c.Set(fiber.HeaderContentDisposition, "attachment; filename=\""+filename+"\"")
+	c.Set(fiber.HeaderContentType, tabular.ContentType(req.Format))
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := render(w); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("handler::renderExport - failed to stream export")
+		}
+	})
+
+	return nil
+}
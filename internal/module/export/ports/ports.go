@@ -0,0 +1,35 @@
+package ports
+
+import (
+	"codebase-app/internal/module/export/entity"
+	"context"
+	"io"
+)
+
+type ExportRepository interface {
+	CreateExport(ctx context.Context, req *entity.CreateExportRequest) (entity.ExportJob, error)
+	GetExport(ctx context.Context, req *entity.GetExportRequest) (entity.ExportJob, error)
+	GetExports(ctx context.Context, req *entity.GetExportsRequest) (entity.GetExportsResponse, error)
+
+	ClaimExport(ctx context.Context) (*entity.ExportJob, error)
+	UpdateExportProgress(ctx context.Context, id string, total, processed int) error
+	CompleteExport(ctx context.Context, id, path string, total int) error
+	FailExport(ctx context.Context, id, reason string) error
+	StreamReport(ctx context.Context, job *entity.ExportJob, fn func(row entity.ExportRow) error) error
+	GetBranchName(ctx context.Context, id string) (string, error)
+}
+
+type ExportService interface {
+	CreateExport(ctx context.Context, req *entity.CreateExportRequest) (entity.ExportJob, error)
+	GetExport(ctx context.Context, req *entity.GetExportRequest) (entity.ExportJob, error)
+	GetExports(ctx context.Context, req *entity.GetExportsRequest) (entity.GetExportsResponse, error)
+
+	// RenderExport prepares a report rendered synchronously, it is meant for
+	// reports requested directly from report endpoints. The filename is known
+	// before render streams the rows into w.
+	RenderExport(ctx context.Context, req *entity.CreateExportRequest) (filename string, render func(w io.Writer) error)
+
+	// ProcessNextExport claims one pending job and renders it, it returns
+	// false when there was nothing to process.
+	ProcessNextExport(ctx context.Context) (bool, error)
+}
@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/export/entity"
+	"codebase-app/internal/module/export/ports"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.ExportRepository = &exportRepository{}
+
+type exportRepository struct {
+	db *sqlx.DB
+}
+
+func NewExportRepository() *exportRepository {
+	return &exportRepository{
+		db: adapter.Adapters.DigihubPostgres,
+	}
+}
+
+const exportColumns = `
+	ej.id,
+	ej.user_id,
+	ej.report,
+	ej.format,
+	ej.locale,
+	ej.params,
+	ej.status,
+	ej.total_rows,
+	ej.processed_rows,
+	ej.error,
+	ej.path,
+	ej.started_at,
+	ej.finished_at,
+	ej.created_at
+`
+
+func (r *exportRepository) CreateExport(ctx context.Context, req *entity.CreateExportRequest) (entity.ExportJob, error) {
+	var res entity.ExportJob
+
+	params, err := json.Marshal(req.Params)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::CreateExport - failed to marshal params")
+		return res, err
+	}
+
+	query := `
+		INSERT INTO export_jobs AS ej (id, user_id, report, format, locale, params)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING ` + exportColumns
+
+	err = r.db.GetContext(ctx, &res, r.db.Rebind(query),
+		ulid.Make().String(), req.UserId, req.Report, req.Format, req.Locale, params)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::CreateExport - failed to create export job")
+		return res, err
+	}
+
+	return res, decodeParams(&res)
+}
+
+func (r *exportRepository) GetExport(ctx context.Context, req *entity.GetExportRequest) (entity.ExportJob, error) {
+	var res entity.ExportJob
+
+	query := `SELECT ` + exportColumns + ` FROM export_jobs ej WHERE ej.id = ? AND ej.user_id = ?`
+
+	err := r.db.GetContext(ctx, &res, r.db.Rebind(query), req.Id, req.UserId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn().Err(err).Any("payload", req).Msg("repo::GetExport - export job not found")
+			return res, errmsg.NewCustomErrors(404, errmsg.WithMessage("Export tidak ditemukan"))
+		}
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetExport - failed to get export job")
+		return res, err
+	}
+
+	return res, decodeParams(&res)
+}
+
+func (r *exportRepository) GetExports(ctx context.Context, req *entity.GetExportsRequest) (entity.GetExportsResponse, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.ExportJob
+	}
+
+	var (
+		res  entity.GetExportsResponse
+		data = make([]dao, 0, req.Paginate)
+	)
+	res.Items = make([]entity.ExportJob, 0, req.Paginate)
+
+	query := `
+		SELECT
+			COUNT(*) OVER() AS total_data,
+		` + exportColumns + `
+		FROM
+			export_jobs ej
+		WHERE
+			ej.user_id = ?
+		ORDER BY
+			ej.created_at DESC
+		LIMIT ? OFFSET ?
+	`
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), req.UserId, req.Paginate, (req.Page-1)*req.Paginate)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetExports - failed to get export jobs")
+		return res, err
+	}
+
+	for _, d := range data {
+		if err := decodeParams(&d.ExportJob); err != nil {
+			return res, err
+		}
+		res.Items = append(res.Items, d.ExportJob)
+	}
+
+	if len(data) > 0 {
+		res.Meta.TotalData = data[0].TotalData
+	}
+
+	res.Meta.CountTotalPage(req.Page, req.Paginate, res.Meta.TotalData)
+
+	return res, nil
+}
+
+// ClaimExport locks the oldest pending job for the calling worker. Jobs that
+// are stuck in processing (the worker died) are picked up again once they
+// stop reporting progress.
+func (r *exportRepository) ClaimExport(ctx context.Context) (*entity.ExportJob, error) {
+	var res entity.ExportJob
+
+	query := `
+		WITH next_job AS (
+			SELECT id
+			FROM export_jobs
+			WHERE
+				status = 'pending'
+				OR (status = 'processing' AND updated_at < NOW() - INTERVAL '15 minutes')
+			ORDER BY created_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		UPDATE export_jobs ej
+		SET
+			status = 'processing',
+			processed_rows = 0,
+			error = NULL,
+			started_at = NOW(),
+			updated_at = NOW()
+		FROM next_job
+		WHERE ej.id = next_job.id
+		RETURNING ` + exportColumns + `,
+			(SELECT r.name FROM users u JOIN roles r ON r.id = u.role_id WHERE u.id = ej.user_id) AS role
+	`
+
+	err := r.db.GetContext(ctx, &res, query)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Error().Err(err).Msg("repo::ClaimExport - failed to claim export job")
+		return nil, err
+	}
+
+	return &res, decodeParams(&res)
+}
+
+func (r *exportRepository) UpdateExportProgress(ctx context.Context, id string, total, processed int) error {
+	query := `
+		UPDATE export_jobs
+		SET total_rows = ?, processed_rows = ?, updated_at = NOW()
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(query), total, processed, id)
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("repo::UpdateExportProgress - failed to update progress")
+		return err
+	}
+
+	return nil
+}
+
+func (r *exportRepository) CompleteExport(ctx context.Context, id, path string, total int) error {
+	query := `
+		UPDATE export_jobs
+		SET
+			status = 'completed',
+			path = ?,
+			total_rows = ?,
+			processed_rows = ?,
+			finished_at = NOW(),
+			updated_at = NOW()
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(query), path, total, total, id)
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("repo::CompleteExport - failed to complete export job")
+		return err
+	}
+
+	return nil
+}
+
+func (r *exportRepository) FailExport(ctx context.Context, id, reason string) error {
+	query := `
+		UPDATE export_jobs
+		SET status = 'failed', error = ?, finished_at = NOW(), updated_at = NOW()
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(query), reason, id)
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("repo::FailExport - failed to mark export job as failed")
+		return err
+	}
+
+	return nil
+}
+
+func decodeParams(job *entity.ExportJob) error {
+	if len(job.ParamsRaw) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(job.ParamsRaw, &job.Params); err != nil {
+		log.Error().Err(err).Str("id", job.Id).Msg("repo::decodeParams - failed to decode params")
+		return err
+	}
+
+	return nil
+}
+
+func (r *exportRepository) GetBranchName(ctx context.Context, id string) (string, error) {
+	var name string
+
+	query := `SELECT name FROM branches WHERE id = ?`
+
+	err := r.db.GetContext(ctx, &name, r.db.Rebind(query), id)
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("repo::GetBranchName - failed to get branch name")
+		return name, err
+	}
+
+	return name, nil
+}
@@ -0,0 +1,456 @@
+package repository
+
+import (
+	"codebase-app/internal/module/export/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+	"github.com/shopspring/decimal"
+)
+
+// StreamReport runs the report query of the job and calls fn once per row,
+// rows are read from the cursor one at a time so exports are never truncated
+// nor fully loaded into memory. Cells are raw values in the column order of
+// the report, formatting is left to the renderer.
+func (r *exportRepository) StreamReport(ctx context.Context, job *entity.ExportJob, fn func(row entity.ExportRow) error) error {
+	switch job.Report {
+	case entity.ReportActivities:
+		return r.streamActivities(ctx, job, fn)
+	case entity.ReportWACs:
+		return r.streamWACs(ctx, job, fn)
+	case entity.ReportClients:
+		return r.streamClients(ctx, job, fn)
+	case entity.ReportMRSBacklog:
+		return r.streamMRSBacklog(ctx, job, fn)
+	case entity.ReportAdminSummaries:
+		return r.streamAdminSummaries(ctx, job, fn)
+	default:
+		return errmsg.NewCustomErrors(400, errmsg.WithMessage("Laporan tidak dikenal"))
+	}
+}
+
+type totalData struct {
+	TotalData int `db:"total_data"`
+}
+
+func (t totalData) total() int {
+	return t.TotalData
+}
+
+type streamable interface {
+	total() int
+}
+
+func streamRows[T streamable](
+	ctx context.Context,
+	db *sqlx.DB,
+	query string,
+	args []any,
+	fn func(row entity.ExportRow) error,
+	cells func(no int, d T) []any,
+) error {
+	rows, err := db.QueryxContext(ctx, db.Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	no := 0
+	for rows.Next() {
+		var d T
+		if err := rows.StructScan(&d); err != nil {
+			return err
+		}
+
+		no++
+		if err := fn(entity.ExportRow{Total: d.total(), Cells: cells(no, d)}); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// dateRangeFilter appends a created_at filter on the given column, the dates
+// are interpreted in the timezone of the export.
+func dateRangeFilter(query *strings.Builder, args *[]any, column string, p entity.ExportParams) {
+	if p.From == "" || p.To == "" {
+		return
+	}
+
+	query.WriteString(` AND (` + column + ` AT TIME ZONE ?)::date BETWEEN ?::date AND ?::date`)
+	*args = append(*args, p.Timezone, p.From, p.To)
+}
+
+func (r *exportRepository) streamActivities(ctx context.Context, job *entity.ExportJob, fn func(row entity.ExportRow) error) error {
+	type dao struct {
+		totalData
+		CreatedAt            time.Time       `db:"created_at"`
+		ClientName           *string         `db:"client_name"`
+		BranchName           *string         `db:"branch_name"`
+		EmployeeName         *string         `db:"employee_name"`
+		Phone                *string         `db:"phone"`
+		VehicleLicenseNumber *string         `db:"vehicle_license_number"`
+		VehicleTypeName      *string         `db:"vehicle_type_name"`
+		Status               string          `db:"status"`
+		TotalPotentialLeads  int             `db:"total_potential_leads"`
+		TotalLeads           int             `db:"total_leads"`
+		TotalRevenue         decimal.Decimal `db:"total_revenue"`
+	}
+
+	var (
+		p     = job.Params
+		query = strings.Builder{}
+		args  = make([]any, 0, 6)
+	)
+
+	query.WriteString(`
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			waca.created_at,
+			c.name AS client_name,
+			b.name AS branch_name,
+			u.name AS employee_name,
+			c.phone,
+			v.license_number AS vehicle_license_number,
+			vt.name AS vehicle_type_name,
+			waca.status,
+			waca.total_potential_leads,
+			waca.total_leads,
+			waca.total_revenue
+		FROM
+			wac_activities waca
+		LEFT JOIN
+			users u ON waca.user_id = u.id
+		LEFT JOIN
+			walk_around_checks wac ON waca.wac_id = wac.id
+		LEFT JOIN
+			branches b ON wac.branch_id = b.id
+		LEFT JOIN
+			clients c ON wac.client_id = c.id
+		LEFT JOIN
+			vehicles v ON wac.vehicle_id = v.id
+		LEFT JOIN
+			vehicle_types vt ON v.vehicle_type_id = vt.id
+		WHERE
+			wac.deleted_at IS NULL
+	`)
+
+	if p.Search != "" {
+		query.WriteString(` AND (waca.status ILIKE ? OR u.name ILIKE ?)`)
+		args = append(args, "%"+p.Search+"%", "%"+p.Search+"%")
+	}
+
+	if p.BranchId != "" {
+		query.WriteString(` AND wac.branch_id = ?`)
+		args = append(args, p.BranchId)
+	}
+
+	dateRangeFilter(&query, &args, "waca.created_at", p)
+	query.WriteString(` ORDER BY waca.created_at DESC`)
+
+	err := streamRows(ctx, r.db, query.String(), args, fn, func(no int, d dao) []any {
+		return []any{
+			no,
+			d.CreatedAt,
+			d.ClientName,
+			d.BranchName,
+			d.EmployeeName,
+			d.Phone,
+			d.VehicleLicenseNumber,
+			d.VehicleTypeName,
+			d.Status,
+			d.TotalPotentialLeads,
+			d.TotalLeads,
+			d.TotalRevenue,
+		}
+	})
+	if err != nil {
+		log.Error().Err(err).Any("payload", job).Msg("repo::streamActivities - failed to stream activities")
+		return err
+	}
+
+	return nil
+}
+
+func (r *exportRepository) streamWACs(ctx context.Context, job *entity.ExportJob, fn func(row entity.ExportRow) error) error {
+	type dao struct {
+		totalData
+		CreatedAt            time.Time `db:"created_at"`
+		ClientName           *string   `db:"client_name"`
+		VehicleLicenseNumber *string   `db:"vehicle_license_number"`
+		BranchName           *string   `db:"branch_name"`
+		ServiceAdvisor       *string   `db:"service_advisor"`
+		Status               string    `db:"status"`
+		TotalPotentialLeads  int       `db:"total_potential_leads"`
+		TotalLeads           int       `db:"total_leads"`
+		TotalLeadsCompleted  int       `db:"total_leads_completed"`
+		TotalFollowUps       int       `db:"total_follow_ups"`
+	}
+
+	var (
+		p     = job.Params
+		query = strings.Builder{}
+		args  = make([]any, 0, 8)
+	)
+
+	query.WriteString(`
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			wac.created_at,
+			c.name AS client_name,
+			v.license_number AS vehicle_license_number,
+			b.name AS branch_name,
+			u.name AS service_advisor,
+			wac.status,
+			wac.total_potential_leads,
+			wac.total_leads,
+			wac.total_leads_completed,
+			wac.total_follow_ups
+		FROM
+			walk_around_checks wac
+		LEFT JOIN
+			clients c ON c.id = wac.client_id
+		LEFT JOIN
+			vehicles v ON v.id = wac.vehicle_id
+		LEFT JOIN
+			branches b ON b.id = wac.branch_id
+		LEFT JOIN
+			users u ON u.id = wac.user_id
+		WHERE
+			wac.deleted_at IS NULL
+	`)
+
+	// service advisors only export the WACs they can see in their own list
+	if job.UserRole == "service_advisor" {
+		query.WriteString(`
+			AND (
+				wac.user_id = ?
+				OR EXISTS (
+					SELECT 1 FROM walk_around_check_conditions wacc
+					WHERE wacc.walk_around_check_id = wac.id AND wacc.assigned_user_id = ?
+				)
+			)
+		`)
+		args = append(args, job.UserId, job.UserId)
+	}
+
+	if p.Search != "" {
+		query.WriteString(` AND (c.name ILIKE ? OR v.license_number ILIKE ?)`)
+		args = append(args, "%"+p.Search+"%", "%"+p.Search+"%")
+	}
+
+	if p.Status != "" {
+		query.WriteString(` AND wac.status = ?`)
+		args = append(args, p.Status)
+	}
+
+	if p.BranchId != "" {
+		query.WriteString(` AND wac.branch_id = ?`)
+		args = append(args, p.BranchId)
+	}
+
+	dateRangeFilter(&query, &args, "wac.created_at", p)
+	query.WriteString(` ORDER BY wac.created_at DESC`)
+
+	err := streamRows(ctx, r.db, query.String(), args, fn, func(no int, d dao) []any {
+		return []any{
+			no,
+			d.CreatedAt,
+			d.ClientName,
+			d.VehicleLicenseNumber,
+			d.BranchName,
+			d.ServiceAdvisor,
+			d.Status,
+			d.TotalPotentialLeads,
+			d.TotalLeads,
+			d.TotalLeadsCompleted,
+			d.TotalFollowUps,
+		}
+	})
+	if err != nil {
+		log.Error().Err(err).Any("payload", job).Msg("repo::streamWACs - failed to stream wacs")
+		return err
+	}
+
+	return nil
+}
+
+func (r *exportRepository) streamClients(ctx context.Context, job *entity.ExportJob, fn func(row entity.ExportRow) error) error {
+	type dao struct {
+		totalData
+		Name                 string  `db:"name"`
+		VehicleLicenseNumber *string `db:"vehicle_license_number"`
+		VehicleType          *string `db:"vehicle_type"`
+		Phone                string  `db:"phone"`
+	}
+
+	var (
+		p     = job.Params
+		query = strings.Builder{}
+		args  = make([]any, 0, 3)
+	)
+
+	query.WriteString(`
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			c.name,
+			v.license_number AS vehicle_license_number,
+			vt.name AS vehicle_type,
+			c.phone
+		FROM
+			clients c
+		LEFT JOIN
+			vehicles v ON v.client_id = c.id AND v.deleted_at IS NULL
+		LEFT JOIN
+			vehicle_types vt ON vt.id = v.vehicle_type_id
+		WHERE
+			c.deleted_at IS NULL
+	`)
+
+	if p.Search != "" {
+		query.WriteString(` AND (c.name ILIKE ? OR v.license_number ILIKE ? OR c.phone ILIKE ?)`)
+		args = append(args, "%"+p.Search+"%", "%"+p.Search+"%", "%"+p.Search+"%")
+	}
+
+	// a client has a row per vehicle
+	query.WriteString(` ORDER BY c.name ASC, c.id, v.created_at`)
+
+	err := streamRows(ctx, r.db, query.String(), args, fn, func(no int, d dao) []any {
+		return []any{no, d.Name, d.VehicleLicenseNumber, d.VehicleType, d.Phone}
+	})
+	if err != nil {
+		log.Error().Err(err).Any("payload", job).Msg("repo::streamClients - failed to stream clients")
+		return err
+	}
+
+	return nil
+}
+
+func (r *exportRepository) streamMRSBacklog(ctx context.Context, job *entity.ExportJob, fn func(row entity.ExportRow) error) error {
+	type dao struct {
+		totalData
+		ClientName           *string    `db:"client_name"`
+		VehicleLicenseNumber *string    `db:"vehicle_license_number"`
+		BranchName           *string    `db:"branch_name"`
+		ServiceAdvisor       *string    `db:"service_advisor"`
+		FollowUpAt           *time.Time `db:"follow_up_at"`
+		TotalFollowUps       int        `db:"total_follow_ups"`
+	}
+
+	var (
+		p     = job.Params
+		query = strings.Builder{}
+		args  = make([]any, 0, 2)
+	)
+
+	query.WriteString(`
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			c.name AS client_name,
+			v.license_number AS vehicle_license_number,
+			b.name AS branch_name,
+			sa.name AS service_advisor,
+			wac.follow_up_at,
+			wac.total_follow_ups
+		FROM
+			walk_around_checks wac
+		LEFT JOIN
+			clients c ON c.id = wac.client_id
+		LEFT JOIN
+			vehicles v ON v.id = wac.vehicle_id
+		LEFT JOIN
+			branches b ON b.id = wac.branch_id
+		LEFT JOIN
+			users sa ON sa.id = wac.user_id
+		WHERE
+			wac.deleted_at IS NULL
+			AND wac.is_needs_follow_up = TRUE
+	`)
+
+	// technicians work on the backlog of their own branch
+	if job.UserRole == "technician" {
+		query.WriteString(` AND wac.branch_id = (SELECT branch_id FROM users WHERE id = ?)`)
+		args = append(args, job.UserId)
+	} else if p.BranchId != "" {
+		query.WriteString(` AND wac.branch_id = ?`)
+		args = append(args, p.BranchId)
+	}
+
+	query.WriteString(` ORDER BY wac.follow_up_at ASC`)
+
+	err := streamRows(ctx, r.db, query.String(), args, fn, func(no int, d dao) []any {
+		return []any{no, d.ClientName, d.VehicleLicenseNumber, d.BranchName, d.ServiceAdvisor, d.FollowUpAt, d.TotalFollowUps}
+	})
+	if err != nil {
+		log.Error().Err(err).Any("payload", job).Msg("repo::streamMRSBacklog - failed to stream mrs backlog")
+		return err
+	}
+
+	return nil
+}
+
+func (r *exportRepository) streamAdminSummaries(ctx context.Context, job *entity.ExportJob, fn func(row entity.ExportRow) error) error {
+	type dao struct {
+		totalData
+		BranchName          string `db:"branch_name"`
+		PotencyName         string `db:"potency_name"`
+		TotalPotentialLeads int    `db:"total_potential_leads"`
+		TotalLeads          int    `db:"total_leads"`
+		TotalWODO           int    `db:"total_wo_do"`
+	}
+
+	var (
+		p     = job.Params
+		query = strings.Builder{}
+		args  = make([]any, 0, 3)
+	)
+
+	query.WriteString(`
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			b.name AS branch_name,
+			p.name AS potency_name,
+			COUNT(wacc.id) AS total_potential_leads,
+			COALESCE(SUM(CASE WHEN wacc.is_interested = TRUE AND wac.status != 'offered' THEN 1 ELSE 0 END), 0) AS total_leads,
+			COALESCE(SUM(CASE WHEN wacc.is_interested = TRUE AND wac.status = 'completed' THEN 1 ELSE 0 END), 0) AS total_wo_do
+		FROM
+			walk_around_check_conditions wacc
+		JOIN
+			walk_around_checks wac ON wac.id = wacc.walk_around_check_id
+		JOIN
+			branches b ON b.id = wac.branch_id
+		JOIN
+			potencies p ON p.id = wacc.potency_id
+		WHERE
+			wac.deleted_at IS NULL
+			AND TO_CHAR(wac.created_at AT TIME ZONE ?, 'YYYY-MM') = ?
+	`)
+	args = append(args, p.Timezone, p.Month)
+
+	if p.BranchId != "" {
+		query.WriteString(` AND wac.branch_id = ?`)
+		args = append(args, p.BranchId)
+	}
+
+	query.WriteString(`
+		GROUP BY
+			b.name, p.name
+		ORDER BY
+			b.name, p.name
+	`)
+
+	err := streamRows(ctx, r.db, query.String(), args, fn, func(no int, d dao) []any {
+		return []any{no, d.BranchName, d.PotencyName, d.TotalPotentialLeads, d.TotalLeads, d.TotalWODO}
+	})
+	if err != nil {
+		log.Error().Err(err).Any("payload", job).Msg("repo::streamAdminSummaries - failed to stream admin summaries")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"codebase-app/internal/module/export/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExportOwnedByTheUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := &exportRepository{db: sqlx.NewDb(db, "postgres")}
+	req := &entity.GetExportRequest{UserId: "other", Id: "job"}
+
+	// the job of another user is not found, the id alone does not give it
+	mock.ExpectQuery(regexp.QuoteMeta("FROM export_jobs ej WHERE ej.id = $1 AND ej.user_id = $2")).
+		WithArgs("job", "other").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.GetExport(context.Background(), req)
+
+	var customErr *errmsg.CustomError
+	if assert.ErrorAs(t, err, &customErr) {
+		assert.Equal(t, 404, customErr.Code)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
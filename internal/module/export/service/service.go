@@ -0,0 +1,235 @@
+package service
+
+import (
+	"codebase-app/internal/infrastructure/config"
+	"codebase-app/internal/module/export/entity"
+	"codebase-app/internal/module/export/ports"
+	"codebase-app/pkg/storage-manager"
+	"codebase-app/pkg/tabular"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.ExportService = &exportService{}
+
+// progressEvery is the number of rows written between two progress updates.
+const progressEvery = 500
+
+type exportService struct {
+	repo ports.ExportRepository
+}
+
+func NewExportService(repo ports.ExportRepository) *exportService {
+	return &exportService{
+		repo: repo,
+	}
+}
+
+func (s *exportService) CreateExport(ctx context.Context, req *entity.CreateExportRequest) (entity.ExportJob, error) {
+	res, err := s.repo.CreateExport(ctx, req)
+	if err != nil {
+		return res, err
+	}
+
+	s.decorate(&res)
+	return res, nil
+}
+
+func (s *exportService) GetExport(ctx context.Context, req *entity.GetExportRequest) (entity.ExportJob, error) {
+	res, err := s.repo.GetExport(ctx, req)
+	if err != nil {
+		return res, err
+	}
+
+	s.decorate(&res)
+	return res, nil
+}
+
+func (s *exportService) GetExports(ctx context.Context, req *entity.GetExportsRequest) (entity.GetExportsResponse, error) {
+	res, err := s.repo.GetExports(ctx, req)
+	if err != nil {
+		return res, err
+	}
+
+	for i := range res.Items {
+		s.decorate(&res.Items[i])
+	}
+
+	return res, nil
+}
+
+// decorate fills the computed fields, the download link is signed on every
+// read so it never outlives the shared link expiration.
+func (s *exportService) decorate(job *entity.ExportJob) {
+	job.SetProgress()
+
+	if job.Status == entity.StatusCompleted && job.Path != nil {
+		exp := time.Duration(config.Envs.Guard.SharedLinkExp) * time.Minute
+		url := storage.GenerateSignedURL(filepath.Base(*job.Path), exp)
+		job.DownloadURL = &url
+	}
+}
+
+func (s *exportService) ProcessNextExport(ctx context.Context) (bool, error) {
+	job, err := s.repo.ClaimExport(ctx)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	log.Info().Str("id", job.Id).Str("report", job.Report).Msg("service::ProcessNextExport - processing export")
+
+	path, total, err := s.render(ctx, job)
+	if err != nil {
+		log.Error().Err(err).Str("id", job.Id).Msg("service::ProcessNextExport - failed to render export")
+		if path != "" {
+			os.Remove(path)
+		}
+
+		// use a fresh context, the job must be marked as failed even on shutdown
+		if errFail := s.repo.FailExport(context.Background(), job.Id, err.Error()); errFail != nil {
+			return true, errFail
+		}
+		return true, err
+	}
+
+	if err := s.repo.CompleteExport(ctx, job.Id, path, total); err != nil {
+		os.Remove(path)
+		return true, err
+	}
+
+	log.Info().Str("id", job.Id).Int("rows", total).Msg("service::ProcessNextExport - export completed")
+	return true, nil
+}
+
+// RenderExport renders the report directly into w without creating a job.
+func (s *exportService) RenderExport(ctx context.Context, req *entity.CreateExportRequest) (string, func(w io.Writer) error) {
+	job := &entity.ExportJob{
+		UserId:   req.UserId,
+		UserRole: req.UserRole,
+		Report:   req.Report,
+		Format:   req.Format,
+		Locale:   req.Locale,
+		Params:   req.Params,
+	}
+
+	return s.filename(job), func(w io.Writer) error {
+		_, err := s.write(ctx, job, w, nil)
+		return err
+	}
+}
+
+// render streams the report rows into a file in private storage.
+func (s *exportService) render(ctx context.Context, job *entity.ExportJob) (path string, total int, err error) {
+	dir := config.Envs.App.LocalStoragePrivatePath
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", 0, err
+	}
+
+	path = filepath.Join(dir, "export-"+job.Id+"-"+s.filename(job))
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	total, err = s.write(ctx, job, file, func(total, processed int) error {
+		return s.repo.UpdateExportProgress(ctx, job.Id, total, processed)
+	})
+	if err != nil {
+		return path, 0, err
+	}
+
+	return path, total, nil
+}
+
+// write renders the report of the job into w and reports the progress every
+// progressEvery rows when progress is not nil.
+func (s *exportService) write(ctx context.Context, job *entity.ExportJob, w io.Writer, progress func(total, processed int) error) (int, error) {
+	report, ok := entity.Reports[job.Report]
+	if !ok {
+		return 0, fmt.Errorf("unknown report %q", job.Report)
+	}
+
+	loc, err := time.LoadLocation(job.Params.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	opt := tabular.Options{
+		Locale:   job.Locale,
+		Location: loc,
+		Preamble: s.preamble(ctx, job, report),
+	}
+
+	tw, err := tabular.NewWriter(w, job.Format, report.Columns, opt)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	err = s.repo.StreamReport(ctx, job, func(row entity.ExportRow) error {
+		processed++
+
+		if err := tw.WriteRow(row.Cells); err != nil {
+			return err
+		}
+
+		if progress != nil && processed%progressEvery == 0 {
+			return progress(row.Total, processed)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return 0, err
+	}
+
+	return processed, nil
+}
+
+// preamble is the title block written above the xlsx table.
+func (s *exportService) preamble(ctx context.Context, job *entity.ExportJob, report entity.Report) [][]any {
+	var (
+		p    = job.Params
+		rows = [][]any{{"Kalla Toyota"}}
+	)
+
+	if p.BranchId != "" {
+		if name, err := s.repo.GetBranchName(ctx, p.BranchId); err == nil {
+			rows[0] = append(rows[0], "Cabang "+name)
+		}
+	}
+
+	switch {
+	case p.From != "" && p.To != "":
+		rows = append(rows, []any{"Periode", p.From + " - " + p.To})
+	case p.Month != "":
+		rows = append(rows, []any{"Periode", p.Month})
+	}
+
+	return append(rows, []any{"Report", report.Name(job.Locale)})
+}
+
+func (s *exportService) filename(job *entity.ExportJob) string {
+	var (
+		report = entity.Reports[job.Report]
+		loc, _ = time.LoadLocation(job.Params.Timezone)
+	)
+
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return report.Slug + "-" + time.Now().In(loc).Format("20060102150405") + "." + tabular.Extension(job.Format)
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"codebase-app/internal/infrastructure/config"
+	"codebase-app/internal/module/export/entity"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/tabular"
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRepo keeps the jobs in memory the way export_jobs does, pending jobs
+// are claimed in order.
+type fakeRepo struct {
+	jobs      map[string]*entity.ExportJob
+	pending   []string
+	rows      [][]any
+	streamErr error
+}
+
+func newFakeRepo(t *testing.T) *fakeRepo {
+	t.Helper()
+
+	config.Envs = &config.Config{}
+	config.Envs.App.LocalStoragePrivatePath = t.TempDir()
+
+	return &fakeRepo{jobs: make(map[string]*entity.ExportJob)}
+}
+
+func (r *fakeRepo) CreateExport(ctx context.Context, req *entity.CreateExportRequest) (entity.ExportJob, error) {
+	job := &entity.ExportJob{
+		Id:       "job" + strconv.Itoa(len(r.jobs)),
+		UserId:   req.UserId,
+		UserRole: req.UserRole,
+		Report:   req.Report,
+		Format:   req.Format,
+		Locale:   req.Locale,
+		Params:   req.Params,
+		Status:   entity.StatusPending,
+	}
+	r.jobs[job.Id] = job
+	r.pending = append(r.pending, job.Id)
+
+	return *job, nil
+}
+
+func (r *fakeRepo) GetExport(ctx context.Context, req *entity.GetExportRequest) (entity.ExportJob, error) {
+	job, ok := r.jobs[req.Id]
+	if !ok || job.UserId != req.UserId {
+		return entity.ExportJob{}, errmsg.NewCustomErrors(404, errmsg.WithMessage("Export tidak ditemukan"))
+	}
+
+	return *job, nil
+}
+
+func (r *fakeRepo) GetExports(ctx context.Context, req *entity.GetExportsRequest) (entity.GetExportsResponse, error) {
+	return entity.GetExportsResponse{}, nil
+}
+
+func (r *fakeRepo) ClaimExport(ctx context.Context) (*entity.ExportJob, error) {
+	if len(r.pending) == 0 {
+		return nil, nil
+	}
+
+	job := r.jobs[r.pending[0]]
+	r.pending = r.pending[1:]
+	job.Status = entity.StatusProcessing
+
+	claimed := *job
+	return &claimed, nil
+}
+
+func (r *fakeRepo) UpdateExportProgress(ctx context.Context, id string, total, processed int) error {
+	r.jobs[id].TotalRows, r.jobs[id].ProcessedRows = total, processed
+	return nil
+}
+
+func (r *fakeRepo) CompleteExport(ctx context.Context, id, path string, total int) error {
+	job := r.jobs[id]
+	job.Status, job.Path, job.TotalRows, job.ProcessedRows = entity.StatusCompleted, &path, total, total
+	return nil
+}
+
+func (r *fakeRepo) FailExport(ctx context.Context, id, reason string) error {
+	job := r.jobs[id]
+	job.Status, job.Error = entity.StatusFailed, &reason
+	return nil
+}
+
+func (r *fakeRepo) StreamReport(ctx context.Context, job *entity.ExportJob, fn func(row entity.ExportRow) error) error {
+	for _, cells := range r.rows {
+		if err := fn(entity.ExportRow{Total: len(r.rows), Cells: cells}); err != nil {
+			return err
+		}
+	}
+
+	return r.streamErr
+}
+
+func (r *fakeRepo) GetBranchName(ctx context.Context, id string) (string, error) {
+	return "", nil
+}
+
+func createCSVExport(t *testing.T, s *exportService) entity.ExportJob {
+	t.Helper()
+
+	job, err := s.CreateExport(context.Background(), &entity.CreateExportRequest{
+		UserId:   "user",
+		UserRole: "admin",
+		Report:   entity.ReportWACs,
+		Format:   tabular.FormatCSV,
+		Locale:   tabular.LocaleEN,
+		Params:   entity.ExportParams{Timezone: "Asia/Makassar"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return job
+}
+
+func wacRow() []any {
+	return make([]any, len(entity.Reports[entity.ReportWACs].Columns))
+}
+
+func TestProcessNextExportCompletes(t *testing.T) {
+	var (
+		repo = newFakeRepo(t)
+		s    = NewExportService(repo)
+		job  = createCSVExport(t, s)
+	)
+	assert.Equal(t, entity.StatusPending, job.Status)
+
+	repo.rows = [][]any{wacRow(), wacRow()}
+
+	ok, err := s.ProcessNextExport(context.Background())
+	assert.True(t, ok)
+	assert.NoError(t, err)
+
+	res, err := s.GetExport(context.Background(), &entity.GetExportRequest{UserId: "user", Id: job.Id})
+	assert.NoError(t, err)
+	assert.Equal(t, entity.StatusCompleted, res.Status)
+	assert.Equal(t, 2, res.TotalRows)
+	assert.Equal(t, float64(100), res.Progress)
+	assert.NotNil(t, res.DownloadURL)
+
+	content, err := os.ReadFile(*res.Path)
+	assert.NoError(t, err)
+	// the header and the two rows
+	assert.Len(t, strings.Split(strings.TrimSpace(string(content)), "\n"), 3)
+
+	// nothing is left to claim
+	ok, err = s.ProcessNextExport(context.Background())
+	assert.False(t, ok)
+	assert.NoError(t, err)
+}
+
+func TestProcessNextExportFails(t *testing.T) {
+	var (
+		repo = newFakeRepo(t)
+		s    = NewExportService(repo)
+		job  = createCSVExport(t, s)
+	)
+
+	repo.rows = [][]any{wacRow()}
+	repo.streamErr = errors.New("connection reset")
+
+	ok, err := s.ProcessNextExport(context.Background())
+	assert.True(t, ok)
+	assert.Error(t, err)
+
+	res, err := s.GetExport(context.Background(), &entity.GetExportRequest{UserId: "user", Id: job.Id})
+	assert.NoError(t, err)
+	assert.Equal(t, entity.StatusFailed, res.Status)
+	assert.Equal(t, "connection reset", *res.Error)
+	assert.Nil(t, res.DownloadURL)
+
+	// the partial file is removed
+	files, _ := os.ReadDir(config.Envs.App.LocalStoragePrivatePath)
+	assert.Empty(t, files)
+}
@@ -34,7 +34,7 @@ func (h *mrsHandler) Register(router fiber.Router) {
 	mrs := router.Group("/mrs", m.AuthBearer, m.AuthRole([]string{"technician"}))
 
 	mrs.Get("/processes", h.getMRSs)
-	mrs.Patch("/processes/:id", h.renewOffer)
+	mrs.Patch("/processes/:id", m.Idempotency, h.renewOffer)
 	mrs.Delete("/processes/:id", h.deleteFollowUp)
 }
 
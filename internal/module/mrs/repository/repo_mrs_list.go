@@ -35,6 +35,7 @@ func (r *mrsRepository) GetMRSs(ctx context.Context, req *entity.GetMRSsRequest)
 		WHERE
 			wac.branch_id = (SELECT branch_id FROM users WHERE id = ?)
 			AND wac.is_needs_follow_up = TRUE
+			AND wac.deleted_at IS NULL
 		ORDER BY
 			wac.follow_up_at DESC
 	`
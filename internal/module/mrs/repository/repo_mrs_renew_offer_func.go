@@ -97,6 +97,7 @@ func (r *mrsRepository) createWACCopy(ctx context.Context, tx *sqlx.Tx, req *ent
 				branch_id,
 				section_id,
 				client_id,
+				vehicle_id,
 				user_id,
 				status,
 
@@ -108,6 +109,7 @@ func (r *mrsRepository) createWACCopy(ctx context.Context, tx *sqlx.Tx, req *ent
 				(SELECT branch_id FROM wac_copy),
 				(SELECT section_id FROM wac_copy),
 				(SELECT client_id FROM wac_copy),
+				(SELECT vehicle_id FROM wac_copy),
 				(SELECT user_id FROM wac_copy),
 				'wip',
 				?,
@@ -141,7 +143,8 @@ func (r *mrsRepository) moveVehicleConditionsToNewWAC(
 				walk_around_check_conditions
 			SET
 				walk_around_check_id = ?,
-				is_interested = TRUE
+				is_interested = TRUE,
+				updated_at = NOW()
 			WHERE
 				is_interested = FALSE
 				AND walk_around_check_id = ?
@@ -181,7 +184,8 @@ func (r *mrsRepository) updateTotalFollowUps(
 			walk_around_checks
 		SET
 			total_potential_leads = total_potential_leads - ?,
-			total_follow_ups = ?
+			total_follow_ups = ?,
+			updated_at = NOW()
 		WHERE
 			id = ?
 	`
@@ -1,11 +1,87 @@
 package entity
 
-import "github.com/LukaGiorgadze/gonull"
+import (
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/period"
+	"time"
 
+	"github.com/LukaGiorgadze/gonull"
+	"github.com/lib/pq"
+)
+
+const (
+	StatusRunning   = "running"
+	StatusScheduled = "scheduled"
+	StatusExpired   = "expired"
+	StatusInactive  = "inactive"
+)
+
+// Running is the condition of the promotions p shown by the apps today, an
+// expired promotion drops out without being touched.
+const Running = `p.deleted_at IS NULL AND p.is_active
+	AND p.start_date <= ` + period.Today + `
+	AND (p.end_date IS NULL OR p.end_date >= ` + period.Today + `)`
+
+// Status is the status of the promotion p, see the Status constants.
+const Status = `CASE
+	WHEN NOT p.is_active THEN '` + StatusInactive + `'
+	WHEN p.start_date > ` + period.Today + ` THEN '` + StatusScheduled + `'
+	WHEN p.end_date < ` + period.Today + ` THEN '` + StatusExpired + `'
+	ELSE '` + StatusRunning + `'
+END`
+
+// TargetsBranch is the condition of the promotions p shown in the branch of
+// the SQL expression, a promotion without target branches is shown in all of
+// them. A NULL branch only matches those.
+func TargetsBranch(branch string) string {
+	return `(
+		NOT EXISTS (SELECT 1 FROM promotion_branches pb WHERE pb.promotion_id = p.id)
+		OR EXISTS (SELECT 1 FROM promotion_branches pb WHERE pb.promotion_id = p.id AND pb.branch_id = ` + branch + `)
+	)`
+}
+
+// TargetsPotency is the condition of the promotions p relevant to one of the
+// potencies selected by the SQL subquery, a promotion without target
+// potencies is relevant to all of them.
+func TargetsPotency(potencies string) string {
+	return `(
+		NOT EXISTS (SELECT 1 FROM promotion_potencies pp WHERE pp.promotion_id = p.id)
+		OR EXISTS (SELECT 1 FROM promotion_potencies pp WHERE pp.promotion_id = p.id AND pp.potency_id IN (` + potencies + `))
+	)`
+}
+
+// RedirectLinkExpiration is how long the redirect link of the promotion
+// listed at now counts the clicks, until the end of its end date.
+func (p Promotion) RedirectLinkExpiration(now time.Time) time.Duration {
+	if p.EndDate == nil {
+		return RedirectLinkExp
+	}
+
+	end, err := time.ParseInLocation("2006-01-02", *p.EndDate, now.Location())
+	if err != nil {
+		return RedirectLinkExp
+	}
+
+	return end.AddDate(0, 0, 1).Sub(now)
+}
+
+/*
+Create Promotion - Start
+  - A promotion runs from start_date, today by default, until end_date, it is
+    open ended without one
+  - The apps show the running promotions by display_order, the lowest first
+  - Empty branch_ids or potency_ids target all the branches or potencies
+*/
 type CreatePromotionRequest struct {
-	Title string  `json:"title" db:"title" validate:"required,max=255"`
-	Image string  `json:"image" validate:"base64"`
-	Link  *string `json:"link" db:"link" validate:"omitempty,url"`
+	Title        string   `json:"title" db:"title" validate:"required,max=255"`
+	Image        string   `json:"image" validate:"base64"`
+	Link         *string  `json:"link" db:"link" validate:"omitempty,url"`
+	StartDate    *string  `json:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate      *string  `json:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	IsActive     *bool    `json:"is_active"`
+	DisplayOrder int      `json:"display_order" validate:"min=0"`
+	BranchIds    []string `json:"branch_ids" validate:"omitempty,unique,dive,ulid,exist=branches.id"`
+	PotencyIds   []string `json:"potency_ids" validate:"omitempty,unique,dive,ulid,exist=potencies.id"`
 
 	Path string `db:"path"`
 }
@@ -14,22 +90,52 @@ func (r *CreatePromotionRequest) RemoveImage() {
 	r.Image = ""
 }
 
+func (r *CreatePromotionRequest) SetDefault(now time.Time) {
+	if r.StartDate == nil {
+		today := now.Format(time.DateOnly)
+		r.StartDate = &today
+	}
+
+	if r.IsActive == nil {
+		isActive := true
+		r.IsActive = &isActive
+	}
+}
+
+func (r *CreatePromotionRequest) Validate() error {
+	return validateDates(r.StartDate, r.EndDate)
+}
+
 type DeletePromotionRequest struct {
 	Id string `params:"id" validate:"ulid"`
 
 	Path string `db:"path"`
 }
 
+/*
+Update Promotion - Start
+  - A null end_date makes the promotion open ended
+  - branch_ids and potency_ids replace the targets when they are given, an
+    empty list targets all of them
+*/
 type UpdatePromotionRequest struct {
 	Id string `params:"id" validate:"ulid"`
 
-	Title gonull.Nullable[string] `json:"title"`
-	Image gonull.Nullable[string] `json:"image"`
-	Link  gonull.Nullable[string] `json:"link"`
+	Title        gonull.Nullable[string] `json:"title"`
+	Image        gonull.Nullable[string] `json:"image"`
+	Link         gonull.Nullable[string] `json:"link"`
+	StartDate    gonull.Nullable[string] `json:"start_date"`
+	EndDate      gonull.Nullable[string] `json:"end_date"`
+	IsActive     *bool                   `json:"is_active"`
+	DisplayOrder *int                    `json:"display_order" validate:"omitempty,min=0"`
+	BranchIds    *[]string               `json:"branch_ids" validate:"omitempty,unique,dive,ulid,exist=branches.id"`
+	PotencyIds   *[]string               `json:"potency_ids" validate:"omitempty,unique,dive,ulid,exist=potencies.id"`
 
-	TitleVal string `validate:"omitempty,max=255" prop:"title"`
-	ImageVal string `validate:"omitempty,base64" prop:"image"`
-	LinkVal  string `validate:"omitempty,url" prop:"link"`
+	TitleVal     string `validate:"omitempty,max=255" prop:"title"`
+	ImageVal     string `validate:"omitempty,base64" prop:"image"`
+	LinkVal      string `validate:"omitempty,url" prop:"link"`
+	StartDateVal string `validate:"omitempty,datetime=2006-01-02" prop:"start_date"`
+	EndDateVal   string `validate:"omitempty,datetime=2006-01-02" prop:"end_date"`
 
 	Path string `db:"path"`
 }
@@ -42,13 +148,68 @@ func (r *UpdatePromotionRequest) SetValues() {
 	r.TitleVal = r.Title.Val
 	r.ImageVal = r.Image.Val
 	r.LinkVal = r.Link.Val
+	r.StartDateVal = r.StartDate.Val
+	r.EndDateVal = r.EndDate.Val
+}
+
+// Validate checks the dates given together, the database checks them against
+// the stored ones.
+func (r *UpdatePromotionRequest) Validate() error {
+	if r.StartDate.Present && !r.StartDate.Valid {
+		return errmsg.NewCustomErrors(400).Add("start_date", "start date tidak boleh kosong")
+	}
+
+	var startDate, endDate *string
+	if r.StartDate.Valid {
+		startDate = &r.StartDate.Val
+	}
+	if r.EndDate.Valid {
+		endDate = &r.EndDate.Val
+	}
+
+	return validateDates(startDate, endDate)
+}
+
+func validateDates(startDate, endDate *string) error {
+	// both are YYYY-MM-DD so they compare as strings
+	if startDate != nil && endDate != nil && *endDate < *startDate {
+		return errmsg.NewCustomErrors(400).Add("end_date", "end date tidak boleh sebelum start date")
+	}
+
+	return nil
+}
+
+/*
+Get Promotions - Start
+  - The admins get all the promotions, optionally by status
+  - The other users get the running promotions of their branch, the visitors
+    the ones shown in all the branches
+  - wac_id narrows them to the ones relevant to the branch and the potencies
+    of the WAC, for the advisors attaching one to an offering
+*/
+type GetPromotionsRequest struct {
+	UserId string
+	Role   string
+
+	Status string `query:"status" validate:"omitempty,oneof=running scheduled expired inactive"`
+	WacId  string `query:"wac_id" validate:"omitempty,ulid,exist=walk_around_checks.id"`
 }
 
 type Promotion struct {
-	Id    string  `json:"id" db:"id"`
-	Title string  `json:"title" db:"title"`
-	Image string  `json:"image"`
-	Link  *string `json:"link" db:"link"`
+	Id           string         `json:"id" db:"id"`
+	Title        string         `json:"title" db:"title"`
+	Image        string         `json:"image"`
+	Link         *string        `json:"link" db:"link"`
+	StartDate    string         `json:"start_date" db:"start_date"`
+	EndDate      *string        `json:"end_date" db:"end_date"`
+	IsActive     bool           `json:"is_active" db:"is_active"`
+	DisplayOrder int            `json:"display_order" db:"display_order"`
+	Status       string         `json:"status" db:"status"`
+	BranchIds    pq.StringArray `json:"branch_ids" db:"branch_ids"`
+	PotencyIds   pq.StringArray `json:"potency_ids" db:"potency_ids"`
+	// RedirectLink opens the link of the promotion counting the click of the
+	// user, see RedirectLinkExpiration
+	RedirectLink *string `json:"redirect_link"`
 
 	Path string `json:"-" db:"path"` // json:"-" to hide the field in response
 }
@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"codebase-app/pkg"
+	"codebase-app/pkg/period"
+	"time"
+)
+
+const (
+	EventImpression = "impression"
+	EventClick      = "click"
+
+	// RedirectLinkExp is how long the redirect link of a promotion without
+	// end date counts the clicks of the user, the link of a promotion with one
+	// counts them until the promotion ends.
+	RedirectLinkExp = 30 * 24 * time.Hour
+)
+
+/*
+Promotion Engagement - Start
+  - The apps report an impression when a promotion is shown and a click when
+    it is opened, a user counts once per promotion, event and day
+  - The redirect endpoint records the click before redirecting to the link of
+    the promotion. The browsers send no token, so the user is identified by
+    the signed redirect_link of the promotion listed to them, the visitors
+    and the expired links are redirected without being counted
+  - The report counts the impressions, clicks and CTR per promotion and
+    branch, the branch is the branch of the user at the time of the event
+*/
+type TrackPromotionRequest struct {
+	UserId string `validate:"ulid"`
+	Id     string `params:"id" validate:"ulid"`
+	Type   string `validate:"oneof=impression click"`
+}
+
+type RedirectPromotionRequest struct {
+	Id string `params:"id" validate:"ulid"`
+	// UserId is empty for the visitors, it is only trusted with a valid
+	// signature
+	UserId    string `query:"user_id" validate:"omitempty,ulid"`
+	Expires   int64  `query:"expires"`
+	Signature string `query:"signature"`
+}
+
+type GetEngagementRequest struct {
+	From        string `query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To          string `query:"to" validate:"omitempty,datetime=2006-01-02"`
+	BranchId    string `query:"branch_id" validate:"omitempty,ulid"`
+	PromotionId string `query:"promotion_id" validate:"omitempty,ulid"`
+
+	Period period.Period
+}
+
+// Resolve defaults to the last 30 days, the events are dated in
+// period.DefaultTimezone.
+func (r *GetEngagementRequest) Resolve() (err error) {
+	req := period.Request{From: r.From, To: r.To, Timezone: period.DefaultTimezone}
+
+	r.Period, err = req.Resolve(period.Default{Days: 29})
+	return err
+}
+
+type GetEngagementResponse struct {
+	From  string           `json:"from"`
+	To    string           `json:"to"`
+	Total EngagementCount  `json:"total"`
+	Items []EngagementItem `json:"items"`
+}
+
+type EngagementItem struct {
+	PromotionId    string  `json:"promotion_id" db:"promotion_id"`
+	PromotionTitle string  `json:"promotion_title" db:"promotion_title"`
+	BranchId       *string `json:"branch_id" db:"branch_id"`
+	BranchName     string  `json:"branch_name" db:"branch_name"`
+	EngagementCount
+}
+
+type EngagementCount struct {
+	Impressions int `json:"impressions" db:"impressions"`
+	Clicks      int `json:"clicks" db:"clicks"`
+	// CTR is the clicks as a percentage of the impressions, a click is
+	// counted even when its impression was not reported
+	CTR float64 `json:"ctr"`
+}
+
+// Complete computes the CTRs and the total of the items.
+func (r *GetEngagementResponse) Complete() {
+	r.Total = EngagementCount{}
+
+	for i := range r.Items {
+		r.Items[i].CTR = pkg.Percentage(r.Items[i].Clicks, r.Items[i].Impressions)
+		r.Total.Impressions += r.Items[i].Impressions
+		r.Total.Clicks += r.Items[i].Clicks
+	}
+
+	r.Total.CTR = pkg.Percentage(r.Total.Clicks, r.Total.Impressions)
+}
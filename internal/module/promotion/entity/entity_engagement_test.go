@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEngagementResponseComplete(t *testing.T) {
+	res := GetEngagementResponse{Items: []EngagementItem{
+		{EngagementCount: EngagementCount{Impressions: 3, Clicks: 1}},
+		{EngagementCount: EngagementCount{Impressions: 0, Clicks: 2}},
+		{EngagementCount: EngagementCount{Impressions: 5, Clicks: 0}},
+	}}
+	res.Complete()
+
+	assert.Equal(t, 33.33, res.Items[0].CTR)
+	assert.Equal(t, float64(0), res.Items[1].CTR)
+	assert.Equal(t, EngagementCount{Impressions: 8, Clicks: 3, CTR: 37.5}, res.Total)
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LukaGiorgadze/gonull"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreatePromotionRequest(t *testing.T) {
+	req := CreatePromotionRequest{}
+	req.SetDefault(time.Date(2024, 10, 24, 10, 0, 0, 0, time.UTC))
+	assert.Equal(t, "2024-10-24", *req.StartDate)
+	assert.True(t, *req.IsActive)
+	assert.NoError(t, req.Validate())
+
+	endDate := "2024-10-23"
+	req.EndDate = &endDate
+	assert.Error(t, req.Validate())
+}
+
+func TestUpdatePromotionRequestValidate(t *testing.T) {
+	req := UpdatePromotionRequest{EndDate: gonull.NewNullable("2024-10-23")}
+	// checked against the stored start date by the database
+	assert.NoError(t, req.Validate())
+
+	req.StartDate = gonull.NewNullable("2024-10-24")
+	assert.Error(t, req.Validate())
+
+	req.StartDate = gonull.Nullable[string]{Present: true}
+	assert.Error(t, req.Validate())
+}
+
+func TestPromotionRedirectLinkExpiration(t *testing.T) {
+	var (
+		wita    = time.FixedZone("WITA", 8*3600)
+		now     = time.Date(2024, 10, 24, 22, 0, 0, 0, wita)
+		endDate = "2024-10-25"
+	)
+
+	// the link counts the clicks until the end of the end date
+	assert.Equal(t, 26*time.Hour, Promotion{EndDate: &endDate}.RedirectLinkExpiration(now))
+	assert.Equal(t, RedirectLinkExp, Promotion{}.RedirectLinkExpiration(now))
+
+	ended := "2024-10-23"
+	assert.Negative(t, Promotion{EndDate: &ended}.RedirectLinkExpiration(now))
+}
@@ -11,6 +11,7 @@ import (
 	"codebase-app/internal/module/promotion/repository"
 	"codebase-app/internal/module/promotion/service"
 	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/period"
 	"codebase-app/pkg/response"
 
 	"github.com/gofiber/fiber/v2"
@@ -39,7 +40,20 @@ func (h *promotionHandler) Register(router fiber.Router) {
 		h.createPromotion,
 	)
 
-	router.Get("/promotions", h.GetPromotions)
+	router.Get("/promotions", middleware.AuthBearerOptional, h.GetPromotions)
+
+	router.Get("/promotions/engagement",
+		middleware.AuthBearer,
+		middleware.AuthRole([]string{"admin"}),
+		h.getEngagement,
+	)
+
+	router.Post("/promotions/:id/impressions", middleware.AuthBearer, h.trackPromotion(entity.EventImpression))
+	router.Post("/promotions/:id/clicks", middleware.AuthBearer, h.trackPromotion(entity.EventClick))
+
+	// opened by the browsers from the redirect_link of GET /promotions, the
+	// visitors are redirected without being counted
+	router.Get("/promotions/:id/redirect", h.redirectPromotion)
 
 	router.Delete("/promotions/:id",
 		middleware.AuthBearer,
@@ -66,6 +80,8 @@ func (h *promotionHandler) createPromotion(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
 	}
 
+	req.SetDefault(period.Now())
+
 	if err := v.Validate(req); err != nil {
 		req.RemoveImage()
 		log.Warn().Err(err).Any("payload", req).Msg("handler::createPromotion - invalid payload")
@@ -73,6 +89,13 @@ func (h *promotionHandler) createPromotion(c *fiber.Ctx) error {
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
+	if err := req.Validate(); err != nil {
+		req.RemoveImage()
+		log.Warn().Err(err).Any("payload", req).Msg("handler::createPromotion - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
 	if err := h.service.CreatePromotion(ctx, req); err != nil {
 		code, errs := errmsg.Errors[error](err)
 		return c.Status(code).JSON(response.Error(errs))
@@ -83,10 +106,38 @@ func (h *promotionHandler) createPromotion(c *fiber.Ctx) error {
 
 func (h *promotionHandler) GetPromotions(c *fiber.Ctx) error {
 	var (
+		req = new(entity.GetPromotionsRequest)
 		ctx = c.Context()
+		v   = adapter.Adapters.Validator
 	)
 
-	promotions, err := h.service.GetPromotions(ctx)
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetPromotions - invalid query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	// the visitors have no locals
+	if c.Locals("user_id") != nil {
+		l := middleware.GetLocals(c)
+		req.UserId = l.GetUserId()
+		req.Role = l.GetRole()
+	}
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::GetPromotions - invalid query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if req.Role != "admin" && req.Status != "" {
+		return c.Status(fiber.StatusForbidden).JSON(response.Error("Filter status hanya untuk admin"))
+	}
+
+	if req.WacId != "" && req.UserId == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(response.Error("Unauthorized"))
+	}
+
+	promotions, err := h.service.GetPromotions(ctx, req)
 	if err != nil {
 		code, errs := errmsg.Errors[error](err)
 		return c.Status(code).JSON(response.Error(errs))
@@ -141,6 +192,13 @@ func (h *promotionHandler) updatePromotion(c *fiber.Ctx) error {
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
+	if err := req.Validate(); err != nil {
+		req.RemoveImage()
+		log.Warn().Err(err).Any("payload", req).Msg("handler::updatePromotion - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
 	if err := h.service.UpdatePromotion(ctx, req); err != nil {
 		code, errs := errmsg.Errors[error](err)
 		return c.Status(code).JSON(response.Error(errs))
@@ -148,3 +206,93 @@ func (h *promotionHandler) updatePromotion(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(response.Success(nil, ""))
 }
+
+func (h *promotionHandler) trackPromotion(event string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var (
+			req = new(entity.TrackPromotionRequest)
+			ctx = c.Context()
+			v   = adapter.Adapters.Validator
+			l   = middleware.GetLocals(c)
+		)
+
+		req.Id = c.Params("id")
+		req.UserId = l.GetUserId()
+		req.Type = event
+
+		if err := v.Validate(req); err != nil {
+			log.Warn().Err(err).Any("payload", req).Msg("handler::trackPromotion - invalid payload")
+			code, errs := errmsg.Errors(err, req)
+			return c.Status(code).JSON(response.Error(errs))
+		}
+
+		if err := h.service.TrackPromotion(ctx, req); err != nil {
+			code, errs := errmsg.Errors[error](err)
+			return c.Status(code).JSON(response.Error(errs))
+		}
+
+		return c.Status(fiber.StatusOK).JSON(response.Success(nil, ""))
+	}
+}
+
+func (h *promotionHandler) redirectPromotion(c *fiber.Ctx) error {
+	var (
+		req = new(entity.RedirectPromotionRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::redirectPromotion - invalid query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.Id = c.Params("id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::redirectPromotion - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	link, err := h.service.RedirectPromotion(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Redirect(link, fiber.StatusFound)
+}
+
+func (h *promotionHandler) getEngagement(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetEngagementRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::getEngagement - invalid query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::getEngagement - invalid query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := req.Resolve(); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::getEngagement - invalid period")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetEngagement(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(res, ""))
+}
@@ -7,15 +7,23 @@ import (
 
 type PromotionRepository interface {
 	CreatePromotion(ctx context.Context, req *entity.CreatePromotionRequest) error
-	GetPromotions(ctx context.Context) ([]entity.Promotion, error)
+	GetPromotions(ctx context.Context, req *entity.GetPromotionsRequest) ([]entity.Promotion, error)
 	DeletePromotion(ctx context.Context, req *entity.DeletePromotionRequest) error
 	UpdatePromotion(ctx context.Context, req *entity.UpdatePromotionRequest) error
 	GetPromotionById(ctx context.Context, id string) (entity.Promotion, error)
+
+	TrackPromotion(ctx context.Context, req *entity.TrackPromotionRequest) error
+	GetPromotionLink(ctx context.Context, id string) (string, error)
+	GetEngagement(ctx context.Context, req *entity.GetEngagementRequest) (entity.GetEngagementResponse, error)
 }
 
 type PromotionService interface {
 	CreatePromotion(ctx context.Context, req *entity.CreatePromotionRequest) error
-	GetPromotions(ctx context.Context) ([]entity.Promotion, error)
+	GetPromotions(ctx context.Context, req *entity.GetPromotionsRequest) ([]entity.Promotion, error)
 	DeletePromotion(ctx context.Context, req *entity.DeletePromotionRequest) error
 	UpdatePromotion(ctx context.Context, req *entity.UpdatePromotionRequest) error
+
+	TrackPromotion(ctx context.Context, req *entity.TrackPromotionRequest) error
+	RedirectPromotion(ctx context.Context, req *entity.RedirectPromotionRequest) (string, error)
+	GetEngagement(ctx context.Context, req *entity.GetEngagementRequest) (entity.GetEngagementResponse, error)
 }
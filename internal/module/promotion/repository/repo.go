@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/oklog/ulid/v2"
 	"github.com/rs/zerolog/log"
 )
@@ -27,14 +28,34 @@ func NewPromotionRepository() *promotionRepository {
 	}
 }
 
-func (r *promotionRepository) CreatePromotion(ctx context.Context, req *entity.CreatePromotionRequest) error {
+func (r *promotionRepository) CreatePromotion(ctx context.Context, req *entity.CreatePromotionRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		req.RemoveImage()
+		log.Error().Err(err).Any("payload", req).Msg("repo::CreatePromotion - failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Msg("repo::CreatePromotion - failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Msg("repo::CreatePromotion - failed to commit transaction")
+		}
+	}()
+
+	id := ulid.Make().String()
 	query := `
-		INSERT INTO promotions (id, title, path, link)
-		VALUES (?, ?, ?, ?)
+		INSERT INTO promotions (id, title, path, link, start_date, end_date, is_active, display_order)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.ExecContext(ctx, r.db.Rebind(query),
-		ulid.Make().String(), req.Title, req.Path, req.Link,
+	_, err = tx.ExecContext(ctx, r.db.Rebind(query),
+		id, req.Title, req.Path, req.Link, req.StartDate, req.EndDate, req.IsActive, req.DisplayOrder,
 	)
 	if err != nil {
 		req.RemoveImage()
@@ -42,34 +63,122 @@ func (r *promotionRepository) CreatePromotion(ctx context.Context, req *entity.C
 		return err
 	}
 
+	err = r.setTargets(ctx, tx, id, &req.BranchIds, &req.PotencyIds)
+	return err
+}
+
+// setTargets replaces the target branches and potencies of the promotion, a
+// nil list is left as it is.
+func (r *promotionRepository) setTargets(ctx context.Context, tx *sqlx.Tx, id string, branchIds, potencyIds *[]string) error {
+	targets := []struct {
+		table  string
+		column string
+		ids    *[]string
+	}{
+		{"promotion_branches", "branch_id", branchIds},
+		{"promotion_potencies", "potency_id", potencyIds},
+	}
+
+	for _, t := range targets {
+		if t.ids == nil {
+			continue
+		}
+
+		query := `DELETE FROM ` + t.table + ` WHERE promotion_id = ?`
+		if _, err := tx.ExecContext(ctx, r.db.Rebind(query), id); err != nil {
+			log.Error().Err(err).Str("id", id).Msg("repo::setTargets - failed to clear " + t.table)
+			return err
+		}
+
+		if len(*t.ids) == 0 {
+			continue
+		}
+
+		query = `
+			INSERT INTO ` + t.table + ` (promotion_id, ` + t.column + `)
+			SELECT ?, UNNEST(CAST(? AS TEXT[]))
+		`
+		if _, err := tx.ExecContext(ctx, r.db.Rebind(query), id, pq.Array(*t.ids)); err != nil {
+			log.Error().Err(err).Str("id", id).Msg("repo::setTargets - failed to insert " + t.table)
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (r *promotionRepository) GetPromotions(ctx context.Context) ([]entity.Promotion, error) {
-	data := make([]entity.Promotion, 0)
+// promotionColumns selects a promotion p with its status and targets.
+const promotionColumns = `
+	p.id,
+	p.title,
+	p.path,
+	p.link,
+	TO_CHAR(p.start_date, 'YYYY-MM-DD') AS start_date,
+	TO_CHAR(p.end_date, 'YYYY-MM-DD') AS end_date,
+	p.is_active,
+	p.display_order,
+	` + entity.Status + ` AS status,
+	ARRAY(SELECT pb.branch_id FROM promotion_branches pb WHERE pb.promotion_id = p.id ORDER BY pb.branch_id) AS branch_ids,
+	ARRAY(SELECT pp.potency_id FROM promotion_potencies pp WHERE pp.promotion_id = p.id ORDER BY pp.potency_id) AS potency_ids
+`
+
+func (r *promotionRepository) GetPromotions(ctx context.Context, req *entity.GetPromotionsRequest) ([]entity.Promotion, error) {
+	var (
+		data = make([]entity.Promotion, 0)
+		args = make([]any, 0)
+	)
 
 	query := `
-		SELECT id, title, path, link
-		FROM promotions
+		SELECT ` + promotionColumns + `
+		FROM promotions p
+		WHERE p.deleted_at IS NULL
 	`
 
-	err := r.db.SelectContext(ctx, &data, query)
+	switch {
+	case req.WacId != "":
+		// the branch of the WAC and the potencies of its conditions
+		query += ` AND ` + entity.Running + `
+			AND ` + entity.TargetsBranch(`(SELECT branch_id FROM walk_around_checks WHERE id = ?)`) + `
+			AND ` + entity.TargetsPotency(`SELECT potency_id FROM walk_around_check_conditions WHERE walk_around_check_id = ? AND deleted_at IS NULL`)
+		args = append(args, req.WacId, req.WacId)
+	case req.Role == "admin":
+		if req.Status != "" {
+			query += ` AND ` + entity.Status + ` = ?`
+			args = append(args, req.Status)
+		}
+	default:
+		// a visitor has no branch, only the promotions of all the branches match
+		query += ` AND ` + entity.Running + `
+			AND ` + entity.TargetsBranch(`(SELECT branch_id FROM users WHERE id = ?)`)
+		args = append(args, req.UserId)
+	}
+
+	query += ` ORDER BY p.display_order, p.start_date DESC, p.created_at DESC`
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...)
 	if err != nil {
-		log.Error().Err(err).Msg("repo::GetPromotions - failed to get promotions")
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetPromotions - failed to get promotions")
 		return nil, err
 	}
 
 	for i := range data {
-		data[i].Image = config.Envs.App.BaseURL + "/" + strings.ReplaceAll(data[i].Path, "storage/", "api/storage/")
+		data[i].Image = imageURL(data[i].Path)
 	}
 
 	return data, nil
 }
 
+func imageURL(path string) string {
+	return config.Envs.App.BaseURL + "/" + strings.ReplaceAll(path, "storage/", "api/storage/")
+}
+
+// DeletePromotion moves the promotion to the trash, the image is deleted when
+// the trash is purged.
 func (r *promotionRepository) DeletePromotion(ctx context.Context, req *entity.DeletePromotionRequest) error {
 	query := `
-		DELETE FROM promotions
-		WHERE id = ?
+		UPDATE promotions
+		SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = ? AND deleted_at IS NULL
 		RETURNING path
 	`
 
@@ -83,83 +192,101 @@ func (r *promotionRepository) DeletePromotion(ctx context.Context, req *entity.D
 		return err
 	}
 
-	req.Path = strings.ReplaceAll(req.Path, "storage/", "./storage/")
-
 	return nil
 }
 
-func (r *promotionRepository) UpdatePromotion(ctx context.Context, req *entity.UpdatePromotionRequest) error {
-	var (
-		args       = make([]any, 0)
-		argsCount  = 0
-		argsFilled = 0
-	)
-
-	if req.Title.Present && req.Title.Valid {
-		argsCount++
-	}
-	if req.Link.Present && req.Link.Valid {
-		argsCount++
-	}
-	if req.Image.Present && req.Image.Valid {
-		argsCount++
+func (r *promotionRepository) UpdatePromotion(ctx context.Context, req *entity.UpdatePromotionRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		req.RemoveImage()
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdatePromotion - failed to begin transaction")
+		return err
 	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Msg("repo::UpdatePromotion - failed to rollback transaction")
+			}
+			return
+		}
 
-	query := `
-		UPDATE promotions
-		SET
-		`
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Msg("repo::UpdatePromotion - failed to commit transaction")
+		}
+	}()
+
+	var (
+		sets = []string{"updated_at = NOW()"}
+		args = make([]any, 0)
+	)
 
 	if req.Title.Present && req.Title.Valid {
-		query += " title = ?"
+		sets = append(sets, "title = ?")
 		args = append(args, req.Title.Val)
-		argsFilled++
-		if argsFilled < argsCount {
-			query += ","
-		}
 	}
-
 	if req.Link.Valid {
-		query += " link = ?"
+		sets = append(sets, "link = ?")
 		args = append(args, req.Link.Val)
-		argsFilled++
-		if argsFilled < argsCount {
-			query += ","
-		}
 	}
-
 	if req.Image.Present && req.Image.Valid {
-		query += " path = ?"
+		sets = append(sets, "path = ?")
 		args = append(args, req.Path)
-		argsFilled++
-		if argsFilled < argsCount {
-			query += ","
-		}
+	}
+	if req.StartDate.Valid {
+		sets = append(sets, "start_date = ?")
+		args = append(args, req.StartDate.Val)
+	}
+	if req.EndDate.Present {
+		// null makes the promotion open ended
+		sets = append(sets, "end_date = ?")
+		args = append(args, req.EndDate)
+	}
+	if req.IsActive != nil {
+		sets = append(sets, "is_active = ?")
+		args = append(args, *req.IsActive)
+	}
+	if req.DisplayOrder != nil {
+		sets = append(sets, "display_order = ?")
+		args = append(args, *req.DisplayOrder)
 	}
 
-	query += `
-		WHERE
-		id = ?
+	query := `
+		UPDATE promotions
+		SET ` + strings.Join(sets, ", ") + `
+		WHERE id = ? AND deleted_at IS NULL
 	`
 	args = append(args, req.Id)
 
-	_, err := r.db.ExecContext(ctx, r.db.Rebind(query), args...)
+	res, err := tx.ExecContext(ctx, r.db.Rebind(query), args...)
 	if err != nil {
 		req.RemoveImage()
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23514" {
+			log.Warn().Err(err).Any("payload", req).Msg("repo::UpdatePromotion - end date before start date")
+			err = errmsg.NewCustomErrors(400).Add("end_date", "end date tidak boleh sebelum start date")
+			return err
+		}
 		log.Error().Any("payload", req).Err(err).Msg("repo::UpdatePromotion - failed to update promotion")
 		return err
 	}
 
-	return nil
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		req.RemoveImage()
+		log.Warn().Any("payload", req).Msg("repo::UpdatePromotion - promotion not found")
+		err = errmsg.NewCustomErrors(404).SetMessage("Promosi tidak ditemukan")
+		return err
+	}
+
+	err = r.setTargets(ctx, tx, req.Id, req.BranchIds, req.PotencyIds)
+	return err
 }
 
 func (r *promotionRepository) GetPromotionById(ctx context.Context, id string) (entity.Promotion, error) {
 	data := entity.Promotion{}
 
 	query := `
-		SELECT id, title, path, link
-		FROM promotions
-		WHERE id = ?
+		SELECT ` + promotionColumns + `
+		FROM promotions p
+		WHERE p.id = ? AND p.deleted_at IS NULL
 	`
 
 	err := r.db.GetContext(ctx, &data, r.db.Rebind(query), id)
@@ -172,6 +299,6 @@ func (r *promotionRepository) GetPromotionById(ctx context.Context, id string) (
 		return data, err
 	}
 
-	data.Image = config.Envs.App.BaseURL + "/" + strings.ReplaceAll(data.Path, "storage/", "api/storage/")
+	data.Image = imageURL(data.Path)
 	return data, nil
 }
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"codebase-app/internal/module/promotion/entity"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/period"
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// TrackPromotion records the event of the user, an event already recorded
+// today is ignored.
+func (r *promotionRepository) TrackPromotion(ctx context.Context, req *entity.TrackPromotionRequest) error {
+	var found bool
+
+	query := `
+		WITH p AS (
+			SELECT id FROM promotions WHERE id = ? AND deleted_at IS NULL
+		), ins AS (
+			INSERT INTO promotion_events (promotion_id, user_id, type, day, branch_id)
+			SELECT p.id, u.id, ?, ` + period.Today + `, u.branch_id
+			FROM p
+			JOIN users u ON u.id = ?
+			ON CONFLICT DO NOTHING
+		)
+		SELECT EXISTS (SELECT 1 FROM p)
+	`
+
+	err := r.db.GetContext(ctx, &found, r.db.Rebind(query), req.Id, req.Type, req.UserId)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::TrackPromotion - failed to record event")
+		return err
+	}
+
+	if !found {
+		log.Warn().Any("payload", req).Msg("repo::TrackPromotion - promotion not found")
+		return errmsg.NewCustomErrors(404).SetMessage("Promosi tidak ditemukan")
+	}
+
+	return nil
+}
+
+func (r *promotionRepository) GetPromotionLink(ctx context.Context, id string) (string, error) {
+	var link *string
+
+	query := `SELECT link FROM promotions WHERE id = ? AND deleted_at IS NULL`
+
+	err := r.db.GetContext(ctx, &link, r.db.Rebind(query), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn().Str("id", id).Msg("repo::GetPromotionLink - promotion not found")
+			return "", errmsg.NewCustomErrors(404).SetMessage("Promosi tidak ditemukan")
+		}
+		log.Error().Err(err).Str("id", id).Msg("repo::GetPromotionLink - failed to get promotion")
+		return "", err
+	}
+
+	if link == nil || *link == "" {
+		log.Warn().Str("id", id).Msg("repo::GetPromotionLink - promotion has no link")
+		return "", errmsg.NewCustomErrors(404).SetMessage("Promosi tidak memiliki link")
+	}
+
+	return *link, nil
+}
+
+func (r *promotionRepository) GetEngagement(ctx context.Context, req *entity.GetEngagementRequest) (entity.GetEngagementResponse, error) {
+	var (
+		res = entity.GetEngagementResponse{
+			From:  req.Period.FromDate(),
+			To:    req.Period.To.Format(period.DateFormat),
+			Items: make([]entity.EngagementItem, 0),
+		}
+		filters = []string{"e.day >= ?", "e.day < ?"}
+		args    = []any{req.Period.FromDate(), req.Period.EndDate()}
+	)
+
+	if req.BranchId != "" {
+		filters = append(filters, "e.branch_id = ?")
+		args = append(args, req.BranchId)
+	}
+	if req.PromotionId != "" {
+		filters = append(filters, "e.promotion_id = ?")
+		args = append(args, req.PromotionId)
+	}
+
+	// the promotions in the trash are still reported
+	query := `
+		SELECT
+			e.promotion_id,
+			p.title AS promotion_title,
+			e.branch_id,
+			COALESCE(b.name, '') AS branch_name,
+			COUNT(*) FILTER (WHERE e.type = '` + entity.EventImpression + `') AS impressions,
+			COUNT(*) FILTER (WHERE e.type = '` + entity.EventClick + `') AS clicks
+		FROM promotion_events e
+		JOIN
+			promotions p ON p.id = e.promotion_id
+		LEFT JOIN
+			branches b ON b.id = e.branch_id
+		WHERE ` + strings.Join(filters, " AND ") + `
+		GROUP BY e.promotion_id, p.title, p.display_order, e.branch_id, b.name
+		ORDER BY p.display_order, p.title, b.name NULLS LAST
+	`
+
+	if err := r.db.SelectContext(ctx, &res.Items, r.db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetEngagement - failed to get engagement")
+		return res, err
+	}
+
+	res.Complete()
+	return res, nil
+}
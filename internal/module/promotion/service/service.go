@@ -1,7 +1,11 @@
 package service
 
 import (
+	"codebase-app/internal/infrastructure/config"
 	integstorage "codebase-app/internal/integration/localstorage"
+	"codebase-app/pkg/period"
+	"codebase-app/pkg/security"
+	"net/url"
 	"strings"
 
 	"codebase-app/internal/module/promotion/entity"
@@ -43,22 +47,47 @@ func (s *promotionService) CreatePromotion(ctx context.Context, req *entity.Crea
 	return nil
 }
 
-func (s *promotionService) GetPromotions(ctx context.Context) ([]entity.Promotion, error) {
-	return s.repo.GetPromotions(ctx)
-}
-
-func (s *promotionService) DeletePromotion(ctx context.Context, req *entity.DeletePromotionRequest) error {
-	err := s.repo.DeletePromotion(ctx, req)
+func (s *promotionService) GetPromotions(ctx context.Context, req *entity.GetPromotionsRequest) ([]entity.Promotion, error) {
+	promotions, err := s.repo.GetPromotions(ctx, req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = s.storage.Delete(req.Path)
-	if err != nil {
-		return err
+	now := period.Now()
+	for i := range promotions {
+		if promotions[i].Link == nil {
+			continue
+		}
+
+		link := redirectURL(promotions[i].Id, req.UserId)
+		if req.UserId != "" {
+			signed, err := security.GenerateSignedURL(link, promotions[i].RedirectLinkExpiration(now))
+			if err != nil {
+				log.Error().Err(err).Any("payload", req).Msg("service::GetPromotions - failed to sign redirect link")
+				return nil, err
+			}
+			link = signed.Link
+		}
+
+		promotions[i].RedirectLink = &link
 	}
 
-	return nil
+	return promotions, nil
+}
+
+// redirectURL is the redirect link of the promotion before it is signed, the
+// one of the visitors has no user.
+func redirectURL(id, userId string) string {
+	link := config.Envs.App.BaseURL + "/api/promotions/" + id + "/redirect"
+	if userId != "" {
+		link += "?user_id=" + url.QueryEscape(userId)
+	}
+
+	return link
+}
+
+func (s *promotionService) DeletePromotion(ctx context.Context, req *entity.DeletePromotionRequest) error {
+	return s.repo.DeletePromotion(ctx, req)
 }
 
 func (s *promotionService) UpdatePromotion(ctx context.Context, req *entity.UpdatePromotionRequest) error {
@@ -90,3 +119,38 @@ func (s *promotionService) UpdatePromotion(ctx context.Context, req *entity.Upda
 
 	return nil
 }
+
+func (s *promotionService) TrackPromotion(ctx context.Context, req *entity.TrackPromotionRequest) error {
+	return s.repo.TrackPromotion(ctx, req)
+}
+
+// RedirectPromotion returns the link of the promotion, the click of a user is
+// recorded first when the redirect link is still valid.
+func (s *promotionService) RedirectPromotion(ctx context.Context, req *entity.RedirectPromotionRequest) (string, error) {
+	link, err := s.repo.GetPromotionLink(ctx, req.Id)
+	if err != nil {
+		return "", err
+	}
+
+	if req.UserId != "" && !security.VerifySignedURL(redirectURL(req.Id, req.UserId), req.Expires, req.Signature) {
+		log.Warn().Any("payload", req).Msg("service::RedirectPromotion - invalid or expired redirect link, click not counted")
+		req.UserId = ""
+	}
+
+	if req.UserId != "" {
+		err = s.repo.TrackPromotion(ctx, &entity.TrackPromotionRequest{
+			UserId: req.UserId,
+			Id:     req.Id,
+			Type:   entity.EventClick,
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return link, nil
+}
+
+func (s *promotionService) GetEngagement(ctx context.Context, req *entity.GetEngagementRequest) (entity.GetEngagementResponse, error) {
+	return s.repo.GetEngagement(ctx, req)
+}
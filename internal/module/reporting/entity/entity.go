@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// WatermarkWACFacts is the watermark of the WAC fact tables refresh.
+	WatermarkWACFacts = "wac_facts"
+
+	DateFormat = "2006-01-02"
+)
+
+// Days returns every day between from and to (inclusive) in DateFormat.
+func Days(from, to time.Time) []string {
+	days := make([]string, 0)
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		days = append(days, d.Format(DateFormat))
+	}
+
+	return days
+}
+
+// DayTotals are the figures compared by the consistency check, on the raw
+// tables and on the fact tables.
+type DayTotals struct {
+	Day              string          `json:"day" db:"day"`
+	TotalWACs        int             `json:"total_wacs" db:"total_wacs"`
+	TotalFollowedUp  int             `json:"total_followed_up" db:"total_followed_up"`
+	WACRevenue       decimal.Decimal `json:"wac_revenue" db:"wac_revenue"`
+	TotalConditions  int             `json:"total_conditions" db:"total_conditions"`
+	TotalInterested  int             `json:"total_interested" db:"total_interested"`
+	ConditionRevenue decimal.Decimal `json:"condition_revenue" db:"condition_revenue"`
+}
+
+type Mismatch struct {
+	Day   string `json:"day"`
+	Field string `json:"field"`
+	Raw   string `json:"raw"`
+	Fact  string `json:"fact"`
+}
+
+// CompareTotals lists every figure that differs between raw and fact, a day
+// missing on one side counts as zero.
+func CompareTotals(raw, fact []DayTotals) []Mismatch {
+	var (
+		res     = make([]Mismatch, 0)
+		days    = make([]string, 0, len(raw))
+		rawMap  = make(map[string]DayTotals, len(raw))
+		factMap = make(map[string]DayTotals, len(fact))
+	)
+
+	for _, t := range raw {
+		rawMap[t.Day] = t
+		days = append(days, t.Day)
+	}
+
+	for _, t := range fact {
+		factMap[t.Day] = t
+		if _, ok := rawMap[t.Day]; !ok {
+			days = append(days, t.Day)
+		}
+	}
+
+	for _, day := range days {
+		r, f := rawMap[day], factMap[day]
+
+		add := func(field string, raw, fact string) {
+			if raw != fact {
+				res = append(res, Mismatch{Day: day, Field: field, Raw: raw, Fact: fact})
+			}
+		}
+
+		add("total_wacs", strconv.Itoa(r.TotalWACs), strconv.Itoa(f.TotalWACs))
+		add("total_followed_up", strconv.Itoa(r.TotalFollowedUp), strconv.Itoa(f.TotalFollowedUp))
+		add("wac_revenue", r.WACRevenue.String(), f.WACRevenue.String())
+		add("total_conditions", strconv.Itoa(r.TotalConditions), strconv.Itoa(f.TotalConditions))
+		add("total_interested", strconv.Itoa(r.TotalInterested), strconv.Itoa(f.TotalInterested))
+		add("condition_revenue", r.ConditionRevenue.String(), f.ConditionRevenue.String())
+	}
+
+	return res
+}
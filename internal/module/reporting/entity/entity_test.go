@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareTotals(t *testing.T) {
+	raw := []DayTotals{
+		{Day: "2024-10-01", TotalWACs: 2, WACRevenue: decimal.NewFromInt(100), TotalConditions: 5, TotalInterested: 3},
+		{Day: "2024-10-02", TotalWACs: 1, TotalConditions: 2},
+	}
+	fact := []DayTotals{
+		{Day: "2024-10-01", TotalWACs: 2, WACRevenue: decimal.RequireFromString("100.0000"), TotalConditions: 5, TotalInterested: 3},
+		{Day: "2024-10-03", TotalWACs: 1},
+	}
+
+	res := CompareTotals(raw, fact)
+
+	assert.Equal(t, []Mismatch{
+		{Day: "2024-10-02", Field: "total_wacs", Raw: "1", Fact: "0"},
+		{Day: "2024-10-02", Field: "total_conditions", Raw: "2", Fact: "0"},
+		{Day: "2024-10-03", Field: "total_wacs", Raw: "0", Fact: "1"},
+	}, res)
+}
+
+func TestDays(t *testing.T) {
+	from := time.Date(2024, time.February, 28, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, []string{"2024-02-28", "2024-02-29", "2024-03-01"}, Days(from, to))
+}
@@ -0,0 +1,28 @@
+package ports
+
+import (
+	"codebase-app/internal/module/reporting/entity"
+	"context"
+	"time"
+)
+
+type ReportingRepository interface {
+	// RefreshDays rebuilds the fact rows of the given days from the raw tables.
+	RefreshDays(ctx context.Context, days []string) error
+	// GetDirtyDays returns the days holding WACs written since the given time.
+	GetDirtyDays(ctx context.Context, since time.Time) ([]string, error)
+	GetWatermark(ctx context.Context, name string) (time.Time, error)
+	SetWatermark(ctx context.Context, name string, at time.Time) error
+
+	GetRawTotals(ctx context.Context, from, to string) ([]entity.DayTotals, error)
+	GetFactTotals(ctx context.Context, from, to string) ([]entity.DayTotals, error)
+}
+
+type ReportingService interface {
+	// Backfill rebuilds the facts of every day between from and to.
+	Backfill(ctx context.Context, from, to time.Time) error
+	// Refresh rebuilds the facts of the days written since the last refresh.
+	Refresh(ctx context.Context) error
+	// Check compares the facts with the raw tables between from and to.
+	Check(ctx context.Context, from, to time.Time) ([]entity.Mismatch, error)
+}
@@ -0,0 +1,343 @@
+package repository
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/reporting/entity"
+	"codebase-app/internal/module/reporting/ports"
+	"codebase-app/pkg/period"
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.ReportingRepository = &reportingRepository{}
+
+type reportingRepository struct {
+	db *sqlx.DB
+}
+
+func NewReportingRepository() *reportingRepository {
+	return &reportingRepository{
+		db: adapter.Adapters.DigihubPostgres,
+	}
+}
+
+// the created_at bounds let postgres use the index before the per-day filter
+const dayFilter = `
+	wac.deleted_at IS NULL
+	AND wac.created_at >= (?::date::timestamp AT TIME ZONE ?)
+	AND wac.created_at < ((?::date + 1)::timestamp AT TIME ZONE ?)
+	AND (wac.created_at AT TIME ZONE ?)::date = ANY(?::date[])
+`
+
+func (r *reportingRepository) RefreshDays(ctx context.Context, days []string) error {
+	if len(days) == 0 {
+		return nil
+	}
+
+	sorted := append([]string(nil), days...)
+	sort.Strings(sorted)
+
+	var (
+		tz   = period.DefaultTimezone
+		from = sorted[0]
+		to   = sorted[len(sorted)-1]
+		args = []any{from, tz, to, tz, tz, pq.Array(sorted)}
+	)
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::RefreshDays - failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Msg("repo::RefreshDays - failed to rollback transaction")
+			}
+		}
+	}()
+
+	for _, table := range []string{"fact_wac_daily", "fact_wac_condition_daily"} {
+		query := `DELETE FROM ` + table + ` WHERE day = ANY(?::date[])`
+		if _, err = tx.ExecContext(ctx, r.db.Rebind(query), pq.Array(sorted)); err != nil {
+			log.Error().Err(err).Str("table", table).Strs("days", sorted).Msg("repo::RefreshDays - failed to clear facts")
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO fact_wac_daily (
+			day, branch_id, user_id, status, is_used_car, is_needs_follow_up,
+			total_wacs, total_followed_up, revenue
+		)
+		SELECT
+			(wac.created_at AT TIME ZONE ?)::date AS day,
+			wac.branch_id,
+			wac.user_id,
+			wac.status,
+			wac.is_used_car,
+			wac.is_needs_follow_up,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE wac.is_followed_up = TRUE),
+			COALESCE(SUM(wac.revenue), 0)
+		FROM
+			walk_around_checks wac
+		WHERE
+			` + dayFilter + `
+		GROUP BY 1, 2, 3, 4, 5, 6
+	`
+
+	if _, err = tx.ExecContext(ctx, r.db.Rebind(query), append([]any{tz}, args...)...); err != nil {
+		log.Error().Err(err).Strs("days", sorted).Msg("repo::RefreshDays - failed to refresh wac facts")
+		return err
+	}
+
+	query = `
+		INSERT INTO fact_wac_condition_daily (
+			day, branch_id, user_id, potency_id, area_id, status, is_used_car, is_needs_follow_up,
+			total_conditions, total_interested, revenue
+		)
+		SELECT
+			(wac.created_at AT TIME ZONE ?)::date AS day,
+			wac.branch_id,
+			wac.user_id,
+			wacc.potency_id,
+			wacc.area_id,
+			wac.status,
+			wac.is_used_car,
+			wac.is_needs_follow_up,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE wacc.is_interested = TRUE),
+			COALESCE(SUM(wacc.revenue), 0)
+		FROM
+			walk_around_check_conditions wacc
+		JOIN
+			walk_around_checks wac
+			ON wac.id = wacc.walk_around_check_id
+		WHERE
+			wacc.deleted_at IS NULL
+			AND ` + dayFilter + `
+		GROUP BY 1, 2, 3, 4, 5, 6, 7, 8
+	`
+
+	if _, err = tx.ExecContext(ctx, r.db.Rebind(query), append([]any{tz}, args...)...); err != nil {
+		log.Error().Err(err).Strs("days", sorted).Msg("repo::RefreshDays - failed to refresh condition facts")
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("repo::RefreshDays - failed to commit transaction")
+		return err
+	}
+
+	return nil
+}
+
+func (r *reportingRepository) GetDirtyDays(ctx context.Context, since time.Time) ([]string, error) {
+	var days = make([]string, 0)
+
+	query := `
+		SELECT
+			TO_CHAR(wac.created_at AT TIME ZONE ?, 'YYYY-MM-DD') AS day
+		FROM
+			walk_around_checks wac
+		WHERE
+			wac.updated_at >= ?
+			OR wac.created_at >= ?
+			OR wac.deleted_at >= ?
+		UNION
+		SELECT
+			TO_CHAR(wac.created_at AT TIME ZONE ?, 'YYYY-MM-DD') AS day
+		FROM
+			walk_around_check_conditions wacc
+		JOIN
+			walk_around_checks wac
+			ON wac.id = wacc.walk_around_check_id
+		WHERE
+			wacc.updated_at >= ?
+			OR wacc.created_at >= ?
+			OR wacc.deleted_at >= ?
+	`
+
+	err := r.db.SelectContext(ctx, &days, r.db.Rebind(query),
+		period.DefaultTimezone, since, since, since,
+		period.DefaultTimezone, since, since, since,
+	)
+	if err != nil {
+		log.Error().Err(err).Time("since", since).Msg("repo::GetDirtyDays - failed to get dirty days")
+		return nil, err
+	}
+
+	return days, nil
+}
+
+func (r *reportingRepository) GetWatermark(ctx context.Context, name string) (time.Time, error) {
+	var at time.Time
+
+	query := `SELECT refreshed_at FROM reporting_watermarks WHERE name = ?`
+
+	err := r.db.GetContext(ctx, &at, r.db.Rebind(query), name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, nil
+		}
+		log.Error().Err(err).Str("name", name).Msg("repo::GetWatermark - failed to get watermark")
+		return at, err
+	}
+
+	return at, nil
+}
+
+func (r *reportingRepository) SetWatermark(ctx context.Context, name string, at time.Time) error {
+	query := `
+		INSERT INTO reporting_watermarks (name, refreshed_at)
+		VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET refreshed_at = EXCLUDED.refreshed_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), name, at); err != nil {
+		log.Error().Err(err).Str("name", name).Msg("repo::SetWatermark - failed to set watermark")
+		return err
+	}
+
+	return nil
+}
+
+func (r *reportingRepository) GetRawTotals(ctx context.Context, from, to string) ([]entity.DayTotals, error) {
+	var (
+		tz         = period.DefaultTimezone
+		wacs       = make([]entity.DayTotals, 0)
+		conditions = make([]entity.DayTotals, 0)
+	)
+
+	query := `
+		SELECT
+			TO_CHAR(wac.created_at AT TIME ZONE ?, 'YYYY-MM-DD') AS day,
+			COUNT(*) AS total_wacs,
+			COUNT(*) FILTER (WHERE wac.is_followed_up = TRUE) AS total_followed_up,
+			COALESCE(SUM(wac.revenue), 0) AS wac_revenue
+		FROM
+			walk_around_checks wac
+		WHERE
+			wac.deleted_at IS NULL
+			AND wac.created_at >= (?::date::timestamp AT TIME ZONE ?)
+			AND wac.created_at < ((?::date + 1)::timestamp AT TIME ZONE ?)
+		GROUP BY 1
+	`
+
+	err := r.db.SelectContext(ctx, &wacs, r.db.Rebind(query), tz, from, tz, to, tz)
+	if err != nil {
+		log.Error().Err(err).Str("from", from).Str("to", to).Msg("repo::GetRawTotals - failed to get wac totals")
+		return nil, err
+	}
+
+	query = `
+		SELECT
+			TO_CHAR(wac.created_at AT TIME ZONE ?, 'YYYY-MM-DD') AS day,
+			COUNT(*) AS total_conditions,
+			COUNT(*) FILTER (WHERE wacc.is_interested = TRUE) AS total_interested,
+			COALESCE(SUM(wacc.revenue), 0) AS condition_revenue
+		FROM
+			walk_around_check_conditions wacc
+		JOIN
+			walk_around_checks wac
+			ON wac.id = wacc.walk_around_check_id
+		WHERE
+			wacc.deleted_at IS NULL
+			AND wac.deleted_at IS NULL
+			AND wac.created_at >= (?::date::timestamp AT TIME ZONE ?)
+			AND wac.created_at < ((?::date + 1)::timestamp AT TIME ZONE ?)
+		GROUP BY 1
+	`
+
+	err = r.db.SelectContext(ctx, &conditions, r.db.Rebind(query), tz, from, tz, to, tz)
+	if err != nil {
+		log.Error().Err(err).Str("from", from).Str("to", to).Msg("repo::GetRawTotals - failed to get condition totals")
+		return nil, err
+	}
+
+	return mergeTotals(wacs, conditions), nil
+}
+
+func (r *reportingRepository) GetFactTotals(ctx context.Context, from, to string) ([]entity.DayTotals, error) {
+	var (
+		wacs       = make([]entity.DayTotals, 0)
+		conditions = make([]entity.DayTotals, 0)
+	)
+
+	query := `
+		SELECT
+			TO_CHAR(f.day, 'YYYY-MM-DD') AS day,
+			SUM(f.total_wacs) AS total_wacs,
+			SUM(f.total_followed_up) AS total_followed_up,
+			SUM(f.revenue) AS wac_revenue
+		FROM
+			fact_wac_daily f
+		WHERE
+			f.day BETWEEN ?::date AND ?::date
+		GROUP BY 1
+	`
+
+	err := r.db.SelectContext(ctx, &wacs, r.db.Rebind(query), from, to)
+	if err != nil {
+		log.Error().Err(err).Str("from", from).Str("to", to).Msg("repo::GetFactTotals - failed to get wac totals")
+		return nil, err
+	}
+
+	query = `
+		SELECT
+			TO_CHAR(f.day, 'YYYY-MM-DD') AS day,
+			SUM(f.total_conditions) AS total_conditions,
+			SUM(f.total_interested) AS total_interested,
+			SUM(f.revenue) AS condition_revenue
+		FROM
+			fact_wac_condition_daily f
+		WHERE
+			f.day BETWEEN ?::date AND ?::date
+		GROUP BY 1
+	`
+
+	err = r.db.SelectContext(ctx, &conditions, r.db.Rebind(query), from, to)
+	if err != nil {
+		log.Error().Err(err).Str("from", from).Str("to", to).Msg("repo::GetFactTotals - failed to get condition totals")
+		return nil, err
+	}
+
+	return mergeTotals(wacs, conditions), nil
+}
+
+// mergeTotals joins the WAC and condition figures of the same day.
+func mergeTotals(wacs, conditions []entity.DayTotals) []entity.DayTotals {
+	var (
+		res   = make([]entity.DayTotals, 0, len(wacs))
+		index = make(map[string]int, len(wacs))
+	)
+
+	for _, w := range wacs {
+		index[w.Day] = len(res)
+		res = append(res, w)
+	}
+
+	for _, c := range conditions {
+		i, ok := index[c.Day]
+		if !ok {
+			i = len(res)
+			index[c.Day] = i
+			res = append(res, entity.DayTotals{Day: c.Day})
+		}
+
+		res[i].TotalConditions = c.TotalConditions
+		res[i].TotalInterested = c.TotalInterested
+		res[i].ConditionRevenue = c.ConditionRevenue
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Day < res[j].Day })
+
+	return res
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"codebase-app/internal/module/reporting/entity"
+	"codebase-app/internal/module/reporting/ports"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.ReportingService = &reportingService{}
+
+const (
+	// backfillChunk is the number of days rebuilt per transaction.
+	backfillChunk = 31
+	// watermarkSkew covers writes committed slightly after the refresh
+	// started reading.
+	watermarkSkew = time.Minute
+)
+
+type reportingService struct {
+	repo ports.ReportingRepository
+}
+
+func NewReportingService(repo ports.ReportingRepository) *reportingService {
+	return &reportingService{
+		repo: repo,
+	}
+}
+
+func (s *reportingService) Backfill(ctx context.Context, from, to time.Time) error {
+	return s.refreshDays(ctx, entity.Days(from, to))
+}
+
+// refreshDays rebuilds the days in chunks to keep each transaction short.
+func (s *reportingService) refreshDays(ctx context.Context, days []string) error {
+	for len(days) > 0 {
+		n := min(backfillChunk, len(days))
+
+		if err := s.repo.RefreshDays(ctx, days[:n]); err != nil {
+			return err
+		}
+
+		log.Debug().Str("first", days[0]).Int("days", n).Msg("service::refreshDays - facts rebuilt")
+		days = days[n:]
+	}
+
+	return nil
+}
+
+func (s *reportingService) Refresh(ctx context.Context) error {
+	startedAt := time.Now()
+
+	since, err := s.repo.GetWatermark(ctx, entity.WatermarkWACFacts)
+	if err != nil {
+		return err
+	}
+
+	if !since.IsZero() {
+		since = since.Add(-watermarkSkew)
+	}
+
+	days, err := s.repo.GetDirtyDays(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	if err := s.refreshDays(ctx, days); err != nil {
+		return err
+	}
+
+	if len(days) > 0 {
+		log.Info().Int("days", len(days)).Msg("service::Refresh - facts refreshed")
+	}
+
+	return s.repo.SetWatermark(ctx, entity.WatermarkWACFacts, startedAt)
+}
+
+func (s *reportingService) Check(ctx context.Context, from, to time.Time) ([]entity.Mismatch, error) {
+	var (
+		fromStr = from.Format(entity.DateFormat)
+		toStr   = to.Format(entity.DateFormat)
+	)
+
+	raw, err := s.repo.GetRawTotals(ctx, fromStr, toStr)
+	if err != nil {
+		return nil, err
+	}
+
+	fact, err := s.repo.GetFactTotals(ctx, fromStr, toStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.CompareTotals(raw, fact), nil
+}
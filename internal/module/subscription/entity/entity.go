@@ -0,0 +1,255 @@
+package entity
+
+import (
+	"codebase-app/pkg/cron"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/period"
+	"codebase-app/pkg/tabular"
+	"codebase-app/pkg/types"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	RangePreviousDay   = "previous_day"
+	RangePreviousWeek  = "previous_week"
+	RangePreviousMonth = "previous_month"
+	RangeMonthToDate   = "month_to_date"
+
+	// a delivery is queued once its email job is published, the email
+	// consumer marks it sent or failed
+	DeliveryProcessing = "processing"
+	DeliveryQueued     = "queued"
+	DeliverySent       = "sent"
+	DeliveryFailed     = "failed"
+
+	// EmailSubjectReport is the subject of the email stream the report
+	// emails are published on.
+	EmailSubjectReport = "crowners.email.report"
+
+	// MaxAttachmentSize keeps the email job under the default 1MB NATS
+	// payload once the attachment is base64 encoded.
+	MaxAttachmentSize = 700 * 1024
+
+	// A report email that can not be sent is tried ReportEmailMaxAttempts
+	// times, ReportEmailRetryDelay apart, before the delivery fails.
+	ReportEmailMaxAttempts = 3
+	ReportEmailRetryDelay  = time.Minute
+)
+
+// SubscriptionParams are the report filters, the dates are resolved from
+// Range on every run.
+type SubscriptionParams struct {
+	Range    string `json:"range" validate:"omitempty,oneof=previous_day previous_week previous_month month_to_date"`
+	BranchId string `json:"branch_id,omitempty" validate:"omitempty,ulid,exist=branches.id"`
+	Search   string `json:"search,omitempty" validate:"omitempty,min=3"`
+	Status   string `json:"status,omitempty" validate:"omitempty,oneof=offered wip completed"`
+}
+
+type Subscription struct {
+	Id         string             `json:"id" db:"id"`
+	UserId     string             `json:"user_id" db:"user_id"`
+	UserRole   string             `json:"-" db:"role"`
+	Name       string             `json:"name" db:"name"`
+	Report     string             `json:"report" db:"report"`
+	Format     string             `json:"format" db:"format"`
+	Locale     string             `json:"locale" db:"locale"`
+	Params     SubscriptionParams `json:"params" db:"-"`
+	ParamsRaw  []byte             `json:"-" db:"params"`
+	Schedule   string             `json:"schedule" db:"schedule"`
+	Timezone   string             `json:"timezone" db:"timezone"`
+	Recipients pq.StringArray     `json:"recipients" db:"recipients"`
+	IsActive   bool               `json:"is_active" db:"is_active"`
+	NextRunAt  *time.Time         `json:"next_run_at" db:"next_run_at"`
+	LastRunAt  *time.Time         `json:"last_run_at" db:"last_run_at"`
+	CreatedAt  time.Time          `json:"created_at" db:"created_at"`
+
+	// ScheduledAt is the run a claimed subscription is processed for.
+	ScheduledAt time.Time `json:"-" db:"-"`
+}
+
+// NextRunAfter returns the next run of the schedule after t, nil when the
+// schedule never runs again.
+func NextRunAfter(schedule, timezone string, t time.Time) (*time.Time, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := cron.Parse(schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	next := s.Next(t.In(loc))
+	if next.IsZero() {
+		return nil, nil
+	}
+
+	return &next, nil
+}
+
+// ResolveRange returns the inclusive dates the report covers for a run at
+// t, weeks start on Monday.
+func ResolveRange(rng string, t time.Time) (from, to time.Time) {
+	today := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+
+	switch rng {
+	case RangePreviousDay:
+		from = today.AddDate(0, 0, -1)
+		to = from
+	case RangePreviousMonth:
+		from = time.Date(today.Year(), today.Month()-1, 1, 0, 0, 0, 0, t.Location())
+		to = from.AddDate(0, 1, -1)
+	case RangeMonthToDate:
+		from = time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, t.Location())
+		to = today
+	default:
+		monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		from = monday.AddDate(0, 0, -7)
+		to = monday.AddDate(0, 0, -1)
+	}
+
+	return from, to
+}
+
+type CreateSubscriptionRequest struct {
+	UserId    string
+	NextRunAt *time.Time
+
+	Name       string             `json:"name" validate:"required,max=255"`
+	Report     string             `json:"report" validate:"required,oneof=activities wacs clients mrs_backlog admin_summaries"`
+	Format     string             `json:"format" validate:"omitempty,oneof=xlsx csv ndjson"`
+	Locale     string             `json:"locale" validate:"omitempty,oneof=id en"`
+	Params     SubscriptionParams `json:"params"`
+	Schedule   string             `json:"schedule" validate:"required,max=100"`
+	Timezone   string             `json:"timezone" validate:"omitempty,timezone"`
+	Recipients []string           `json:"recipients" validate:"required,min=1,max=20,unique_in_slice,dive,email"`
+	IsActive   *bool              `json:"is_active"`
+}
+
+func (r *CreateSubscriptionRequest) SetDefault() {
+	if r.Format == "" {
+		r.Format = tabular.FormatXLSX
+	}
+
+	if r.Locale == "" {
+		r.Locale = tabular.LocaleID
+	}
+
+	if r.Timezone == "" {
+		r.Timezone = period.DefaultTimezone
+	}
+
+	if r.Params.Range == "" {
+		r.Params.Range = RangePreviousWeek
+	}
+
+	if r.IsActive == nil {
+		active := true
+		r.IsActive = &active
+	}
+}
+
+// Validate checks the schedule and computes the next run.
+func (r *CreateSubscriptionRequest) Validate() error {
+	next, err := NextRunAfter(r.Schedule, r.Timezone, time.Now())
+	if err != nil {
+		return errmsg.NewCustomErrors(400).Add("schedule", "schedule bukan ekspresi cron yang valid")
+	}
+
+	if next == nil {
+		return errmsg.NewCustomErrors(400).Add("schedule", "schedule tidak pernah berjalan")
+	}
+
+	r.NextRunAt = next
+	return nil
+}
+
+type UpdateSubscriptionRequest struct {
+	Id string `params:"id" validate:"ulid"`
+	CreateSubscriptionRequest
+}
+
+type DeleteSubscriptionRequest struct {
+	UserId string
+
+	Id string `params:"id" validate:"ulid"`
+}
+
+type GetSubscriptionsRequest struct {
+	UserId string
+
+	Page     int `query:"page" validate:"required"`
+	Paginate int `query:"paginate" validate:"required"`
+}
+
+func (r *GetSubscriptionsRequest) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type GetSubscriptionsResponse struct {
+	Items []Subscription `json:"items"`
+	Meta  types.Meta     `json:"meta"`
+}
+
+type Delivery struct {
+	Id             string          `json:"id" db:"id"`
+	SubscriptionId string          `json:"subscription_id" db:"subscription_id"`
+	Status         string          `json:"status" db:"status"`
+	ScheduledAt    time.Time       `json:"scheduled_at" db:"scheduled_at"`
+	Recipients     pq.StringArray  `json:"recipients" db:"recipients"`
+	Params         json.RawMessage `json:"params" db:"params"`
+	Filename       *string         `json:"filename" db:"filename"`
+	SizeBytes      *int            `json:"size_bytes" db:"size_bytes"`
+	Error          *string         `json:"error" db:"error"`
+	StartedAt      time.Time       `json:"started_at" db:"started_at"`
+	FinishedAt     *time.Time      `json:"finished_at" db:"finished_at"`
+}
+
+type GetDeliveriesRequest struct {
+	UserId string
+
+	SubscriptionId string `params:"id" validate:"ulid"`
+
+	Page     int `query:"page" validate:"required"`
+	Paginate int `query:"paginate" validate:"required"`
+}
+
+func (r *GetDeliveriesRequest) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type GetDeliveriesResponse struct {
+	Items []Delivery `json:"items"`
+	Meta  types.Meta `json:"meta"`
+}
+
+// ReportEmailPayload is the email job published for every delivery.
+type ReportEmailPayload struct {
+	DeliveryId string     `json:"delivery_id"`
+	To         []string   `json:"to"`
+	Subject    string     `json:"subject"`
+	Body       string     `json:"body"`
+	Attachment Attachment `json:"attachment"`
+}
+
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     []byte `json:"content"`
+}
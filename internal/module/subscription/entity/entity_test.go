@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"codebase-app/pkg/period"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveRange(t *testing.T) {
+	loc, _ := time.LoadLocation(period.DefaultTimezone)
+	// a wednesday
+	now := time.Date(2024, 10, 16, 7, 0, 0, 0, loc)
+
+	tests := []struct {
+		rng      string
+		from, to string
+	}{
+		{RangePreviousDay, "2024-10-15", "2024-10-15"},
+		{RangePreviousWeek, "2024-10-07", "2024-10-13"},
+		{RangePreviousMonth, "2024-09-01", "2024-09-30"},
+		{RangeMonthToDate, "2024-10-01", "2024-10-16"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rng, func(t *testing.T) {
+			from, to := ResolveRange(tt.rng, now)
+			assert.Equal(t, tt.from, from.Format(time.DateOnly))
+			assert.Equal(t, tt.to, to.Format(time.DateOnly))
+		})
+	}
+
+	// monday runs cover the week that just ended
+	from, to := ResolveRange(RangePreviousWeek, time.Date(2024, 10, 14, 7, 0, 0, 0, loc))
+	assert.Equal(t, "2024-10-07", from.Format(time.DateOnly))
+	assert.Equal(t, "2024-10-13", to.Format(time.DateOnly))
+}
+
+func TestNextRunAfter(t *testing.T) {
+	now := time.Date(2024, 10, 16, 0, 0, 0, 0, time.UTC)
+
+	next, err := NextRunAfter("0 7 * * 1", period.DefaultTimezone, now)
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-10-21T07:00:00+08:00", next.Format(time.RFC3339))
+
+	_, err = NextRunAfter("0 7 * *", period.DefaultTimezone, now)
+	assert.Error(t, err)
+
+	next, err = NextRunAfter("0 0 30 2 *", period.DefaultTimezone, now)
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+}
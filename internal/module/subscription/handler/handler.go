@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"codebase-app/internal/adapter"
+	m "codebase-app/internal/middleware"
+	exportRepository "codebase-app/internal/module/export/repository"
+	exportService "codebase-app/internal/module/export/service"
+	"codebase-app/internal/module/subscription/entity"
+	"codebase-app/internal/module/subscription/ports"
+	"codebase-app/internal/module/subscription/repository"
+	"codebase-app/internal/module/subscription/service"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type subscriptionHandler struct {
+	service ports.SubscriptionService
+}
+
+func NewSubscriptionHandler() *subscriptionHandler {
+	var (
+		handler = new(subscriptionHandler)
+		repo    = repository.NewSubscriptionRepository()
+		exports = exportService.NewExportService(exportRepository.NewExportRepository())
+		service = service.NewSubscriptionService(repo, exports, adapter.Adapters.EmailPublisher)
+	)
+
+	handler.service = service
+
+	return handler
+}
+
+func (h *subscriptionHandler) Register(router fiber.Router) {
+	subscriptions := router.Group("/report-subscriptions", m.AuthBearer, m.AuthRole([]string{"admin"}))
+
+	subscriptions.Get("/", h.getSubscriptions)
+	subscriptions.Post("/", h.createSubscription)
+	subscriptions.Put("/:id", h.updateSubscription)
+	subscriptions.Delete("/:id", h.deleteSubscription)
+	subscriptions.Get("/:id/deliveries", h.getDeliveries)
+}
+
+func (h *subscriptionHandler) createSubscription(c *fiber.Ctx) error {
+	var (
+		req = new(entity.CreateSubscriptionRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = m.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::createSubscription - failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserId = l.GetUserId()
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::createSubscription - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := req.Validate(); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::createSubscription - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.CreateSubscription(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success(res, "Langganan laporan berhasil dibuat"))
+}
+
+func (h *subscriptionHandler) updateSubscription(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UpdateSubscriptionRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = m.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::updateSubscription - failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.Id = c.Params("id")
+	req.UserId = l.GetUserId()
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::updateSubscription - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := req.Validate(); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::updateSubscription - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.UpdateSubscription(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(res, "Langganan laporan berhasil diperbarui"))
+}
+
+func (h *subscriptionHandler) deleteSubscription(c *fiber.Ctx) error {
+	var (
+		req = new(entity.DeleteSubscriptionRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = m.GetLocals(c)
+	)
+
+	req.Id = c.Params("id")
+	req.UserId = l.GetUserId()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::deleteSubscription - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := h.service.DeleteSubscription(ctx, req); err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(nil, "Langganan laporan berhasil dihapus"))
+}
+
+func (h *subscriptionHandler) getSubscriptions(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetSubscriptionsRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = m.GetLocals(c)
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::getSubscriptions - failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserId = l.GetUserId()
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::getSubscriptions - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetSubscriptions(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(res, ""))
+}
+
+func (h *subscriptionHandler) getDeliveries(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetDeliveriesRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = m.GetLocals(c)
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::getDeliveries - failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.SubscriptionId = c.Params("id")
+	req.UserId = l.GetUserId()
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::getDeliveries - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetDeliveries(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(res, ""))
+}
@@ -0,0 +1,31 @@
+package ports
+
+import (
+	"codebase-app/internal/module/subscription/entity"
+	"context"
+)
+
+type SubscriptionRepository interface {
+	CreateSubscription(ctx context.Context, req *entity.CreateSubscriptionRequest) (entity.Subscription, error)
+	UpdateSubscription(ctx context.Context, req *entity.UpdateSubscriptionRequest) (entity.Subscription, error)
+	DeleteSubscription(ctx context.Context, req *entity.DeleteSubscriptionRequest) error
+	GetSubscriptions(ctx context.Context, req *entity.GetSubscriptionsRequest) (entity.GetSubscriptionsResponse, error)
+	GetDeliveries(ctx context.Context, req *entity.GetDeliveriesRequest) (entity.GetDeliveriesResponse, error)
+
+	ClaimDueSubscription(ctx context.Context) (*entity.Subscription, error)
+	CreateDelivery(ctx context.Context, delivery *entity.Delivery) error
+	FinishDelivery(ctx context.Context, delivery *entity.Delivery) error
+	CompleteDelivery(ctx context.Context, id, status string, reason *string) error
+}
+
+type SubscriptionService interface {
+	CreateSubscription(ctx context.Context, req *entity.CreateSubscriptionRequest) (entity.Subscription, error)
+	UpdateSubscription(ctx context.Context, req *entity.UpdateSubscriptionRequest) (entity.Subscription, error)
+	DeleteSubscription(ctx context.Context, req *entity.DeleteSubscriptionRequest) error
+	GetSubscriptions(ctx context.Context, req *entity.GetSubscriptionsRequest) (entity.GetSubscriptionsResponse, error)
+	GetDeliveries(ctx context.Context, req *entity.GetDeliveriesRequest) (entity.GetDeliveriesResponse, error)
+
+	// ProcessNextSubscription claims one due subscription, renders its report
+	// and publishes the email job, it returns false when nothing was due.
+	ProcessNextSubscription(ctx context.Context) (bool, error)
+}
@@ -0,0 +1,420 @@
+package repository
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/subscription/entity"
+	"codebase-app/internal/module/subscription/ports"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.SubscriptionRepository = &subscriptionRepository{}
+
+type subscriptionRepository struct {
+	db *sqlx.DB
+}
+
+func NewSubscriptionRepository() *subscriptionRepository {
+	return &subscriptionRepository{
+		db: adapter.Adapters.DigihubPostgres,
+	}
+}
+
+const subscriptionColumns = `
+	rs.id,
+	rs.user_id,
+	rs.name,
+	rs.report,
+	rs.format,
+	rs.locale,
+	rs.params,
+	rs.schedule,
+	rs.timezone,
+	rs.recipients,
+	rs.is_active,
+	rs.next_run_at,
+	rs.last_run_at,
+	rs.created_at
+`
+
+func (r *subscriptionRepository) CreateSubscription(ctx context.Context, req *entity.CreateSubscriptionRequest) (entity.Subscription, error) {
+	var res entity.Subscription
+
+	params, err := json.Marshal(req.Params)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::CreateSubscription - failed to marshal params")
+		return res, err
+	}
+
+	query := `
+		INSERT INTO report_subscriptions AS rs (
+			id, user_id, name, report, format, locale, params,
+			schedule, timezone, recipients, is_active, next_run_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING ` + subscriptionColumns
+
+	err = r.db.GetContext(ctx, &res, r.db.Rebind(query),
+		ulid.Make().String(), req.UserId, req.Name, req.Report, req.Format, req.Locale, params,
+		req.Schedule, req.Timezone, pq.Array(req.Recipients), *req.IsActive, req.NextRunAt)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::CreateSubscription - failed to create subscription")
+		return res, err
+	}
+
+	return res, decodeParams(&res)
+}
+
+func (r *subscriptionRepository) UpdateSubscription(ctx context.Context, req *entity.UpdateSubscriptionRequest) (entity.Subscription, error) {
+	var res entity.Subscription
+
+	params, err := json.Marshal(req.Params)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateSubscription - failed to marshal params")
+		return res, err
+	}
+
+	query := `
+		UPDATE report_subscriptions AS rs
+		SET
+			name = ?,
+			report = ?,
+			format = ?,
+			locale = ?,
+			params = ?,
+			schedule = ?,
+			timezone = ?,
+			recipients = ?,
+			is_active = ?,
+			next_run_at = ?,
+			updated_at = NOW()
+		WHERE
+			rs.id = ?
+			AND rs.user_id = ?
+			AND rs.deleted_at IS NULL
+		RETURNING ` + subscriptionColumns
+
+	err = r.db.GetContext(ctx, &res, r.db.Rebind(query),
+		req.Name, req.Report, req.Format, req.Locale, params,
+		req.Schedule, req.Timezone, pq.Array(req.Recipients), *req.IsActive, req.NextRunAt,
+		req.Id, req.UserId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn().Err(err).Any("payload", req).Msg("repo::UpdateSubscription - subscription not found")
+			return res, errmsg.NewCustomErrors(404, errmsg.WithMessage("Langganan laporan tidak ditemukan"))
+		}
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateSubscription - failed to update subscription")
+		return res, err
+	}
+
+	return res, decodeParams(&res)
+}
+
+func (r *subscriptionRepository) DeleteSubscription(ctx context.Context, req *entity.DeleteSubscriptionRequest) error {
+	query := `
+		UPDATE report_subscriptions
+		SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), req.Id, req.UserId)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteSubscription - failed to delete subscription")
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteSubscription - failed to get affected rows")
+		return err
+	}
+
+	if affected == 0 {
+		log.Warn().Any("payload", req).Msg("repo::DeleteSubscription - subscription not found")
+		return errmsg.NewCustomErrors(404, errmsg.WithMessage("Langganan laporan tidak ditemukan"))
+	}
+
+	return nil
+}
+
+func (r *subscriptionRepository) GetSubscriptions(ctx context.Context, req *entity.GetSubscriptionsRequest) (entity.GetSubscriptionsResponse, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.Subscription
+	}
+
+	var (
+		res  entity.GetSubscriptionsResponse
+		data = make([]dao, 0, req.Paginate)
+	)
+	res.Items = make([]entity.Subscription, 0, req.Paginate)
+
+	query := `
+		SELECT
+			COUNT(*) OVER() AS total_data,
+		` + subscriptionColumns + `
+		FROM
+			report_subscriptions rs
+		WHERE
+			rs.user_id = ?
+			AND rs.deleted_at IS NULL
+		ORDER BY
+			rs.created_at DESC
+		LIMIT ? OFFSET ?
+	`
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), req.UserId, req.Paginate, (req.Page-1)*req.Paginate)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetSubscriptions - failed to get subscriptions")
+		return res, err
+	}
+
+	for _, d := range data {
+		if err := decodeParams(&d.Subscription); err != nil {
+			return res, err
+		}
+		res.Items = append(res.Items, d.Subscription)
+	}
+
+	if len(data) > 0 {
+		res.Meta.TotalData = data[0].TotalData
+	}
+
+	res.Meta.CountTotalPage(req.Page, req.Paginate, res.Meta.TotalData)
+
+	return res, nil
+}
+
+func (r *subscriptionRepository) GetDeliveries(ctx context.Context, req *entity.GetDeliveriesRequest) (entity.GetDeliveriesResponse, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.Delivery
+	}
+
+	var (
+		res    entity.GetDeliveriesResponse
+		data   = make([]dao, 0, req.Paginate)
+		exists bool
+	)
+	res.Items = make([]entity.Delivery, 0, req.Paginate)
+
+	query := `SELECT EXISTS (SELECT 1 FROM report_subscriptions WHERE id = ? AND user_id = ? AND deleted_at IS NULL)`
+
+	err := r.db.GetContext(ctx, &exists, r.db.Rebind(query), req.SubscriptionId, req.UserId)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetDeliveries - failed to check subscription")
+		return res, err
+	}
+
+	if !exists {
+		log.Warn().Any("payload", req).Msg("repo::GetDeliveries - subscription not found")
+		return res, errmsg.NewCustomErrors(404, errmsg.WithMessage("Langganan laporan tidak ditemukan"))
+	}
+
+	query = `
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			rd.id,
+			rd.subscription_id,
+			rd.status,
+			rd.scheduled_at,
+			rd.recipients,
+			rd.params,
+			rd.filename,
+			rd.size_bytes,
+			rd.error,
+			rd.started_at,
+			rd.finished_at
+		FROM
+			report_deliveries rd
+		WHERE
+			rd.subscription_id = ?
+		ORDER BY
+			rd.created_at DESC
+		LIMIT ? OFFSET ?
+	`
+
+	err = r.db.SelectContext(ctx, &data, r.db.Rebind(query), req.SubscriptionId, req.Paginate, (req.Page-1)*req.Paginate)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetDeliveries - failed to get deliveries")
+		return res, err
+	}
+
+	for _, d := range data {
+		res.Items = append(res.Items, d.Delivery)
+	}
+
+	if len(data) > 0 {
+		res.Meta.TotalData = data[0].TotalData
+	}
+
+	res.Meta.CountTotalPage(req.Page, req.Paginate, res.Meta.TotalData)
+
+	return res, nil
+}
+
+// ClaimDueSubscription locks one subscription whose run is due and moves its
+// next run forward before returning it, so another worker never sends the
+// same run twice. Runs missed while no worker was running are skipped.
+func (r *subscriptionRepository) ClaimDueSubscription(ctx context.Context) (*entity.Subscription, error) {
+	var res entity.Subscription
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::ClaimDueSubscription - failed to begin transaction")
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Msg("repo::ClaimDueSubscription - failed to rollback transaction")
+			}
+		}
+	}()
+
+	query := `
+		SELECT ` + subscriptionColumns + `,
+			ro.name AS role
+		FROM
+			report_subscriptions rs
+		JOIN
+			users u
+			ON u.id = rs.user_id
+		JOIN
+			roles ro
+			ON ro.id = u.role_id
+		WHERE
+			rs.is_active = TRUE
+			AND rs.deleted_at IS NULL
+			AND rs.next_run_at <= NOW()
+		ORDER BY
+			rs.next_run_at
+		LIMIT 1
+		FOR UPDATE OF rs SKIP LOCKED
+	`
+
+	err = tx.GetContext(ctx, &res, query)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = tx.Rollback()
+			return nil, err
+		}
+		log.Error().Err(err).Msg("repo::ClaimDueSubscription - failed to claim subscription")
+		return nil, err
+	}
+
+	now := time.Now()
+	res.ScheduledAt = *res.NextRunAt
+
+	// an invalid schedule stops the subscription instead of failing every poll
+	next, errNext := entity.NextRunAfter(res.Schedule, res.Timezone, now)
+	if errNext != nil {
+		log.Warn().Err(errNext).Str("id", res.Id).Msg("repo::ClaimDueSubscription - invalid schedule, stopping subscription")
+	}
+
+	query = `
+		UPDATE report_subscriptions
+		SET next_run_at = ?, last_run_at = ?, updated_at = NOW()
+		WHERE id = ?
+	`
+
+	if _, err = tx.ExecContext(ctx, r.db.Rebind(query), next, now, res.Id); err != nil {
+		log.Error().Err(err).Str("id", res.Id).Msg("repo::ClaimDueSubscription - failed to schedule next run")
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Str("id", res.Id).Msg("repo::ClaimDueSubscription - failed to commit transaction")
+		return nil, err
+	}
+
+	res.NextRunAt = next
+	res.LastRunAt = &now
+
+	return &res, decodeParams(&res)
+}
+
+func (r *subscriptionRepository) CreateDelivery(ctx context.Context, delivery *entity.Delivery) error {
+	query := `
+		INSERT INTO report_deliveries (id, subscription_id, status, scheduled_at, recipients, params)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id, started_at
+	`
+
+	delivery.Id = ulid.Make().String()
+	delivery.Status = entity.DeliveryProcessing
+
+	err := r.db.QueryRowxContext(ctx, r.db.Rebind(query),
+		delivery.Id, delivery.SubscriptionId, delivery.Status, delivery.ScheduledAt,
+		delivery.Recipients, []byte(delivery.Params),
+	).Scan(&delivery.Id, &delivery.StartedAt)
+	if err != nil {
+		log.Error().Err(err).Any("payload", delivery).Msg("repo::CreateDelivery - failed to create delivery")
+		return err
+	}
+
+	return nil
+}
+
+func (r *subscriptionRepository) FinishDelivery(ctx context.Context, delivery *entity.Delivery) error {
+	query := `
+		UPDATE report_deliveries
+		SET
+			status = ?,
+			filename = ?,
+			size_bytes = ?,
+			error = ?,
+			-- a queued delivery is finished by the email consumer
+			finished_at = CASE WHEN ? THEN NULL ELSE NOW() END
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(query),
+		delivery.Status, delivery.Filename, delivery.SizeBytes, delivery.Error,
+		delivery.Status == entity.DeliveryQueued, delivery.Id)
+	if err != nil {
+		log.Error().Err(err).Any("payload", delivery).Msg("repo::FinishDelivery - failed to finish delivery")
+		return err
+	}
+
+	return nil
+}
+
+// CompleteDelivery marks a queued delivery sent or failed once its email is
+// handled, a delivery already completed is left as it is.
+func (r *subscriptionRepository) CompleteDelivery(ctx context.Context, id, status string, reason *string) error {
+	query := `
+		UPDATE report_deliveries
+		SET status = ?, error = ?, finished_at = NOW()
+		WHERE id = ? AND status = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(query), status, reason, id, entity.DeliveryQueued)
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Str("status", status).Msg("repo::CompleteDelivery - failed to complete delivery")
+		return err
+	}
+
+	return nil
+}
+
+func decodeParams(sub *entity.Subscription) error {
+	if len(sub.ParamsRaw) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(sub.ParamsRaw, &sub.Params); err != nil {
+		log.Error().Err(err).Str("id", sub.Id).Msg("repo::decodeParams - failed to decode params")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"bytes"
+	exportEntity "codebase-app/internal/module/export/entity"
+	exportPorts "codebase-app/internal/module/export/ports"
+	"codebase-app/internal/module/subscription/entity"
+	"codebase-app/internal/module/subscription/ports"
+	"codebase-app/pkg/tabular"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.SubscriptionService = &subscriptionService{}
+
+var errAttachmentTooLarge = fmt.Errorf("laporan melebihi batas lampiran email %d KB, persempit filter laporan", entity.MaxAttachmentSize/1024)
+
+type subscriptionService struct {
+	repo      ports.SubscriptionRepository
+	exports   exportPorts.ExportService
+	publisher jetstream.Publisher
+}
+
+func NewSubscriptionService(repo ports.SubscriptionRepository, exports exportPorts.ExportService, publisher jetstream.Publisher) *subscriptionService {
+	return &subscriptionService{
+		repo:      repo,
+		exports:   exports,
+		publisher: publisher,
+	}
+}
+
+func (s *subscriptionService) CreateSubscription(ctx context.Context, req *entity.CreateSubscriptionRequest) (entity.Subscription, error) {
+	return s.repo.CreateSubscription(ctx, req)
+}
+
+func (s *subscriptionService) UpdateSubscription(ctx context.Context, req *entity.UpdateSubscriptionRequest) (entity.Subscription, error) {
+	return s.repo.UpdateSubscription(ctx, req)
+}
+
+func (s *subscriptionService) DeleteSubscription(ctx context.Context, req *entity.DeleteSubscriptionRequest) error {
+	return s.repo.DeleteSubscription(ctx, req)
+}
+
+func (s *subscriptionService) GetSubscriptions(ctx context.Context, req *entity.GetSubscriptionsRequest) (entity.GetSubscriptionsResponse, error) {
+	return s.repo.GetSubscriptions(ctx, req)
+}
+
+func (s *subscriptionService) GetDeliveries(ctx context.Context, req *entity.GetDeliveriesRequest) (entity.GetDeliveriesResponse, error) {
+	return s.repo.GetDeliveries(ctx, req)
+}
+
+func (s *subscriptionService) ProcessNextSubscription(ctx context.Context) (bool, error) {
+	sub, err := s.repo.ClaimDueSubscription(ctx)
+	if err != nil || sub == nil {
+		return false, err
+	}
+
+	log.Info().Str("id", sub.Id).Str("report", sub.Report).Msg("service::ProcessNextSubscription - delivering report")
+
+	req := s.exportRequest(sub)
+
+	params, err := json.Marshal(req.Params)
+	if err != nil {
+		return true, err
+	}
+
+	delivery := &entity.Delivery{
+		SubscriptionId: sub.Id,
+		ScheduledAt:    sub.ScheduledAt,
+		Recipients:     sub.Recipients,
+		Params:         params,
+	}
+
+	if err := s.repo.CreateDelivery(ctx, delivery); err != nil {
+		return true, err
+	}
+
+	err = s.deliver(ctx, sub, req, delivery)
+	if err != nil {
+		log.Error().Err(err).Str("id", sub.Id).Str("delivery_id", delivery.Id).Msg("service::ProcessNextSubscription - failed to deliver report")
+
+		reason := err.Error()
+		delivery.Status = entity.DeliveryFailed
+		delivery.Error = &reason
+	} else {
+		delivery.Status = entity.DeliveryQueued
+	}
+
+	// use a fresh context, the delivery must be finished even on shutdown
+	if errFinish := s.repo.FinishDelivery(context.Background(), delivery); errFinish != nil {
+		return true, errFinish
+	}
+
+	if err == nil {
+		log.Info().Str("id", sub.Id).Str("delivery_id", delivery.Id).Msg("service::ProcessNextSubscription - report queued")
+	}
+
+	return true, err
+}
+
+// exportRequest resolves the relative range of the subscription against the
+// scheduled run in the timezone of the subscription.
+func (s *subscriptionService) exportRequest(sub *entity.Subscription) *exportEntity.CreateExportRequest {
+	loc, err := time.LoadLocation(sub.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	from, to := entity.ResolveRange(sub.Params.Range, sub.ScheduledAt.In(loc))
+
+	req := &exportEntity.CreateExportRequest{
+		UserId:   sub.UserId,
+		UserRole: sub.UserRole,
+		Report:   sub.Report,
+		Format:   sub.Format,
+		Locale:   sub.Locale,
+		Params: exportEntity.ExportParams{
+			From:     from.Format(time.DateOnly),
+			To:       to.Format(time.DateOnly),
+			Timezone: sub.Timezone,
+			BranchId: sub.Params.BranchId,
+			Search:   sub.Params.Search,
+			Status:   sub.Params.Status,
+		},
+	}
+
+	if sub.Report == exportEntity.ReportAdminSummaries {
+		req.Params.Month = from.Format("2006-01")
+	}
+
+	req.SetDefault()
+
+	return req
+}
+
+// deliver renders the report in memory and publishes the email job with the
+// report attached, it fills the filename and size of the delivery.
+func (s *subscriptionService) deliver(ctx context.Context, sub *entity.Subscription, req *exportEntity.CreateExportRequest, delivery *entity.Delivery) error {
+	if s.publisher == nil {
+		return errors.New("email publisher is not configured")
+	}
+
+	// the role of the owner may have changed since the subscription was made
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	filename, render := s.exports.RenderExport(ctx, req)
+	if err := render(&buf); err != nil {
+		return err
+	}
+
+	size := buf.Len()
+	delivery.Filename = &filename
+	delivery.SizeBytes = &size
+
+	if size > entity.MaxAttachmentSize {
+		return errAttachmentTooLarge
+	}
+
+	period := req.Params.From
+	if req.Params.To != req.Params.From {
+		period += " s/d " + req.Params.To
+	}
+
+	payload := entity.ReportEmailPayload{
+		DeliveryId: delivery.Id,
+		To:         sub.Recipients,
+		Subject:    fmt.Sprintf("[Crowners] %s (%s)", sub.Name, period),
+		Body: `
+			<p>Terlampir laporan <b>` + html.EscapeString(sub.Name) + `</b> periode ` + period + `.</p>
+			<p>Email ini dikirim otomatis sesuai jadwal langganan laporan.</p>
+		`,
+		Attachment: entity.Attachment{
+			Filename:    filename,
+			ContentType: tabular.ContentType(req.Format),
+			Content:     buf.Bytes(),
+		},
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.publisher.Publish(ctx, entity.EmailSubjectReport, data); err != nil {
+		return err
+	}
+
+	return nil
+}
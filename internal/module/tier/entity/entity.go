@@ -0,0 +1,173 @@
+package entity
+
+import (
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/types"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	PeriodMonthly   = "monthly"
+	PeriodQuarterly = "quarterly"
+	PeriodYearly    = "yearly"
+)
+
+type Tier struct {
+	Id            string          `json:"id" db:"id"`
+	BranchId      *string         `json:"branch_id" db:"branch_id"`
+	BranchName    *string         `json:"branch_name" db:"branch_name"`
+	Key           string          `json:"key" db:"key"`
+	Name          string          `json:"name" db:"name"`
+	Threshold     decimal.Decimal `json:"threshold" db:"threshold"`
+	Period        string          `json:"period" db:"period"`
+	EffectiveFrom time.Time       `json:"effective_from" db:"effective_from"`
+	EffectiveTo   *time.Time      `json:"effective_to" db:"effective_to"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+}
+
+// PeriodRange returns the bounds of the tier period that contains date,
+// end is exclusive.
+func PeriodRange(period string, date time.Time) (start, end time.Time) {
+	y, m, _ := date.Date()
+	loc := date.Location()
+
+	switch period {
+	case PeriodMonthly:
+		start = time.Date(y, m, 1, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 1, 0)
+	case PeriodQuarterly:
+		start = time.Date(y, m-(m-1)%3, 1, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 3, 0)
+	default:
+		start = time.Date(y, 1, 1, 0, 0, 0, 0, loc)
+		end = start.AddDate(1, 0, 0)
+	}
+
+	return start, end
+}
+
+type CreateTierRequest struct {
+	BranchId      *string         `json:"branch_id" validate:"omitempty,ulid,exist=branches.id"`
+	Key           string          `json:"key" validate:"required,max=50"`
+	Name          string          `json:"name" validate:"required,max=255"`
+	Threshold     decimal.Decimal `json:"threshold"`
+	Period        string          `json:"period" validate:"required,oneof=monthly quarterly yearly"`
+	EffectiveFrom string          `json:"effective_from" validate:"required,datetime=2006-01-02"`
+	EffectiveTo   *string         `json:"effective_to" validate:"omitempty,datetime=2006-01-02"`
+}
+
+func (r *CreateTierRequest) Validate() error {
+	return validateTier(r.Threshold, r.EffectiveFrom, r.EffectiveTo)
+}
+
+type UpdateTierRequest struct {
+	Id string `params:"id" validate:"ulid"`
+	CreateTierRequest
+}
+
+type DeleteTierRequest struct {
+	Id string `params:"id" validate:"ulid"`
+}
+
+func validateTier(threshold decimal.Decimal, from string, to *string) error {
+	errs := errmsg.NewCustomErrors(400)
+
+	if threshold.IsNegative() {
+		errs.Add("threshold", "threshold tidak boleh negatif")
+	}
+
+	if to != nil && *to < from {
+		errs.Add("effective_to", "effective_to seharusnya tidak lebih kecil dari effective_from")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+
+	return nil
+}
+
+type GetTiersRequest struct {
+	BranchId string `query:"branch_id" validate:"omitempty,ulid"`
+	Date     string `query:"date" validate:"omitempty,datetime=2006-01-02"`
+	// include tiers that are not effective on date
+	All bool `query:"all"`
+}
+
+type GetActiveTiersRequest struct {
+	// UserId resolves BranchId from the user when BranchId is empty
+	UserId string
+	// BranchId selects the per-branch override, empty uses the global tiers
+	BranchId string
+	Date     time.Time
+}
+
+type AdvisorTierRequest struct {
+	UserId   string
+	Date     time.Time // the tier is evaluated as of this date
+	Timezone string
+}
+
+// AdvisorTiersRequest calculates the tiers of several advisors at once, with
+// the same queries whatever their number.
+type AdvisorTiersRequest struct {
+	UserIds  []string
+	Date     time.Time // the tiers are evaluated as of this date
+	Timezone string
+}
+
+type TierRef struct {
+	Id        string          `json:"id"`
+	Key       string          `json:"key"`
+	Name      string          `json:"name"`
+	Threshold decimal.Decimal `json:"threshold"`
+}
+
+type AdvisorTier struct {
+	UserId      string          `json:"user_id"`
+	BranchId    *string         `json:"branch_id"`
+	Current     *TierRef        `json:"current"`
+	Next        *TierRef        `json:"next"`
+	Revenue     decimal.Decimal `json:"revenue"`
+	Period      string          `json:"period"`
+	PeriodStart time.Time       `json:"period_start"`
+	PeriodEnd   time.Time       `json:"period_end"`
+}
+
+type GetTierHistoriesRequest struct {
+	UserId string `query:"user_id" validate:"omitempty,ulid"`
+
+	Page     int `query:"page" validate:"required"`
+	Paginate int `query:"paginate" validate:"required"`
+}
+
+func (r *GetTierHistoriesRequest) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type TierHistory struct {
+	Id          string          `json:"id" db:"id"`
+	UserId      string          `json:"user_id" db:"user_id"`
+	UserName    string          `json:"user_name" db:"user_name"`
+	BranchId    *string         `json:"branch_id" db:"branch_id"`
+	TierId      *string         `json:"tier_id" db:"tier_id"`
+	TierKey     string          `json:"tier_key" db:"tier_key"`
+	Period      string          `json:"period" db:"period"`
+	PeriodStart time.Time       `json:"period_start" db:"period_start"`
+	PeriodEnd   time.Time       `json:"period_end" db:"period_end"`
+	Revenue     decimal.Decimal `json:"revenue" db:"revenue"`
+	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+type GetTierHistoriesResponse struct {
+	Items []TierHistory `json:"items"`
+	Meta  types.Meta    `json:"meta"`
+}
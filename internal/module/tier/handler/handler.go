@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"codebase-app/internal/adapter"
+	m "codebase-app/internal/middleware"
+	"codebase-app/internal/module/tier/entity"
+	"codebase-app/internal/module/tier/ports"
+	"codebase-app/internal/module/tier/repository"
+	"codebase-app/internal/module/tier/service"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type tierHandler struct {
+	service ports.TierService
+}
+
+func NewTierHandler() *tierHandler {
+	var (
+		handler = new(tierHandler)
+		repo    = repository.NewTierRepository()
+		service = service.NewTierService(repo)
+	)
+
+	handler.service = service
+
+	return handler
+}
+
+func (h *tierHandler) Register(router fiber.Router) {
+	tiers := router.Group("/tiers", m.AuthBearer)
+	admin := m.AuthRole([]string{"admin"})
+
+	tiers.Get("/me", m.AuthRole([]string{"service_advisor"}), h.getMyTier)
+	tiers.Get("/histories", h.getTierHistories)
+
+	tiers.Get("/", admin, h.getTiers)
+	tiers.Post("/", admin, h.createTier)
+	tiers.Put("/:id", admin, h.updateTier)
+	tiers.Delete("/:id", admin, h.deleteTier)
+}
+
+func (h *tierHandler) getTiers(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetTiersRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::getTiers - failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::getTiers - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetTiers(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(res, ""))
+}
+
+func (h *tierHandler) createTier(c *fiber.Ctx) error {
+	var (
+		req = new(entity.CreateTierRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::createTier - failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::createTier - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := req.Validate(); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::createTier - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.CreateTier(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success(res, ""))
+}
+
+func (h *tierHandler) updateTier(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UpdateTierRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::updateTier - failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.Id = c.Params("id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::updateTier - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := req.Validate(); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::updateTier - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.UpdateTier(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(res, ""))
+}
+
+func (h *tierHandler) deleteTier(c *fiber.Ctx) error {
+	var (
+		req = new(entity.DeleteTierRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	req.Id = c.Params("id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::deleteTier - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := h.service.DeleteTier(ctx, req); err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(nil, ""))
+}
+
+func (h *tierHandler) getMyTier(c *fiber.Ctx) error {
+	var (
+		req = new(entity.AdvisorTierRequest)
+		ctx = c.Context()
+		l   = m.GetLocals(c)
+	)
+
+	req.UserId = l.GetUserId()
+	req.Timezone = c.Query("timezone")
+
+	res, err := h.service.CalculateAdvisorTier(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(res, ""))
+}
+
+func (h *tierHandler) getTierHistories(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetTierHistoriesRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = m.GetLocals(c)
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::getTierHistories - failed to parse query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	// only admins may look at other advisors
+	if l.GetRole() != "admin" {
+		req.UserId = l.GetUserId()
+	}
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::getTierHistories - invalid payload")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetTierHistories(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(res, ""))
+}
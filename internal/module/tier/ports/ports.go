@@ -0,0 +1,43 @@
+package ports
+
+import (
+	"codebase-app/internal/module/tier/entity"
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type TierRepository interface {
+	GetTiers(ctx context.Context, req *entity.GetTiersRequest) ([]entity.Tier, error)
+	GetActiveTiers(ctx context.Context, req *entity.GetActiveTiersRequest) ([]entity.Tier, error)
+	CreateTier(ctx context.Context, req *entity.CreateTierRequest) (entity.Tier, error)
+	UpdateTier(ctx context.Context, req *entity.UpdateTierRequest) (entity.Tier, error)
+	DeleteTier(ctx context.Context, req *entity.DeleteTierRequest) error
+
+	GetUserBranchId(ctx context.Context, userId string) (*string, error)
+	GetUserBranchIds(ctx context.Context, userIds []string) (map[string]*string, error)
+	GetAdvisorIds(ctx context.Context) ([]string, error)
+	GetAdvisorRevenues(ctx context.Context, userIds []string, from, to time.Time) (map[string]decimal.Decimal, error)
+	UpsertTierHistory(ctx context.Context, history *entity.TierHistory) error
+	GetTierHistories(ctx context.Context, req *entity.GetTierHistoriesRequest) (entity.GetTierHistoriesResponse, error)
+}
+
+type TierService interface {
+	GetTiers(ctx context.Context, req *entity.GetTiersRequest) ([]entity.Tier, error)
+	GetActiveTiers(ctx context.Context, req *entity.GetActiveTiersRequest) ([]entity.Tier, error)
+	CreateTier(ctx context.Context, req *entity.CreateTierRequest) (entity.Tier, error)
+	UpdateTier(ctx context.Context, req *entity.UpdateTierRequest) (entity.Tier, error)
+	DeleteTier(ctx context.Context, req *entity.DeleteTierRequest) error
+
+	// CalculateAdvisorTier is the single place where an advisor's tier is
+	// derived, both the master endpoint and the dashboards go through it.
+	CalculateAdvisorTier(ctx context.Context, req *entity.AdvisorTierRequest) (entity.AdvisorTier, error)
+	// CalculateAdvisorTiers is CalculateAdvisorTier for several advisors, by
+	// user id, the unknown users are left out.
+	CalculateAdvisorTiers(ctx context.Context, req *entity.AdvisorTiersRequest) (map[string]entity.AdvisorTier, error)
+	// SnapshotAdvisorTiers records the tier of every service advisor for the
+	// period containing date.
+	SnapshotAdvisorTiers(ctx context.Context, date time.Time) error
+	GetTierHistories(ctx context.Context, req *entity.GetTierHistoriesRequest) (entity.GetTierHistoriesResponse, error)
+}
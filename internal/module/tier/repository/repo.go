@@ -0,0 +1,463 @@
+package repository
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/tier/entity"
+	"codebase-app/internal/module/tier/ports"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/shopspring/decimal"
+)
+
+var _ ports.TierRepository = &tierRepository{}
+
+type tierRepository struct {
+	db *sqlx.DB
+}
+
+func NewTierRepository() *tierRepository {
+	return &tierRepository{
+		db: adapter.Adapters.DigihubPostgres,
+	}
+}
+
+const tierColumns = `
+	t.id,
+	t.branch_id,
+	b.name AS branch_name,
+	t.key,
+	t.name,
+	t.threshold,
+	t.period,
+	t.effective_from,
+	t.effective_to,
+	t.created_at
+`
+
+func (r *tierRepository) GetTiers(ctx context.Context, req *entity.GetTiersRequest) ([]entity.Tier, error) {
+	var (
+		res   = make([]entity.Tier, 0)
+		query = strings.Builder{}
+		args  = make([]any, 0, 3)
+	)
+
+	query.WriteString(`
+		SELECT ` + tierColumns + `
+		FROM
+			tiers t
+		LEFT JOIN
+			branches b ON b.id = t.branch_id
+		WHERE
+			t.deleted_at IS NULL
+	`)
+
+	if req.BranchId != "" {
+		query.WriteString(` AND t.branch_id = ?`)
+		args = append(args, req.BranchId)
+	}
+
+	if !req.All {
+		query.WriteString(` AND t.effective_from <= ?::date AND (t.effective_to IS NULL OR t.effective_to >= ?::date)`)
+		args = append(args, req.Date, req.Date)
+	}
+
+	query.WriteString(` ORDER BY t.branch_id NULLS FIRST, t.effective_from DESC, t.threshold DESC`)
+
+	err := r.db.SelectContext(ctx, &res, r.db.Rebind(query.String()), args...)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetTiers - failed to get tiers")
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetActiveTiers returns the tiers effective on the given date ordered from
+// the highest threshold. A branch that has its own tiers effective on that
+// date replaces the global programme entirely.
+func (r *tierRepository) GetActiveTiers(ctx context.Context, req *entity.GetActiveTiersRequest) ([]entity.Tier, error) {
+	var (
+		data = make([]entity.Tier, 0)
+		date = req.Date.Format("2006-01-02")
+	)
+
+	query := `
+		SELECT ` + tierColumns + `
+		FROM
+			tiers t
+		LEFT JOIN
+			branches b ON b.id = t.branch_id
+		WHERE
+			t.deleted_at IS NULL
+			AND t.effective_from <= ?::date
+			AND (t.effective_to IS NULL OR t.effective_to >= ?::date)
+			AND (t.branch_id IS NULL OR t.branch_id = ?)
+		ORDER BY
+			t.threshold DESC
+	`
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), date, date, req.BranchId)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetActiveTiers - failed to get tiers")
+		return nil, err
+	}
+
+	var branch, global []entity.Tier
+	for _, t := range data {
+		if t.BranchId != nil {
+			branch = append(branch, t)
+		} else {
+			global = append(global, t)
+		}
+	}
+
+	if len(branch) > 0 {
+		return branch, nil
+	}
+
+	if global == nil {
+		global = make([]entity.Tier, 0)
+	}
+
+	return global, nil
+}
+
+func (r *tierRepository) CreateTier(ctx context.Context, req *entity.CreateTierRequest) (entity.Tier, error) {
+	id := ulid.Make().String()
+
+	if err := r.checkOverlap(ctx, "", req); err != nil {
+		return entity.Tier{}, err
+	}
+
+	query := `
+		INSERT INTO tiers (id, branch_id, key, name, threshold, period, effective_from, effective_to)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(query),
+		id, req.BranchId, req.Key, req.Name, req.Threshold, req.Period, req.EffectiveFrom, req.EffectiveTo)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::CreateTier - failed to create tier")
+		return entity.Tier{}, err
+	}
+
+	return r.getTier(ctx, id)
+}
+
+func (r *tierRepository) UpdateTier(ctx context.Context, req *entity.UpdateTierRequest) (entity.Tier, error) {
+	if err := r.checkOverlap(ctx, req.Id, &req.CreateTierRequest); err != nil {
+		return entity.Tier{}, err
+	}
+
+	query := `
+		UPDATE tiers
+		SET
+			branch_id = ?,
+			key = ?,
+			name = ?,
+			threshold = ?,
+			period = ?,
+			effective_from = ?,
+			effective_to = ?,
+			updated_at = NOW()
+		WHERE
+			id = ?
+			AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query),
+		req.BranchId, req.Key, req.Name, req.Threshold, req.Period, req.EffectiveFrom, req.EffectiveTo, req.Id)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateTier - failed to update tier")
+		return entity.Tier{}, err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return entity.Tier{}, errmsg.NewCustomErrors(404, errmsg.WithMessage("Tier tidak ditemukan"))
+	}
+
+	return r.getTier(ctx, req.Id)
+}
+
+func (r *tierRepository) DeleteTier(ctx context.Context, req *entity.DeleteTierRequest) error {
+	query := `UPDATE tiers SET deleted_at = NOW(), updated_at = NOW() WHERE id = ? AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), req.Id)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteTier - failed to delete tier")
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errmsg.NewCustomErrors(404, errmsg.WithMessage("Tier tidak ditemukan"))
+	}
+
+	return nil
+}
+
+func (r *tierRepository) getTier(ctx context.Context, id string) (entity.Tier, error) {
+	var res entity.Tier
+
+	query := `
+		SELECT ` + tierColumns + `
+		FROM
+			tiers t
+		LEFT JOIN
+			branches b ON b.id = t.branch_id
+		WHERE
+			t.id = ?
+			AND t.deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &res, r.db.Rebind(query), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return res, errmsg.NewCustomErrors(404, errmsg.WithMessage("Tier tidak ditemukan"))
+		}
+		log.Error().Err(err).Str("id", id).Msg("repo::getTier - failed to get tier")
+		return res, err
+	}
+
+	return res, nil
+}
+
+// checkOverlap rejects a tier whose key is already effective in the same
+// scope (global or branch) for an overlapping date range.
+func (r *tierRepository) checkOverlap(ctx context.Context, id string, req *entity.CreateTierRequest) error {
+	var exists bool
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM tiers
+			WHERE
+				deleted_at IS NULL
+				AND id != ?
+				AND key = ?
+				AND branch_id IS NOT DISTINCT FROM ?
+				AND daterange(effective_from, effective_to, '[]') && daterange(?::date, ?::date, '[]')
+		)
+	`
+
+	err := r.db.GetContext(ctx, &exists, r.db.Rebind(query), id, req.Key, req.BranchId, req.EffectiveFrom, req.EffectiveTo)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::checkOverlap - failed to check tier overlap")
+		return err
+	}
+
+	if exists {
+		return errmsg.NewCustomErrors(409).Add("key", "tier dengan key yang sama sudah berlaku pada rentang tanggal tersebut")
+	}
+
+	return nil
+}
+
+func (r *tierRepository) GetUserBranchId(ctx context.Context, userId string) (*string, error) {
+	var branchId *string
+
+	query := `SELECT branch_id FROM users WHERE id = ?`
+
+	err := r.db.GetContext(ctx, &branchId, r.db.Rebind(query), userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("User not found"))
+		}
+		log.Error().Err(err).Str("user_id", userId).Msg("repo::GetUserBranchId - failed to get user branch")
+		return nil, err
+	}
+
+	return branchId, nil
+}
+
+// GetUserBranchIds returns the branch of the users by id, the unknown users
+// are left out.
+func (r *tierRepository) GetUserBranchIds(ctx context.Context, userIds []string) (map[string]*string, error) {
+	var (
+		data []struct {
+			Id       string  `db:"id"`
+			BranchId *string `db:"branch_id"`
+		}
+		branchIds = make(map[string]*string, len(userIds))
+	)
+
+	query := `SELECT id, branch_id FROM users WHERE id = ANY(?)`
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), pq.Array(userIds))
+	if err != nil {
+		log.Error().Err(err).Strs("user_ids", userIds).Msg("repo::GetUserBranchIds - failed to get user branches")
+		return nil, err
+	}
+
+	for _, d := range data {
+		branchIds[d.Id] = d.BranchId
+	}
+
+	return branchIds, nil
+}
+
+func (r *tierRepository) GetAdvisorIds(ctx context.Context) ([]string, error) {
+	var ids = make([]string, 0)
+
+	query := `
+		SELECT u.id
+		FROM users u
+		JOIN roles r ON r.id = u.role_id
+		WHERE r.name = 'service_advisor' AND u.deleted_at IS NULL
+	`
+
+	if err := r.db.SelectContext(ctx, &ids, query); err != nil {
+		log.Error().Err(err).Msg("repo::GetAdvisorIds - failed to get service advisors")
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// GetAdvisorRevenues sums the revenue of completed WACs created in [from, to)
+// per advisor, used-car revenue is recorded on the WAC itself instead of its
+// conditions. The advisors without revenue are left out.
+func (r *tierRepository) GetAdvisorRevenues(ctx context.Context, userIds []string, from, to time.Time) (map[string]decimal.Decimal, error) {
+	var (
+		data []struct {
+			UserId  string          `db:"user_id"`
+			Revenue decimal.Decimal `db:"revenue"`
+		}
+		revenues = make(map[string]decimal.Decimal, len(userIds))
+	)
+
+	query := `
+		SELECT
+			t.user_id,
+			COALESCE(SUM(t.revenue), 0) AS revenue
+		FROM (
+			SELECT wac.user_id, wacc.revenue
+			FROM walk_around_check_conditions wacc
+			JOIN walk_around_checks wac ON wac.id = wacc.walk_around_check_id
+			WHERE
+				wac.user_id = ANY(?)
+				AND wac.status = 'completed'
+				AND wac.deleted_at IS NULL
+				AND wacc.deleted_at IS NULL
+				AND wac.created_at >= ? AND wac.created_at < ?
+			UNION ALL
+			SELECT wac.user_id, wac.revenue
+			FROM walk_around_checks wac
+			WHERE
+				wac.user_id = ANY(?)
+				AND wac.status = 'completed'
+				AND wac.is_used_car = TRUE
+				AND wac.deleted_at IS NULL
+				AND wac.created_at >= ? AND wac.created_at < ?
+		) t
+		GROUP BY t.user_id
+	`
+
+	ids := pq.Array(userIds)
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), ids, from, to, ids, from, to)
+	if err != nil {
+		log.Error().Err(err).Strs("user_ids", userIds).Msg("repo::GetAdvisorRevenues - failed to get revenues")
+		return nil, err
+	}
+
+	for _, d := range data {
+		revenues[d.UserId] = d.Revenue
+	}
+
+	return revenues, nil
+}
+
+func (r *tierRepository) UpsertTierHistory(ctx context.Context, h *entity.TierHistory) error {
+	query := `
+		INSERT INTO advisor_tier_histories (
+			id, user_id, branch_id, tier_id, tier_key, period, period_start, period_end, revenue
+		) VALUES (?, ?, ?, ?, ?, ?, ?::date, ?::date, ?)
+		ON CONFLICT (user_id, period, period_start) DO UPDATE
+		SET
+			branch_id = EXCLUDED.branch_id,
+			tier_id = EXCLUDED.tier_id,
+			tier_key = EXCLUDED.tier_key,
+			period_end = EXCLUDED.period_end,
+			revenue = EXCLUDED.revenue,
+			updated_at = NOW()
+	`
+
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(query),
+		ulid.Make().String(), h.UserId, h.BranchId, h.TierId, h.TierKey, h.Period,
+		h.PeriodStart.Format("2006-01-02"), h.PeriodEnd.Format("2006-01-02"), h.Revenue)
+	if err != nil {
+		log.Error().Err(err).Any("payload", h).Msg("repo::UpsertTierHistory - failed to upsert tier history")
+		return err
+	}
+
+	return nil
+}
+
+func (r *tierRepository) GetTierHistories(ctx context.Context, req *entity.GetTierHistoriesRequest) (entity.GetTierHistoriesResponse, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.TierHistory
+	}
+
+	var (
+		res  entity.GetTierHistoriesResponse
+		data = make([]dao, 0, req.Paginate)
+		args = make([]any, 0, 3)
+	)
+	res.Items = make([]entity.TierHistory, 0, req.Paginate)
+
+	query := `
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			h.id,
+			h.user_id,
+			u.name AS user_name,
+			h.branch_id,
+			h.tier_id,
+			h.tier_key,
+			h.period,
+			h.period_start,
+			h.period_end,
+			h.revenue,
+			h.updated_at
+		FROM
+			advisor_tier_histories h
+		JOIN
+			users u ON u.id = h.user_id
+		WHERE
+			1 = 1
+	`
+
+	if req.UserId != "" {
+		query += ` AND h.user_id = ?`
+		args = append(args, req.UserId)
+	}
+
+	query += ` ORDER BY h.period_start DESC, u.name ASC LIMIT ? OFFSET ?`
+	args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetTierHistories - failed to get tier histories")
+		return res, err
+	}
+
+	for _, d := range data {
+		res.Items = append(res.Items, d.TierHistory)
+	}
+
+	if len(data) > 0 {
+		res.Meta.TotalData = data[0].TotalData
+	}
+
+	res.Meta.CountTotalPage(req.Page, req.Paginate, res.Meta.TotalData)
+
+	return res, nil
+}
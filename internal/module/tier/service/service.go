@@ -0,0 +1,277 @@
+package service
+
+import (
+	"codebase-app/internal/module/tier/entity"
+	"codebase-app/internal/module/tier/ports"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/period"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/shopspring/decimal"
+)
+
+var _ ports.TierService = &tierService{}
+
+type tierService struct {
+	repo ports.TierRepository
+}
+
+func NewTierService(repo ports.TierRepository) *tierService {
+	return &tierService{
+		repo: repo,
+	}
+}
+
+func (s *tierService) GetTiers(ctx context.Context, req *entity.GetTiersRequest) ([]entity.Tier, error) {
+	if req.Date == "" {
+		loc, _ := time.LoadLocation(period.DefaultTimezone)
+		req.Date = time.Now().In(loc).Format("2006-01-02")
+	}
+
+	return s.repo.GetTiers(ctx, req)
+}
+
+func (s *tierService) GetActiveTiers(ctx context.Context, req *entity.GetActiveTiersRequest) ([]entity.Tier, error) {
+	if req.BranchId == "" && req.UserId != "" {
+		branchId, err := s.repo.GetUserBranchId(ctx, req.UserId)
+		if err != nil {
+			return nil, err
+		}
+
+		if branchId != nil {
+			req.BranchId = *branchId
+		}
+	}
+
+	if req.Date.IsZero() {
+		loc, _ := time.LoadLocation(period.DefaultTimezone)
+		req.Date = time.Now().In(loc)
+	}
+
+	return s.repo.GetActiveTiers(ctx, req)
+}
+
+func (s *tierService) CreateTier(ctx context.Context, req *entity.CreateTierRequest) (entity.Tier, error) {
+	return s.repo.CreateTier(ctx, req)
+}
+
+func (s *tierService) UpdateTier(ctx context.Context, req *entity.UpdateTierRequest) (entity.Tier, error) {
+	return s.repo.UpdateTier(ctx, req)
+}
+
+func (s *tierService) DeleteTier(ctx context.Context, req *entity.DeleteTierRequest) error {
+	return s.repo.DeleteTier(ctx, req)
+}
+
+func (s *tierService) GetTierHistories(ctx context.Context, req *entity.GetTierHistoriesRequest) (entity.GetTierHistoriesResponse, error) {
+	return s.repo.GetTierHistories(ctx, req)
+}
+
+func (s *tierService) CalculateAdvisorTier(ctx context.Context, req *entity.AdvisorTierRequest) (entity.AdvisorTier, error) {
+	tiers, err := s.CalculateAdvisorTiers(ctx, &entity.AdvisorTiersRequest{
+		UserIds:  []string{req.UserId},
+		Date:     req.Date,
+		Timezone: req.Timezone,
+	})
+	if err != nil {
+		return entity.AdvisorTier{UserId: req.UserId}, err
+	}
+
+	tier, ok := tiers[req.UserId]
+	if !ok {
+		return entity.AdvisorTier{UserId: req.UserId}, errmsg.NewCustomErrors(404, errmsg.WithMessage("User not found"))
+	}
+
+	return tier, nil
+}
+
+func (s *tierService) CalculateAdvisorTiers(ctx context.Context, req *entity.AdvisorTiersRequest) (map[string]entity.AdvisorTier, error) {
+	var res = make(map[string]entity.AdvisorTier, len(req.UserIds))
+
+	if len(req.UserIds) == 0 {
+		return res, nil
+	}
+
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = period.DefaultTimezone
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return res, errmsg.NewCustomErrors(400).Add("timezone", "timezone tidak valid")
+	}
+
+	date := req.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	date = date.In(loc)
+
+	branchIds, err := s.repo.GetUserBranchIds(ctx, req.UserIds)
+	if err != nil {
+		return res, err
+	}
+
+	// the active tiers per branch, "" for the users without branch
+	activeTiers := make(map[string][]entity.Tier)
+	tiersOf := func(branchId *string) ([]entity.Tier, error) {
+		activeReq := &entity.GetActiveTiersRequest{Date: date}
+		if branchId != nil {
+			activeReq.BranchId = *branchId
+		}
+
+		if v, ok := activeTiers[activeReq.BranchId]; ok {
+			return v, nil
+		}
+
+		v, err := s.repo.GetActiveTiers(ctx, activeReq)
+		if err != nil {
+			return nil, err
+		}
+
+		activeTiers[activeReq.BranchId] = v
+		return v, nil
+	}
+
+	// tiers may run on different periods, each is compared against the
+	// revenue of its own period, queried once for all the advisors.
+	revenues := make(map[string]map[string]decimal.Decimal, 3)
+	revenueOf := func(userId string) func(period string) (decimal.Decimal, error) {
+		return func(period string) (decimal.Decimal, error) {
+			perUser, ok := revenues[period]
+			if !ok {
+				start, end := entity.PeriodRange(period, date)
+
+				var err error
+				perUser, err = s.repo.GetAdvisorRevenues(ctx, req.UserIds, start, end)
+				if err != nil {
+					return decimal.Zero, err
+				}
+
+				revenues[period] = perUser
+			}
+
+			return perUser[userId], nil
+		}
+	}
+
+	for _, userId := range req.UserIds {
+		branchId, ok := branchIds[userId]
+		if !ok {
+			continue
+		}
+
+		tiers, err := tiersOf(branchId)
+		if err != nil {
+			return res, err
+		}
+
+		var (
+			tier    = entity.AdvisorTier{UserId: userId, BranchId: branchId}
+			revenue = revenueOf(userId)
+		)
+
+		current, next, err := resolveTier(tiers, revenue)
+		if err != nil {
+			return res, err
+		}
+
+		period := entity.PeriodYearly
+		if current >= 0 {
+			period = tiers[current].Period
+			tier.Current = toRef(tiers[current])
+		}
+		if next >= 0 {
+			tier.Next = toRef(tiers[next])
+		}
+
+		tier.Period = period
+		tier.PeriodStart, tier.PeriodEnd = entity.PeriodRange(period, date)
+		tier.Revenue, err = revenue(period)
+		if err != nil {
+			return res, err
+		}
+
+		res[userId] = tier
+	}
+
+	return res, nil
+}
+
+// resolveTier picks the highest tier whose threshold is reached, tiers must be
+// ordered by threshold descending. When no threshold is reached the lowest
+// tier is used as the floor. It returns -1 for a missing current or next tier.
+func resolveTier(tiers []entity.Tier, revenue func(period string) (decimal.Decimal, error)) (current, next int, err error) {
+	current, next = -1, -1
+	if len(tiers) == 0 {
+		return current, next, nil
+	}
+
+	for i, t := range tiers {
+		v, err := revenue(t.Period)
+		if err != nil {
+			return -1, -1, err
+		}
+
+		if v.GreaterThanOrEqual(t.Threshold) {
+			current = i
+			break
+		}
+	}
+
+	if current < 0 {
+		current = len(tiers) - 1
+	}
+
+	next = current - 1
+	return current, next, nil
+}
+
+func toRef(t entity.Tier) *entity.TierRef {
+	return &entity.TierRef{
+		Id:        t.Id,
+		Key:       t.Key,
+		Name:      t.Name,
+		Threshold: t.Threshold,
+	}
+}
+
+func (s *tierService) SnapshotAdvisorTiers(ctx context.Context, date time.Time) error {
+	ids, err := s.repo.GetAdvisorIds(ctx)
+	if err != nil {
+		return err
+	}
+
+	tiers, err := s.CalculateAdvisorTiers(ctx, &entity.AdvisorTiersRequest{UserIds: ids, Date: date})
+	if err != nil {
+		log.Error().Err(err).Msg("service::SnapshotAdvisorTiers - failed to calculate tiers")
+		return err
+	}
+
+	for _, id := range ids {
+		tier, ok := tiers[id]
+		if !ok || tier.Current == nil {
+			continue
+		}
+
+		history := &entity.TierHistory{
+			UserId:      id,
+			BranchId:    tier.BranchId,
+			TierId:      &tier.Current.Id,
+			TierKey:     tier.Current.Key,
+			Period:      tier.Period,
+			PeriodStart: tier.PeriodStart,
+			PeriodEnd:   tier.PeriodEnd.AddDate(0, 0, -1),
+			Revenue:     tier.Revenue,
+		}
+
+		if err := s.repo.UpsertTierHistory(ctx, history); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
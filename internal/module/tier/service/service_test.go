@@ -0,0 +1,111 @@
+package service
+
+import (
+	"codebase-app/internal/module/tier/entity"
+	"codebase-app/internal/module/tier/ports"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveTier(t *testing.T) {
+	tiers := []entity.Tier{
+		{Key: "platinum", Threshold: decimal.NewFromInt(1_500_000), Period: entity.PeriodYearly},
+		{Key: "gold", Threshold: decimal.NewFromInt(300_000), Period: entity.PeriodMonthly},
+		{Key: "silver", Threshold: decimal.NewFromInt(500_000), Period: entity.PeriodYearly},
+	}
+
+	cases := []struct {
+		name          string
+		yearly        int64
+		monthly       int64
+		current, next int
+	}{
+		{"platinum", 1_600_000, 0, 0, -1},
+		{"gold on its own period", 900_000, 300_000, 1, 0},
+		{"silver", 600_000, 100_000, 2, 1},
+		{"below every threshold falls back to the lowest", 0, 0, 2, 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			current, next, err := resolveTier(tiers, func(period string) (decimal.Decimal, error) {
+				if period == entity.PeriodMonthly {
+					return decimal.NewFromInt(tc.monthly), nil
+				}
+				return decimal.NewFromInt(tc.yearly), nil
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.current, current)
+			assert.Equal(t, tc.next, next)
+		})
+	}
+
+	current, next, err := resolveTier(nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, -1, current)
+	assert.Equal(t, -1, next)
+}
+
+func TestPeriodRange(t *testing.T) {
+	date := time.Date(2024, time.August, 17, 10, 0, 0, 0, time.UTC)
+
+	start, end := entity.PeriodRange(entity.PeriodQuarterly, date)
+	assert.Equal(t, time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2024, time.October, 1, 0, 0, 0, 0, time.UTC), end)
+
+	start, end = entity.PeriodRange(entity.PeriodMonthly, date)
+	assert.Equal(t, time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2024, time.September, 1, 0, 0, 0, 0, time.UTC), end)
+
+	start, end = entity.PeriodRange(entity.PeriodYearly, date)
+	assert.Equal(t, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), end)
+}
+
+// tiersRepo serves the tier calculation and counts the revenue queries, the
+// other methods are not used.
+type tiersRepo struct {
+	ports.TierRepository
+	revenueQueries int
+}
+
+func (r *tiersRepo) GetUserBranchIds(_ context.Context, userIds []string) (map[string]*string, error) {
+	branchId := "branch"
+	return map[string]*string{"a": &branchId, "b": nil}, nil
+}
+
+func (r *tiersRepo) GetActiveTiers(_ context.Context, req *entity.GetActiveTiersRequest) ([]entity.Tier, error) {
+	return []entity.Tier{
+		{Key: "gold", Threshold: decimal.NewFromInt(1_000), Period: entity.PeriodYearly},
+		{Key: "silver", Threshold: decimal.NewFromInt(100), Period: entity.PeriodYearly},
+	}, nil
+}
+
+func (r *tiersRepo) GetAdvisorRevenues(_ context.Context, userIds []string, from, to time.Time) (map[string]decimal.Decimal, error) {
+	r.revenueQueries++
+	return map[string]decimal.Decimal{"a": decimal.NewFromInt(2_000)}, nil
+}
+
+func TestCalculateAdvisorTiers(t *testing.T) {
+	repo := &tiersRepo{}
+	s := NewTierService(repo)
+
+	tiers, err := s.CalculateAdvisorTiers(context.Background(), &entity.AdvisorTiersRequest{UserIds: []string{"a", "b", "unknown"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, repo.revenueQueries)
+
+	assert.Len(t, tiers, 2)
+	assert.Equal(t, "gold", tiers["a"].Current.Key)
+	assert.Nil(t, tiers["a"].Next)
+	assert.Equal(t, "silver", tiers["b"].Current.Key)
+	assert.Equal(t, "gold", tiers["b"].Next.Key)
+	assert.True(t, tiers["b"].Revenue.IsZero())
+
+	_, err = s.CalculateAdvisorTier(context.Background(), &entity.AdvisorTierRequest{UserId: "unknown"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,232 @@
+package entity
+
+import (
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/period"
+	"codebase-app/pkg/types"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	StatusDraft      = "draft"
+	StatusScheduled  = "scheduled"
+	StatusActive     = "active"
+	StatusSuperseded = "superseded"
+
+	// MinYear is the oldest model year a price can be given for.
+	MinYear = 1980
+)
+
+// CurrentPriceList selects the id of the published price list in effect
+// today, the prices change at midnight in period.DefaultTimezone.
+const CurrentPriceList = `(
+	SELECT cpl.id
+	FROM trade_in_price_lists cpl
+	WHERE cpl.published_at IS NOT NULL AND cpl.effective_from <= ` + period.Today + `
+	ORDER BY cpl.effective_from DESC
+	LIMIT 1
+)`
+
+/*
+Trade In Price Lists - Start
+  - The prices of the trade in catalogue are grouped in price lists, only a
+    draft can be changed, deleted or published
+  - A published list takes effect on effective_from and stays in effect until
+    a later published list does, it is never changed again so a quote can be
+    priced again with the list it was made with
+  - A new list copies the prices of copy_from_id, of the list in effect when
+    it is empty, unless empty is set
+  - status is draft, scheduled (published, not in effect yet), active or
+    superseded
+*/
+type PriceList struct {
+	Id            string     `json:"id" db:"id"`
+	Name          string     `json:"name" db:"name"`
+	EffectiveFrom time.Time  `json:"effective_from" db:"effective_from"`
+	Status        string     `json:"status" db:"status"`
+	TotalPrices   int        `json:"total_prices" db:"total_prices"`
+	CreatedBy     *string    `json:"created_by" db:"created_by"`
+	CreatedByName *string    `json:"created_by_name" db:"created_by_name"`
+	PublishedAt   *time.Time `json:"published_at" db:"published_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type GetPriceListsRequest struct {
+	Status   string `query:"status" validate:"omitempty,oneof=draft scheduled active superseded"`
+	Page     int    `query:"page" validate:"required"`
+	Paginate int    `query:"paginate" validate:"required,max=100"`
+}
+
+func (r *GetPriceListsRequest) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type GetPriceListsResponse struct {
+	Items []PriceList `json:"items"`
+	Meta  types.Meta  `json:"meta"`
+}
+
+type GetPriceListRequest struct {
+	Id string `params:"id" validate:"ulid"`
+}
+
+type CreatePriceListRequest struct {
+	UserId string
+
+	Name          string  `json:"name" validate:"required,max=255"`
+	EffectiveFrom string  `json:"effective_from" validate:"required,datetime=2006-01-02"`
+	CopyFromId    *string `json:"copy_from_id" validate:"omitempty,ulid,exist=trade_in_price_lists.id"`
+	Empty         bool    `json:"empty"`
+}
+
+func (r *CreatePriceListRequest) Validate() error {
+	if r.Empty && r.CopyFromId != nil {
+		return errmsg.NewCustomErrors(400).Add("empty", "empty tidak boleh diisi bersama copy_from_id")
+	}
+
+	return nil
+}
+
+// UpdatePriceListRequest renames or reschedules a draft, nil fields are left
+// untouched.
+type UpdatePriceListRequest struct {
+	Id string `params:"id" validate:"ulid"`
+
+	Name          *string `json:"name" validate:"omitempty,min=1,max=255"`
+	EffectiveFrom *string `json:"effective_from" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// PublishPriceListRequest publishes a draft, its effective_from can not be in
+// the past nor the date of another published list.
+type PublishPriceListRequest struct {
+	Id string `params:"id" validate:"ulid"`
+}
+
+type DeletePriceListRequest struct {
+	Id string `params:"id" validate:"ulid"`
+}
+
+/*
+Trade In Prices - Start
+  - The brand, model, type and year of a price are upper cased, they are
+    unique in a price list
+  - min_purchase can not be more than max_purchase, the year is from MinYear
+    to next year
+*/
+type Price struct {
+	Id          string    `json:"id" db:"id"`
+	Brand       string    `json:"brand" db:"brand"`
+	Model       string    `json:"model" db:"model"`
+	Type        string    `json:"type" db:"type"`
+	Year        int       `json:"year" db:"year"`
+	MinPurchase int       `json:"min_purchase" db:"min_purchase"`
+	MaxPurchase int       `json:"max_purchase" db:"max_purchase"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type GetPricesRequest struct {
+	PriceListId string `params:"id" validate:"ulid"`
+
+	Search   string `query:"search" validate:"omitempty,min=2"`
+	Brand    string `query:"brand"`
+	Page     int    `query:"page" validate:"required"`
+	Paginate int    `query:"paginate" validate:"required,max=100"`
+}
+
+func (r *GetPricesRequest) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+
+	r.Brand = strings.ToUpper(strings.TrimSpace(r.Brand))
+}
+
+type GetPricesResponse struct {
+	Items []Price    `json:"items"`
+	Meta  types.Meta `json:"meta"`
+}
+
+type PriceRequest struct {
+	Brand       string `json:"brand" validate:"required,max=255"`
+	Model       string `json:"model" validate:"required,max=255"`
+	Type        string `json:"type" validate:"required,max=255"`
+	Year        int    `json:"year" validate:"required"`
+	MinPurchase int    `json:"min_purchase" validate:"min=0"`
+	MaxPurchase int    `json:"max_purchase" validate:"min=0"`
+}
+
+func (r *PriceRequest) Normalize() {
+	r.Brand = normalizeName(r.Brand)
+	r.Model = normalizeName(r.Model)
+	r.Type = normalizeName(r.Type)
+}
+
+func (r *PriceRequest) Validate() error {
+	errs := errmsg.NewCustomErrors(400)
+
+	for field, msg := range validatePrice(r.Year, r.MinPurchase, r.MaxPurchase, time.Now()) {
+		errs.Add(field, msg)
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+
+	return nil
+}
+
+type CreatePriceRequest struct {
+	PriceListId string `params:"id" validate:"ulid"`
+
+	PriceRequest
+}
+
+type UpdatePriceRequest struct {
+	PriceListId string `params:"id" validate:"ulid"`
+	Id          string `params:"price_id" validate:"uuid"`
+
+	PriceRequest
+}
+
+type DeletePriceRequest struct {
+	PriceListId string `params:"id" validate:"ulid"`
+	Id          string `params:"price_id" validate:"uuid"`
+}
+
+// normalizeName upper cases a brand, model or type and collapses its spaces,
+// the way the catalogue has always been seeded.
+func normalizeName(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), " "))
+}
+
+// validatePrice returns the messages of the invalid fields of a price.
+func validatePrice(year, minPurchase, maxPurchase int, now time.Time) map[string]string {
+	errs := make(map[string]string)
+
+	if maxYear := now.Year() + 1; year < MinYear || year > maxYear {
+		errs["year"] = "tahun harus antara " + strconv.Itoa(MinYear) + " dan " + strconv.Itoa(maxYear)
+	}
+
+	if minPurchase < 0 {
+		errs["min_purchase"] = "min purchase tidak boleh negatif"
+	}
+
+	if minPurchase > maxPurchase {
+		errs["max_purchase"] = "max purchase tidak boleh lebih kecil dari min purchase"
+	}
+
+	return errs
+}
@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"codebase-app/pkg/errmsg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePriceRows(t *testing.T) {
+	now := time.Date(2024, 10, 21, 0, 0, 0, 0, time.UTC)
+
+	rows, err := ParsePriceRows([][]string{
+		{"No", "Brand", "Model", "Type", "Year", "Min Purchase", "Max Purchase"},
+		{"1", " toyota ", "avanza", "1.3  g", "2019", "150.000.000", "170.000.000"},
+		{"", "", "", "", "", "", ""},
+	}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, []PriceRow{{
+		Row: 2, Brand: "TOYOTA", Model: "AVANZA", Type: "1.3 G", Year: 2019,
+		MinPurchase: 150000000, MaxPurchase: 170000000,
+	}}, rows)
+
+	_, err = ParsePriceRows([][]string{
+		{"brand", "model", "type", "year", "min_purchase", "max_purchase"},
+		{"TOYOTA", "AVANZA", "1.3 G", "2019", "170000000", "150000000"},
+		{"TOYOTA", "AVANZA", "1.3 G", "2019", "150000000", "170000000"},
+		{"TOYOTA", "", "1.3 G", "1970", "x", "1"},
+	}, now)
+	errs, ok := err.(*errmsg.CustomError)
+	assert.True(t, ok)
+	assert.Contains(t, errs.Errors, "file[2].max_purchase")
+	assert.Contains(t, errs.Errors, "file[3].year")
+	assert.Contains(t, errs.Errors, "file[4].model")
+	assert.Contains(t, errs.Errors, "file[4].year")
+	assert.Contains(t, errs.Errors, "file[4].min_purchase")
+
+	_, err = ParsePriceRows([][]string{{"brand", "model"}, {"TOYOTA", "AVANZA"}}, now)
+	assert.Error(t, err)
+}
+
+func TestDiffPrices(t *testing.T) {
+	current := []Price{
+		{Id: "a", Brand: "TOYOTA", Model: "AVANZA", Type: "G", Year: 2019, MinPurchase: 100, MaxPurchase: 200},
+		{Id: "b", Brand: "TOYOTA", Model: "AVANZA", Type: "G", Year: 2020, MinPurchase: 150, MaxPurchase: 250},
+		{Id: "c", Brand: "HONDA", Model: "BRIO", Type: "S", Year: 2020, MinPurchase: 90, MaxPurchase: 120},
+	}
+	rows := []PriceRow{
+		{Row: 2, Brand: "TOYOTA", Model: "AVANZA", Type: "G", Year: 2019, MinPurchase: 100, MaxPurchase: 200},
+		{Row: 3, Brand: "TOYOTA", Model: "AVANZA", Type: "G", Year: 2020, MinPurchase: 160, MaxPurchase: 260},
+		{Row: 4, Brand: "TOYOTA", Model: "AVANZA", Type: "G", Year: 2021, MinPurchase: 200, MaxPurchase: 300},
+	}
+
+	diff := DiffPrices(current, rows, UploadModeMerge)
+	assert.Equal(t, 1, diff.Unchanged)
+	assert.Len(t, diff.Added, 1)
+	assert.Equal(t, 2021, diff.Added[0].Year)
+	assert.Len(t, diff.Changed, 1)
+	assert.Equal(t, 150, diff.Changed[0].OldMinPurchase)
+	assert.Equal(t, 160, diff.Changed[0].MinPurchase)
+	assert.Empty(t, diff.Removed)
+
+	diff = DiffPrices(current, rows, UploadModeReplace)
+	assert.Len(t, diff.Removed, 1)
+	assert.Equal(t, "c", diff.Removed[0].Id)
+}
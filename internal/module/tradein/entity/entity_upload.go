@@ -0,0 +1,224 @@
+package entity
+
+import (
+	"codebase-app/pkg/errmsg"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	UploadModeMerge   = "merge"
+	UploadModeReplace = "replace"
+
+	FormatXLSX = "xlsx"
+	FormatCSV  = "csv"
+
+	// MaxUploadRows caps the prices of one upload.
+	MaxUploadRows = 20000
+)
+
+// uploadHeaders are the columns an upload must have, in any order, other
+// columns are ignored.
+var uploadHeaders = []string{"brand", "model", "type", "year", "min_purchase", "max_purchase"}
+
+/*
+Trade In Price Upload - Start
+  - Loads the prices of a draft from an xlsx (first sheet) or csv file given
+    in base64, the first row is the header with the columns brand, model,
+    type, year, min_purchase and max_purchase
+  - merge adds and changes the prices of the file, replace also removes the
+    prices that are not in the file
+  - The preview returns the changes without saving them, the upload saves
+    the same changes, a file with an invalid row is rejected as a whole
+*/
+type UploadPricesRequest struct {
+	PriceListId string `params:"id" validate:"ulid"`
+
+	File   string `json:"file" validate:"required,base64"`
+	Format string `json:"format" validate:"required,oneof=xlsx csv"`
+	Mode   string `json:"mode" validate:"omitempty,oneof=merge replace"`
+
+	// Commit saves the changes, the preview leaves it false
+	Commit bool `json:"-"`
+}
+
+func (r *UploadPricesRequest) SetDefault() {
+	if r.Mode == "" {
+		r.Mode = UploadModeMerge
+	}
+}
+
+// PriceRow is a price read from an upload, Row is its row in the file.
+type PriceRow struct {
+	Row         int    `json:"row"`
+	Brand       string `json:"brand"`
+	Model       string `json:"model"`
+	Type        string `json:"type"`
+	Year        int    `json:"year"`
+	MinPurchase int    `json:"min_purchase"`
+	MaxPurchase int    `json:"max_purchase"`
+}
+
+type PriceChange struct {
+	PriceRow
+
+	OldMinPurchase int `json:"old_min_purchase"`
+	OldMaxPurchase int `json:"old_max_purchase"`
+}
+
+type PriceDiff struct {
+	Mode      string        `json:"mode"`
+	Committed bool          `json:"committed"`
+	Added     []PriceRow    `json:"added"`
+	Changed   []PriceChange `json:"changed"`
+	Removed   []Price       `json:"removed"`
+	Unchanged int           `json:"unchanged"`
+}
+
+// priceKey identifies a price in a price list.
+func priceKey(brand, model, type_ string, year int) string {
+	return brand + "\x00" + model + "\x00" + type_ + "\x00" + strconv.Itoa(year)
+}
+
+// ParsePriceRows reads the prices of the records of an upload, the first
+// record is the header. Every invalid cell is reported as file[row].column.
+func ParsePriceRows(records [][]string, now time.Time) ([]PriceRow, error) {
+	errs := errmsg.NewCustomErrors(400).SetMessage("Beberapa data harga tidak valid")
+
+	if len(records) < 2 {
+		return nil, errmsg.NewCustomErrors(400).SetMessage("File tidak memiliki data harga")
+	}
+
+	if len(records)-1 > MaxUploadRows {
+		return nil, errmsg.NewCustomErrors(400).SetMessage(fmt.Sprintf("File maksimal %d baris harga", MaxUploadRows))
+	}
+
+	columns := make(map[string]int)
+	for i, h := range records[0] {
+		columns[strings.ReplaceAll(strings.ToLower(strings.TrimSpace(h)), " ", "_")] = i
+	}
+
+	for _, h := range uploadHeaders {
+		if _, ok := columns[h]; !ok {
+			errs.Add("file.header", "kolom "+h+" tidak ditemukan")
+		}
+	}
+
+	if errs.HasErrors() {
+		return nil, errs
+	}
+
+	var (
+		rows = make([]PriceRow, 0, len(records)-1)
+		seen = make(map[string]int)
+	)
+
+	for i, record := range records[1:] {
+		var (
+			rowNumber = i + 2
+			rowname   = fmt.Sprintf("file[%d]", rowNumber)
+			cell      = func(h string) string {
+				if c := columns[h]; c < len(record) {
+					return strings.TrimSpace(record[c])
+				}
+				return ""
+			}
+			number = func(h string) int {
+				// thousand separators are allowed, "150.000.000" is 150000000
+				v := strings.NewReplacer(".", "", ",", "", " ", "").Replace(cell(h))
+				n, err := strconv.Atoi(v)
+				if err != nil {
+					errs.Add(rowname+"."+h, h+" harus berupa angka")
+				}
+				return n
+			}
+		)
+
+		// blank rows at the end of a sheet are skipped
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		row := PriceRow{
+			Row:         rowNumber,
+			Brand:       normalizeName(cell("brand")),
+			Model:       normalizeName(cell("model")),
+			Type:        normalizeName(cell("type")),
+			Year:        number("year"),
+			MinPurchase: number("min_purchase"),
+			MaxPurchase: number("max_purchase"),
+		}
+
+		for h, v := range map[string]string{"brand": row.Brand, "model": row.Model, "type": row.Type} {
+			if v == "" {
+				errs.Add(rowname+"."+h, h+" tidak boleh kosong")
+			}
+		}
+
+		for field, msg := range validatePrice(row.Year, row.MinPurchase, row.MaxPurchase, now) {
+			errs.Add(rowname+"."+field, msg)
+		}
+
+		key := priceKey(row.Brand, row.Model, row.Type, row.Year)
+		if first, ok := seen[key]; ok {
+			errs.Add(rowname+".year", fmt.Sprintf("harga duplikat dengan baris %d", first))
+		}
+		seen[key] = rowNumber
+
+		rows = append(rows, row)
+	}
+
+	if errs.HasErrors() {
+		return nil, errs
+	}
+
+	return rows, nil
+}
+
+// DiffPrices compares the prices of a price list with the rows of an upload,
+// the prices missing from the rows are removed in the replace mode only.
+func DiffPrices(current []Price, rows []PriceRow, mode string) PriceDiff {
+	diff := PriceDiff{
+		Mode:    mode,
+		Added:   make([]PriceRow, 0),
+		Changed: make([]PriceChange, 0),
+		Removed: make([]Price, 0),
+	}
+
+	byKey := make(map[string]Price, len(current))
+	for _, p := range current {
+		byKey[priceKey(p.Brand, p.Model, p.Type, p.Year)] = p
+	}
+
+	inRows := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		key := priceKey(row.Brand, row.Model, row.Type, row.Year)
+		inRows[key] = true
+
+		p, ok := byKey[key]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, row)
+		case p.MinPurchase != row.MinPurchase || p.MaxPurchase != row.MaxPurchase:
+			diff.Changed = append(diff.Changed, PriceChange{
+				PriceRow:       row,
+				OldMinPurchase: p.MinPurchase,
+				OldMaxPurchase: p.MaxPurchase,
+			})
+		default:
+			diff.Unchanged++
+		}
+	}
+
+	if mode == UploadModeReplace {
+		for _, p := range current {
+			if !inRows[priceKey(p.Brand, p.Model, p.Type, p.Year)] {
+				diff.Removed = append(diff.Removed, p)
+			}
+		}
+	}
+
+	return diff
+}
@@ -0,0 +1,377 @@
+package handler
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/middleware"
+	"codebase-app/internal/module/tradein/entity"
+	"codebase-app/internal/module/tradein/ports"
+	"codebase-app/internal/module/tradein/repository"
+	"codebase-app/internal/module/tradein/service"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type tradeInHandler struct {
+	service ports.TradeInService
+}
+
+func NewTradeInHandler() *tradeInHandler {
+	var (
+		repo    = repository.NewTradeInRepository()
+		service = service.NewTradeInService(repo)
+		handler = new(tradeInHandler)
+	)
+	handler.service = service
+
+	return handler
+}
+
+func (h *tradeInHandler) Register(router fiber.Router) {
+	priceList := router.Group("/trade-in/price-lists", middleware.AuthBearer, middleware.AuthRole([]string{"admin"}))
+
+	priceList.Get("/", h.getPriceLists)
+	priceList.Post("/", h.createPriceList)
+	priceList.Get("/:id", h.getPriceList)
+	priceList.Patch("/:id", h.updatePriceList)
+	priceList.Delete("/:id", h.deletePriceList)
+	priceList.Post("/:id/publish", h.publishPriceList)
+	priceList.Get("/:id/prices", h.getPrices)
+	priceList.Post("/:id/prices", h.createPrice)
+	priceList.Put("/:id/prices/:price_id", h.updatePrice)
+	priceList.Delete("/:id/prices/:price_id", h.deletePrice)
+	priceList.Post("/:id/uploads/preview", h.uploadPrices(false))
+	priceList.Post("/:id/uploads", h.uploadPrices(true))
+}
+
+func (h *tradeInHandler) getPriceLists(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetPriceListsRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetPriceLists - failed to parse request")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::GetPriceLists - invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetPriceLists(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(res, ""))
+}
+
+func (h *tradeInHandler) getPriceList(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetPriceListRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	req.Id = c.Params("id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::GetPriceList - invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetPriceList(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(res, ""))
+}
+
+func (h *tradeInHandler) createPriceList(c *fiber.Ctx) error {
+	var (
+		req = new(entity.CreatePriceListRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::CreatePriceList - failed to parse request")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserId = l.UserId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::CreatePriceList - invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := req.Validate(); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::CreatePriceList - invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.CreatePriceList(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success(res, "Daftar harga berhasil dibuat"))
+}
+
+func (h *tradeInHandler) updatePriceList(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UpdatePriceListRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UpdatePriceList - failed to parse request")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.Id = c.Params("id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::UpdatePriceList - invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.UpdatePriceList(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(res, "Daftar harga berhasil diperbarui"))
+}
+
+func (h *tradeInHandler) deletePriceList(c *fiber.Ctx) error {
+	var (
+		req = new(entity.DeletePriceListRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	req.Id = c.Params("id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::DeletePriceList - invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := h.service.DeletePriceList(ctx, req); err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(nil, "Daftar harga berhasil dihapus"))
+}
+
+func (h *tradeInHandler) publishPriceList(c *fiber.Ctx) error {
+	var (
+		req = new(entity.PublishPriceListRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	req.Id = c.Params("id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::PublishPriceList - invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.PublishPriceList(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(res, "Daftar harga berhasil dipublikasikan"))
+}
+
+func (h *tradeInHandler) getPrices(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetPricesRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetPrices - failed to parse request")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.PriceListId = c.Params("id")
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::GetPrices - invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetPrices(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(res, ""))
+}
+
+func (h *tradeInHandler) createPrice(c *fiber.Ctx) error {
+	var (
+		req = new(entity.CreatePriceRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::CreatePrice - failed to parse request")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.PriceListId = c.Params("id")
+	req.Normalize()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::CreatePrice - invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := req.Validate(); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::CreatePrice - invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.CreatePrice(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success(res, "Harga berhasil ditambahkan"))
+}
+
+func (h *tradeInHandler) updatePrice(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UpdatePriceRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UpdatePrice - failed to parse request")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.PriceListId = c.Params("id")
+	req.Id = c.Params("price_id")
+	req.Normalize()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::UpdatePrice - invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := req.Validate(); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::UpdatePrice - invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.UpdatePrice(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(res, "Harga berhasil diperbarui"))
+}
+
+func (h *tradeInHandler) deletePrice(c *fiber.Ctx) error {
+	var (
+		req = new(entity.DeletePriceRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	req.PriceListId = c.Params("id")
+	req.Id = c.Params("price_id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::DeletePrice - invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := h.service.DeletePrice(ctx, req); err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(nil, "Harga berhasil dihapus"))
+}
+
+// uploadPrices previews an upload, or saves it when commit is set.
+func (h *tradeInHandler) uploadPrices(commit bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var (
+			req = new(entity.UploadPricesRequest)
+			ctx = c.Context()
+			v   = adapter.Adapters.Validator
+		)
+
+		if err := c.BodyParser(req); err != nil {
+			log.Warn().Err(err).Msg("handler::UploadPrices - failed to parse request")
+			return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+		}
+
+		req.PriceListId = c.Params("id")
+		req.Commit = commit
+		req.SetDefault()
+
+		if err := v.Validate(req); err != nil {
+			log.Warn().Err(err).Any("payload", req.PriceListId).Msg("handler::UploadPrices - invalid request")
+			code, errs := errmsg.Errors(err, req)
+			return c.Status(code).JSON(response.Error(errs))
+		}
+
+		res, err := h.service.UploadPrices(ctx, req)
+		if err != nil {
+			code, errs := errmsg.Errors[error](err)
+			return c.Status(code).JSON(response.Error(errs))
+		}
+
+		if !commit {
+			return c.JSON(response.Success(res, ""))
+		}
+
+		return c.JSON(response.Success(res, "Harga berhasil diunggah"))
+	}
+}
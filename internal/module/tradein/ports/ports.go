@@ -0,0 +1,36 @@
+package ports
+
+import (
+	"codebase-app/internal/module/tradein/entity"
+	"context"
+)
+
+type TradeInRepository interface {
+	GetPriceLists(ctx context.Context, req *entity.GetPriceListsRequest) (entity.GetPriceListsResponse, error)
+	GetPriceList(ctx context.Context, req *entity.GetPriceListRequest) (entity.PriceList, error)
+	CreatePriceList(ctx context.Context, req *entity.CreatePriceListRequest) (string, error)
+	UpdatePriceList(ctx context.Context, req *entity.UpdatePriceListRequest) error
+	PublishPriceList(ctx context.Context, req *entity.PublishPriceListRequest) error
+	DeletePriceList(ctx context.Context, req *entity.DeletePriceListRequest) error
+	GetPrices(ctx context.Context, req *entity.GetPricesRequest) (entity.GetPricesResponse, error)
+	CreatePrice(ctx context.Context, req *entity.CreatePriceRequest) (entity.Price, error)
+	UpdatePrice(ctx context.Context, req *entity.UpdatePriceRequest) (entity.Price, error)
+	DeletePrice(ctx context.Context, req *entity.DeletePriceRequest) error
+	// UploadPrices diffs the rows with the prices of the draft, the changes are
+	// saved only when the request commits.
+	UploadPrices(ctx context.Context, req *entity.UploadPricesRequest, rows []entity.PriceRow) (entity.PriceDiff, error)
+}
+
+type TradeInService interface {
+	GetPriceLists(ctx context.Context, req *entity.GetPriceListsRequest) (entity.GetPriceListsResponse, error)
+	GetPriceList(ctx context.Context, req *entity.GetPriceListRequest) (entity.PriceList, error)
+	CreatePriceList(ctx context.Context, req *entity.CreatePriceListRequest) (entity.PriceList, error)
+	UpdatePriceList(ctx context.Context, req *entity.UpdatePriceListRequest) (entity.PriceList, error)
+	PublishPriceList(ctx context.Context, req *entity.PublishPriceListRequest) (entity.PriceList, error)
+	DeletePriceList(ctx context.Context, req *entity.DeletePriceListRequest) error
+	GetPrices(ctx context.Context, req *entity.GetPricesRequest) (entity.GetPricesResponse, error)
+	CreatePrice(ctx context.Context, req *entity.CreatePriceRequest) (entity.Price, error)
+	UpdatePrice(ctx context.Context, req *entity.UpdatePriceRequest) (entity.Price, error)
+	DeletePrice(ctx context.Context, req *entity.DeletePriceRequest) error
+	UploadPrices(ctx context.Context, req *entity.UploadPricesRequest) (entity.PriceDiff, error)
+}
@@ -0,0 +1,538 @@
+package repository
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/tradein/entity"
+	"codebase-app/internal/module/tradein/ports"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/period"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.TradeInRepository = &tradeInRepository{}
+
+const pqUniqueViolation = "23505"
+
+// priceListStatus is the status of the price list pl.
+const priceListStatus = `
+	CASE
+		WHEN pl.published_at IS NULL THEN '` + entity.StatusDraft + `'
+		WHEN pl.id = ` + entity.CurrentPriceList + ` THEN '` + entity.StatusActive + `'
+		WHEN pl.effective_from > ` + period.Today + ` THEN '` + entity.StatusScheduled + `'
+		ELSE '` + entity.StatusSuperseded + `'
+	END
+`
+
+type tradeInRepository struct {
+	db *sqlx.DB
+}
+
+func NewTradeInRepository() *tradeInRepository {
+	return &tradeInRepository{
+		db: adapter.Adapters.DigihubPostgres,
+	}
+}
+
+func (r *tradeInRepository) GetPriceLists(ctx context.Context, req *entity.GetPriceListsRequest) (entity.GetPriceListsResponse, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.PriceList
+	}
+
+	var (
+		data = make([]dao, 0)
+		args = make([]any, 0, 3)
+		res  = entity.GetPriceListsResponse{}
+	)
+	res.Items = make([]entity.PriceList, 0)
+
+	query := `
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			pl.*
+		FROM (
+			SELECT
+				pl.id,
+				pl.name,
+				pl.effective_from,
+				` + priceListStatus + ` AS status,
+				(SELECT COUNT(*) FROM trade_in_trends t WHERE t.price_list_id = pl.id) AS total_prices,
+				pl.created_by,
+				u.name AS created_by_name,
+				pl.published_at,
+				pl.created_at,
+				pl.updated_at
+			FROM trade_in_price_lists pl
+			LEFT JOIN
+				users u ON u.id = pl.created_by
+		) pl
+		WHERE 1 = 1
+	`
+
+	if req.Status != "" {
+		query += ` AND pl.status = ?`
+		args = append(args, req.Status)
+	}
+
+	query += ` ORDER BY pl.effective_from DESC, pl.created_at DESC LIMIT ? OFFSET ?`
+	args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetPriceLists - failed to get price lists")
+		return res, err
+	}
+
+	for _, d := range data {
+		res.Items = append(res.Items, d.PriceList)
+	}
+
+	if len(res.Items) > 0 {
+		res.Meta.TotalData = data[0].TotalData
+	}
+
+	res.Meta.CountTotalPage(req.Page, req.Paginate, res.Meta.TotalData)
+	return res, nil
+}
+
+func (r *tradeInRepository) GetPriceList(ctx context.Context, req *entity.GetPriceListRequest) (entity.PriceList, error) {
+	var res entity.PriceList
+
+	query := `
+		SELECT
+			pl.id,
+			pl.name,
+			pl.effective_from,
+			` + priceListStatus + ` AS status,
+			(SELECT COUNT(*) FROM trade_in_trends t WHERE t.price_list_id = pl.id) AS total_prices,
+			pl.created_by,
+			u.name AS created_by_name,
+			pl.published_at,
+			pl.created_at,
+			pl.updated_at
+		FROM trade_in_price_lists pl
+		LEFT JOIN
+			users u ON u.id = pl.created_by
+		WHERE pl.id = ?
+	`
+	if err := r.db.GetContext(ctx, &res, r.db.Rebind(query), req.Id); err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn().Any("payload", req).Msg("repo::GetPriceList - price list not found")
+			return res, errmsg.NewCustomErrors(404, errmsg.WithMessage("Daftar harga tidak ditemukan"))
+		}
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetPriceList - failed to get price list")
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r *tradeInRepository) CreatePriceList(ctx context.Context, req *entity.CreatePriceListRequest) (id string, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::CreatePriceList - failed to begin transaction")
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::CreatePriceList - failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::CreatePriceList - failed to commit transaction")
+		}
+	}()
+
+	id = ulid.Make().String()
+
+	query := `
+		INSERT INTO trade_in_price_lists (id, name, effective_from, created_by)
+		VALUES (?, ?, ?, ?)
+	`
+	if _, err = tx.ExecContext(ctx, r.db.Rebind(query), id, req.Name, req.EffectiveFrom, req.UserId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::CreatePriceList - failed to create price list")
+		return "", err
+	}
+
+	if req.Empty {
+		return id, nil
+	}
+
+	// without copy_from_id the prices in effect today are copied
+	query = `
+		INSERT INTO trade_in_trends (price_list_id, brand, model, type, year, min_purchase, max_purchase)
+		SELECT ?, t.brand, t.model, t.type, t.year, t.min_purchase, t.max_purchase
+		FROM trade_in_trends t
+		WHERE t.price_list_id = COALESCE(CAST(? AS CHAR(26)), ` + entity.CurrentPriceList + `)
+	`
+	if _, err = tx.ExecContext(ctx, r.db.Rebind(query), id, req.CopyFromId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::CreatePriceList - failed to copy prices")
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (r *tradeInRepository) UpdatePriceList(ctx context.Context, req *entity.UpdatePriceListRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdatePriceList - failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::UpdatePriceList - failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::UpdatePriceList - failed to commit transaction")
+		}
+	}()
+
+	if err = r.lockDraft(ctx, tx, req.Id); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE trade_in_price_lists
+		SET
+			name = COALESCE(?, name),
+			effective_from = COALESCE(CAST(? AS DATE), effective_from),
+			updated_at = NOW()
+		WHERE id = ?
+	`
+	if _, err = tx.ExecContext(ctx, r.db.Rebind(query), req.Name, req.EffectiveFrom, req.Id); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdatePriceList - failed to update price list")
+		return err
+	}
+
+	return nil
+}
+
+func (r *tradeInRepository) PublishPriceList(ctx context.Context, req *entity.PublishPriceListRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::PublishPriceList - failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::PublishPriceList - failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::PublishPriceList - failed to commit transaction")
+		}
+	}()
+
+	if err = r.lockDraft(ctx, tx, req.Id); err != nil {
+		return err
+	}
+
+	var check struct {
+		Upcoming    bool `db:"upcoming"`
+		TotalPrices int  `db:"total_prices"`
+	}
+	query := `
+		SELECT
+			pl.effective_from >= ` + period.Today + ` AS upcoming,
+			(SELECT COUNT(*) FROM trade_in_trends t WHERE t.price_list_id = pl.id) AS total_prices
+		FROM trade_in_price_lists pl
+		WHERE pl.id = ?
+	`
+	if err = tx.GetContext(ctx, &check, r.db.Rebind(query), req.Id); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::PublishPriceList - failed to check price list")
+		return err
+	}
+
+	// a past date would change the price of the quotes already made
+	if !check.Upcoming {
+		err = errmsg.NewCustomErrors(400, errmsg.WithMessage("Tanggal berlaku tidak boleh sebelum hari ini"))
+		return err
+	}
+
+	if check.TotalPrices == 0 {
+		err = errmsg.NewCustomErrors(400, errmsg.WithMessage("Daftar harga belum memiliki harga"))
+		return err
+	}
+
+	query = `UPDATE trade_in_price_lists SET published_at = NOW(), updated_at = NOW() WHERE id = ?`
+	if _, err = tx.ExecContext(ctx, r.db.Rebind(query), req.Id); err != nil {
+		var errPq *pq.Error
+		if errors.As(err, &errPq) && errPq.Code == pqUniqueViolation {
+			log.Warn().Any("payload", req).Msg("repo::PublishPriceList - effective date already published")
+			err = errmsg.NewCustomErrors(409, errmsg.WithMessage("Sudah ada daftar harga yang berlaku pada tanggal tersebut"))
+			return err
+		}
+		log.Error().Err(err).Any("payload", req).Msg("repo::PublishPriceList - failed to publish price list")
+		return err
+	}
+
+	return nil
+}
+
+func (r *tradeInRepository) DeletePriceList(ctx context.Context, req *entity.DeletePriceListRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeletePriceList - failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::DeletePriceList - failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::DeletePriceList - failed to commit transaction")
+		}
+	}()
+
+	if err = r.lockDraft(ctx, tx, req.Id); err != nil {
+		return err
+	}
+
+	for _, q := range []string{
+		`DELETE FROM trade_in_trends WHERE price_list_id = ?`,
+		`DELETE FROM trade_in_price_lists WHERE id = ?`,
+	} {
+		if _, err = tx.ExecContext(ctx, r.db.Rebind(q), req.Id); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::DeletePriceList - failed to delete price list")
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *tradeInRepository) GetPrices(ctx context.Context, req *entity.GetPricesRequest) (entity.GetPricesResponse, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.Price
+	}
+
+	var (
+		data = make([]dao, 0)
+		args = make([]any, 0, 5)
+		res  = entity.GetPricesResponse{}
+	)
+	res.Items = make([]entity.Price, 0)
+
+	query := `
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			t.id,
+			t.brand,
+			t.model,
+			t.type,
+			t.year,
+			t.min_purchase,
+			t.max_purchase,
+			t.updated_at
+		FROM trade_in_trends t
+		WHERE t.price_list_id = ?
+	`
+	args = append(args, req.PriceListId)
+
+	if req.Brand != "" {
+		query += ` AND t.brand = ?`
+		args = append(args, req.Brand)
+	}
+
+	if req.Search != "" {
+		query += ` AND CONCAT_WS(' ', t.brand, t.model, t.type) ILIKE ?`
+		args = append(args, "%"+req.Search+"%")
+	}
+
+	query += ` ORDER BY t.brand, t.model, t.type, t.year DESC LIMIT ? OFFSET ?`
+	args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetPrices - failed to get prices")
+		return res, err
+	}
+
+	for _, d := range data {
+		res.Items = append(res.Items, d.Price)
+	}
+
+	if len(res.Items) > 0 {
+		res.Meta.TotalData = data[0].TotalData
+	}
+
+	res.Meta.CountTotalPage(req.Page, req.Paginate, res.Meta.TotalData)
+	return res, nil
+}
+
+func (r *tradeInRepository) CreatePrice(ctx context.Context, req *entity.CreatePriceRequest) (res entity.Price, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::CreatePrice - failed to begin transaction")
+		return res, err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::CreatePrice - failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::CreatePrice - failed to commit transaction")
+		}
+	}()
+
+	if err = r.lockDraft(ctx, tx, req.PriceListId); err != nil {
+		return res, err
+	}
+
+	query := `
+		INSERT INTO trade_in_trends (price_list_id, brand, model, type, year, min_purchase, max_purchase)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id, brand, model, type, year, min_purchase, max_purchase, updated_at
+	`
+	err = tx.GetContext(ctx, &res, r.db.Rebind(query),
+		req.PriceListId, req.Brand, req.Model, req.Type, req.Year, req.MinPurchase, req.MaxPurchase)
+	if err != nil {
+		err = r.priceError(err, "repo::CreatePrice", req)
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r *tradeInRepository) UpdatePrice(ctx context.Context, req *entity.UpdatePriceRequest) (res entity.Price, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdatePrice - failed to begin transaction")
+		return res, err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::UpdatePrice - failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::UpdatePrice - failed to commit transaction")
+		}
+	}()
+
+	if err = r.lockDraft(ctx, tx, req.PriceListId); err != nil {
+		return res, err
+	}
+
+	query := `
+		UPDATE trade_in_trends
+		SET
+			brand = ?,
+			model = ?,
+			type = ?,
+			year = ?,
+			min_purchase = ?,
+			max_purchase = ?,
+			updated_at = NOW()
+		WHERE id = ? AND price_list_id = ?
+		RETURNING id, brand, model, type, year, min_purchase, max_purchase, updated_at
+	`
+	err = tx.GetContext(ctx, &res, r.db.Rebind(query),
+		req.Brand, req.Model, req.Type, req.Year, req.MinPurchase, req.MaxPurchase, req.Id, req.PriceListId)
+	if err != nil {
+		err = r.priceError(err, "repo::UpdatePrice", req)
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r *tradeInRepository) DeletePrice(ctx context.Context, req *entity.DeletePriceRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeletePrice - failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::DeletePrice - failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::DeletePrice - failed to commit transaction")
+		}
+	}()
+
+	if err = r.lockDraft(ctx, tx, req.PriceListId); err != nil {
+		return err
+	}
+
+	var id string
+	query := `DELETE FROM trade_in_trends WHERE id = ? AND price_list_id = ? RETURNING id`
+	if err = tx.GetContext(ctx, &id, r.db.Rebind(query), req.Id, req.PriceListId); err != nil {
+		err = r.priceError(err, "repo::DeletePrice", req)
+		return err
+	}
+
+	return nil
+}
+
+// lockDraft locks the price list for the rest of the transaction, a published
+// list can not be changed.
+func (r *tradeInRepository) lockDraft(ctx context.Context, tx *sqlx.Tx, id string) error {
+	var published bool
+	query := `SELECT published_at IS NOT NULL FROM trade_in_price_lists WHERE id = ? FOR UPDATE`
+	if err := tx.GetContext(ctx, &published, r.db.Rebind(query), id); err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn().Str("id", id).Msg("repo::lockDraft - price list not found")
+			return errmsg.NewCustomErrors(404, errmsg.WithMessage("Daftar harga tidak ditemukan"))
+		}
+		log.Error().Err(err).Str("id", id).Msg("repo::lockDraft - failed to lock price list")
+		return err
+	}
+
+	if published {
+		log.Warn().Str("id", id).Msg("repo::lockDraft - price list already published")
+		return errmsg.NewCustomErrors(400, errmsg.WithMessage("Daftar harga yang sudah dipublikasikan tidak dapat diubah"))
+	}
+
+	return nil
+}
+
+// priceError maps the error of a query on a single price.
+func (r *tradeInRepository) priceError(err error, fn string, req any) error {
+	if err == sql.ErrNoRows {
+		log.Warn().Any("payload", req).Msg(fn + " - price not found")
+		return errmsg.NewCustomErrors(404, errmsg.WithMessage("Harga tidak ditemukan"))
+	}
+
+	var errPq *pq.Error
+	if errors.As(err, &errPq) && errPq.Code == pqUniqueViolation {
+		log.Warn().Any("payload", req).Msg(fn + " - price already exists")
+		return errmsg.NewCustomErrors(409, errmsg.WithMessage("Harga untuk brand, model, type dan year tersebut sudah ada"))
+	}
+
+	log.Error().Err(err).Any("payload", req).Msg(fn + " - failed to save price")
+	return err
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"codebase-app/internal/module/tradein/entity"
+	"context"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// uploadBatchSize is the number of prices saved by one statement.
+const uploadBatchSize = 500
+
+func (r *tradeInRepository) UploadPrices(ctx context.Context, req *entity.UploadPricesRequest, rows []entity.PriceRow) (diff entity.PriceDiff, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Str("id", req.PriceListId).Msg("repo::UploadPrices - failed to begin transaction")
+		return diff, err
+	}
+	defer func() {
+		// the preview never saves
+		if err != nil || !req.Commit {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Str("id", req.PriceListId).Msg("repo::UploadPrices - failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Str("id", req.PriceListId).Msg("repo::UploadPrices - failed to commit transaction")
+		}
+	}()
+
+	if err = r.lockDraft(ctx, tx, req.PriceListId); err != nil {
+		return diff, err
+	}
+
+	current := make([]entity.Price, 0)
+	query := `
+		SELECT id, brand, model, type, year, min_purchase, max_purchase, updated_at
+		FROM trade_in_trends
+		WHERE price_list_id = ?
+	`
+	if err = tx.SelectContext(ctx, &current, r.db.Rebind(query), req.PriceListId); err != nil {
+		log.Error().Err(err).Str("id", req.PriceListId).Msg("repo::UploadPrices - failed to get prices")
+		return diff, err
+	}
+
+	diff = entity.DiffPrices(current, rows, req.Mode)
+	if !req.Commit {
+		return diff, nil
+	}
+
+	upserts := make([]entity.PriceRow, 0, len(diff.Added)+len(diff.Changed))
+	upserts = append(upserts, diff.Added...)
+	for _, c := range diff.Changed {
+		upserts = append(upserts, c.PriceRow)
+	}
+
+	for start := 0; start < len(upserts); start += uploadBatchSize {
+		batch := upserts[start:min(start+uploadBatchSize, len(upserts))]
+
+		var (
+			values = make([]string, 0, len(batch))
+			args   = make([]any, 0, len(batch)*7)
+		)
+		for _, p := range batch {
+			values = append(values, `(?, ?, ?, ?, ?, ?, ?)`)
+			args = append(args, req.PriceListId, p.Brand, p.Model, p.Type, p.Year, p.MinPurchase, p.MaxPurchase)
+		}
+
+		query = `
+			INSERT INTO trade_in_trends (price_list_id, brand, model, type, year, min_purchase, max_purchase)
+			VALUES ` + strings.Join(values, ", ") + `
+			ON CONFLICT (price_list_id, brand, model, type, year) DO UPDATE
+			SET
+				min_purchase = EXCLUDED.min_purchase,
+				max_purchase = EXCLUDED.max_purchase,
+				updated_at = NOW()
+		`
+		if _, err = tx.ExecContext(ctx, r.db.Rebind(query), args...); err != nil {
+			log.Error().Err(err).Str("id", req.PriceListId).Msg("repo::UploadPrices - failed to save prices")
+			return diff, err
+		}
+	}
+
+	if len(diff.Removed) > 0 {
+		ids := make([]string, 0, len(diff.Removed))
+		for _, p := range diff.Removed {
+			ids = append(ids, p.Id)
+		}
+
+		query = `DELETE FROM trade_in_trends WHERE price_list_id = ? AND id = ANY(CAST(? AS UUID[]))`
+		if _, err = tx.ExecContext(ctx, r.db.Rebind(query), req.PriceListId, pq.Array(ids)); err != nil {
+			log.Error().Err(err).Str("id", req.PriceListId).Msg("repo::UploadPrices - failed to remove prices")
+			return diff, err
+		}
+	}
+
+	query = `UPDATE trade_in_price_lists SET updated_at = NOW() WHERE id = ?`
+	if _, err = tx.ExecContext(ctx, r.db.Rebind(query), req.PriceListId); err != nil {
+		log.Error().Err(err).Str("id", req.PriceListId).Msg("repo::UploadPrices - failed to update price list")
+		return diff, err
+	}
+
+	diff.Committed = true
+	return diff, nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"bytes"
+	"codebase-app/internal/module/tradein/entity"
+	"codebase-app/internal/module/tradein/ports"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/xuri/excelize/v2"
+)
+
+var _ ports.TradeInService = &tradeInService{}
+
+type tradeInService struct {
+	repo ports.TradeInRepository
+}
+
+func NewTradeInService(repo ports.TradeInRepository) *tradeInService {
+	return &tradeInService{
+		repo: repo,
+	}
+}
+
+func (s *tradeInService) GetPriceLists(ctx context.Context, req *entity.GetPriceListsRequest) (entity.GetPriceListsResponse, error) {
+	return s.repo.GetPriceLists(ctx, req)
+}
+
+func (s *tradeInService) GetPriceList(ctx context.Context, req *entity.GetPriceListRequest) (entity.PriceList, error) {
+	return s.repo.GetPriceList(ctx, req)
+}
+
+func (s *tradeInService) CreatePriceList(ctx context.Context, req *entity.CreatePriceListRequest) (entity.PriceList, error) {
+	id, err := s.repo.CreatePriceList(ctx, req)
+	if err != nil {
+		return entity.PriceList{}, err
+	}
+
+	return s.repo.GetPriceList(ctx, &entity.GetPriceListRequest{Id: id})
+}
+
+func (s *tradeInService) UpdatePriceList(ctx context.Context, req *entity.UpdatePriceListRequest) (entity.PriceList, error) {
+	if err := s.repo.UpdatePriceList(ctx, req); err != nil {
+		return entity.PriceList{}, err
+	}
+
+	return s.repo.GetPriceList(ctx, &entity.GetPriceListRequest{Id: req.Id})
+}
+
+func (s *tradeInService) PublishPriceList(ctx context.Context, req *entity.PublishPriceListRequest) (entity.PriceList, error) {
+	if err := s.repo.PublishPriceList(ctx, req); err != nil {
+		return entity.PriceList{}, err
+	}
+
+	return s.repo.GetPriceList(ctx, &entity.GetPriceListRequest{Id: req.Id})
+}
+
+func (s *tradeInService) DeletePriceList(ctx context.Context, req *entity.DeletePriceListRequest) error {
+	return s.repo.DeletePriceList(ctx, req)
+}
+
+func (s *tradeInService) GetPrices(ctx context.Context, req *entity.GetPricesRequest) (entity.GetPricesResponse, error) {
+	// an unknown price list is a 404, not an empty page
+	if _, err := s.repo.GetPriceList(ctx, &entity.GetPriceListRequest{Id: req.PriceListId}); err != nil {
+		return entity.GetPricesResponse{}, err
+	}
+
+	return s.repo.GetPrices(ctx, req)
+}
+
+func (s *tradeInService) CreatePrice(ctx context.Context, req *entity.CreatePriceRequest) (entity.Price, error) {
+	return s.repo.CreatePrice(ctx, req)
+}
+
+func (s *tradeInService) UpdatePrice(ctx context.Context, req *entity.UpdatePriceRequest) (entity.Price, error) {
+	return s.repo.UpdatePrice(ctx, req)
+}
+
+func (s *tradeInService) DeletePrice(ctx context.Context, req *entity.DeletePriceRequest) error {
+	return s.repo.DeletePrice(ctx, req)
+}
+
+func (s *tradeInService) UploadPrices(ctx context.Context, req *entity.UploadPricesRequest) (entity.PriceDiff, error) {
+	data, err := base64.StdEncoding.DecodeString(req.File)
+	if err != nil {
+		log.Warn().Err(err).Msg("service::UploadPrices - Failed to decode base64 string")
+		return entity.PriceDiff{}, errmsg.NewCustomErrors(400).SetMessage("Gagal mendecode file base64")
+	}
+
+	var records [][]string
+	switch req.Format {
+	case entity.FormatXLSX:
+		records, err = readXLSX(data)
+	case entity.FormatCSV:
+		records, err = readCSV(data)
+	}
+	if err != nil {
+		return entity.PriceDiff{}, err
+	}
+
+	rows, err := entity.ParsePriceRows(records, time.Now())
+	if err != nil {
+		return entity.PriceDiff{}, err
+	}
+
+	return s.repo.UploadPrices(ctx, req, rows)
+}
+
+// readXLSX returns the rows of the first sheet of a workbook.
+func readXLSX(data []byte) ([][]string, error) {
+	xlsx, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		log.Warn().Err(err).Msg("service::UploadPrices - File is not a valid xlsx file")
+		return nil, errmsg.NewCustomErrors(400).SetMessage("File bukan file xlsx yang valid")
+	}
+	defer xlsx.Close()
+
+	sheets := xlsx.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errmsg.NewCustomErrors(400).SetMessage("File tidak memiliki sheet")
+	}
+
+	rows, err := xlsx.GetRows(sheets[0])
+	if err != nil {
+		log.Error().Err(err).Msg("service::UploadPrices - Failed to get rows from xlsx file")
+		return nil, errmsg.NewCustomErrors(400).SetMessage("Gagal membaca file xlsx")
+	}
+
+	return rows, nil
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	// spreadsheet exports often start with a byte order mark
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		log.Warn().Err(err).Msg("service::UploadPrices - File is not a valid csv file")
+		return nil, errmsg.NewCustomErrors(400).SetMessage("File bukan file csv yang valid")
+	}
+
+	return records, nil
+}
@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"time"
+)
+
+// The types of the soft deleted rows, each type is one table.
+const (
+	TypeWAC       = "wac"
+	TypeClient    = "client"
+	TypePromotion = "promotion"
+	TypeEmployee  = "employee"
+)
+
+// Types is the listing order of the types when no type is requested.
+var Types = []string{TypeWAC, TypeClient, TypePromotion, TypeEmployee}
+
+/*
+Trash - Start
+  - Lists the soft deleted WACs, clients, promotions and employees, the most
+    recently deleted first
+  - purge_at is when the purge job permanently deletes the row and its stored
+    files, after TRASH_RETENTION_DAYS
+  - A restored WAC restores its client too
+*/
+type GetTrashRequest struct {
+	Type     string `query:"type" validate:"omitempty,oneof=wac client promotion employee"`
+	Page     int    `query:"page" validate:"required"`
+	Paginate int    `query:"paginate" validate:"required,max=100"`
+}
+
+func (r *GetTrashRequest) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type GetTrashResponse struct {
+	Items []TrashItem `json:"items"`
+	Meta  types.Meta  `json:"meta"`
+}
+
+type TrashItem struct {
+	Id        string    `json:"id" db:"id"`
+	Type      string    `json:"type" db:"type"`
+	Name      string    `json:"name" db:"name"`
+	DeletedAt time.Time `json:"deleted_at" db:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at" db:"-"`
+}
+
+type RestoreRequest struct {
+	Type string `params:"type" validate:"oneof=wac client promotion employee"`
+	Id   string `params:"id" validate:"ulid"`
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"codebase-app/internal/adapter"
+	integstorage "codebase-app/internal/integration/localstorage"
+	m "codebase-app/internal/middleware"
+	"codebase-app/internal/module/trash/entity"
+	"codebase-app/internal/module/trash/ports"
+	"codebase-app/internal/module/trash/repository"
+	"codebase-app/internal/module/trash/service"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type trashHandler struct {
+	service ports.TrashService
+}
+
+func NewTrashHandler(storage integstorage.LocalStorageContract) *trashHandler {
+	var (
+		handler = new(trashHandler)
+		repo    = repository.NewTrashRepository()
+		service = service.NewTrashService(repo, storage)
+	)
+
+	handler.service = service
+
+	return handler
+}
+
+func (h *trashHandler) Register(router fiber.Router) {
+	trash := router.Group("/trash", m.AuthBearer, m.AuthRole([]string{"admin"}))
+
+	trash.Get("/", h.getTrash)
+	trash.Post("/:type/:id/restore", h.restore)
+}
+
+func (h *trashHandler) getTrash(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetTrashRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::getTrash - failed to parse request")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::getTrash - invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.GetTrash(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(res, ""))
+}
+
+func (h *trashHandler) restore(c *fiber.Ctx) error {
+	var (
+		req = new(entity.RestoreRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	req.Type = c.Params("type")
+	req.Id = c.Params("id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::restore - invalid request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := h.service.Restore(ctx, req); err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(nil, "Data berhasil dipulihkan"))
+}
@@ -0,0 +1,27 @@
+package ports
+
+import (
+	"codebase-app/internal/module/trash/entity"
+	"context"
+	"time"
+)
+
+type TrashRepository interface {
+	GetTrash(ctx context.Context, req *entity.GetTrashRequest) (entity.GetTrashResponse, error)
+	Restore(ctx context.Context, req *entity.RestoreRequest) error
+
+	// GetExpired returns the rows deleted before the given time.
+	GetExpired(ctx context.Context, before time.Time) ([]entity.TrashItem, error)
+	// Purge permanently deletes a row deleted before the given time and
+	// returns its stored files, purged is false when the row is kept.
+	Purge(ctx context.Context, item entity.TrashItem, before time.Time) (paths []string, purged bool, err error)
+}
+
+type TrashService interface {
+	GetTrash(ctx context.Context, req *entity.GetTrashRequest) (entity.GetTrashResponse, error)
+	Restore(ctx context.Context, req *entity.RestoreRequest) error
+
+	// PurgeExpired permanently deletes the rows past the retention period and
+	// returns how many were purged.
+	PurgeExpired(ctx context.Context) (int, error)
+}
@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/trash/entity"
+	"codebase-app/internal/module/trash/ports"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.TrashRepository = &trashRepository{}
+
+// pqForeignKeyViolation is returned when a purged row is still referenced.
+const pqForeignKeyViolation = "23503"
+
+// trashTables are the tables of the types.
+var trashTables = map[string]string{
+	entity.TypeWAC:       "walk_around_checks",
+	entity.TypeClient:    "clients",
+	entity.TypePromotion: "promotions",
+	entity.TypeEmployee:  "users",
+}
+
+// trashQueries select the soft deleted rows of a type as trash items.
+var trashQueries = map[string]string{
+	entity.TypeWAC: `
+		SELECT wac.id, 'wac' AS type, COALESCE(c.name, '') AS name, wac.deleted_at
+		FROM walk_around_checks wac
+		LEFT JOIN clients c ON c.id = wac.client_id
+		WHERE wac.deleted_at IS NOT NULL
+	`,
+	entity.TypeClient: `
+		SELECT id, 'client' AS type, name, deleted_at
+		FROM clients
+		WHERE deleted_at IS NOT NULL
+	`,
+	entity.TypePromotion: `
+		SELECT id, 'promotion' AS type, title AS name, deleted_at
+		FROM promotions
+		WHERE deleted_at IS NOT NULL
+	`,
+	entity.TypeEmployee: `
+		SELECT id, 'employee' AS type, name, deleted_at
+		FROM users
+		WHERE deleted_at IS NOT NULL
+	`,
+}
+
+type trashRepository struct {
+	db *sqlx.DB
+}
+
+func NewTrashRepository() *trashRepository {
+	return &trashRepository{
+		db: adapter.Adapters.DigihubPostgres,
+	}
+}
+
+// trashUnion is the union of the trash queries of the types, every type when
+// none is given.
+func trashUnion(types ...string) string {
+	if len(types) == 0 {
+		types = entity.Types
+	}
+
+	queries := make([]string, 0, len(types))
+	for _, t := range types {
+		queries = append(queries, trashQueries[t])
+	}
+
+	return strings.Join(queries, " UNION ALL ")
+}
+
+func (r *trashRepository) GetTrash(ctx context.Context, req *entity.GetTrashRequest) (entity.GetTrashResponse, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.TrashItem
+	}
+
+	var (
+		data = make([]dao, 0)
+		res  = entity.GetTrashResponse{}
+	)
+	res.Items = make([]entity.TrashItem, 0)
+
+	union := trashUnion()
+	if req.Type != "" {
+		union = trashUnion(req.Type)
+	}
+
+	query := `
+		SELECT
+			COUNT(*) OVER() AS total_data,
+			t.*
+		FROM (` + union + `) t
+		ORDER BY t.deleted_at DESC, t.id DESC
+		LIMIT ? OFFSET ?
+	`
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), req.Paginate, (req.Page-1)*req.Paginate)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetTrash - failed to get trash")
+		return res, err
+	}
+
+	for _, d := range data {
+		res.Items = append(res.Items, d.TrashItem)
+	}
+
+	if len(res.Items) > 0 {
+		res.Meta.TotalData = data[0].TotalData
+	}
+
+	res.Meta.CountTotalPage(req.Page, req.Paginate, res.Meta.TotalData)
+	return res, nil
+}
+
+// Restore takes a row out of the trash, a restored WAC restores its client.
+func (r *trashRepository) Restore(ctx context.Context, req *entity.RestoreRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::Restore - failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::Restore - failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::Restore - failed to commit transaction")
+		}
+	}()
+
+	query := `
+		UPDATE ` + trashTables[req.Type] + `
+		SET deleted_at = NULL, updated_at = NOW()
+		WHERE id = ? AND deleted_at IS NOT NULL
+	`
+	result, err := tx.ExecContext(ctx, r.db.Rebind(query), req.Id)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::Restore - failed to restore")
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::Restore - failed to get affected rows")
+		return err
+	}
+
+	if affected == 0 {
+		log.Warn().Any("payload", req).Msg("repo::Restore - not found in trash")
+		err = errmsg.NewCustomErrors(404, errmsg.WithMessage("Data tidak ditemukan di tempat sampah"))
+		return err
+	}
+
+	if req.Type == entity.TypeWAC {
+		query = `
+			UPDATE clients
+			SET deleted_at = NULL, updated_at = NOW()
+			WHERE id = (SELECT client_id FROM walk_around_checks WHERE id = ?) AND deleted_at IS NOT NULL
+		`
+		if _, err = tx.ExecContext(ctx, r.db.Rebind(query), req.Id); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::Restore - failed to restore client")
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *trashRepository) GetExpired(ctx context.Context, before time.Time) ([]entity.TrashItem, error) {
+	var data = make([]entity.TrashItem, 0)
+
+	query := `
+		SELECT t.*
+		FROM (` + trashUnion() + `) t
+		WHERE t.deleted_at < ?
+		ORDER BY t.deleted_at ASC
+	`
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), before); err != nil {
+		log.Error().Err(err).Time("before", before).Msg("repo::GetExpired - failed to get expired trash")
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Purge deletes one row in its own transaction so a kept row never blocks the
+// others, a client is kept while a WAC or a vehicle with a WAC refers to it
+// and an employee while any row refers to it.
+func (r *trashRepository) Purge(ctx context.Context, item entity.TrashItem, before time.Time) (paths []string, purged bool, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("item", item).Msg("repo::Purge - failed to begin transaction")
+		return nil, false, err
+	}
+	defer func() {
+		if err != nil || !purged {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("item", item).Msg("repo::Purge - failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Any("item", item).Msg("repo::Purge - failed to commit transaction")
+			paths, purged = nil, false
+		}
+	}()
+
+	switch item.Type {
+	case entity.TypeWAC:
+		paths, purged, err = r.purgeWAC(ctx, tx, item.Id, before)
+	case entity.TypeClient:
+		purged, err = r.purgeClient(ctx, tx, item.Id, before)
+	case entity.TypePromotion:
+		paths, purged, err = r.purgeWithPath(ctx, tx, `
+			DELETE FROM promotions
+			WHERE id = ? AND deleted_at < ?
+			RETURNING path
+		`, item.Id, before)
+	case entity.TypeEmployee:
+		paths, purged, err = r.purgeWithPath(ctx, tx, `
+			DELETE FROM users
+			WHERE id = ? AND deleted_at < ?
+			RETURNING COALESCE(path, '')
+		`, item.Id, before)
+	}
+
+	var errPq *pq.Error
+	if errors.As(err, &errPq) && errPq.Code == pqForeignKeyViolation {
+		log.Info().Any("item", item).Msg("repo::Purge - still referenced, kept")
+		return nil, false, nil
+	}
+
+	if err != nil {
+		log.Error().Err(err).Any("item", item).Msg("repo::Purge - failed to purge")
+		return nil, false, err
+	}
+
+	return paths, purged, nil
+}
+
+// purgeWAC deletes the walk around check with its conditions, activities and
+// follow up logs, it returns the images of the conditions.
+func (r *trashRepository) purgeWAC(ctx context.Context, tx *sqlx.Tx, id string, before time.Time) ([]string, bool, error) {
+	var locked []string
+	query := `SELECT id FROM walk_around_checks WHERE id = ? AND deleted_at < ? FOR UPDATE`
+	if err := tx.SelectContext(ctx, &locked, r.db.Rebind(query), id, before); err != nil {
+		return nil, false, err
+	}
+
+	// restored or deleted again since it was listed
+	if len(locked) == 0 {
+		return nil, false, nil
+	}
+
+	var paths = make([]string, 0)
+	query = `
+		SELECT path
+		FROM walk_around_check_conditions
+		WHERE walk_around_check_id = ? AND COALESCE(path, '') <> ''
+	`
+	if err := tx.SelectContext(ctx, &paths, r.db.Rebind(query), id); err != nil {
+		return nil, false, err
+	}
+
+	queries := []string{
+		`UPDATE walk_around_checks SET follow_up_wac_id = NULL WHERE follow_up_wac_id = ?`,
+		`DELETE FROM wac_activities WHERE wac_id = ?`,
+		`DELETE FROM wac_follow_up_logs WHERE walk_around_check_id = ?`,
+		`DELETE FROM walk_around_check_conditions WHERE walk_around_check_id = ?`,
+		`DELETE FROM walk_around_checks WHERE id = ?`,
+	}
+
+	for _, q := range queries {
+		if _, err := tx.ExecContext(ctx, r.db.Rebind(q), id); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return paths, true, nil
+}
+
+// purgeClient deletes the client with its vehicles, a vehicle with a WAC
+// keeps the client.
+func (r *trashRepository) purgeClient(ctx context.Context, tx *sqlx.Tx, id string, before time.Time) (bool, error) {
+	query := `
+		DELETE FROM vehicles
+		WHERE client_id = ?
+			AND NOT EXISTS (SELECT 1 FROM walk_around_checks WHERE vehicle_id = vehicles.id)
+	`
+	if _, err := tx.ExecContext(ctx, r.db.Rebind(query), id); err != nil {
+		return false, err
+	}
+
+	return r.purgeRow(ctx, tx, `
+		DELETE FROM clients
+		WHERE id = ? AND deleted_at < ?
+			AND NOT EXISTS (SELECT 1 FROM walk_around_checks WHERE client_id = clients.id)
+			AND NOT EXISTS (SELECT 1 FROM vehicles WHERE client_id = clients.id)
+	`, id, before)
+}
+
+func (r *trashRepository) purgeRow(ctx context.Context, tx *sqlx.Tx, query, id string, before time.Time) (bool, error) {
+	result, err := tx.ExecContext(ctx, r.db.Rebind(query), id, before)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (r *trashRepository) purgeWithPath(ctx context.Context, tx *sqlx.Tx, query, id string, before time.Time) ([]string, bool, error) {
+	var paths = make([]string, 0)
+	if err := tx.SelectContext(ctx, &paths, r.db.Rebind(query), id, before); err != nil {
+		return nil, false, err
+	}
+
+	if len(paths) == 0 {
+		return nil, false, nil
+	}
+
+	if paths[0] == "" {
+		return nil, true, nil
+	}
+
+	return paths, true, nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"codebase-app/internal/module/trash/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+const testId = "01JAXW1T8D3KX6V9P0Q2R4S6T8"
+
+func newMockRepository(t *testing.T) (*trashRepository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return &trashRepository{db: sqlx.NewDb(db, "postgres")}, mock
+}
+
+func TestRestoreWACRestoresDeletedClient(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE walk_around_checks")).
+		WithArgs(testId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// the client of the WAC is still in the trash, it comes back with it
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE clients")).
+		WithArgs(testId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.Restore(context.Background(), &entity.RestoreRequest{Type: entity.TypeWAC, Id: testId})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreClientLeavesItsWACs(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE clients")).
+		WithArgs(testId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.Restore(context.Background(), &entity.RestoreRequest{Type: entity.TypeClient, Id: testId})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreNotInTrash(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE walk_around_checks")).
+		WithArgs(testId).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := repo.Restore(context.Background(), &entity.RestoreRequest{Type: entity.TypeWAC, Id: testId})
+
+	var customErr *errmsg.CustomError
+	if assert.ErrorAs(t, err, &customErr) {
+		assert.Equal(t, 404, customErr.Code)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetExpiredBeforeCutoff(t *testing.T) {
+	var (
+		repo, mock = newMockRepository(t)
+		before     = time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE t.deleted_at < $1")).
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "name", "deleted_at"}).
+			AddRow(testId, entity.TypeClient, "Budi", before.Add(-time.Hour)))
+
+	items, err := repo.GetExpired(context.Background(), before)
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeKeepsReferencedRow(t *testing.T) {
+	var (
+		repo, mock = newMockRepository(t)
+		before     = time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+		item       = entity.TrashItem{Id: testId, Type: entity.TypeEmployee}
+	)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("DELETE FROM users")).
+		WithArgs(testId, before).
+		WillReturnError(&pq.Error{Code: pqForeignKeyViolation})
+	mock.ExpectRollback()
+
+	paths, purged, err := repo.Purge(context.Background(), item, before)
+	assert.NoError(t, err)
+	assert.False(t, purged)
+	assert.Empty(t, paths)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"codebase-app/internal/infrastructure/config"
+	integstorage "codebase-app/internal/integration/localstorage"
+	"codebase-app/internal/module/trash/entity"
+	"codebase-app/internal/module/trash/ports"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.TrashService = &trashService{}
+
+type trashService struct {
+	repo    ports.TrashRepository
+	storage integstorage.LocalStorageContract
+}
+
+func NewTrashService(repo ports.TrashRepository, storage integstorage.LocalStorageContract) *trashService {
+	return &trashService{
+		repo:    repo,
+		storage: storage,
+	}
+}
+
+// retention is how long a deleted row stays in the trash.
+func retention() time.Duration {
+	return time.Duration(config.Envs.Trash.RetentionDays) * 24 * time.Hour
+}
+
+func (s *trashService) GetTrash(ctx context.Context, req *entity.GetTrashRequest) (entity.GetTrashResponse, error) {
+	res, err := s.repo.GetTrash(ctx, req)
+	if err != nil {
+		return res, err
+	}
+
+	for i := range res.Items {
+		res.Items[i].PurgeAt = res.Items[i].DeletedAt.Add(retention())
+	}
+
+	return res, nil
+}
+
+func (s *trashService) Restore(ctx context.Context, req *entity.RestoreRequest) error {
+	return s.repo.Restore(ctx, req)
+}
+
+func (s *trashService) PurgeExpired(ctx context.Context) (int, error) {
+	before := time.Now().Add(-retention())
+
+	items, err := s.repo.GetExpired(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+	for _, item := range items {
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+
+		paths, purged, err := s.repo.Purge(ctx, item, before)
+		if err != nil {
+			continue
+		}
+
+		if !purged {
+			continue
+		}
+
+		// the files go once the rows are gone, a failure only leaves an
+		// orphan file
+		for _, path := range paths {
+			if err := s.storage.Delete(path); err != nil {
+				log.Warn().Err(err).Str("path", path).Msg("service::PurgeExpired - failed to delete file")
+			}
+		}
+
+		total++
+	}
+
+	return total, nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"codebase-app/internal/infrastructure/config"
+	"codebase-app/internal/module/trash/entity"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRepo struct {
+	items  []entity.TrashItem
+	kept   map[string]bool
+	cutoff []time.Time
+	purged []string
+}
+
+func (r *fakeRepo) GetTrash(ctx context.Context, req *entity.GetTrashRequest) (entity.GetTrashResponse, error) {
+	return entity.GetTrashResponse{Items: r.items}, nil
+}
+
+func (r *fakeRepo) Restore(ctx context.Context, req *entity.RestoreRequest) error {
+	return nil
+}
+
+func (r *fakeRepo) GetExpired(ctx context.Context, before time.Time) ([]entity.TrashItem, error) {
+	r.cutoff = append(r.cutoff, before)
+
+	res := make([]entity.TrashItem, 0)
+	for _, item := range r.items {
+		if item.DeletedAt.Before(before) {
+			res = append(res, item)
+		}
+	}
+
+	return res, nil
+}
+
+func (r *fakeRepo) Purge(ctx context.Context, item entity.TrashItem, before time.Time) ([]string, bool, error) {
+	r.cutoff = append(r.cutoff, before)
+	if r.kept[item.Id] {
+		return nil, false, nil
+	}
+
+	r.purged = append(r.purged, item.Id)
+	return []string{"storage/" + item.Id + ".jpg"}, true, nil
+}
+
+type fakeStorage struct {
+	deleted []string
+}
+
+func (s *fakeStorage) Save(base64String, path string) (string, error) {
+	return path, nil
+}
+
+func (s *fakeStorage) Delete(fullpath string) error {
+	s.deleted = append(s.deleted, fullpath)
+	return nil
+}
+
+func TestPurgeExpiredCutoff(t *testing.T) {
+	config.Envs = &config.Config{}
+	config.Envs.Trash.RetentionDays = 30
+
+	var (
+		now  = time.Now()
+		repo = &fakeRepo{
+			items: []entity.TrashItem{
+				{Id: "old", DeletedAt: now.AddDate(0, 0, -31)},
+				{Id: "referenced", DeletedAt: now.AddDate(0, 0, -40)},
+				{Id: "recent", DeletedAt: now.AddDate(0, 0, -29)},
+			},
+			kept: map[string]bool{"referenced": true},
+		}
+		storage = &fakeStorage{}
+		s       = NewTrashService(repo, storage)
+	)
+
+	total, err := s.PurgeExpired(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, []string{"old"}, repo.purged)
+	assert.Equal(t, []string{"storage/old.jpg"}, storage.deleted)
+
+	// the listing and every purge use the same cutoff, retention days ago
+	for _, before := range repo.cutoff {
+		assert.Equal(t, repo.cutoff[0], before)
+	}
+	assert.WithinDuration(t, now.AddDate(0, 0, -30), repo.cutoff[0], time.Minute)
+}
+
+func TestGetTrashPurgeAt(t *testing.T) {
+	config.Envs = &config.Config{}
+	config.Envs.Trash.RetentionDays = 30
+
+	deletedAt := time.Date(2024, 10, 1, 8, 0, 0, 0, time.UTC)
+	s := NewTrashService(&fakeRepo{items: []entity.TrashItem{{Id: "a", DeletedAt: deletedAt}}}, &fakeStorage{})
+
+	res, err := s.GetTrash(context.Background(), &entity.GetTrashRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, deletedAt.AddDate(0, 0, 30), res.Items[0].PurgeAt)
+}
@@ -1,6 +1,11 @@
 package entity
 
-import "time"
+import (
+	"codebase-app/pkg/validator"
+	"time"
+
+	"github.com/LukaGiorgadze/gonull"
+)
 
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
@@ -34,3 +39,58 @@ type GetProfileResponse struct {
 	Branch  CommonResponse `json:"branch"`
 	Section CommonResponse `json:"section"`
 }
+
+type GoogleAuthResponse struct {
+	URL   string `json:"url"`
+	State string `json:"-"`
+}
+
+type GoogleCallbackRequest struct {
+	Code        string `query:"code" validate:"required"`
+	State       string `query:"state" validate:"required"`
+	CookieState string
+}
+
+type UpdateProfileRequest struct {
+	UserId string
+
+	WANum gonull.Nullable[string] `json:"whatsapp_number"`
+	Image gonull.Nullable[string] `json:"image"` // null removes the avatar
+
+	WANumVal string `validate:"omitempty,id_phone" prop:"whatsapp_number"`
+	ImageVal string `validate:"omitempty,base64" prop:"image"`
+
+	Path    *string
+	OldPath *string
+}
+
+func (r *UpdateProfileRequest) RemoveImage() {
+	r.Image = gonull.NewNullable("")
+	r.ImageVal = ""
+}
+
+func (r *UpdateProfileRequest) SetValues() {
+	if r.WANum.Val != "" {
+		r.WANum.Val = validator.FormatPhone(r.WANum.Val)
+	}
+
+	r.WANumVal = r.WANum.Val
+	r.ImageVal = r.Image.Val
+}
+
+// IsImageRemoved reports whether the client explicitly sent "image": null.
+func (r *UpdateProfileRequest) IsImageRemoved() bool {
+	return r.Image.Present && !r.Image.Valid
+}
+
+func (r *UpdateProfileRequest) IsImageReplaced() bool {
+	return r.Image.Present && r.Image.Valid
+}
+
+type UpdatePasswordRequest struct {
+	UserId string
+
+	CurrentPassword         string `json:"current_password" validate:"required"`
+	NewPassword             string `json:"new_password" validate:"required,strong_password,nefield=CurrentPassword"`
+	NewPasswordConfirmation string `json:"new_password_confirmation" validate:"required,eqfield=NewPassword"`
+}
@@ -2,6 +2,9 @@ package handler
 
 import (
 	"codebase-app/internal/adapter"
+	"codebase-app/internal/infrastructure/config"
+	integstorage "codebase-app/internal/integration/localstorage"
+	integoauth "codebase-app/internal/integration/oauth2google"
 	m "codebase-app/internal/middleware"
 	"codebase-app/internal/module/user/entity"
 	"codebase-app/internal/module/user/ports"
@@ -9,6 +12,7 @@ import (
 	"codebase-app/internal/module/user/service"
 	"codebase-app/pkg/errmsg"
 	"codebase-app/pkg/response"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog/log"
@@ -18,11 +22,13 @@ type userHandler struct {
 	service ports.UserService
 }
 
-func NewUserHandler() *userHandler {
+func NewUserHandler(storage integstorage.LocalStorageContract) *userHandler {
 	var (
 		handler = new(userHandler)
+		envs    = config.Envs.Oauth.Google
 		repo    = repository.NewUserRepository()
-		service = service.NewUserService(repo)
+		google  = integoauth.NewOauth2googleIntegration(envs.ClientId, envs.ClientSecret, envs.RedirectURL)
+		service = service.NewUserService(repo, google, storage)
 	)
 
 	handler.service = service
@@ -34,7 +40,11 @@ func (h *userHandler) Register(router fiber.Router) {
 	auth := router.Group("/authentications/login")
 
 	auth.Post("/", h.login)
+	router.Get("/authentications/google", h.googleRedirect)
+	router.Get("/authentications/google/callback", h.googleCallback)
 	router.Get("/users/profiles", m.AuthBearer, h.getProfile)
+	router.Patch("/users/profiles", m.AuthBearer, h.updateProfile)
+	router.Put("/users/profiles/password", m.AuthBearer, h.updatePassword)
 }
 
 func (h *userHandler) login(c *fiber.Ctx) error {
@@ -64,6 +74,69 @@ func (h *userHandler) login(c *fiber.Ctx) error {
 	return c.JSON(response.Success(res, ""))
 }
 
+const googleStateCookie = "oauth_google_state"
+
+func (h *userHandler) googleRedirect(c *fiber.Ctx) error {
+	var (
+		ctx = c.Context()
+	)
+
+	res, err := h.service.GetGoogleAuthURL(ctx)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     googleStateCookie,
+		Value:    res.State,
+		Path:     "/api/authentications/google",
+		Expires:  time.Now().Add(10 * time.Minute),
+		HTTPOnly: true,
+		Secure:   config.Envs.App.Environtment == "production",
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	return c.Redirect(res.URL, fiber.StatusTemporaryRedirect)
+}
+
+func (h *userHandler) googleCallback(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GoogleCallbackRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::googleCallback - query parser")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.CookieState = c.Cookies(googleStateCookie)
+
+	// the state is single use, drop it whatever the outcome is
+	c.Cookie(&fiber.Cookie{
+		Name:     googleStateCookie,
+		Path:     "/api/authentications/google",
+		Expires:  time.Now().Add(-time.Hour),
+		HTTPOnly: true,
+	})
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::googleCallback - validation")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.LoginWithGoogle(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(res, ""))
+}
+
 func (h *userHandler) getProfile(c *fiber.Ctx) error {
 	var (
 		req = new(entity.GetProfileRequest)
@@ -81,3 +154,65 @@ func (h *userHandler) getProfile(c *fiber.Ctx) error {
 
 	return c.JSON(response.Success(res, ""))
 }
+
+func (h *userHandler) updateProfile(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UpdateProfileRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = m.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::updateProfile - body parser")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserId = l.UserId
+	req.SetValues()
+
+	if err := v.Validate(req); err != nil {
+		req.RemoveImage()
+		log.Warn().Err(err).Any("payload", req).Msg("handler::updateProfile - validation")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.UpdateProfile(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(res, ""))
+}
+
+func (h *userHandler) updatePassword(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UpdatePasswordRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = m.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::updatePassword - body parser")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserId = l.UserId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::updatePassword - validation")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	res, err := h.service.UpdatePassword(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(res, "Password berhasil diubah, sesi lain telah dikeluarkan"))
+}
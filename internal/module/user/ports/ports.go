@@ -7,10 +7,17 @@ import (
 
 type UserRepository interface {
 	Login(ctx context.Context, req *entity.LoginRequest) (entity.LoginResponse, error)
+	LoginByEmail(ctx context.Context, email string) (entity.LoginResponse, error)
 	GetProfile(ctx context.Context, req *entity.GetProfileRequest) (entity.GetProfileResponse, error)
+	UpdateProfile(ctx context.Context, req *entity.UpdateProfileRequest) error
+	UpdatePassword(ctx context.Context, req *entity.UpdatePasswordRequest) (entity.LoginResponse, error)
 }
 
 type UserService interface {
 	Login(ctx context.Context, req *entity.LoginRequest) (entity.LoginResponse, error)
+	GetGoogleAuthURL(ctx context.Context) (entity.GoogleAuthResponse, error)
+	LoginWithGoogle(ctx context.Context, req *entity.GoogleCallbackRequest) (entity.LoginResponse, error)
 	GetProfile(ctx context.Context, req *entity.GetProfileRequest) (entity.GetProfileResponse, error)
+	UpdateProfile(ctx context.Context, req *entity.UpdateProfileRequest) (entity.GetProfileResponse, error)
+	UpdatePassword(ctx context.Context, req *entity.UpdatePasswordRequest) (entity.LoginResponse, error)
 }
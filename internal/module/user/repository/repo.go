@@ -29,59 +29,89 @@ func NewUserRepository() *userRepository {
 	}
 }
 
+type loginDao struct {
+	Id       string `db:"id"`
+	Email    string `db:"email"`
+	Name     string `db:"name"`
+	Password string `db:"password"`
+	Role     string `db:"role"`
+	// TokenVersion is written in the tokens, see middleware.AuthBearer
+	TokenVersion int `db:"token_version"`
+}
+
 func (r *userRepository) Login(ctx context.Context, req *entity.LoginRequest) (entity.LoginResponse, error) {
-	type dao struct {
-		Id       string `db:"id"`
-		Email    string `db:"email"`
-		Name     string `db:"name"`
-		Password string `db:"password"`
-		Role     string `db:"role"`
+	var res entity.LoginResponse
+
+	data, err := r.getLoginUser(ctx, req.Email)
+	if err != nil {
+		return res, err
 	}
 
-	var (
-		res  entity.LoginResponse
-		data dao
-	)
+	err = bcrypt.CompareHashAndPassword([]byte(data.Password), []byte(req.Password))
+	if err != nil {
+		log.Error().Err(err).Msg("repo::Login - invalid password")
+		return res, errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid Kredensial"))
+	}
+
+	return r.issueToken(data)
+}
+
+// LoginByEmail issues a token for an already registered user whose identity
+// has been verified by an external provider (e.g. Google).
+func (r *userRepository) LoginByEmail(ctx context.Context, email string) (entity.LoginResponse, error) {
+	var res entity.LoginResponse
+
+	data, err := r.getLoginUser(ctx, email)
+	if err != nil {
+		return res, err
+	}
+
+	return r.issueToken(data)
+}
+
+func (r *userRepository) getLoginUser(ctx context.Context, email string) (loginDao, error) {
+	var data loginDao
 
 	query := `
 		SELECT
-			u.id, u.email, u.name, u.password, r.name as role
+			u.id, u.email, u.name, u.password, u.token_version, r.name as role
 		FROM
 			users u
 		LEFT JOIN
 			roles r ON u.role_id = r.id
-		WHERE email = ?
+		WHERE LOWER(u.email) = LOWER(?)
+			AND u.deleted_at IS NULL
 	`
 
-	err := r.db.GetContext(ctx, &data, r.db.Rebind(query), req.Email)
+	err := r.db.GetContext(ctx, &data, r.db.Rebind(query), email)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			log.Error().Err(err).Msg("repo::Login - user not found")
-			return res, errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid Kredensial"))
+			log.Error().Err(err).Msg("repo::getLoginUser - user not found")
+			return data, errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid Kredensial"))
 		}
 
-		log.Error().Err(err).Msg("repo::Login - failed to get user data")
-		return res, err
+		log.Error().Err(err).Msg("repo::getLoginUser - failed to get user data")
+		return data, err
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(data.Password), []byte(req.Password))
-	if err != nil {
-		log.Error().Err(err).Msg("repo::Login - invalid password")
-		return res, errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid Kredensial"))
-	}
+	return data, nil
+}
 
+func (r *userRepository) issueToken(data loginDao) (entity.LoginResponse, error) {
 	var (
+		res       entity.LoginResponse
 		expiredAt = time.Now().Add(time.Hour * 12)
 		payload   = jwthandler.CostumClaimsPayload{
 			UserId:          data.Id,
 			Role:            data.Role,
+			TokenVersion:    data.TokenVersion,
 			TokenExpiration: expiredAt,
 		}
 	)
 
 	token, err := jwthandler.GenerateTokenString(payload)
 	if err != nil {
-		log.Error().Err(err).Msg("repo::Login - failed to generate token")
+		log.Error().Err(err).Msg("repo::issueToken - failed to generate token")
 		return res, errmsg.NewCustomErrors(500, errmsg.WithMessage("Internal Server Error"))
 	}
 
@@ -162,16 +192,7 @@ func (r *userRepository) GetProfile(ctx context.Context, req *entity.GetProfileR
 	// check if user has profile picture
 	// if yes, generate public url
 	if data.Path != nil {
-		var (
-			filePath = strings.Split(*data.Path, "/")
-			filename string
-		)
-
-		if len(filePath) > 0 {
-			filename = filePath[len(filePath)-1]
-		}
-
-		url := storage.GeneratePublicURL(filename)
+		url := storage.GeneratePublicURL(strings.TrimPrefix(*data.Path, "storage/public/"))
 		res.Image = &url
 	}
 
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"codebase-app/internal/module/user/entity"
+	"codebase-app/pkg"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+func (r *userRepository) UpdateProfile(ctx context.Context, req *entity.UpdateProfileRequest) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::UpdateProfile - failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query := `SELECT path FROM users WHERE id = ? AND deleted_at IS NULL FOR UPDATE`
+	err = tx.GetContext(ctx, &req.OldPath, r.db.Rebind(query), req.UserId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn().Err(err).Str("user_id", req.UserId).Msg("repo::UpdateProfile - user not found")
+			return errmsg.NewCustomErrors(404, errmsg.WithMessage("User not found"))
+		}
+		log.Error().Err(err).Str("user_id", req.UserId).Msg("repo::UpdateProfile - failed to get user")
+		return err
+	}
+
+	var (
+		sets = []string{"updated_at = NOW()"}
+		args = make([]any, 0)
+	)
+
+	if req.WANum.Present && req.WANum.Valid {
+		sets = append(sets, "whatsapp_number = ?")
+		args = append(args, req.WANum.Val)
+	}
+
+	if req.IsImageReplaced() || req.IsImageRemoved() {
+		sets = append(sets, "path = ?")
+		args = append(args, req.Path)
+	}
+
+	query = `UPDATE users SET ` + strings.Join(sets, ", ") + ` WHERE id = ?`
+	args = append(args, req.UserId)
+
+	_, err = tx.ExecContext(ctx, r.db.Rebind(query), args...)
+	if err != nil {
+		req.RemoveImage()
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateProfile - failed to update user")
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("repo::UpdateProfile - failed to commit transaction")
+		return err
+	}
+
+	return nil
+}
+
+// UpdatePassword changes the password and bumps the token version, which
+// revokes every token issued before. A fresh token is returned so the current
+// session survives.
+func (r *userRepository) UpdatePassword(ctx context.Context, req *entity.UpdatePasswordRequest) (entity.LoginResponse, error) {
+	var (
+		res  entity.LoginResponse
+		data loginDao
+	)
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::UpdatePassword - failed to begin transaction")
+		return res, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query := `
+		SELECT
+			u.id, u.email, u.name, u.password, u.token_version, r.name as role
+		FROM
+			users u
+		LEFT JOIN
+			roles r ON u.role_id = r.id
+		WHERE u.id = ?
+			AND u.deleted_at IS NULL
+		FOR UPDATE OF u
+	`
+
+	err = tx.GetContext(ctx, &data, r.db.Rebind(query), req.UserId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn().Err(err).Str("user_id", req.UserId).Msg("repo::UpdatePassword - user not found")
+			return res, errmsg.NewCustomErrors(404, errmsg.WithMessage("User not found"))
+		}
+		log.Error().Err(err).Str("user_id", req.UserId).Msg("repo::UpdatePassword - failed to get user")
+		return res, err
+	}
+
+	if !pkg.ComparePassword(data.Password, req.CurrentPassword) {
+		err = errmsg.NewCustomErrors(400).Add("current_password", "Password saat ini salah")
+		return res, err
+	}
+
+	hashed, err := pkg.HashPassword(req.NewPassword)
+	if err != nil {
+		log.Error().Err(err).Msg("repo::UpdatePassword - failed to hash password")
+		return res, err
+	}
+
+	query = `
+		UPDATE users
+		SET
+			password = ?,
+			password_changed_at = ?,
+			token_version = token_version + 1,
+			updated_at = NOW()
+		WHERE id = ?
+		RETURNING token_version
+	`
+
+	err = tx.GetContext(ctx, &data.TokenVersion, r.db.Rebind(query), hashed, time.Now().UTC(), req.UserId)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", req.UserId).Msg("repo::UpdatePassword - failed to update password")
+		return res, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("repo::UpdatePassword - failed to commit transaction")
+		return res, err
+	}
+
+	return r.issueToken(data)
+}
@@ -1,20 +1,35 @@
 package service
 
 import (
+	integstorage "codebase-app/internal/integration/localstorage"
+	integoauth "codebase-app/internal/integration/oauth2google"
 	"codebase-app/internal/module/user/entity"
 	"codebase-app/internal/module/user/ports"
+	"codebase-app/pkg"
+	"codebase-app/pkg/errmsg"
 	"context"
+	"crypto/subtle"
+
+	"github.com/rs/zerolog/log"
 )
 
 var _ ports.UserService = &userService{}
 
 type userService struct {
-	repo ports.UserRepository
+	repo    ports.UserRepository
+	google  integoauth.Oauth2googleContract
+	storage integstorage.LocalStorageContract
 }
 
-func NewUserService(repo ports.UserRepository) *userService {
+func NewUserService(
+	repo ports.UserRepository,
+	google integoauth.Oauth2googleContract,
+	storage integstorage.LocalStorageContract,
+) *userService {
 	return &userService{
-		repo: repo,
+		repo:    repo,
+		google:  google,
+		storage: storage,
 	}
 }
 
@@ -22,6 +37,89 @@ func (s *userService) Login(ctx context.Context, req *entity.LoginRequest) (enti
 	return s.repo.Login(ctx, req)
 }
 
+func (s *userService) GetGoogleAuthURL(ctx context.Context) (entity.GoogleAuthResponse, error) {
+	var res entity.GoogleAuthResponse
+
+	state, err := pkg.GenerateRandomToken(32)
+	if err != nil {
+		log.Error().Err(err).Msg("service::GetGoogleAuthURL - failed to generate state")
+		return res, errmsg.NewCustomErrors(500, errmsg.WithMessage("Internal Server Error"))
+	}
+
+	res.State = state
+	res.URL = s.google.GetUrl(state)
+
+	return res, nil
+}
+
+// LoginWithGoogle only signs in employees that already exist in users,
+// there is no self registration through Google.
+func (s *userService) LoginWithGoogle(ctx context.Context, req *entity.GoogleCallbackRequest) (entity.LoginResponse, error) {
+	var res entity.LoginResponse
+
+	if req.CookieState == "" || subtle.ConstantTimeCompare([]byte(req.State), []byte(req.CookieState)) != 1 {
+		log.Warn().Msg("service::LoginWithGoogle - state mismatch")
+		return res, errmsg.NewCustomErrors(400, errmsg.WithMessage("State tidak valid, silakan ulangi proses login"))
+	}
+
+	token, err := s.google.Exchange(ctx, req.Code)
+	if err != nil {
+		log.Warn().Err(err).Msg("service::LoginWithGoogle - failed to exchange code")
+		return res, errmsg.NewCustomErrors(400, errmsg.WithMessage("Kode otorisasi Google tidak valid"))
+	}
+
+	info, err := s.google.GetUserInfo(ctx, token)
+	if err != nil {
+		log.Error().Err(err).Msg("service::LoginWithGoogle - failed to get user info")
+		return res, errmsg.NewCustomErrors(502, errmsg.WithMessage("Gagal mengambil data akun Google"))
+	}
+
+	if !info.VerifiedEmail || info.Email == "" {
+		log.Warn().Str("email", info.Email).Msg("service::LoginWithGoogle - email not verified")
+		return res, errmsg.NewCustomErrors(403, errmsg.WithMessage("Email akun Google belum terverifikasi"))
+	}
+
+	res, err = s.repo.LoginByEmail(ctx, info.Email)
+	if err != nil {
+		if customErr, ok := err.(*errmsg.CustomError); ok && customErr.Code == 400 {
+			return res, errmsg.NewCustomErrors(403, errmsg.WithMessage("Akun Google tidak terdaftar sebagai karyawan"))
+		}
+		return res, err
+	}
+
+	return res, nil
+}
+
 func (s *userService) GetProfile(ctx context.Context, req *entity.GetProfileRequest) (entity.GetProfileResponse, error) {
 	return s.repo.GetProfile(ctx, req)
 }
+
+func (s *userService) UpdateProfile(ctx context.Context, req *entity.UpdateProfileRequest) (entity.GetProfileResponse, error) {
+	if req.IsImageReplaced() {
+		fullpath, err := s.storage.Save(req.Image.Val, "storage/public/avatars")
+		if err != nil {
+			return entity.GetProfileResponse{}, err
+		}
+		req.Path = &fullpath
+	}
+
+	err := s.repo.UpdateProfile(ctx, req)
+	if err != nil {
+		if req.Path != nil {
+			s.storage.Delete(*req.Path)
+		}
+		return entity.GetProfileResponse{}, err
+	}
+
+	if (req.IsImageReplaced() || req.IsImageRemoved()) && req.OldPath != nil {
+		if err := s.storage.Delete(*req.OldPath); err != nil {
+			log.Warn().Err(err).Str("path", *req.OldPath).Msg("service::UpdateProfile - failed to delete old avatar")
+		}
+	}
+
+	return s.repo.GetProfile(ctx, &entity.GetProfileRequest{UserId: req.UserId})
+}
+
+func (s *userService) UpdatePassword(ctx context.Context, req *entity.UpdatePasswordRequest) (entity.LoginResponse, error) {
+	return s.repo.UpdatePassword(ctx, req)
+}
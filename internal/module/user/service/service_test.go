@@ -0,0 +1,102 @@
+package service
+
+import (
+	integoauth "codebase-app/internal/integration/oauth2google"
+	oauthentity "codebase-app/internal/integration/oauth2google/entity"
+	"codebase-app/internal/module/user/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubUserRepository struct {
+	emails map[string]bool
+}
+
+func (r *stubUserRepository) Login(ctx context.Context, req *entity.LoginRequest) (entity.LoginResponse, error) {
+	return entity.LoginResponse{}, nil
+}
+
+func (r *stubUserRepository) LoginByEmail(ctx context.Context, email string) (entity.LoginResponse, error) {
+	if !r.emails[email] {
+		return entity.LoginResponse{}, errmsg.NewCustomErrors(400, errmsg.WithMessage("Invalid Kredensial"))
+	}
+
+	return entity.LoginResponse{Email: email, AccessToken: "token"}, nil
+}
+
+func (r *stubUserRepository) GetProfile(ctx context.Context, req *entity.GetProfileRequest) (entity.GetProfileResponse, error) {
+	return entity.GetProfileResponse{}, nil
+}
+
+func (r *stubUserRepository) UpdateProfile(ctx context.Context, req *entity.UpdateProfileRequest) error {
+	return nil
+}
+
+func (r *stubUserRepository) UpdatePassword(ctx context.Context, req *entity.UpdatePasswordRequest) (entity.LoginResponse, error) {
+	return entity.LoginResponse{}, nil
+}
+
+func newTestService() *userService {
+	repo := &stubUserRepository{emails: map[string]bool{"sa@digihub.test": true}}
+	google := integoauth.NewFakeOauth2googleIntegration(map[string]oauthentity.UserInfoResponse{
+		"code-employee":   {Email: "sa@digihub.test", VerifiedEmail: true},
+		"code-stranger":   {Email: "stranger@gmail.test", VerifiedEmail: true},
+		"code-unverified": {Email: "sa@digihub.test", VerifiedEmail: false},
+	})
+
+	return NewUserService(repo, google, nil)
+}
+
+func errCode(err error) int {
+	if customErr, ok := err.(*errmsg.CustomError); ok {
+		return customErr.Code
+	}
+
+	return 0
+}
+
+func TestGetGoogleAuthURL(t *testing.T) {
+	s := newTestService()
+
+	first, err := s.GetGoogleAuthURL(context.Background())
+	assert.NoError(t, err)
+	assert.NotEmpty(t, first.State)
+	assert.Contains(t, first.URL, first.State)
+
+	second, err := s.GetGoogleAuthURL(context.Background())
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.State, second.State)
+}
+
+func TestLoginWithGoogle(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      entity.GoogleCallbackRequest
+		wantCode int
+	}{
+		{"registered employee", entity.GoogleCallbackRequest{Code: "code-employee", State: "s", CookieState: "s"}, 0},
+		{"missing cookie state", entity.GoogleCallbackRequest{Code: "code-employee", State: "s"}, 400},
+		{"state mismatch", entity.GoogleCallbackRequest{Code: "code-employee", State: "s", CookieState: "x"}, 400},
+		{"invalid code", entity.GoogleCallbackRequest{Code: "nope", State: "s", CookieState: "s"}, 400},
+		{"unverified email", entity.GoogleCallbackRequest{Code: "code-unverified", State: "s", CookieState: "s"}, 403},
+		{"not an employee", entity.GoogleCallbackRequest{Code: "code-stranger", State: "s", CookieState: "s"}, 403},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService()
+
+			res, err := s.LoginWithGoogle(context.Background(), &tt.req)
+			if tt.wantCode == 0 {
+				assert.NoError(t, err)
+				assert.Equal(t, "sa@digihub.test", res.Email)
+				return
+			}
+
+			assert.Equal(t, tt.wantCode, errCode(err))
+		})
+	}
+}
@@ -21,7 +21,9 @@ type GetWACResponse struct {
 	ClientName           string       `json:"client_name"`
 	SAName               string       `json:"service_advisor_name"`
 	BranchName           string       `json:"branch_name"`
+	VehicleId            string       `json:"vehicle_id"`
 	VehicleLicenseNumber string       `json:"vehicle_license_number"`
+	Mileage              *int         `json:"mileage"`
 	WhatsappNumber       string       `json:"whatsapp_number"`
 	IsUsedCar            bool         `json:"is_used_car"`
 	IsOffered            bool         `json:"is_offered"`
@@ -31,6 +33,10 @@ type GetWACResponse struct {
 	CreatedAt            time.Time    `json:"created_at"`
 	VehicleType          Common       `json:"vehicle_type"`
 	VehicleConditions    []VCondition `json:"vehicle_conditions"`
+	// TradeInQuote is the quote of a used car, nil when it is not quoted
+	TradeInQuote *TradeInQuote `json:"trade_in_quote"`
+	// Promotion is the promotion attached to the offering, nil without one
+	Promotion *Common `json:"promotion"`
 }
 
 type VCondition struct {
@@ -1,15 +1,36 @@
 package entity
 
+import (
+	"codebase-app/pkg"
+	"codebase-app/pkg/validator"
+)
+
+// CreateWACRequest creates a WAC of the vehicle of the plate. A known plate
+// keeps its owner unless client_id names the new owner, a new plate belongs
+// to the client with the same name and phone or to a new client.
 type CreateWACRequest struct {
 	UserId string `validate:"required,ulid,exist=users.id"`
 
+	ClientId                  *string            `json:"client_id" validate:"omitempty,ulid,exist=clients.id"`
 	Name                      string             `json:"name" validate:"required"`
-	VehicleRegistrationNumber string             `json:"vehicle_registration_number" validate:"min=3,max=15"`
+	VehicleRegistrationNumber string             `json:"vehicle_registration_number" validate:"required,id_plate"`
 	VehicleTypeId             string             `json:"vehicle_type_id" validate:"ulid,exist=vehicle_types.id"`
-	WhatsAppNumber            string             `json:"whatsapp_number" validate:"required"`
+	WhatsAppNumber            string             `json:"whatsapp_number" validate:"required,id_phone"`
+	Vin                       *string            `json:"vin" validate:"omitempty,len=17,alphanum"`
+	Year                      *int               `json:"year" validate:"omitempty,min=1900,max=2100"`
+	Colour                    *string            `json:"colour" validate:"omitempty,max=50"`
+	Mileage                   *int               `json:"mileage" validate:"omitempty,min=0"`
 	VehicleConditions         []VehicleCondition `json:"vehicle_conditions" validate:"required,dive"`
 }
 
+// Normalize writes the name, plate and phone the way they are stored so the
+// client and the vehicle are matched on them.
+func (r *CreateWACRequest) Normalize() {
+	r.Name = pkg.NormalizeName(r.Name)
+	r.VehicleRegistrationNumber = validator.FormatPlate(r.VehicleRegistrationNumber)
+	r.WhatsAppNumber = validator.FormatPhone(r.WhatsAppNumber)
+}
+
 type VehicleCondition struct {
 	PotencyId        string  `json:"potency_id" validate:"ulid,exist=potencies.id"`
 	AreaId           string  `json:"area_id" validate:"ulid,exist=areas.id"`
@@ -0,0 +1,7 @@
+package entity
+
+// DeleteWACRequest moves a WAC to the trash, it can be restored until the
+// trash is purged.
+type DeleteWACRequest struct {
+	Id string `params:"id" validate:"ulid"`
+}
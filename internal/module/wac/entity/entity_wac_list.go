@@ -1,17 +1,61 @@
 package entity
 
 import (
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/period"
 	"codebase-app/pkg/types"
+	"encoding/base64"
+	"encoding/json"
 	"time"
 )
 
+const (
+	WACSortCreatedAt = "created_at"
+	WACSortUpdatedAt = "updated_at"
+)
+
+/*
+WAC List - Start
+  - Service advisors and technicians see the WACs they created or have a
+    condition assigned to, admins see every WAC
+  - Items are grouped per day of the sort column in the requested timezone,
+    groups and their items keep the sort order
+  - from and to filter the sort column
+  - Either page based (page, paginate) or cursor based (cursor, paginate),
+    the cursor is the next_cursor of the previous response. The cursor is only
+    given when sorted by created_at, which never changes, so a page never
+    skips nor repeats a WAC. updated_at changes while the list is read, sorted
+    by it the list is page based only
+*/
 type GetWACsRequest struct {
-	UserId string
+	UserId   string
+	UserRole string
 
 	Page     int    `query:"page" validate:"required"`
-	Paginate int    `query:"paginate" validate:"required"`
-	Query    string `query:"query" validate:"omitempty,min=3"`
-	Status   string `query:"status" validate:"omitempty,oneof=created offered wip completed"`
+	Paginate int    `query:"paginate" validate:"required,max=100"`
+	Cursor   string `query:"cursor" validate:"omitempty,base64rawurl"`
+
+	Query           string `query:"query" validate:"omitempty,min=3"`
+	Status          string `query:"status" validate:"omitempty,oneof=offered wip completed"`
+	From            string `query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To              string `query:"to" validate:"omitempty,datetime=2006-01-02"`
+	Timezone        string `query:"timezone" validate:"omitempty,timezone"`
+	BranchId        string `query:"branch_id" validate:"omitempty,ulid"`
+	PotencyId       string `query:"potency_id" validate:"omitempty,ulid"`
+	AreaId          string `query:"area_id" validate:"omitempty,ulid"`
+	AssignedUserId  string `query:"assigned_user_id" validate:"omitempty,ulid"`
+	IsUsedCar       *bool  `query:"is_used_car"`
+	IsNeedsFollowUp *bool  `query:"is_needs_follow_up"`
+
+	SortBy string `query:"sort_by" validate:"omitempty,oneof=created_at updated_at"`
+	Order  string `query:"order" validate:"omitempty,oneof=asc desc"`
+
+	Location *time.Location
+	// FromTime and ToTime are the bounds of the from and to days, ToTime is
+	// exclusive
+	FromTime *time.Time
+	ToTime   *time.Time
+	After    *WACCursor
 }
 
 func (r *GetWACsRequest) SetDefault() {
@@ -22,20 +66,143 @@ func (r *GetWACsRequest) SetDefault() {
 	if r.Paginate < 1 {
 		r.Paginate = 10
 	}
+
+	if r.Timezone == "" {
+		r.Timezone = period.DefaultTimezone
+	}
+
+	if r.SortBy == "" {
+		r.SortBy = WACSortCreatedAt
+	}
+
+	if r.Order == "" {
+		r.Order = "desc"
+	}
+}
+
+// Resolve parses the dates in the requested timezone and decodes the cursor,
+// a cursor only continues the created_at sort it was issued for.
+func (r *GetWACsRequest) Resolve() error {
+	errs := errmsg.NewCustomErrors(400)
+
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return errs.Add("timezone", "timezone tidak valid")
+	}
+	r.Location = loc
+
+	if r.From != "" {
+		from, _ := time.ParseInLocation("2006-01-02", r.From, loc)
+		r.FromTime = &from
+	}
+
+	if r.To != "" {
+		to, _ := time.ParseInLocation("2006-01-02", r.To, loc)
+		to = to.AddDate(0, 0, 1)
+		r.ToTime = &to
+	}
+
+	if r.FromTime != nil && r.ToTime != nil && !r.FromTime.Before(*r.ToTime) {
+		errs.Add("from", "from seharusnya tidak lebih besar dari to")
+	}
+
+	if r.Cursor != "" && r.SortBy != WACSortCreatedAt {
+		errs.Add("cursor", "cursor hanya tersedia untuk sort_by created_at")
+	} else if r.Cursor != "" {
+		cursor, err := DecodeWACCursor(r.Cursor)
+		if err != nil || cursor.SortBy != r.SortBy || cursor.Order != r.Order {
+			errs.Add("cursor", "cursor tidak valid untuk urutan ini")
+		} else {
+			r.After = &cursor
+		}
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+
+	return nil
+}
+
+// WACCursor is the position of the last returned WAC, the id breaks the ties
+// of WACs created at the same time.
+type WACCursor struct {
+	SortBy string    `json:"s"`
+	Order  string    `json:"o"`
+	Value  time.Time `json:"v"`
+	Id     string    `json:"i"`
+}
+
+func (c WACCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeWACCursor(s string) (WACCursor, error) {
+	var c WACCursor
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+
+	err = json.Unmarshal(b, &c)
+	return c, err
 }
 
 type GetWACsResponse struct {
-	Items map[string][]WacItem `json:"items"`
-	Meta  types.Meta           `json:"meta"`
+	Items []WACGroup `json:"items"`
+	Meta  types.Meta `json:"meta"`
+	// NextCursor is null on the last page
+	NextCursor *string `json:"next_cursor"`
+}
+
+// WACGroup holds the WACs of one day, a day may continue on the next page.
+type WACGroup struct {
+	Date  string    `json:"date"`
+	Items []WacItem `json:"items"`
 }
 
 type WacItem struct {
-	Id                  string    `json:"id" db:"id"`
-	ClientName          string    `json:"client_name" db:"client_name"`
-	TotalPotentialLeads int       `json:"total_potential_leads" db:"total_potential_leads"`
-	TotalLeads          int       `json:"total_leads" db:"total_leads"`
-	TotalLeadsCompleted int       `json:"total_leads_completed" db:"total_leads_completed"`
-	TotalFollowUps      int       `json:"total_follow_ups" db:"total_follow_ups"`
-	Status              string    `json:"status" db:"status"`
-	CreatedAt           time.Time `json:"created_at" db:"created_at"`
+	Id                   string    `json:"id" db:"id"`
+	ClientName           string    `json:"client_name" db:"client_name"`
+	VehicleLicenseNumber string    `json:"vehicle_license_number" db:"vehicle_license_number"`
+	BranchId             string    `json:"branch_id" db:"branch_id"`
+	UserId               string    `json:"user_id" db:"user_id"`
+	TotalPotentialLeads  int       `json:"total_potential_leads" db:"total_potential_leads"`
+	TotalLeads           int       `json:"total_leads" db:"total_leads"`
+	TotalLeadsCompleted  int       `json:"total_leads_completed" db:"total_leads_completed"`
+	TotalFollowUps       int       `json:"total_follow_ups" db:"total_follow_ups"`
+	Status               string    `json:"status" db:"status"`
+	IsUsedCar            bool      `json:"is_used_car" db:"is_used_car"`
+	IsNeedsFollowUp      bool      `json:"is_needs_follow_up" db:"is_needs_follow_up"`
+	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SortValue is the value of the sort column of the item.
+func (i WacItem) SortValue(sortBy string) time.Time {
+	if sortBy == WACSortUpdatedAt {
+		return i.UpdatedAt
+	}
+
+	return i.CreatedAt
+}
+
+// GroupWACs groups the sorted items per day of the sort column in loc.
+func GroupWACs(items []WacItem, sortBy string, loc *time.Location) []WACGroup {
+	groups := make([]WACGroup, 0)
+
+	for _, item := range items {
+		date := item.SortValue(sortBy).In(loc).Format("2006-01-02")
+
+		if n := len(groups); n > 0 && groups[n-1].Date == date {
+			groups[n-1].Items = append(groups[n-1].Items, item)
+			continue
+		}
+
+		groups = append(groups, WACGroup{Date: date, Items: []WacItem{item}})
+	}
+
+	return groups
 }
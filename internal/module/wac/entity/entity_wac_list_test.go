@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"codebase-app/pkg/period"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupWACs(t *testing.T) {
+	loc, _ := time.LoadLocation(period.DefaultTimezone)
+
+	items := []WacItem{
+		// 2024-10-16 01:00 in Makassar
+		{Id: "3", CreatedAt: time.Date(2024, 10, 15, 17, 0, 0, 0, time.UTC)},
+		{Id: "2", CreatedAt: time.Date(2024, 10, 15, 15, 0, 0, 0, time.UTC)},
+		{Id: "1", CreatedAt: time.Date(2024, 10, 15, 1, 0, 0, 0, time.UTC)},
+	}
+
+	groups := GroupWACs(items, WACSortCreatedAt, loc)
+
+	assert.Len(t, groups, 2)
+	assert.Equal(t, "2024-10-16", groups[0].Date)
+	assert.Equal(t, "3", groups[0].Items[0].Id)
+	assert.Equal(t, "2024-10-15", groups[1].Date)
+	assert.Len(t, groups[1].Items, 2)
+
+	assert.Empty(t, GroupWACs(nil, WACSortCreatedAt, loc))
+}
+
+func TestGetWACsRequestResolve(t *testing.T) {
+	cursor := WACCursor{
+		SortBy: WACSortCreatedAt,
+		Order:  "desc",
+		Value:  time.Date(2024, 10, 15, 1, 0, 0, 123456000, time.UTC),
+		Id:     "01J9ZQ4W2Y0000000000000000",
+	}
+
+	req := &GetWACsRequest{Cursor: cursor.Encode(), From: "2024-10-01", To: "2024-10-15"}
+	req.SetDefault()
+
+	assert.NoError(t, req.Resolve())
+	assert.True(t, cursor.Value.Equal(req.After.Value))
+	assert.Equal(t, cursor.Id, req.After.Id)
+	assert.Equal(t, "2024-10-16T00:00:00+08:00", req.ToTime.Format(time.RFC3339))
+
+	// a cursor of another sort is rejected
+	req = &GetWACsRequest{Cursor: cursor.Encode(), SortBy: WACSortUpdatedAt}
+	req.SetDefault()
+	assert.Error(t, req.Resolve())
+
+	// updated_at changes while the list is read, it is never cursor based
+	cursor.SortBy = WACSortUpdatedAt
+	req = &GetWACsRequest{Cursor: cursor.Encode(), SortBy: WACSortUpdatedAt}
+	req.SetDefault()
+	assert.Error(t, req.Resolve())
+
+	req = &GetWACsRequest{From: "2024-10-16", To: "2024-10-15"}
+	req.SetDefault()
+	assert.Error(t, req.Resolve())
+}
@@ -1,11 +1,40 @@
 package entity
 
+import (
+	"codebase-app/pkg/errmsg"
+	"time"
+)
+
 type OfferWACRequest struct {
 	UserId string `validate:"ulid"`
 
 	Id          string           `params:"id" validate:"ulid,exist=walk_around_checks.id"`
 	IsUsedCar   bool             `json:"is_used_car"`
 	VConditions []OfferCondition `json:"vehicle_conditions" validate:"omitempty,min=1,dive"`
+	// TradeIn is the quote of a used car, it is optional for the apps that
+	// do not quote yet
+	TradeIn *TradeInQuoteRequest `json:"trade_in" validate:"omitempty"`
+	// PromotionId is a running promotion relevant to the WAC, see GET
+	// /promotions?wac_id=
+	PromotionId *string `json:"promotion_id" validate:"omitempty,ulid"`
+}
+
+func (r *OfferWACRequest) Normalize() {
+	if r.TradeIn != nil {
+		r.TradeIn.Normalize()
+	}
+}
+
+func (r *OfferWACRequest) Validate(now time.Time) error {
+	if r.TradeIn == nil {
+		return nil
+	}
+
+	if !r.IsUsedCar {
+		return errmsg.NewCustomErrors(400).Add("trade_in", "trade in hanya untuk used car")
+	}
+
+	return r.TradeIn.Validate("trade_in.", now)
 }
 
 type OfferCondition struct {
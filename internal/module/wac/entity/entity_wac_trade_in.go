@@ -0,0 +1,171 @@
+package entity
+
+import (
+	"codebase-app/pkg"
+	"codebase-app/pkg/errmsg"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// TradeInQuoteValidDays is the validity of a quote without valid_until,
+	// TradeInQuoteMaxValidDays the longest one an advisor can give.
+	TradeInQuoteValidDays    = 7
+	TradeInQuoteMaxValidDays = 30
+
+	// A car is expected to run TradeInYearlyMileage km a year, every
+	// TradeInMileageStep km above it takes TradeInMileageStepPercent of the
+	// base price off, up to TradeInMaxMileagePercent.
+	TradeInYearlyMileage      = 15000
+	TradeInMileageStep        = 10000
+	TradeInMileageStepPercent = 1
+	TradeInMaxMileagePercent  = 20
+)
+
+/*
+Trade In Quote - Start
+  - The valuation of a used-car WAC, the price range of the brand, model, type
+    and year comes from the price list in effect and is kept with the quote
+  - The base price is the middle of the range, the mileage above the expected
+    one and the deductions for the damages of the condition photos are taken
+    off it to suggest a price, the offered price defaults to the suggested
+    one and can not be more than max_purchase
+  - The mileage defaults to the mileage of the WAC, a quote is valid for
+    TradeInQuoteValidDays days unless valid_until is given
+  - A WAC has one quote, quoting again replaces it
+*/
+type TradeInQuoteRequest struct {
+	Brand                string                       `json:"brand" validate:"required,max=255"`
+	Model                string                       `json:"model" validate:"required,max=255"`
+	Type                 string                       `json:"type" validate:"required,max=255"`
+	Year                 int                          `json:"year" validate:"required"`
+	Mileage              *int                         `json:"mileage" validate:"omitempty,min=0"`
+	ConditionAdjustments []TradeInConditionAdjustment `json:"condition_adjustments" validate:"omitempty,dive"`
+	OfferedPrice         *int                         `json:"offered_price" validate:"omitempty,min=0"`
+	ValidUntil           *string                      `json:"valid_until" validate:"omitempty,datetime=2006-01-02"`
+	Notes                *string                      `json:"notes" validate:"omitempty,max=255"`
+}
+
+type TradeInConditionAdjustment struct {
+	ConditionId string  `json:"condition_id" validate:"ulid"`
+	Deduction   int     `json:"deduction" validate:"min=0"`
+	Notes       *string `json:"notes" validate:"omitempty,max=255"`
+}
+
+// Normalize writes the brand, model and type the way the price lists store
+// them.
+func (r *TradeInQuoteRequest) Normalize() {
+	r.Brand = strings.ToUpper(pkg.NormalizeName(r.Brand))
+	r.Model = strings.ToUpper(pkg.NormalizeName(r.Model))
+	r.Type = strings.ToUpper(pkg.NormalizeName(r.Type))
+}
+
+// Validate checks valid_until against the date of now and that a condition is
+// adjusted once, field is the prefix of the reported fields.
+func (r *TradeInQuoteRequest) Validate(field string, now time.Time) error {
+	errs := errmsg.NewCustomErrors(400)
+
+	if r.ValidUntil != nil {
+		var (
+			today      = now.Format(time.DateOnly)
+			maxDate    = now.AddDate(0, 0, TradeInQuoteMaxValidDays).Format(time.DateOnly)
+			validUntil = *r.ValidUntil
+		)
+
+		// both are YYYY-MM-DD so they compare as strings
+		if validUntil < today || validUntil > maxDate {
+			errs.Add(field+"valid_until", fmt.Sprintf("valid until harus antara %s dan %s", today, maxDate))
+		}
+	}
+
+	seen := make(map[string]bool, len(r.ConditionAdjustments))
+	for i, a := range r.ConditionAdjustments {
+		if seen[a.ConditionId] {
+			errs.Add(fmt.Sprintf("%scondition_adjustments[%d].condition_id", field, i), "kondisi tidak boleh duplikat")
+		}
+		seen[a.ConditionId] = true
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+
+	return nil
+}
+
+// ValidUntilDate returns valid_until or the default validity from the date of
+// now.
+func (r *TradeInQuoteRequest) ValidUntilDate(now time.Time) string {
+	if r.ValidUntil != nil {
+		return *r.ValidUntil
+	}
+
+	return now.AddDate(0, 0, TradeInQuoteValidDays).Format(time.DateOnly)
+}
+
+// SaveTradeInQuoteRequest quotes a used-car WAC again, it is still in progress.
+type SaveTradeInQuoteRequest struct {
+	UserId string `validate:"ulid"`
+	Id     string `params:"id" validate:"ulid,exist=walk_around_checks.id"`
+
+	TradeInQuoteRequest
+}
+
+// TradeInPrice is the pricing of a quote.
+type TradeInPrice struct {
+	BasePrice           int `json:"base_price" db:"base_price"`
+	MileageAdjustment   int `json:"mileage_adjustment" db:"mileage_adjustment"`
+	ConditionAdjustment int `json:"condition_adjustment" db:"condition_adjustment"`
+	SuggestedPrice      int `json:"suggested_price" db:"suggested_price"`
+}
+
+// PriceTradeIn prices a car of the year with the price range, the adjustments
+// are negative. quotedAt is the time of the quote, it sets the age of the car.
+func PriceTradeIn(minPurchase, maxPurchase, year int, mileage *int, deductions []int, quotedAt time.Time) TradeInPrice {
+	p := TradeInPrice{BasePrice: (minPurchase + maxPurchase) / 2}
+
+	if mileage != nil {
+		age := max(quotedAt.Year()-year, 1)
+		if excess := *mileage - age*TradeInYearlyMileage; excess > 0 {
+			percent := min(excess/TradeInMileageStep*TradeInMileageStepPercent, TradeInMaxMileagePercent)
+			p.MileageAdjustment = -p.BasePrice * percent / 100
+		}
+	}
+
+	for _, d := range deductions {
+		p.ConditionAdjustment -= d
+	}
+
+	p.SuggestedPrice = max(p.BasePrice+p.MileageAdjustment+p.ConditionAdjustment, 0)
+	return p
+}
+
+type TradeInQuote struct {
+	Id           string  `json:"id" db:"id"`
+	PriceListId  string  `json:"price_list_id" db:"price_list_id"`
+	Brand        string  `json:"brand" db:"brand"`
+	Model        string  `json:"model" db:"model"`
+	Type         string  `json:"type" db:"type"`
+	Year         int     `json:"year" db:"year"`
+	Mileage      *int    `json:"mileage" db:"mileage"`
+	MinPurchase  int     `json:"min_purchase" db:"min_purchase"`
+	MaxPurchase  int     `json:"max_purchase" db:"max_purchase"`
+	OfferedPrice int     `json:"offered_price" db:"offered_price"`
+	ValidUntil   string  `json:"valid_until" db:"valid_until"`
+	IsExpired    bool    `json:"is_expired" db:"is_expired"`
+	Notes        *string `json:"notes" db:"notes"`
+	UserId       string  `json:"user_id" db:"user_id"`
+	UserName     string  `json:"user_name" db:"user_name"`
+	TradeInPrice
+
+	Adjustments []TradeInAdjustment `json:"condition_adjustments"`
+	CreatedAt   time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+type TradeInAdjustment struct {
+	ConditionId string  `json:"condition_id" db:"condition_id"`
+	Deduction   int     `json:"deduction" db:"deduction"`
+	Notes       *string `json:"notes" db:"notes"`
+}
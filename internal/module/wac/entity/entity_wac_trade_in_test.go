@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"codebase-app/pkg/errmsg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceTradeIn(t *testing.T) {
+	quotedAt := time.Date(2024, 10, 22, 10, 0, 0, 0, time.UTC)
+
+	// 5 years expect 75.000 km, 102.000 km is 2 steps above
+	mileage := 102000
+	p := PriceTradeIn(100_000_000, 120_000_000, 2019, &mileage, []int{1_500_000, 500_000}, quotedAt)
+	assert.Equal(t, 110_000_000, p.BasePrice)
+	assert.Equal(t, -2_200_000, p.MileageAdjustment)
+	assert.Equal(t, -2_000_000, p.ConditionAdjustment)
+	assert.Equal(t, 105_800_000, p.SuggestedPrice)
+
+	// the mileage deduction is capped
+	mileage = 1_000_000
+	p = PriceTradeIn(100, 100, 2024, &mileage, nil, quotedAt)
+	assert.Equal(t, -20, p.MileageAdjustment)
+
+	// without mileage nor damages the base price is suggested, never below zero
+	p = PriceTradeIn(100, 200, 2020, nil, nil, quotedAt)
+	assert.Equal(t, 150, p.SuggestedPrice)
+	p = PriceTradeIn(100, 200, 2020, nil, []int{500}, quotedAt)
+	assert.Equal(t, 0, p.SuggestedPrice)
+}
+
+func TestTradeInQuoteRequestValidate(t *testing.T) {
+	now := time.Date(2024, 10, 22, 10, 0, 0, 0, time.UTC)
+
+	req := TradeInQuoteRequest{}
+	assert.NoError(t, req.Validate("", now))
+	assert.Equal(t, "2024-10-29", req.ValidUntilDate(now))
+
+	past, far := "2024-10-21", "2024-11-22"
+	req.ValidUntil = &past
+	req.ConditionAdjustments = []TradeInConditionAdjustment{{ConditionId: "a"}, {ConditionId: "a"}}
+
+	err := req.Validate("trade_in.", now)
+	errs, ok := err.(*errmsg.CustomError)
+	assert.True(t, ok)
+	assert.Contains(t, errs.Errors, "trade_in.valid_until")
+	assert.Contains(t, errs.Errors, "trade_in.condition_adjustments[1].condition_id")
+
+	req.ValidUntil = &far
+	req.ConditionAdjustments = nil
+	assert.Error(t, req.Validate("", now))
+}
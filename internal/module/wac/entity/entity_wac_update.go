@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"codebase-app/pkg"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/validator"
+)
+
+// ActivityEdited is the wac_activities status of an amendment, the dashboards
+// only count the offered, wip and completed activities.
+const ActivityEdited = "edited"
+
+/*
+WAC Amendment - Start
+  - Only the creator may amend a WAC and only while it is not offered yet
+    (status offered)
+  - The header is the client (name and phone) and the vehicle (plate and
+    vehicle type) of the WAC, nil fields are left untouched
+  - The client is only amended when it has no other WAC, 409 otherwise, the
+    admins fix a shared client from the clients
+  - A plate of another vehicle of the client moves the WAC to that vehicle, a
+    plate of another client is refused with 409
+  - Conditions can be added, updated and removed, total_potential_leads is
+    recounted after every change
+  - A replaced image is deleted from the storage once the change is saved
+*/
+type UpdateWACRequest struct {
+	UserId string
+	Id     string `params:"id" validate:"ulid"`
+
+	Name                      *string `json:"name" validate:"omitempty,min=1,max=255"`
+	VehicleRegistrationNumber *string `json:"vehicle_registration_number" validate:"omitempty,id_plate"`
+	VehicleTypeId             *string `json:"vehicle_type_id" validate:"omitempty,ulid,exist=vehicle_types.id"`
+	WhatsAppNumber            *string `json:"whatsapp_number" validate:"omitempty,id_phone"`
+}
+
+func (r *UpdateWACRequest) Normalize() {
+	normalize := func(v *string, fn func(string) string) *string {
+		if v == nil {
+			return nil
+		}
+
+		n := fn(*v)
+		return &n
+	}
+
+	r.Name = normalize(r.Name, pkg.NormalizeName)
+	r.VehicleRegistrationNumber = normalize(r.VehicleRegistrationNumber, validator.FormatPlate)
+	r.WhatsAppNumber = normalize(r.WhatsAppNumber, validator.FormatPhone)
+}
+
+type AddWACConditionRequest struct {
+	UserId string
+	Id     string `params:"id" validate:"ulid"`
+
+	VehicleCondition
+}
+
+func (r *AddWACConditionRequest) RemoveBase64() {
+	r.Image = ""
+}
+
+type UpdateWACConditionRequest struct {
+	UserId      string
+	Id          string `params:"id" validate:"ulid"`
+	ConditionId string `params:"condition_id" validate:"ulid"`
+
+	PotencyId        *string `json:"potency_id" validate:"omitempty,ulid,exist=potencies.id"`
+	AreaId           *string `json:"area_id" validate:"omitempty,ulid,exist=areas.id"`
+	ServiceAdvisorId *string `json:"service_advisor_id" validate:"omitempty,ulid,exist=users.id"`
+	// Unassign removes the assigned service advisor
+	Unassign bool    `json:"unassign"`
+	Notes    *string `json:"notes" validate:"omitempty,max=255"`
+	Image    *string `json:"image" validate:"omitempty,base64"`
+
+	// Path is the stored new image, OldPath the image it replaces
+	Path    string
+	OldPath string
+}
+
+func (r *UpdateWACConditionRequest) Validate() error {
+	if r.Unassign && r.ServiceAdvisorId != nil {
+		return errmsg.NewCustomErrors(400).Add("unassign", "unassign tidak boleh diisi bersama service advisor id")
+	}
+
+	return nil
+}
+
+func (r *UpdateWACConditionRequest) RemoveBase64() {
+	r.Image = nil
+}
+
+type DeleteWACConditionRequest struct {
+	UserId      string
+	Id          string `params:"id" validate:"ulid"`
+	ConditionId string `params:"condition_id" validate:"ulid"`
+}
@@ -0,0 +1,36 @@
+package entity
+
+import "codebase-app/pkg/validator"
+
+// PlateMatch compares the license_number column with a plate parameter the
+// way the unique index of the vehicles does, without spaces nor case.
+const PlateMatch = `UPPER(REPLACE(v.license_number, ' ', '')) = UPPER(REPLACE(?, ' ', ''))`
+
+/*
+Vehicle Lookup - Start
+  - Finds the vehicle of a plate before a WAC is created so the client and the
+    vehicle are prefilled, a plate is matched without spaces nor case
+  - last_mileage is the mileage of the last WAC of the vehicle
+*/
+type GetVehicleRequest struct {
+	LicenseNumber string `query:"license_number" validate:"required,id_plate"`
+}
+
+func (r *GetVehicleRequest) Normalize() {
+	r.LicenseNumber = validator.FormatPlate(r.LicenseNumber)
+}
+
+type GetVehicleResponse struct {
+	Id            string  `json:"id" db:"id"`
+	LicenseNumber string  `json:"license_number" db:"license_number"`
+	VehicleType   Common  `json:"vehicle_type" db:"-"`
+	Vin           *string `json:"vin" db:"vin"`
+	Year          *int    `json:"year" db:"year"`
+	Colour        *string `json:"colour" db:"colour"`
+	LastMileage   *int    `json:"last_mileage" db:"last_mileage"`
+	Client        struct {
+		Id    string `json:"id"`
+		Name  string `json:"name"`
+		Phone string `json:"phone"`
+	} `json:"client" db:"-"`
+}
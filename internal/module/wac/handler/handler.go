@@ -10,6 +10,7 @@ import (
 	"codebase-app/internal/module/wac/repository"
 	"codebase-app/internal/module/wac/service"
 	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/period"
 	"codebase-app/pkg/response"
 	"codebase-app/pkg/security"
 	"time"
@@ -38,25 +39,65 @@ func (h *wachHandler) Register(router fiber.Router) {
 
 	wac.Post("/documents",
 		m.AuthRole([]string{"service_advisor"}),
+		m.Idempotency,
 		h.createWAC,
 	)
 	wac.Patch(
 		"/documents/:id/offerings",
 		m.AuthRole([]string{"service_advisor"}),
+		m.Idempotency,
 		h.OfferWAC,
 	)
+	wac.Put(
+		"/documents/:id/trade-in-quote",
+		m.AuthRole([]string{"service_advisor"}),
+		h.saveTradeInQuote,
+	)
 	wac.Patch(
 		"/documents/:id/revenues",
 		m.AuthRole([]string{"service_advisor"}),
+		m.Idempotency,
 		h.AddRevenue,
 	)
 
 	wac.Patch(
 		"/documents/:id/revenues-v2",
 		m.AuthRole([]string{"service_advisor"}),
+		m.Idempotency,
 		h.AddRevenues,
 	)
 
+	wac.Patch(
+		"/documents/:id",
+		m.AuthRole([]string{"service_advisor"}),
+		h.updateWAC,
+	)
+	wac.Post(
+		"/documents/:id/conditions",
+		m.AuthRole([]string{"service_advisor"}),
+		h.addWACCondition,
+	)
+	wac.Patch(
+		"/documents/:id/conditions/:condition_id",
+		m.AuthRole([]string{"service_advisor"}),
+		h.updateWACCondition,
+	)
+	wac.Delete(
+		"/documents/:id/conditions/:condition_id",
+		m.AuthRole([]string{"service_advisor"}),
+		h.deleteWACCondition,
+	)
+	wac.Delete(
+		"/documents/:id",
+		m.AuthRole([]string{"admin"}),
+		h.deleteWAC,
+	)
+
+	wac.Get("/vehicles",
+		m.AuthRole([]string{"service_advisor"}),
+		h.getVehicle,
+	)
+
 	wac.Get("/documents", h.getWACs)
 	wac.Get("/documents/:id", h.getWAC)
 	wac.Get("/documents/:id/generate-pdf-signature", h.getWACPDFSignature)
@@ -77,6 +118,7 @@ func (h *wachHandler) createWAC(c *fiber.Ctx) error {
 	}
 
 	req.UserId = l.GetUserId()
+	req.Normalize()
 
 	if err := v.Validate(req); err != nil {
 		log.Warn().Err(err).Any("payload", req).Msg("handler::createWAC - Invalid input")
@@ -108,6 +150,7 @@ func (h *wachHandler) getWACs(c *fiber.Ctx) error {
 
 	req.SetDefault()
 	req.UserId = l.GetUserId()
+	req.UserRole = l.GetRole()
 
 	if err := v.Validate(req); err != nil {
 		log.Warn().Err(err).Any("payload", req).Msg("handler::getWAC - Invalid input")
@@ -115,6 +158,12 @@ func (h *wachHandler) getWACs(c *fiber.Ctx) error {
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
+	if err := req.Resolve(); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::getWAC - Invalid input")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
 	resp, err := h.service.GetWACs(ctx, req)
 	if err != nil {
 		code, errs := errmsg.Errors[error](err)
@@ -164,6 +213,7 @@ func (h *wachHandler) OfferWAC(c *fiber.Ctx) error {
 
 	req.Id = c.Params("id")
 	req.UserId = l.GetUserId()
+	req.Normalize()
 
 	if err := v.Validate(req); err != nil {
 		log.Warn().Err(err).Any("payload", req).Msg("handler::OfferWAC - Invalid input")
@@ -171,6 +221,12 @@ func (h *wachHandler) OfferWAC(c *fiber.Ctx) error {
 		return c.Status(code).JSON(response.Error(errs))
 	}
 
+	if err := req.Validate(period.Now()); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::OfferWAC - Invalid input")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
 	resp, err := h.service.OfferWAC(ctx, req)
 	if err != nil {
 		code, errs := errmsg.Errors[error](err)
@@ -180,6 +236,44 @@ func (h *wachHandler) OfferWAC(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(response.Success(resp, ""))
 }
 
+func (h *wachHandler) saveTradeInQuote(c *fiber.Ctx) error {
+	var (
+		req = new(entity.SaveTradeInQuoteRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = m.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::saveTradeInQuote - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.Id = c.Params("id")
+	req.UserId = l.GetUserId()
+	req.Normalize()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::saveTradeInQuote - Invalid input")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := req.Validate("", period.Now()); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::saveTradeInQuote - Invalid input")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.SaveTradeInQuote(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.JSON(response.Success(resp, "Penawaran trade in berhasil disimpan"))
+}
+
 func (h *wachHandler) AddRevenue(c *fiber.Ctx) error {
 	var (
 		req = new(entity.AddWACRevenueRequest)
@@ -268,3 +362,186 @@ func (h *wachHandler) getWACPDFSignature(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(response.Success(resp, ""))
 }
+
+func (h *wachHandler) updateWAC(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UpdateWACRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = m.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::updateWAC - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.Id = c.Params("id")
+	req.UserId = l.GetUserId()
+	req.Normalize()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::updateWAC - Invalid input")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.UpdateWAC(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp, "Walk around check berhasil diperbarui"))
+}
+
+func (h *wachHandler) addWACCondition(c *fiber.Ctx) error {
+	var (
+		req = new(entity.AddWACConditionRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = m.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::addWACCondition - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.Id = c.Params("id")
+	req.UserId = l.GetUserId()
+
+	if err := v.Validate(req); err != nil {
+		req.RemoveBase64()
+		log.Warn().Err(err).Any("payload", req).Msg("handler::addWACCondition - Invalid input")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.AddWACCondition(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success(resp, "Kondisi berhasil ditambahkan"))
+}
+
+func (h *wachHandler) updateWACCondition(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UpdateWACConditionRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = m.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::updateWACCondition - Failed to parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.Id = c.Params("id")
+	req.ConditionId = c.Params("condition_id")
+	req.UserId = l.GetUserId()
+
+	if err := v.Validate(req); err != nil {
+		req.RemoveBase64()
+		log.Warn().Err(err).Any("payload", req).Msg("handler::updateWACCondition - Invalid input")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := req.Validate(); err != nil {
+		req.RemoveBase64()
+		log.Warn().Err(err).Any("payload", req).Msg("handler::updateWACCondition - Invalid input")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.UpdateWACCondition(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp, "Kondisi berhasil diperbarui"))
+}
+
+func (h *wachHandler) deleteWACCondition(c *fiber.Ctx) error {
+	var (
+		req = new(entity.DeleteWACConditionRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = m.GetLocals(c)
+	)
+
+	req.Id = c.Params("id")
+	req.ConditionId = c.Params("condition_id")
+	req.UserId = l.GetUserId()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::deleteWACCondition - Invalid input")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.DeleteWACCondition(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp, "Kondisi berhasil dihapus"))
+}
+
+func (h *wachHandler) deleteWAC(c *fiber.Ctx) error {
+	var (
+		req = new(entity.DeleteWACRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	req.Id = c.Params("id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::deleteWAC - Invalid input")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := h.service.DeleteWAC(ctx, req); err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(nil, "Walk around check berhasil dihapus"))
+}
+
+func (h *wachHandler) getVehicle(c *fiber.Ctx) error {
+	var (
+		req = new(entity.GetVehicleRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::getVehicle - Failed to parse request query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.Normalize()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::getVehicle - Invalid input")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.GetVehicle(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp, ""))
+}
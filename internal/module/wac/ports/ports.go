@@ -13,8 +13,15 @@ type WACRepository interface {
 	AddRevenues(tx context.Context, req *entity.AddWACRevenuesRequest) error
 
 	OfferWAC(ctx context.Context, req *entity.OfferWACRequest) (entity.OfferWACResponse, error)
+	UpdateWAC(ctx context.Context, req *entity.UpdateWACRequest) error
+	AddWACCondition(ctx context.Context, req *entity.AddWACConditionRequest) error
+	UpdateWACCondition(ctx context.Context, req *entity.UpdateWACConditionRequest) error
+	DeleteWACCondition(ctx context.Context, req *entity.DeleteWACConditionRequest) error
+	DeleteWAC(ctx context.Context, req *entity.DeleteWACRequest) error
+	GetVehicle(ctx context.Context, req *entity.GetVehicleRequest) (entity.GetVehicleResponse, error)
 	IsWACCreator(ctx context.Context, userId, WACId string) (bool, error)
 	IsWACStatus(ctx context.Context, WACId, status string) (bool, error)
+	SaveTradeInQuote(ctx context.Context, req *entity.SaveTradeInQuoteRequest) error
 }
 
 type WACService interface {
@@ -23,6 +30,13 @@ type WACService interface {
 	GetWAC(ctx context.Context, req *entity.GetWACRequest) (entity.GetWACResponse, error)
 
 	OfferWAC(ctx context.Context, req *entity.OfferWACRequest) (entity.OfferWACResponse, error)
+	UpdateWAC(ctx context.Context, req *entity.UpdateWACRequest) (entity.GetWACResponse, error)
+	AddWACCondition(ctx context.Context, req *entity.AddWACConditionRequest) (entity.GetWACResponse, error)
+	UpdateWACCondition(ctx context.Context, req *entity.UpdateWACConditionRequest) (entity.GetWACResponse, error)
+	DeleteWACCondition(ctx context.Context, req *entity.DeleteWACConditionRequest) (entity.GetWACResponse, error)
+	DeleteWAC(ctx context.Context, req *entity.DeleteWACRequest) error
+	GetVehicle(ctx context.Context, req *entity.GetVehicleRequest) (entity.GetVehicleResponse, error)
 	AddRevenue(ctx context.Context, req *entity.AddWACRevenueRequest) (entity.AddWACRevenueResponse, error)
 	AddRevenues(tx context.Context, req *entity.AddWACRevenuesRequest) (entity.AddWACRevenueResponse, error)
+	SaveTradeInQuote(ctx context.Context, req *entity.SaveTradeInQuoteRequest) (entity.GetWACResponse, error)
 }
@@ -3,7 +3,6 @@ package repository
 import (
 	"codebase-app/internal/module/wac/entity"
 	"context"
-	"database/sql"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/oklog/ulid/v2"
@@ -44,11 +43,11 @@ func (r *wacRepository) CreateWAC(ctx context.Context, req *entity.CreateWACRequ
 	// Generate WAC Id
 	wacId := ulid.Make().String()
 
-	// Get client Id or create new client if not exists
-	clientId, err := r.getClientId(ctx, tx, req)
+	// Get the client and the vehicle of the plate, created when they are new
+	clientId, vehicleId, err := r.resolveVehicle(ctx, tx, req)
 	if err != nil {
 		req.RemoveBase64()
-		log.Error().Err(err).Any("payload", req).Msg("repo::CreateWAC - Failed to get client id")
+		log.Error().Err(err).Any("payload", req).Msg("repo::CreateWAC - Failed to resolve vehicle")
 		return result, err
 	}
 
@@ -61,7 +60,7 @@ func (r *wacRepository) CreateWAC(ctx context.Context, req *entity.CreateWACRequ
 	}
 
 	// Create walk around check record
-	err = r.createWACRecord(ctx, tx, wacId, userData, clientId, req.UserId)
+	err = r.createWACRecord(ctx, tx, wacId, userData, clientId, vehicleId, req.UserId, req.Mileage)
 	if err != nil {
 		req.RemoveBase64()
 		log.Error().Err(err).Any("payload", req).Msg("repo::CreateWAC - Failed to create walk around check record")
@@ -93,37 +92,6 @@ func (r *wacRepository) CreateWAC(ctx context.Context, req *entity.CreateWACRequ
 	return result, nil
 }
 
-func (r *wacRepository) getClientId(ctx context.Context, tx *sqlx.Tx, req *entity.CreateWACRequest) (string, error) {
-	var clientId string
-	query := `SELECT id FROM clients WHERE vehicle_license_number = ? AND name = ?`
-	err := tx.GetContext(ctx, &clientId, r.db.Rebind(query), req.VehicleRegistrationNumber, req.Name)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return r.createClient(ctx, tx, req)
-		} else {
-			req.RemoveBase64()
-			log.Error().Err(err).Any("payload", req).Msg("repo::CreateWAC - Failed to get client id")
-			return "", err
-		}
-	}
-	return clientId, nil
-}
-
-func (r *wacRepository) createClient(ctx context.Context, tx *sqlx.Tx, req *entity.CreateWACRequest) (string, error) {
-	clientId := ulid.Make().String()
-	query := `
-	INSERT INTO clients (id, name, vehicle_type_id, vehicle_license_number, phone)
-	VALUES (?, ?, ?, ?, ?)`
-	_, err := tx.ExecContext(ctx, r.db.Rebind(query), clientId, req.Name, req.VehicleTypeId, req.VehicleRegistrationNumber, req.WhatsAppNumber)
-	if err != nil {
-		req.RemoveBase64()
-		log.Error().Err(err).Any("payload", req).Msg("repo::CreateWAC - Failed to create new client")
-		return "", err
-	}
-	return clientId, nil
-
-}
-
 func (r *wacRepository) getUserData(ctx context.Context, tx *sqlx.Tx, userId string) (user, error) {
 	var u user
 	query := `SELECT id, branch_id, section_id FROM users WHERE id = ?`
@@ -135,11 +103,11 @@ func (r *wacRepository) getUserData(ctx context.Context, tx *sqlx.Tx, userId str
 	return u, nil
 }
 
-func (r *wacRepository) createWACRecord(ctx context.Context, tx *sqlx.Tx, wacId string, u user, clientId, userId string) error {
+func (r *wacRepository) createWACRecord(ctx context.Context, tx *sqlx.Tx, wacId string, u user, clientId, vehicleId, userId string, mileage *int) error {
 	query := `
-	INSERT INTO walk_around_checks (id, branch_id, section_id, user_id, client_id)
-	VALUES (?, ?, ?, ?, ?)`
-	_, err := tx.ExecContext(ctx, r.db.Rebind(query), wacId, u.BranchId, u.SectionId, userId, clientId)
+	INSERT INTO walk_around_checks (id, branch_id, section_id, user_id, client_id, vehicle_id, mileage)
+	VALUES (?, ?, ?, ?, ?, ?, ?)`
+	_, err := tx.ExecContext(ctx, r.db.Rebind(query), wacId, u.BranchId, u.SectionId, userId, clientId, vehicleId, mileage)
 	if err != nil {
 		log.Error().Err(err).Any("wac_id", wacId).Any("user_id", userId).
 			Msg("repo::CreateWAC - Failed to create walk around check")
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"codebase-app/internal/module/wac/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DeleteWAC soft deletes the walk around check, its conditions and images are
+// kept until the trash is purged.
+func (r *wacRepository) DeleteWAC(ctx context.Context, req *entity.DeleteWACRequest) error {
+	query := `
+		UPDATE walk_around_checks
+		SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), req.Id)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteWAC - Failed to delete walk around check")
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteWAC - Failed to get affected rows")
+		return err
+	}
+
+	if affected == 0 {
+		log.Warn().Any("payload", req).Msg("repo::DeleteWAC - walk around check not found")
+		return errmsg.NewCustomErrors(404, errmsg.WithMessage("Walk around check tidak ditemukan"))
+	}
+
+	return nil
+}
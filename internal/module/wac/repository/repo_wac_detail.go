@@ -18,7 +18,9 @@ func (r *wacRepository) GetWAC(ctx context.Context, req *entity.GetWACRequest) (
 		ClientName  string    `db:"client_name"`
 		SAName      string    `db:"service_advisor_name"`
 		BranchName  string    `db:"branch_name"`
+		VehicleId   string    `db:"vehicle_id"`
 		VLicenseNum string    `db:"vehicle_license_number"`
+		Mileage     *int      `db:"mileage"`
 		VTypeId     string    `db:"vehicle_type_id"`
 		VTypeName   string    `db:"vehicle_type_name"`
 		ClientWANum string    `db:"whatsapp_number"`
@@ -28,6 +30,8 @@ func (r *wacRepository) GetWAC(ctx context.Context, req *entity.GetWACRequest) (
 		Revenue     float64   `db:"revenue"`
 		Status      string    `db:"status"`
 		CreatedAt   time.Time `db:"created_at"`
+		PromotionId *string   `db:"promotion_id"`
+		PromoTitle  *string   `db:"promotion_title"`
 	}
 
 	type daoVCondition struct {
@@ -61,7 +65,9 @@ func (r *wacRepository) GetWAC(ctx context.Context, req *entity.GetWACRequest) (
 			c.name AS client_name,
 			u.name AS service_advisor_name,
 			b.name AS branch_name,
-			c.vehicle_license_number,
+			v.id AS vehicle_id,
+			v.license_number AS vehicle_license_number,
+			wac.mileage,
 			vt.id AS vehicle_type_id,
 			vt.name AS vehicle_type_name,
 			c.phone AS whatsapp_number,
@@ -73,7 +79,9 @@ func (r *wacRepository) GetWAC(ctx context.Context, req *entity.GetWACRequest) (
 			wac.invoice_number,
 			wac.revenue,
 			wac.status,
-			wac.created_at
+			wac.created_at,
+			pr.id AS promotion_id,
+			pr.title AS promotion_title
 		FROM
 			walk_around_checks wac
 		LEFT JOIN
@@ -83,7 +91,11 @@ func (r *wacRepository) GetWAC(ctx context.Context, req *entity.GetWACRequest) (
 		LEFT JOIN
 			clients c ON c.id = wac.client_id
 		LEFT JOIN
-			vehicle_types vt ON vt.id = c.vehicle_type_id
+			vehicles v ON v.id = wac.vehicle_id
+		LEFT JOIN
+			vehicle_types vt ON vt.id = v.vehicle_type_id
+		LEFT JOIN
+			promotions pr ON pr.id = wac.promotion_id AND pr.deleted_at IS NULL
 		WHERE
 			wac.id = ?
 			AND wac.deleted_at IS NULL
@@ -142,7 +154,9 @@ func (r *wacRepository) GetWAC(ctx context.Context, req *entity.GetWACRequest) (
 	res.ClientName = data.ClientName
 	res.SAName = data.SAName
 	res.BranchName = data.BranchName
+	res.VehicleId = data.VehicleId
 	res.VehicleLicenseNumber = data.VLicenseNum
+	res.Mileage = data.Mileage
 	res.WhatsappNumber = data.ClientWANum
 	res.IsUsedCar = data.IsUsedCar
 	res.IsOffered = data.IsOffered
@@ -153,6 +167,15 @@ func (r *wacRepository) GetWAC(ctx context.Context, req *entity.GetWACRequest) (
 	res.VehicleType.Name = data.VTypeName
 	res.CreatedAt = data.CreatedAt
 
+	if data.PromotionId != nil && data.PromoTitle != nil {
+		res.Promotion = &entity.Common{Id: *data.PromotionId, Name: *data.PromoTitle}
+	}
+
+	res.TradeInQuote, err = r.getTradeInQuote(ctx, req.Id)
+	if err != nil {
+		return res, err
+	}
+
 	for _, vc := range datavc {
 		var (
 			filePath = strings.Split(vc.Path, "/")
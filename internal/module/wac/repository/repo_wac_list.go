@@ -16,37 +16,59 @@ func (r *wacRepository) GetWACs(ctx context.Context, req *entity.GetWACsRequest)
 
 	var (
 		query = strings.Builder{}
-		args  = make([]any, 0, 8)
+		args  = make([]any, 0, 16)
 		res   entity.GetWACsResponse
-		data  = make([]dao, 0, req.Paginate)
+		data  = make([]dao, 0, req.Paginate+1)
 	)
-	res.Items = make(map[string][]entity.WacItem)
 
+	// conditions are filtered with EXISTS so a WAC is never repeated per
+	// matching condition
 	query.WriteString(`
-		SELECT
-			COUNT(*) OVER() AS total_data,
-			wac.id,
-			c.name AS client_name,
-			wac.status,
-			wac.total_potential_leads,
-			wac.total_leads,
-			wac.total_leads_completed,
-			wac.total_follow_ups,
-			wac.created_at
-		FROM
-			walk_around_checks wac
-		LEFT JOIN
-			walk_around_check_conditions wacc ON wacc.walk_around_check_id = wac.id
-		LEFT JOIN
-			clients c ON c.id = wac.client_id
-		WHERE
-			wac.deleted_at IS NULL
-			AND (wac.user_id = ? OR wacc.assigned_user_id = ?)
+		WITH filtered AS (
+			SELECT
+				wac.id,
+				c.name AS client_name,
+				v.license_number AS vehicle_license_number,
+				wac.branch_id,
+				wac.user_id,
+				wac.status,
+				wac.total_potential_leads,
+				wac.total_leads,
+				wac.total_leads_completed,
+				wac.total_follow_ups,
+				wac.is_used_car,
+				wac.is_needs_follow_up,
+				wac.created_at,
+				wac.updated_at
+			FROM
+				walk_around_checks wac
+			LEFT JOIN
+				clients c ON c.id = wac.client_id
+			LEFT JOIN
+				vehicles v ON v.id = wac.vehicle_id
+			WHERE
+				wac.deleted_at IS NULL
 	`)
-	args = append(args, req.UserId, req.UserId)
+
+	if req.UserRole != "admin" {
+		query.WriteString(`
+				AND (
+					wac.user_id = ?
+					OR EXISTS (
+						SELECT 1
+						FROM walk_around_check_conditions wacc
+						WHERE
+							wacc.walk_around_check_id = wac.id
+							AND wacc.assigned_user_id = ?
+							AND wacc.deleted_at IS NULL
+					)
+				)
+		`)
+		args = append(args, req.UserId, req.UserId)
+	}
 
 	if req.Query != "" {
-		query.WriteString(" AND (c.name ILIKE ? OR c.vehicle_license_number ILIKE ?)")
+		query.WriteString(" AND (c.name ILIKE ? OR v.license_number ILIKE ?)")
 		args = append(args, "%"+req.Query+"%", "%"+req.Query+"%")
 	}
 
@@ -55,22 +77,94 @@ func (r *wacRepository) GetWACs(ctx context.Context, req *entity.GetWACsRequest)
 		args = append(args, req.Status)
 	}
 
+	// the sort column is validated against a fixed set
+	if req.FromTime != nil {
+		query.WriteString(" AND wac." + req.SortBy + " >= ?")
+		args = append(args, *req.FromTime)
+	}
+
+	if req.ToTime != nil {
+		query.WriteString(" AND wac." + req.SortBy + " < ?")
+		args = append(args, *req.ToTime)
+	}
+
+	if req.BranchId != "" {
+		query.WriteString(" AND wac.branch_id = ?")
+		args = append(args, req.BranchId)
+	}
+
+	if req.IsUsedCar != nil {
+		query.WriteString(" AND wac.is_used_car = ?")
+		args = append(args, *req.IsUsedCar)
+	}
+
+	if req.IsNeedsFollowUp != nil {
+		query.WriteString(" AND wac.is_needs_follow_up = ?")
+		args = append(args, *req.IsNeedsFollowUp)
+	}
+
+	conditionFilters := []struct {
+		column string
+		value  string
+	}{
+		{"wacc.potency_id", req.PotencyId},
+		{"wacc.area_id", req.AreaId},
+		{"wacc.assigned_user_id", req.AssignedUserId},
+	}
+
+	for _, f := range conditionFilters {
+		if f.value == "" {
+			continue
+		}
+
+		query.WriteString(`
+				AND EXISTS (
+					SELECT 1
+					FROM walk_around_check_conditions wacc
+					WHERE
+						wacc.walk_around_check_id = wac.id
+						AND wacc.deleted_at IS NULL
+						AND ` + f.column + ` = ?
+				)
+		`)
+		args = append(args, f.value)
+	}
+
+	// the sort column and direction are validated against a fixed set
+	var (
+		sortColumn = "f." + req.SortBy
+		direction  = "DESC"
+		comparator = "<"
+	)
+	if req.Order == "asc" {
+		direction = "ASC"
+		comparator = ">"
+	}
+
 	query.WriteString(`
-		GROUP BY
-			wac.id,
-			c.name,
-			wac.status,
-			wac.total_potential_leads,
-			wac.total_leads,
-			wac.total_leads_completed,
-			wac.total_follow_ups,
-			wac.created_at
+		)
+		SELECT
+			(SELECT COUNT(*) FROM filtered) AS total_data,
+			f.*
+		FROM
+			filtered f
 	`)
 
-	query.WriteString(" ORDER BY wac.created_at DESC")
+	if req.After != nil {
+		query.WriteString(" WHERE (" + sortColumn + ", f.id) " + comparator + " (?, ?)")
+		args = append(args, req.After.Value, req.After.Id)
+	}
+
+	query.WriteString(" ORDER BY " + sortColumn + " " + direction + ", f.id " + direction)
+
+	// one more row tells whether there is a next page
+	query.WriteString(" LIMIT ?")
+	args = append(args, req.Paginate+1)
 
-	query.WriteString(" LIMIT ? OFFSET ?")
-	args = append(args, req.Paginate, (req.Page-1)*req.Paginate)
+	if req.After == nil {
+		query.WriteString(" OFFSET ?")
+		args = append(args, (req.Page-1)*req.Paginate)
+	}
 
 	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query.String()), args...)
 	if err != nil {
@@ -78,12 +172,27 @@ func (r *wacRepository) GetWACs(ctx context.Context, req *entity.GetWACsRequest)
 		return res, err
 	}
 
+	hasMore := len(data) > req.Paginate
+	if hasMore {
+		data = data[:req.Paginate]
+	}
+
+	items := make([]entity.WacItem, 0, len(data))
 	for _, d := range data {
-		date := d.CreatedAt.UTC().Format("2006-01-02")
-		if _, ok := res.Items[date]; !ok {
-			res.Items[date] = make([]entity.WacItem, 0)
-		}
-		res.Items[date] = append(res.Items[date], d.WacItem)
+		items = append(items, d.WacItem)
+	}
+
+	res.Items = entity.GroupWACs(items, req.SortBy, req.Location)
+
+	if hasMore && req.SortBy == entity.WACSortCreatedAt {
+		last := items[len(items)-1]
+		cursor := entity.WACCursor{
+			SortBy: req.SortBy,
+			Order:  req.Order,
+			Value:  last.SortValue(req.SortBy),
+			Id:     last.Id,
+		}.Encode()
+		res.NextCursor = &cursor
 	}
 
 	if len(data) > 0 {
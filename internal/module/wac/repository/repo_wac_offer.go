@@ -39,6 +39,13 @@ func (r *wacRepository) OfferWAC(ctx context.Context, req *entity.OfferWACReques
 		}
 	}()
 
+	if req.PromotionId != nil {
+		err = r.attachPromotion(ctx, tx, req.Id, *req.PromotionId)
+		if err != nil {
+			return res, err
+		}
+	}
+
 	for _, c := range req.VConditions {
 		if c.IsInterested {
 			isAnyInterest = true
@@ -185,6 +192,21 @@ func (r *wacRepository) OfferWACUsedCard(ctx context.Context, req *entity.OfferW
 		return res, err
 	}
 
+	// after the conditions became used-car ones
+	if req.PromotionId != nil {
+		err = r.attachPromotion(ctx, tx, req.Id, *req.PromotionId)
+		if err != nil {
+			return res, err
+		}
+	}
+
+	if req.TradeIn != nil {
+		err = r.saveTradeInQuote(ctx, tx, req.Id, req.UserId, "trade_in.", req.TradeIn)
+		if err != nil {
+			return res, err
+		}
+	}
+
 	res.Id = req.Id
 
 	// create activity
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	promotionEntity "codebase-app/internal/module/promotion/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// attachPromotion attaches the promotion to the offering of the WAC, it has to
+// be running and target the branch and one of the potencies of the WAC.
+func (r *wacRepository) attachPromotion(ctx context.Context, tx *sqlx.Tx, wacId, promotionId string) error {
+	var isRelevant bool
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM promotions p
+			WHERE
+				p.id = ?
+				AND ` + promotionEntity.Running + `
+				AND ` + promotionEntity.TargetsBranch(`(SELECT branch_id FROM walk_around_checks WHERE id = ?)`) + `
+				AND ` + promotionEntity.TargetsPotency(`SELECT potency_id FROM walk_around_check_conditions WHERE walk_around_check_id = ? AND deleted_at IS NULL`) + `
+		)
+	`
+	err := tx.GetContext(ctx, &isRelevant, r.db.Rebind(query), promotionId, wacId, wacId)
+	if err != nil {
+		log.Error().Err(err).Str("wac_id", wacId).Str("promotion_id", promotionId).Msg("repo::attachPromotion - failed to check promotion")
+		return err
+	}
+
+	if !isRelevant {
+		log.Warn().Str("wac_id", wacId).Str("promotion_id", promotionId).Msg("repo::attachPromotion - promotion is not running for the wac")
+		return errmsg.NewCustomErrors(400).Add("promotion_id", "promosi tidak berlaku untuk WAC ini")
+	}
+
+	query = `UPDATE walk_around_checks SET promotion_id = ?, updated_at = NOW() WHERE id = ?`
+	if _, err = tx.ExecContext(ctx, r.db.Rebind(query), promotionId, wacId); err != nil {
+		log.Error().Err(err).Str("wac_id", wacId).Str("promotion_id", promotionId).Msg("repo::attachPromotion - failed to attach promotion")
+		return err
+	}
+
+	return nil
+}
@@ -11,7 +11,7 @@ func (r *wacRepository) IsWACCreator(ctx context.Context, userId, WACId string)
 		SELECT EXISTS(
 			SELECT 1
 			FROM walk_around_checks
-			WHERE id = ? AND user_id = ?
+			WHERE id = ? AND user_id = ? AND deleted_at IS NULL
 		)
 	`
 
@@ -42,7 +42,7 @@ func (r *wacRepository) IsWACStatus(ctx context.Context, WACId, status string) (
 		SELECT EXISTS(
 			SELECT 1
 			FROM walk_around_checks
-			WHERE id = ? AND status = ?
+			WHERE id = ? AND status = ? AND deleted_at IS NULL
 		)
 	`
 
@@ -0,0 +1,263 @@
+package repository
+
+import (
+	tradeInEntity "codebase-app/internal/module/tradein/entity"
+	"codebase-app/internal/module/wac/entity"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/period"
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+func (r *wacRepository) SaveTradeInQuote(ctx context.Context, req *entity.SaveTradeInQuoteRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::SaveTradeInQuote - failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::SaveTradeInQuote - failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::SaveTradeInQuote - failed to commit transaction")
+		}
+	}()
+
+	var isQuotable bool
+	query := `
+		SELECT is_used_car AND status = 'wip'
+		FROM walk_around_checks
+		WHERE id = ? AND deleted_at IS NULL
+		FOR UPDATE
+	`
+	if err = tx.GetContext(ctx, &isQuotable, r.db.Rebind(query), req.Id); err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn().Any("payload", req).Msg("repo::SaveTradeInQuote - wac not found")
+			err = errmsg.NewCustomErrors(404, errmsg.WithMessage("WAC tidak ditemukan"))
+			return err
+		}
+		log.Error().Err(err).Any("payload", req).Msg("repo::SaveTradeInQuote - failed to lock wac")
+		return err
+	}
+
+	if !isQuotable {
+		log.Warn().Any("payload", req).Msg("repo::SaveTradeInQuote - wac is not an ongoing used car")
+		err = errmsg.NewCustomErrors(400, errmsg.WithMessage("Penawaran trade in hanya untuk WAC used car yang sedang berjalan"))
+		return err
+	}
+
+	err = r.saveTradeInQuote(ctx, tx, req.Id, req.UserId, "", &req.TradeInQuoteRequest)
+	return err
+}
+
+// saveTradeInQuote prices the quote of the WAC with the price list in effect
+// and saves it in place of the previous one, field prefixes the reported
+// fields.
+func (r *wacRepository) saveTradeInQuote(ctx context.Context, tx *sqlx.Tx, wacId, userId, field string, q *entity.TradeInQuoteRequest) error {
+	var (
+		now   = period.Now()
+		price struct {
+			PriceListId string `db:"price_list_id"`
+			MinPurchase int    `db:"min_purchase"`
+			MaxPurchase int    `db:"max_purchase"`
+		}
+	)
+
+	query := `
+		SELECT price_list_id, min_purchase, max_purchase
+		FROM trade_in_trends
+		WHERE
+			price_list_id = ` + tradeInEntity.CurrentPriceList + `
+			AND brand = ? AND model = ? AND type = ? AND year = ?
+	`
+	err := tx.GetContext(ctx, &price, r.db.Rebind(query), q.Brand, q.Model, q.Type, q.Year)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn().Str("wac_id", wacId).Any("quote", q).Msg("repo::saveTradeInQuote - trade in price not found")
+			return errmsg.NewCustomErrors(404, errmsg.WithMessage("Harga trade in kendaraan tersebut tidak ditemukan"))
+		}
+		log.Error().Err(err).Str("wac_id", wacId).Any("quote", q).Msg("repo::saveTradeInQuote - failed to get trade in price")
+		return err
+	}
+
+	mileage := q.Mileage
+	if mileage == nil {
+		query = `SELECT mileage FROM walk_around_checks WHERE id = ?`
+		if err = tx.GetContext(ctx, &mileage, r.db.Rebind(query), wacId); err != nil {
+			log.Error().Err(err).Str("wac_id", wacId).Msg("repo::saveTradeInQuote - failed to get wac mileage")
+			return err
+		}
+	}
+
+	var (
+		conditionIds = make([]string, 0, len(q.ConditionAdjustments))
+		deductions   = make([]int, 0, len(q.ConditionAdjustments))
+	)
+	for _, a := range q.ConditionAdjustments {
+		conditionIds = append(conditionIds, a.ConditionId)
+		deductions = append(deductions, a.Deduction)
+	}
+
+	if len(conditionIds) > 0 {
+		var found int
+		query = `
+			SELECT COUNT(*)
+			FROM walk_around_check_conditions
+			WHERE walk_around_check_id = ? AND deleted_at IS NULL AND id = ANY(?)
+		`
+		if err = tx.GetContext(ctx, &found, r.db.Rebind(query), wacId, pq.Array(conditionIds)); err != nil {
+			log.Error().Err(err).Str("wac_id", wacId).Msg("repo::saveTradeInQuote - failed to check conditions")
+			return err
+		}
+
+		if found != len(conditionIds) {
+			log.Warn().Str("wac_id", wacId).Any("quote", q).Msg("repo::saveTradeInQuote - condition not found")
+			return errmsg.NewCustomErrors(400).Add(field+"condition_adjustments", "kondisi tidak ditemukan pada WAC ini")
+		}
+	}
+
+	p := entity.PriceTradeIn(price.MinPurchase, price.MaxPurchase, q.Year, mileage, deductions, now)
+
+	offeredPrice := p.SuggestedPrice
+	if q.OfferedPrice != nil {
+		offeredPrice = *q.OfferedPrice
+	}
+
+	if offeredPrice > price.MaxPurchase {
+		log.Warn().Str("wac_id", wacId).Any("quote", q).Msg("repo::saveTradeInQuote - offered price above max purchase")
+		return errmsg.NewCustomErrors(400).Add(field+"offered_price", "harga penawaran tidak boleh lebih dari max purchase")
+	}
+
+	var quoteId string
+	query = `
+		INSERT INTO trade_in_quotes (
+			id, walk_around_check_id, price_list_id, user_id,
+			brand, model, type, year, mileage,
+			min_purchase, max_purchase,
+			base_price, mileage_adjustment, condition_adjustment, suggested_price,
+			offered_price, valid_until, notes
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (walk_around_check_id) DO UPDATE
+		SET
+			price_list_id = EXCLUDED.price_list_id,
+			user_id = EXCLUDED.user_id,
+			brand = EXCLUDED.brand,
+			model = EXCLUDED.model,
+			type = EXCLUDED.type,
+			year = EXCLUDED.year,
+			mileage = EXCLUDED.mileage,
+			min_purchase = EXCLUDED.min_purchase,
+			max_purchase = EXCLUDED.max_purchase,
+			base_price = EXCLUDED.base_price,
+			mileage_adjustment = EXCLUDED.mileage_adjustment,
+			condition_adjustment = EXCLUDED.condition_adjustment,
+			suggested_price = EXCLUDED.suggested_price,
+			offered_price = EXCLUDED.offered_price,
+			valid_until = EXCLUDED.valid_until,
+			notes = EXCLUDED.notes,
+			updated_at = NOW()
+		RETURNING id
+	`
+	err = tx.GetContext(ctx, &quoteId, r.db.Rebind(query),
+		ulid.Make().String(), wacId, price.PriceListId, userId,
+		q.Brand, q.Model, q.Type, q.Year, mileage,
+		price.MinPurchase, price.MaxPurchase,
+		p.BasePrice, p.MileageAdjustment, p.ConditionAdjustment, p.SuggestedPrice,
+		offeredPrice, q.ValidUntilDate(now), q.Notes,
+	)
+	if err != nil {
+		log.Error().Err(err).Str("wac_id", wacId).Any("quote", q).Msg("repo::saveTradeInQuote - failed to save quote")
+		return err
+	}
+
+	query = `DELETE FROM trade_in_quote_adjustments WHERE trade_in_quote_id = ?`
+	if _, err = tx.ExecContext(ctx, r.db.Rebind(query), quoteId); err != nil {
+		log.Error().Err(err).Str("wac_id", wacId).Msg("repo::saveTradeInQuote - failed to clear adjustments")
+		return err
+	}
+
+	query = `
+		INSERT INTO trade_in_quote_adjustments (id, trade_in_quote_id, walk_around_check_condition_id, deduction, notes)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	for _, a := range q.ConditionAdjustments {
+		_, err = tx.ExecContext(ctx, r.db.Rebind(query), ulid.Make().String(), quoteId, a.ConditionId, a.Deduction, a.Notes)
+		if err != nil {
+			log.Error().Err(err).Str("wac_id", wacId).Msg("repo::saveTradeInQuote - failed to save adjustment")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getTradeInQuote returns the quote of the WAC, nil when it has none.
+func (r *wacRepository) getTradeInQuote(ctx context.Context, wacId string) (*entity.TradeInQuote, error) {
+	var quote entity.TradeInQuote
+
+	query := `
+		SELECT
+			q.id,
+			q.price_list_id,
+			q.brand,
+			q.model,
+			q.type,
+			q.year,
+			q.mileage,
+			q.min_purchase,
+			q.max_purchase,
+			q.base_price,
+			q.mileage_adjustment,
+			q.condition_adjustment,
+			q.suggested_price,
+			q.offered_price,
+			TO_CHAR(q.valid_until, 'YYYY-MM-DD') AS valid_until,
+			q.valid_until < ` + period.Today + ` AS is_expired,
+			q.notes,
+			q.user_id,
+			COALESCE(u.name, '') AS user_name,
+			q.created_at,
+			q.updated_at
+		FROM trade_in_quotes q
+		LEFT JOIN
+			users u ON u.id = q.user_id
+		WHERE q.walk_around_check_id = ?
+	`
+	if err := r.db.GetContext(ctx, &quote, r.db.Rebind(query), wacId); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Error().Err(err).Str("wac_id", wacId).Msg("repo::getTradeInQuote - failed to get quote")
+		return nil, err
+	}
+
+	quote.Adjustments = make([]entity.TradeInAdjustment, 0)
+	query = `
+		SELECT
+			a.walk_around_check_condition_id AS condition_id,
+			a.deduction,
+			a.notes
+		FROM trade_in_quote_adjustments a
+		JOIN
+			walk_around_check_conditions wacc ON wacc.id = a.walk_around_check_condition_id
+		WHERE a.trade_in_quote_id = ? AND wacc.deleted_at IS NULL
+		ORDER BY wacc.created_at
+	`
+	if err := r.db.SelectContext(ctx, &quote.Adjustments, r.db.Rebind(query), quote.Id); err != nil {
+		log.Error().Err(err).Str("wac_id", wacId).Msg("repo::getTradeInQuote - failed to get adjustments")
+		return nil, err
+	}
+
+	return &quote, nil
+}
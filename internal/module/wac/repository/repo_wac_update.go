@@ -0,0 +1,453 @@
+package repository
+
+import (
+	"codebase-app/internal/module/wac/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type amendableWAC struct {
+	UserId    string `db:"user_id"`
+	Status    string `db:"status"`
+	ClientId  string `db:"client_id"`
+	VehicleId string `db:"vehicle_id"`
+}
+
+// lockAmendableWAC locks the WAC for the rest of the transaction, so it can
+// not be offered while it is being amended.
+func (r *wacRepository) lockAmendableWAC(ctx context.Context, tx *sqlx.Tx, wacId, userId string) (amendableWAC, error) {
+	var w amendableWAC
+
+	query := `
+		SELECT user_id, status, client_id, vehicle_id
+		FROM walk_around_checks
+		WHERE id = ? AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	err := tx.GetContext(ctx, &w, r.db.Rebind(query), wacId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn().Str("wac_id", wacId).Msg("repo::lockAmendableWAC - walk around check not found")
+			return w, errmsg.NewCustomErrors(404, errmsg.WithMessage("Walk around check tidak ditemukan"))
+		}
+		log.Error().Err(err).Str("wac_id", wacId).Msg("repo::lockAmendableWAC - Failed to lock walk around check")
+		return w, err
+	}
+
+	if w.UserId != userId {
+		log.Warn().Str("wac_id", wacId).Str("user_id", userId).Msg("repo::lockAmendableWAC - You are not the creator of this walk around check")
+		return w, errmsg.NewCustomErrors(403, errmsg.WithMessage("Anda bukan pembuat walk around check ini"))
+	}
+
+	if w.Status != "offered" {
+		log.Warn().Str("wac_id", wacId).Str("status", w.Status).Msg("repo::lockAmendableWAC - Walk around check has been offered")
+		return w, errmsg.NewCustomErrors(403, errmsg.WithMessage("Walk around check sudah ditawarkan dan tidak dapat diubah"))
+	}
+
+	return w, nil
+}
+
+// finishAmendment recounts the potential leads of the WAC and records the
+// amendment as an activity. The offered activity follows the recount, the
+// dashboards read the potential leads from it.
+func (r *wacRepository) finishAmendment(ctx context.Context, tx *sqlx.Tx, wacId, userId string) error {
+	var potentialLeads int
+
+	query := `
+		UPDATE walk_around_checks
+		SET
+			total_potential_leads = (
+				SELECT COUNT(*)
+				FROM walk_around_check_conditions
+				WHERE walk_around_check_id = ? AND deleted_at IS NULL
+			),
+			updated_at = NOW()
+		WHERE id = ?
+		RETURNING total_potential_leads
+	`
+
+	err := tx.GetContext(ctx, &potentialLeads, r.db.Rebind(query), wacId, wacId)
+	if err != nil {
+		log.Error().Err(err).Str("wac_id", wacId).Msg("repo::finishAmendment - Failed to update total potential leads")
+		return err
+	}
+
+	query = `
+		UPDATE wac_activities
+		SET total_potential_leads = ?, updated_at = NOW()
+		WHERE wac_id = ? AND status = 'offered'
+	`
+	if _, err = tx.ExecContext(ctx, r.db.Rebind(query), potentialLeads, wacId); err != nil {
+		log.Error().Err(err).Str("wac_id", wacId).Msg("repo::finishAmendment - Failed to update offered activity")
+		return err
+	}
+
+	return r.addActivity(ctx, tx, &activity{
+		WacId:               wacId,
+		UserId:              userId,
+		Status:              entity.ActivityEdited,
+		TotalPotentialLeads: potentialLeads,
+	})
+}
+
+// UpdateWAC amends the client and the vehicle of the WAC, see amendClient and
+// amendVehicle.
+func (r *wacRepository) UpdateWAC(ctx context.Context, req *entity.UpdateWACRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateWAC - Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::UpdateWAC - Failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::UpdateWAC - Failed to commit transaction")
+		}
+	}()
+
+	w, err := r.lockAmendableWAC(ctx, tx, req.Id, req.UserId)
+	if err != nil {
+		return err
+	}
+
+	if req.Name != nil || req.WhatsAppNumber != nil {
+		if err = r.amendClient(ctx, tx, req, w); err != nil {
+			return err
+		}
+	}
+
+	if req.VehicleRegistrationNumber != nil || req.VehicleTypeId != nil {
+		if err = r.amendVehicle(ctx, tx, req, w); err != nil {
+			return err
+		}
+	}
+
+	return r.finishAmendment(ctx, tx, req.Id, req.UserId)
+}
+
+// amendClient fixes the name and phone of the client of the WAC. The client of
+// other WACs too is refused, amending one WAC must not change the others, the
+// admins fix it from the clients instead.
+func (r *wacRepository) amendClient(ctx context.Context, tx *sqlx.Tx, req *entity.UpdateWACRequest, w amendableWAC) error {
+	var shared bool
+
+	query := `SELECT EXISTS (SELECT 1 FROM walk_around_checks WHERE client_id = ? AND id <> ?)`
+	if err := tx.GetContext(ctx, &shared, r.db.Rebind(query), w.ClientId, req.Id); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateWAC - Failed to check client walk around checks")
+		return err
+	}
+
+	if shared {
+		log.Warn().Any("payload", req).Msg("repo::UpdateWAC - client has other walk around checks")
+		return errmsg.NewCustomErrors(409, errmsg.WithMessage("Klien memiliki walk around check lain, ubah data klien melalui menu klien"))
+	}
+
+	query = `
+		UPDATE clients
+		SET
+			name = COALESCE(?, name),
+			phone = COALESCE(?, phone),
+			updated_at = NOW()
+		WHERE id = ?
+	`
+	if _, err := tx.ExecContext(ctx, r.db.Rebind(query), req.Name, req.WhatsAppNumber, w.ClientId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateWAC - Failed to update client")
+		return err
+	}
+
+	return nil
+}
+
+// amendVehicle fixes the plate and the type of the vehicle of the WAC.
+//   - A plate of another vehicle of the client moves the WAC to it, that
+//     vehicle is left as it is so its type must not differ from the amended one
+//   - A plate of a vehicle of another client is refused
+//   - A new plate is fixed on the vehicle of the WAC, unless other WACs refer
+//     to it, the WAC then gets a new vehicle and the others keep theirs
+//   - The type alone is fixed on the vehicle of the WAC, it is the same car
+func (r *wacRepository) amendVehicle(ctx context.Context, tx *sqlx.Tx, req *entity.UpdateWACRequest, w amendableWAC) error {
+	var current wacVehicle
+
+	query := `SELECT id, client_id, vehicle_type_id, license_number FROM vehicles WHERE id = ? FOR UPDATE`
+	if err := tx.GetContext(ctx, &current, r.db.Rebind(query), w.VehicleId); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateWAC - Failed to get vehicle")
+		return err
+	}
+
+	amended := current
+	if req.VehicleTypeId != nil {
+		amended.VehicleTypeId = *req.VehicleTypeId
+	}
+
+	if req.VehicleRegistrationNumber != nil && *req.VehicleRegistrationNumber != current.LicenseNumber {
+		amended.LicenseNumber = *req.VehicleRegistrationNumber
+
+		existing, err := r.vehicleByPlate(ctx, tx, amended.LicenseNumber)
+		if err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::UpdateWAC - Failed to get vehicle by plate")
+			return err
+		}
+
+		if existing != nil && existing.Id != current.Id {
+			if existing.ClientId != w.ClientId {
+				log.Warn().Any("payload", req).Str("vehicle_id", existing.Id).Msg("repo::UpdateWAC - plate belongs to another client")
+				return errmsg.NewCustomErrors(409).Add("vehicle_registration_number", "plat nomor sudah terdaftar atas klien lain")
+			}
+
+			if req.VehicleTypeId != nil && existing.VehicleTypeId != *req.VehicleTypeId {
+				log.Warn().Any("payload", req).Str("vehicle_id", existing.Id).Msg("repo::UpdateWAC - plate registered with another vehicle type")
+				return errmsg.NewCustomErrors(409).Add("vehicle_type_id", "plat nomor sudah terdaftar dengan tipe kendaraan lain")
+			}
+
+			return r.moveWACToVehicle(ctx, tx, req.Id, existing.Id)
+		}
+
+		var shared bool
+		query = `SELECT EXISTS (SELECT 1 FROM walk_around_checks WHERE vehicle_id = ? AND id <> ?)`
+		if err = tx.GetContext(ctx, &shared, r.db.Rebind(query), current.Id, req.Id); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::UpdateWAC - Failed to check vehicle walk around checks")
+			return err
+		}
+
+		if shared {
+			amended.Id = ulid.Make().String()
+			query = `INSERT INTO vehicles (id, client_id, vehicle_type_id, license_number) VALUES (?, ?, ?, ?)`
+			_, err = tx.ExecContext(ctx, r.db.Rebind(query), amended.Id, w.ClientId, amended.VehicleTypeId, amended.LicenseNumber)
+			if err != nil {
+				log.Error().Err(err).Any("payload", req).Msg("repo::UpdateWAC - Failed to create vehicle")
+				return err
+			}
+
+			return r.moveWACToVehicle(ctx, tx, req.Id, amended.Id)
+		}
+	}
+
+	query = `
+		UPDATE vehicles
+		SET vehicle_type_id = ?, license_number = ?, updated_at = NOW()
+		WHERE id = ?
+	`
+	if _, err := tx.ExecContext(ctx, r.db.Rebind(query), amended.VehicleTypeId, amended.LicenseNumber, current.Id); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateWAC - Failed to update vehicle")
+		return err
+	}
+
+	return nil
+}
+
+func (r *wacRepository) moveWACToVehicle(ctx context.Context, tx *sqlx.Tx, wacId, vehicleId string) error {
+	query := `UPDATE walk_around_checks SET vehicle_id = ? WHERE id = ?`
+	if _, err := tx.ExecContext(ctx, r.db.Rebind(query), vehicleId, wacId); err != nil {
+		log.Error().Err(err).Str("wac_id", wacId).Str("vehicle_id", vehicleId).Msg("repo::UpdateWAC - Failed to move walk around check to vehicle")
+		return err
+	}
+
+	return nil
+}
+
+func (r *wacRepository) AddWACCondition(ctx context.Context, req *entity.AddWACConditionRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		req.RemoveBase64()
+		log.Error().Err(err).Any("payload", req).Msg("repo::AddWACCondition - Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				req.RemoveBase64()
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::AddWACCondition - Failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			req.RemoveBase64()
+			log.Error().Err(err).Any("payload", req).Msg("repo::AddWACCondition - Failed to commit transaction")
+		}
+	}()
+
+	if _, err = r.lockAmendableWAC(ctx, tx, req.Id, req.UserId); err != nil {
+		return err
+	}
+
+	if err = r.createWACConditions(ctx, tx, req.Id, []entity.VehicleCondition{req.VehicleCondition}); err != nil {
+		return err
+	}
+
+	return r.finishAmendment(ctx, tx, req.Id, req.UserId)
+}
+
+// UpdateWACCondition amends a condition, it fills req.OldPath with the image
+// the new one replaces.
+func (r *wacRepository) UpdateWACCondition(ctx context.Context, req *entity.UpdateWACConditionRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		req.RemoveBase64()
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateWACCondition - Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				req.RemoveBase64()
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::UpdateWACCondition - Failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			req.RemoveBase64()
+			log.Error().Err(err).Any("payload", req).Msg("repo::UpdateWACCondition - Failed to commit transaction")
+		}
+	}()
+
+	if _, err = r.lockAmendableWAC(ctx, tx, req.Id, req.UserId); err != nil {
+		return err
+	}
+
+	var oldPath sql.NullString
+	query := `
+		SELECT path
+		FROM walk_around_check_conditions
+		WHERE id = ? AND walk_around_check_id = ? AND deleted_at IS NULL
+	`
+	err = tx.GetContext(ctx, &oldPath, r.db.Rebind(query), req.ConditionId, req.Id)
+	if err != nil {
+		req.RemoveBase64()
+		if err == sql.ErrNoRows {
+			log.Warn().Any("payload", req).Msg("repo::UpdateWACCondition - condition not found")
+			return errmsg.NewCustomErrors(404, errmsg.WithMessage("Kondisi walk around check tidak ditemukan"))
+		}
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateWACCondition - Failed to get condition")
+		return err
+	}
+
+	var (
+		set  = `updated_at = NOW()`
+		args = make([]any, 0, 8)
+	)
+
+	if req.PotencyId != nil {
+		set += `, potency_id = ?`
+		args = append(args, *req.PotencyId)
+	}
+
+	if req.AreaId != nil {
+		set += `, area_id = ?`
+		args = append(args, *req.AreaId)
+	}
+
+	if req.Notes != nil {
+		set += `, notes = ?`
+		args = append(args, *req.Notes)
+	}
+
+	if req.Path != "" {
+		set += `, path = ?`
+		args = append(args, req.Path)
+		req.OldPath = oldPath.String
+	}
+
+	switch {
+	case req.Unassign:
+		set += `, assigned_user_id = NULL, assigned_branch_id = NULL, assigned_section_id = NULL`
+	case req.ServiceAdvisorId != nil:
+		var ua user
+		query = `SELECT id, branch_id, section_id FROM users WHERE id = ?`
+		if err = tx.GetContext(ctx, &ua, r.db.Rebind(query), *req.ServiceAdvisorId); err != nil {
+			req.RemoveBase64()
+			log.Error().Err(err).Any("payload", req).Msg("repo::UpdateWACCondition - Failed to get user data")
+			return err
+		}
+
+		set += `, assigned_user_id = ?, assigned_branch_id = ?, assigned_section_id = ?`
+		args = append(args, ua.Id, ua.BranchId, ua.SectionId)
+	}
+
+	query = `UPDATE walk_around_check_conditions SET ` + set + ` WHERE id = ?`
+	args = append(args, req.ConditionId)
+
+	if _, err = tx.ExecContext(ctx, r.db.Rebind(query), args...); err != nil {
+		req.RemoveBase64()
+		log.Error().Err(err).Any("payload", req).Msg("repo::UpdateWACCondition - Failed to update condition")
+		return err
+	}
+
+	return r.finishAmendment(ctx, tx, req.Id, req.UserId)
+}
+
+// DeleteWACCondition removes a condition, a WAC keeps at least one.
+func (r *wacRepository) DeleteWACCondition(ctx context.Context, req *entity.DeleteWACConditionRequest) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteWACCondition - Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				log.Error().Err(errRollback).Any("payload", req).Msg("repo::DeleteWACCondition - Failed to rollback transaction")
+			}
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repo::DeleteWACCondition - Failed to commit transaction")
+		}
+	}()
+
+	if _, err = r.lockAmendableWAC(ctx, tx, req.Id, req.UserId); err != nil {
+		return err
+	}
+
+	var total int
+	query := `SELECT COUNT(*) FROM walk_around_check_conditions WHERE walk_around_check_id = ? AND deleted_at IS NULL`
+	if err = tx.GetContext(ctx, &total, r.db.Rebind(query), req.Id); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteWACCondition - Failed to count conditions")
+		return err
+	}
+
+	query = `
+		UPDATE walk_around_check_conditions
+		SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = ? AND walk_around_check_id = ? AND deleted_at IS NULL
+	`
+	result, err := tx.ExecContext(ctx, r.db.Rebind(query), req.ConditionId, req.Id)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteWACCondition - Failed to delete condition")
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repo::DeleteWACCondition - Failed to get affected rows")
+		return err
+	}
+
+	if affected == 0 {
+		log.Warn().Any("payload", req).Msg("repo::DeleteWACCondition - condition not found")
+		err = errmsg.NewCustomErrors(404, errmsg.WithMessage("Kondisi walk around check tidak ditemukan"))
+		return err
+	}
+
+	if total <= 1 {
+		log.Warn().Any("payload", req).Msg("repo::DeleteWACCondition - last condition of the walk around check")
+		err = errmsg.NewCustomErrors(400, errmsg.WithMessage("Walk around check harus memiliki minimal satu kondisi"))
+		return err
+	}
+
+	return r.finishAmendment(ctx, tx, req.Id, req.UserId)
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"codebase-app/internal/module/wac/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRepository(t *testing.T) (*wacRepository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return &wacRepository{db: sqlx.NewDb(db, "postgres")}, mock
+}
+
+func q(query string) string {
+	return regexp.QuoteMeta(query)
+}
+
+func errorCode(err error) int {
+	var customErr *errmsg.CustomError
+	if errors.As(err, &customErr) {
+		return customErr.Code
+	}
+
+	return 0
+}
+
+func expectLock(mock sqlmock.Sqlmock, userId, status string) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(q("SELECT user_id, status, client_id, vehicle_id")).
+		WithArgs("wac").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "status", "client_id", "vehicle_id"}).
+			AddRow(userId, status, "client", "vehicle"))
+}
+
+func expectFinish(mock sqlmock.Sqlmock, potentialLeads int) {
+	mock.ExpectQuery(q("UPDATE walk_around_checks")).
+		WithArgs("wac", "wac").
+		WillReturnRows(sqlmock.NewRows([]string{"total_potential_leads"}).AddRow(potentialLeads))
+	mock.ExpectExec(q("UPDATE wac_activities")).
+		WithArgs(potentialLeads, "wac").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(q("INSERT INTO wac_activities")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+}
+
+func expectVehicle(mock sqlmock.Sqlmock, id, clientId, typeId, plate string) {
+	mock.ExpectQuery(q("SELECT id, client_id, vehicle_type_id, license_number FROM vehicles")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "client_id", "vehicle_type_id", "license_number"}).
+			AddRow(id, clientId, typeId, plate))
+}
+
+func expectPlate(mock sqlmock.Sqlmock, plate string, rows *sqlmock.Rows) {
+	mock.ExpectQuery(q("FROM vehicles v")).WithArgs(plate).WillReturnRows(rows)
+}
+
+func vehicleRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "client_id", "vehicle_type_id", "license_number"})
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+func TestLockAmendableWAC(t *testing.T) {
+	cases := []struct {
+		name   string
+		userId string
+		status string
+		code   int
+	}{
+		{"another user", "other", "offered", 403},
+		{"already offered", "user", "wip", 403},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, mock := newMockRepository(t)
+			expectLock(mock, tc.userId, tc.status)
+			mock.ExpectRollback()
+
+			err := r.UpdateWAC(context.Background(), &entity.UpdateWACRequest{UserId: "user", Id: "wac", Name: ptr("Budi")})
+			assert.Equal(t, tc.code, errorCode(err))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("locks the walk around check", func(t *testing.T) {
+		r, mock := newMockRepository(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`FOR UPDATE`).WithArgs("wac").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "status", "client_id", "vehicle_id"}))
+		mock.ExpectRollback()
+
+		err := r.DeleteWACCondition(context.Background(), &entity.DeleteWACConditionRequest{UserId: "user", Id: "wac"})
+		assert.Equal(t, 404, errorCode(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateWACClient(t *testing.T) {
+	t.Run("client of other walk around checks", func(t *testing.T) {
+		r, mock := newMockRepository(t)
+		expectLock(mock, "user", "offered")
+		mock.ExpectQuery(q("WHERE client_id = ")).WithArgs("client", "wac").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+
+		err := r.UpdateWAC(context.Background(), &entity.UpdateWACRequest{UserId: "user", Id: "wac", Name: ptr("Budi")})
+		assert.Equal(t, 409, errorCode(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("client of this walk around check only", func(t *testing.T) {
+		r, mock := newMockRepository(t)
+		expectLock(mock, "user", "offered")
+		mock.ExpectQuery(q("WHERE client_id = ")).WithArgs("client", "wac").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec(q("UPDATE clients")).WithArgs("Budi", nil, "client").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectFinish(mock, 2)
+
+		err := r.UpdateWAC(context.Background(), &entity.UpdateWACRequest{UserId: "user", Id: "wac", Name: ptr("Budi")})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateWACVehicle(t *testing.T) {
+	t.Run("plate of another vehicle of the client moves the walk around check", func(t *testing.T) {
+		r, mock := newMockRepository(t)
+		expectLock(mock, "user", "offered")
+		expectVehicle(mock, "vehicle", "client", "sedan", "DK 1 A")
+		expectPlate(mock, "DK 2 B", vehicleRows().AddRow("other-vehicle", "client", "sedan", "DK 2 B"))
+		mock.ExpectExec(q("UPDATE walk_around_checks SET vehicle_id = ")).WithArgs("other-vehicle", "wac").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectFinish(mock, 1)
+
+		err := r.UpdateWAC(context.Background(), &entity.UpdateWACRequest{
+			UserId: "user", Id: "wac", VehicleRegistrationNumber: ptr("DK 2 B"),
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("plate of another client", func(t *testing.T) {
+		r, mock := newMockRepository(t)
+		expectLock(mock, "user", "offered")
+		expectVehicle(mock, "vehicle", "client", "sedan", "DK 1 A")
+		expectPlate(mock, "DK 2 B", vehicleRows().AddRow("other-vehicle", "other-client", "sedan", "DK 2 B"))
+		mock.ExpectRollback()
+
+		err := r.UpdateWAC(context.Background(), &entity.UpdateWACRequest{
+			UserId: "user", Id: "wac", VehicleRegistrationNumber: ptr("DK 2 B"),
+		})
+		assert.Equal(t, 409, errorCode(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("plate of a vehicle of another type", func(t *testing.T) {
+		r, mock := newMockRepository(t)
+		expectLock(mock, "user", "offered")
+		expectVehicle(mock, "vehicle", "client", "sedan", "DK 1 A")
+		expectPlate(mock, "DK 2 B", vehicleRows().AddRow("other-vehicle", "client", "sedan", "DK 2 B"))
+		mock.ExpectRollback()
+
+		err := r.UpdateWAC(context.Background(), &entity.UpdateWACRequest{
+			UserId: "user", Id: "wac", VehicleRegistrationNumber: ptr("DK 2 B"), VehicleTypeId: ptr("suv"),
+		})
+		assert.Equal(t, 409, errorCode(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("new plate of a shared vehicle creates a vehicle", func(t *testing.T) {
+		r, mock := newMockRepository(t)
+		expectLock(mock, "user", "offered")
+		expectVehicle(mock, "vehicle", "client", "sedan", "DK 1 A")
+		expectPlate(mock, "DK 2 B", vehicleRows())
+		mock.ExpectQuery(q("WHERE vehicle_id = ")).WithArgs("vehicle", "wac").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectExec(q("INSERT INTO vehicles")).WithArgs(sqlmock.AnyArg(), "client", "sedan", "DK 2 B").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(q("UPDATE walk_around_checks SET vehicle_id = ")).WithArgs(sqlmock.AnyArg(), "wac").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectFinish(mock, 1)
+
+		err := r.UpdateWAC(context.Background(), &entity.UpdateWACRequest{
+			UserId: "user", Id: "wac", VehicleRegistrationNumber: ptr("DK 2 B"),
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("new plate of a vehicle of this walk around check only", func(t *testing.T) {
+		r, mock := newMockRepository(t)
+		expectLock(mock, "user", "offered")
+		expectVehicle(mock, "vehicle", "client", "sedan", "DK 1 A")
+		expectPlate(mock, "DK 2 B", vehicleRows())
+		mock.ExpectQuery(q("WHERE vehicle_id = ")).WithArgs("vehicle", "wac").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec(q("UPDATE vehicles")).WithArgs("suv", "DK 2 B", "vehicle").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectFinish(mock, 1)
+
+		err := r.UpdateWAC(context.Background(), &entity.UpdateWACRequest{
+			UserId: "user", Id: "wac", VehicleRegistrationNumber: ptr("DK 2 B"), VehicleTypeId: ptr("suv"),
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"codebase-app/internal/module/wac/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type wacVehicle struct {
+	Id            string `db:"id"`
+	ClientId      string `db:"client_id"`
+	VehicleTypeId string `db:"vehicle_type_id"`
+	LicenseNumber string `db:"license_number"`
+}
+
+func (r *wacRepository) GetVehicle(ctx context.Context, req *entity.GetVehicleRequest) (entity.GetVehicleResponse, error) {
+	type dao struct {
+		entity.GetVehicleResponse
+		VehicleTypeId   string `db:"vehicle_type_id"`
+		VehicleTypeName string `db:"vehicle_type_name"`
+		ClientId        string `db:"client_id"`
+		ClientName      string `db:"client_name"`
+		ClientPhone     string `db:"client_phone"`
+	}
+
+	var data dao
+
+	query := `
+		SELECT
+			v.id,
+			v.license_number,
+			v.vin,
+			v.year,
+			v.colour,
+			(
+				SELECT wac.mileage
+				FROM walk_around_checks wac
+				WHERE wac.vehicle_id = v.id AND wac.deleted_at IS NULL AND wac.mileage IS NOT NULL
+				ORDER BY wac.created_at DESC
+				LIMIT 1
+			) AS last_mileage,
+			vt.id AS vehicle_type_id,
+			vt.name AS vehicle_type_name,
+			c.id AS client_id,
+			c.name AS client_name,
+			c.phone AS client_phone
+		FROM
+			vehicles v
+		JOIN
+			clients c ON c.id = v.client_id
+		LEFT JOIN
+			vehicle_types vt ON vt.id = v.vehicle_type_id
+		WHERE
+			` + entity.PlateMatch + `
+			AND v.deleted_at IS NULL
+			AND c.deleted_at IS NULL
+	`
+
+	if err := r.db.GetContext(ctx, &data, r.db.Rebind(query), req.LicenseNumber); err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn().Any("payload", req).Msg("repo::GetVehicle - vehicle not found")
+			return data.GetVehicleResponse, errmsg.NewCustomErrors(404, errmsg.WithMessage("Kendaraan tidak ditemukan"))
+		}
+
+		log.Error().Err(err).Any("payload", req).Msg("repo::GetVehicle - failed to get vehicle")
+		return data.GetVehicleResponse, err
+	}
+
+	res := data.GetVehicleResponse
+	res.VehicleType = entity.Common{Id: data.VehicleTypeId, Name: data.VehicleTypeName}
+	res.Client.Id = data.ClientId
+	res.Client.Name = data.ClientName
+	res.Client.Phone = data.ClientPhone
+
+	return res, nil
+}
+
+// vehicleByPlate locks the vehicle of the plate, it is nil for a new plate.
+func (r *wacRepository) vehicleByPlate(ctx context.Context, tx *sqlx.Tx, plate string) (*wacVehicle, error) {
+	var v wacVehicle
+
+	query := `
+		SELECT v.id, v.client_id, v.vehicle_type_id, v.license_number
+		FROM vehicles v
+		WHERE ` + entity.PlateMatch + ` AND v.deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	err := tx.GetContext(ctx, &v, r.db.Rebind(query), plate)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+// resolveVehicle returns the client and the vehicle of a new WAC, see
+// entity.CreateWACRequest. A vehicle changing hands is moved to its new owner.
+func (r *wacRepository) resolveVehicle(ctx context.Context, tx *sqlx.Tx, req *entity.CreateWACRequest) (clientId, vehicleId string, err error) {
+	vehicle, err := r.vehicleByPlate(ctx, tx, req.VehicleRegistrationNumber)
+	if err != nil {
+		log.Error().Err(err).Str("plate", req.VehicleRegistrationNumber).Msg("repo::CreateWAC - Failed to get vehicle")
+		return "", "", err
+	}
+
+	switch {
+	case req.ClientId != nil:
+		clientId = *req.ClientId
+	case vehicle != nil:
+		clientId = vehicle.ClientId
+	default:
+		clientId, err = r.getClientId(ctx, tx, req)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	// a client in the trash comes back with the new walk around check
+	query := `UPDATE clients SET deleted_at = NULL, updated_at = NOW() WHERE id = ? AND deleted_at IS NOT NULL`
+	if _, err = tx.ExecContext(ctx, r.db.Rebind(query), clientId); err != nil {
+		log.Error().Err(err).Str("client_id", clientId).Msg("repo::CreateWAC - Failed to restore client")
+		return "", "", err
+	}
+
+	if vehicle == nil {
+		vehicleId = ulid.Make().String()
+		query = `
+			INSERT INTO vehicles (id, client_id, vehicle_type_id, license_number, vin, year, colour)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`
+		_, err = tx.ExecContext(ctx, r.db.Rebind(query),
+			vehicleId, clientId, req.VehicleTypeId, req.VehicleRegistrationNumber, req.Vin, req.Year, req.Colour)
+		if err != nil {
+			log.Error().Err(err).Str("plate", req.VehicleRegistrationNumber).Msg("repo::CreateWAC - Failed to create vehicle")
+			return "", "", err
+		}
+
+		return clientId, vehicleId, nil
+	}
+
+	query = `
+		UPDATE vehicles
+		SET
+			client_id = ?,
+			vehicle_type_id = ?,
+			vin = COALESCE(?, vin),
+			year = COALESCE(?, year),
+			colour = COALESCE(?, colour),
+			updated_at = NOW()
+		WHERE id = ?
+	`
+	_, err = tx.ExecContext(ctx, r.db.Rebind(query),
+		clientId, req.VehicleTypeId, req.Vin, req.Year, req.Colour, vehicle.Id)
+	if err != nil {
+		log.Error().Err(err).Str("vehicle_id", vehicle.Id).Msg("repo::CreateWAC - Failed to update vehicle")
+		return "", "", err
+	}
+
+	return clientId, vehicle.Id, nil
+}
+
+// getClientId returns the client with the same name and phone, a new client
+// when there is none.
+func (r *wacRepository) getClientId(ctx context.Context, tx *sqlx.Tx, req *entity.CreateWACRequest) (string, error) {
+	var clientId string
+
+	query := `
+		SELECT id
+		FROM clients
+		WHERE LOWER(name) = LOWER(?) AND phone = ?
+		ORDER BY deleted_at IS NULL DESC, created_at
+		LIMIT 1
+	`
+	err := tx.GetContext(ctx, &clientId, r.db.Rebind(query), req.Name, req.WhatsAppNumber)
+	if err == nil {
+		return clientId, nil
+	}
+	if err != sql.ErrNoRows {
+		log.Error().Err(err).Str("name", req.Name).Msg("repo::CreateWAC - Failed to get client id")
+		return "", err
+	}
+
+	clientId = ulid.Make().String()
+	query = `INSERT INTO clients (id, name, phone) VALUES (?, ?, ?)`
+	if _, err = tx.ExecContext(ctx, r.db.Rebind(query), clientId, req.Name, req.WhatsAppNumber); err != nil {
+		log.Error().Err(err).Str("name", req.Name).Msg("repo::CreateWAC - Failed to create new client")
+		return "", err
+	}
+
+	return clientId, nil
+}
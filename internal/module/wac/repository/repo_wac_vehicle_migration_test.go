@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+// vehiclesMigration is the migration splitting the vehicles from the clients.
+const vehiclesMigration = "20241018010000_create_vehicles_table.up.sql"
+
+// id pads a short name into a CHAR(26) id.
+func id(name string) string {
+	return strings.ToUpper(name) + strings.Repeat("0", 26-len(name))
+}
+
+// TestVehiclesMigrationBackfill runs the migrations up to the vehicles one on
+// an empty database and checks the vehicles it backfills. It needs
+// TEST_DATABASE_URL, a postgres database it may drop the tables of.
+func TestVehiclesMigrationBackfill(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`DROP SCHEMA public CASCADE; CREATE SCHEMA public`); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob("../../../../db/migrations/*.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+
+	migrate := func(file string) {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(string(content)); err != nil {
+			t.Fatalf("%s: %v", filepath.Base(file), err)
+		}
+	}
+
+	for _, file := range files {
+		if filepath.Base(file) >= vehiclesMigration {
+			break
+		}
+		migrate(file)
+	}
+
+	// Budi and Andi brought the same plate written differently, Andi last,
+	// Citra's plate belongs to her and to a deleted client without a WAC
+	fixtures := `
+		INSERT INTO roles (id, name) VALUES ('` + id("role") + `', 'service_advisor');
+		INSERT INTO branches (id, name) VALUES ('` + id("branch") + `', 'Makassar');
+		INSERT INTO potencies (id, name) VALUES ('` + id("section") + `', 'GR');
+		INSERT INTO vehicle_types (id, name) VALUES ('` + id("type") + `', 'Avanza');
+		INSERT INTO users (id, role_id, name, email, password)
+			VALUES ('` + id("user") + `', '` + id("role") + `', 'SA', 'sa@example.com', '');
+
+		INSERT INTO clients (id, vehicle_type_id, name, phone, vehicle_license_number, updated_at, deleted_at) VALUES
+			('` + id("budi") + `', '` + id("type") + `', 'Budi', '1', 'DD 1234 AB', '2024-10-05', NULL),
+			('` + id("andi") + `', '` + id("type") + `', 'Andi', '2', 'dd1234ab', '2024-09-01', NULL),
+			('` + id("citra") + `', '` + id("type") + `', 'Citra', '3', 'B 1 X', '2024-08-01', NULL),
+			('` + id("deleted") + `', '` + id("type") + `', 'Dewi', '4', 'B1X', '2024-10-01', '2024-10-02');
+
+		INSERT INTO walk_around_checks (id, branch_id, section_id, user_id, client_id, created_at) VALUES
+			('` + id("wacbudi") + `', '` + id("branch") + `', '` + id("section") + `', '` + id("user") + `', '` + id("budi") + `', '2024-09-01'),
+			('` + id("wacandi") + `', '` + id("branch") + `', '` + id("section") + `', '` + id("user") + `', '` + id("andi") + `', '2024-10-01');
+	`
+	if _, err := db.Exec(fixtures); err != nil {
+		t.Fatal(err)
+	}
+
+	migrate(filepath.Join(filepath.Dir(files[0]), vehiclesMigration))
+
+	type vehicle struct {
+		Id       string `db:"id"`
+		ClientId string `db:"client_id"`
+	}
+
+	var vehicles []vehicle
+	err = db.Select(&vehicles, `SELECT id, client_id FROM vehicles ORDER BY license_number`)
+	assert.NoError(t, err)
+	assert.Equal(t, []vehicle{
+		{Id: id("citra"), ClientId: id("citra")},
+		{Id: id("andi"), ClientId: id("andi")},
+	}, vehicles)
+
+	// both WACs of the plate are of its one vehicle
+	var vehicleIds []string
+	err = db.Select(&vehicleIds, `SELECT vehicle_id FROM walk_around_checks ORDER BY created_at`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{id("andi"), id("andi")}, vehicleIds)
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"codebase-app/internal/module/wac/entity"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const restoreClient = "UPDATE clients SET deleted_at = NULL"
+
+func resolve(t *testing.T, repo *wacRepository, req *entity.CreateWACRequest) (clientId, vehicleId string, err error) {
+	t.Helper()
+
+	tx, err := repo.db.BeginTxx(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	return repo.resolveVehicle(context.Background(), tx, req)
+}
+
+func newCreateRequest(clientId *string) *entity.CreateWACRequest {
+	return &entity.CreateWACRequest{
+		ClientId:                  clientId,
+		Name:                      "Budi",
+		VehicleRegistrationNumber: "DD 1234 AB",
+		VehicleTypeId:             "type",
+		WhatsAppNumber:            "6281234567890",
+	}
+}
+
+func TestResolveVehicleKnownPlateNewOwner(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	mock.ExpectBegin()
+	expectPlate(mock, "DD 1234 AB", vehicleRows().AddRow("vehicle", "old-owner", "type", "DD 1234 AB"))
+	mock.ExpectExec(q(restoreClient)).WithArgs("new-owner").WillReturnResult(sqlmock.NewResult(0, 0))
+	// the vehicle changes hands, its WACs stay with the vehicle
+	mock.ExpectExec(q("UPDATE vehicles")).
+		WithArgs("new-owner", "type", nil, nil, nil, "vehicle").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	clientId, vehicleId, err := resolve(t, repo, newCreateRequest(ptr("new-owner")))
+	assert.NoError(t, err)
+	assert.Equal(t, "new-owner", clientId)
+	assert.Equal(t, "vehicle", vehicleId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResolveVehicleKnownPlateKeepsOwner(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	// without client_id the name and phone do not look up another client
+	mock.ExpectBegin()
+	expectPlate(mock, "DD 1234 AB", vehicleRows().AddRow("vehicle", "owner", "type", "DD 1234 AB"))
+	mock.ExpectExec(q(restoreClient)).WithArgs("owner").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(q("UPDATE vehicles")).
+		WithArgs("owner", "type", nil, nil, nil, "vehicle").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	clientId, vehicleId, err := resolve(t, repo, newCreateRequest(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "owner", clientId)
+	assert.Equal(t, "vehicle", vehicleId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResolveVehicleNewPlateKnownClient(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	mock.ExpectBegin()
+	expectPlate(mock, "DD 1234 AB", vehicleRows())
+	mock.ExpectQuery(q("SELECT id FROM clients WHERE LOWER(name) = LOWER($1) AND phone = $2")).
+		WithArgs("Budi", "6281234567890").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("client"))
+	mock.ExpectExec(q(restoreClient)).WithArgs("client").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(q("INSERT INTO vehicles")).
+		WithArgs(sqlmock.AnyArg(), "client", "type", "DD 1234 AB", nil, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	clientId, vehicleId, err := resolve(t, repo, newCreateRequest(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "client", clientId)
+	assert.Len(t, vehicleId, 26)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResolveVehicleNewPlateNewClient(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	mock.ExpectBegin()
+	expectPlate(mock, "DD 1234 AB", vehicleRows())
+	mock.ExpectQuery(q("SELECT id FROM clients")).
+		WithArgs("Budi", "6281234567890").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec(q("INSERT INTO clients (id, name, phone)")).
+		WithArgs(sqlmock.AnyArg(), "Budi", "6281234567890").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(q(restoreClient)).WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(q("INSERT INTO vehicles")).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "type", "DD 1234 AB", nil, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	clientId, vehicleId, err := resolve(t, repo, newCreateRequest(nil))
+	assert.NoError(t, err)
+	assert.Len(t, clientId, 26)
+	assert.Len(t, vehicleId, 26)
+	assert.NotEqual(t, clientId, vehicleId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -94,6 +94,24 @@ func (s *wacService) OfferWAC(ctx context.Context, req *entity.OfferWACRequest)
 	return s.repo.OfferWAC(ctx, req)
 }
 
+func (s *wacService) SaveTradeInQuote(ctx context.Context, req *entity.SaveTradeInQuoteRequest) (entity.GetWACResponse, error) {
+	isCreator, err := s.repo.IsWACCreator(ctx, req.UserId, req.Id)
+	if err != nil {
+		return entity.GetWACResponse{}, err
+	}
+
+	if !isCreator {
+		log.Warn().Any("payload", req).Msg("service::SaveTradeInQuote - You are not the creator of this walk around check")
+		return entity.GetWACResponse{}, errmsg.NewCustomErrors(403, errmsg.WithMessage("Anda bukan pembuat walk around check ini"))
+	}
+
+	if err := s.repo.SaveTradeInQuote(ctx, req); err != nil {
+		return entity.GetWACResponse{}, err
+	}
+
+	return s.repo.GetWAC(ctx, &entity.GetWACRequest{Id: req.Id})
+}
+
 func (s *wacService) AddRevenue(ctx context.Context, req *entity.AddWACRevenueRequest) (entity.AddWACRevenueResponse, error) {
 	var (
 		resp entity.AddWACRevenueResponse
@@ -151,3 +169,81 @@ func (s *wacService) AddRevenues(ctx context.Context, req *entity.AddWACRevenues
 
 	return resp, s.repo.AddRevenues(ctx, req)
 }
+
+// UpdateWAC amends the client of a WAC that is not offered yet and returns
+// the amended WAC.
+func (s *wacService) UpdateWAC(ctx context.Context, req *entity.UpdateWACRequest) (entity.GetWACResponse, error) {
+	if err := s.repo.UpdateWAC(ctx, req); err != nil {
+		return entity.GetWACResponse{}, err
+	}
+
+	return s.repo.GetWAC(ctx, &entity.GetWACRequest{Id: req.Id})
+}
+
+func (s *wacService) AddWACCondition(ctx context.Context, req *entity.AddWACConditionRequest) (entity.GetWACResponse, error) {
+	path, err := s.storage.Save(req.Image, PRIVATE_FOLDER)
+	if err != nil {
+		req.RemoveBase64()
+		log.Error().Err(err).Any("payload", req).Msg("service::AddWACCondition - Failed to save image")
+		return entity.GetWACResponse{}, errmsg.NewCustomErrors(http.StatusBadRequest).Add("image", "gambar gagal disimpan.")
+	}
+	req.Path = path
+
+	if err := s.repo.AddWACCondition(ctx, req); err != nil {
+		s.deleteImage(path)
+		return entity.GetWACResponse{}, err
+	}
+
+	return s.repo.GetWAC(ctx, &entity.GetWACRequest{Id: req.Id})
+}
+
+// UpdateWACCondition amends a condition, a replaced image is only deleted
+// once the new one is saved with the condition.
+func (s *wacService) UpdateWACCondition(ctx context.Context, req *entity.UpdateWACConditionRequest) (entity.GetWACResponse, error) {
+	if req.Image != nil {
+		path, err := s.storage.Save(*req.Image, PRIVATE_FOLDER)
+		if err != nil {
+			req.RemoveBase64()
+			log.Error().Err(err).Any("payload", req).Msg("service::UpdateWACCondition - Failed to save image")
+			return entity.GetWACResponse{}, errmsg.NewCustomErrors(http.StatusBadRequest).Add("image", "gambar gagal disimpan.")
+		}
+		req.Path = path
+	}
+
+	if err := s.repo.UpdateWACCondition(ctx, req); err != nil {
+		s.deleteImage(req.Path)
+		return entity.GetWACResponse{}, err
+	}
+
+	s.deleteImage(req.OldPath)
+
+	return s.repo.GetWAC(ctx, &entity.GetWACRequest{Id: req.Id})
+}
+
+// DeleteWACCondition soft deletes a condition, its image is kept with it.
+func (s *wacService) DeleteWACCondition(ctx context.Context, req *entity.DeleteWACConditionRequest) (entity.GetWACResponse, error) {
+	if err := s.repo.DeleteWACCondition(ctx, req); err != nil {
+		return entity.GetWACResponse{}, err
+	}
+
+	return s.repo.GetWAC(ctx, &entity.GetWACRequest{Id: req.Id})
+}
+
+func (s *wacService) DeleteWAC(ctx context.Context, req *entity.DeleteWACRequest) error {
+	return s.repo.DeleteWAC(ctx, req)
+}
+
+func (s *wacService) GetVehicle(ctx context.Context, req *entity.GetVehicleRequest) (entity.GetVehicleResponse, error) {
+	return s.repo.GetVehicle(ctx, req)
+}
+
+// deleteImage removes a stored image, a failure only leaves an orphan file.
+func (s *wacService) deleteImage(path string) {
+	if path == "" {
+		return
+	}
+
+	if err := s.storage.Delete(path); err != nil {
+		log.Warn().Err(err).Str("path", path).Msg("service::deleteImage - Failed to delete image")
+	}
+}
@@ -8,14 +8,20 @@ import (
 	commonHandler "codebase-app/internal/module/common/handler"
 	dashboardHandler "codebase-app/internal/module/dashboard/handler"
 	employeeHandler "codebase-app/internal/module/employee/handler"
+	exportHandler "codebase-app/internal/module/export/handler"
 	mrsHandler "codebase-app/internal/module/mrs/handler"
 	promotionHandler "codebase-app/internal/module/promotion/handler"
+	subscriptionHandler "codebase-app/internal/module/subscription/handler"
+	tierHandler "codebase-app/internal/module/tier/handler"
+	tradeInHandler "codebase-app/internal/module/tradein/handler"
+	trashHandler "codebase-app/internal/module/trash/handler"
 	userHandler "codebase-app/internal/module/user/handler"
 	wacHandler "codebase-app/internal/module/wac/handler"
 
 	"codebase-app/pkg/response"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog/log"
@@ -29,7 +35,7 @@ func SetupRoutes(app *fiber.App) {
 	api.Get("/storage/private/:filename", m.ValidateSignedURL, storageFile)
 
 	wacHandler.NewWacHandler(storage).Register(api)
-	userHandler.NewUserHandler().Register(api)
+	userHandler.NewUserHandler(storage).Register(api)
 	commonHandler.NewCommonHandler().Register(api)
 	dashboardHandler.NewDashboardHandler().Register(api)
 	mrsHandler.NewMRSHandler().Register(api)
@@ -37,6 +43,11 @@ func SetupRoutes(app *fiber.App) {
 	promotionHandler.NewPromotionHandler(storage).Register(api)
 	clientHandler.NewClientHandler().Register(api)
 	employeeHandler.NewEmployeeHandler().Register(api)
+	exportHandler.NewExportHandler().Register(api)
+	tierHandler.NewTierHandler().Register(api)
+	subscriptionHandler.NewSubscriptionHandler().Register(api)
+	trashHandler.NewTrashHandler(storage).Register(api)
+	tradeInHandler.NewTradeInHandler().Register(api)
 
 	// fallback route
 	app.Use(func(c *fiber.Ctx) error {
@@ -65,16 +76,29 @@ func storageFile(c *fiber.Ctx) error {
 		filePath = filepath.Join("storage", "private", fileName)
 	)
 
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+	// the exports may be large, the file is streamed instead of read whole,
+	// the response closes it once sent
+	file, err := os.Open(filePath)
+	if os.IsNotExist(err) {
 		log.Error().Err(err).Any("url", filePath).Msg("handler::getWAC - File not found")
 		return c.Status(fiber.StatusNotFound).JSON(response.Error("File not found"))
 	}
+	if err != nil {
+		log.Error().Err(err).Any("url", filePath).Msg("handler::getWAC - Failed to read file")
+		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(err.Error()))
+	}
 
-	fileBytes, err := os.ReadFile(filePath)
+	info, err := file.Stat()
 	if err != nil {
+		file.Close()
 		log.Error().Err(err).Any("url", filePath).Msg("handler::getWAC - Failed to read file")
 		return c.Status(fiber.StatusInternalServerError).JSON(response.Error(err.Error()))
 	}
 
-	return c.Send(fileBytes)
+	c.Type(filepath.Ext(fileName))
+	if strings.HasPrefix(fileName, "export-") {
+		c.Attachment(fileName)
+	}
+
+	return c.SendStream(file, int(info.Size()))
 }
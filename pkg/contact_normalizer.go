@@ -0,0 +1,8 @@
+package pkg
+
+import "strings"
+
+// NormalizeName trims a name and collapses its inner spaces, the case is kept.
+func NormalizeName(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
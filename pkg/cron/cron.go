@@ -0,0 +1,188 @@
+// Package cron parses standard 5 field cron expressions (minute, hour, day of
+// month, month, day of week) and computes their next run.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression, each field is a bitset of the
+// allowed values.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// when both days are restricted either one matching is enough
+	domStar, dowStar bool
+}
+
+type bounds struct {
+	name     string
+	min, max int
+}
+
+var (
+	minutes = bounds{"minute", 0, 59}
+	hours   = bounds{"hour", 0, 23}
+	doms    = bounds{"day of month", 1, 31}
+	months  = bounds{"month", 1, 12}
+	dows    = bounds{"day of week", 0, 7}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a cron expression such as "0 7 * * 1" or a macro such as
+// "@weekly".
+func Parse(expr string) (Schedule, error) {
+	var s Schedule
+
+	expr = strings.TrimSpace(expr)
+	if m, ok := macros[expr]; ok {
+		expr = m
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return s, fmt.Errorf("cron: expected 5 fields, got %d", len(fields))
+	}
+
+	var err error
+	if s.minute, err = parseField(fields[0], minutes); err != nil {
+		return s, err
+	}
+	if s.hour, err = parseField(fields[1], hours); err != nil {
+		return s, err
+	}
+	if s.dom, err = parseField(fields[2], doms); err != nil {
+		return s, err
+	}
+	if s.month, err = parseField(fields[3], months); err != nil {
+		return s, err
+	}
+	if s.dow, err = parseField(fields[4], dows); err != nil {
+		return s, err
+	}
+
+	// 7 is sunday as well
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	s.domStar = fields[2] == "*" || fields[2] == "?"
+	s.dowStar = fields[4] == "*" || fields[4] == "?"
+
+	return s, nil
+}
+
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		var (
+			rng  = part
+			step = 1
+			err  error
+		)
+
+		if i := strings.Index(part, "/"); i >= 0 {
+			rng = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step < 1 {
+				return 0, fmt.Errorf("cron: invalid step in %s %q", b.name, part)
+			}
+		}
+
+		lo, hi := b.min, b.max
+		switch {
+		case rng == "*" || rng == "?":
+		case strings.Contains(rng, "-"):
+			ends := strings.SplitN(rng, "-", 2)
+			if lo, err = strconv.Atoi(ends[0]); err != nil {
+				return 0, fmt.Errorf("cron: invalid %s %q", b.name, part)
+			}
+			if hi, err = strconv.Atoi(ends[1]); err != nil {
+				return 0, fmt.Errorf("cron: invalid %s %q", b.name, part)
+			}
+		default:
+			if lo, err = strconv.Atoi(rng); err != nil {
+				return 0, fmt.Errorf("cron: invalid %s %q", b.name, part)
+			}
+			hi = lo
+			if strings.Contains(part, "/") {
+				hi = b.max
+			}
+		}
+
+		if lo < b.min || hi > b.max || lo > hi {
+			return 0, fmt.Errorf("cron: %s %q out of range %d-%d", b.name, part, b.min, b.max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+
+	return bits, nil
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<v) != 0
+}
+
+func (s Schedule) dayMatches(t time.Time) bool {
+	dom, dow := has(s.dom, t.Day()), has(s.dow, int(t.Weekday()))
+
+	switch {
+	case s.domStar && s.dowStar:
+		return true
+	case s.domStar:
+		return dow
+	case s.dowStar:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// Next returns the first run strictly after t in the location of t, or the
+// zero time when there is none within five years (e.g. 30 February).
+func (s Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if !has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !has(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+
+		if !has(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
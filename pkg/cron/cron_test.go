@@ -0,0 +1,42 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Makassar")
+	from := time.Date(2024, 10, 16, 9, 30, 15, 0, loc) // wednesday
+
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 7 * * 1", time.Date(2024, 10, 21, 7, 0, 0, 0, loc)},
+		{"*/15 * * * *", time.Date(2024, 10, 16, 9, 45, 0, 0, loc)},
+		{"@monthly", time.Date(2024, 11, 1, 0, 0, 0, 0, loc)},
+		{"30 9 16 10 *", time.Date(2025, 10, 16, 9, 30, 0, 0, loc)},
+		{"0 8 1-5 * 7", time.Date(2024, 10, 20, 8, 0, 0, 0, loc)},
+		{"0 9,18 * * 1-5", time.Date(2024, 10, 16, 18, 0, 0, 0, loc)},
+	}
+
+	for _, c := range cases {
+		s, err := Parse(c.expr)
+		assert.NoError(t, err, c.expr)
+		assert.Equal(t, c.want, s.Next(from), c.expr)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := Parse(expr)
+		assert.Error(t, err, expr)
+	}
+
+	s, err := Parse("0 0 30 2 *")
+	assert.NoError(t, err)
+	assert.True(t, s.Next(time.Now()).IsZero())
+}
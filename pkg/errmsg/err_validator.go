@@ -29,6 +29,7 @@ func errorValidationHandler[T any](err error, payload *T) (int, map[string][]str
 
 			// Get the error message
 		)
+		fieldParts = withoutEmbedded(reflect.TypeOf(payload), strings.Split(err.StructNamespace(), "."), fieldParts)
 		lastField := fieldParts[len(fieldParts)-1]                                // get the last element
 		fieldParts = fieldParts[1:]                                               // remove the first element
 		field = strings.Join(fieldParts, ".")                                     // join the rest of the elements
@@ -66,6 +67,12 @@ func errorValidationHandler[T any](err error, payload *T) (int, map[string][]str
 		case "strong_password":
 			// message = fmt.Sprintf("%s must be at least 12 characters and contain at least one uppercase letter, one lowercase letter, and one number.", fieldInMsg)
 			message = fmt.Sprintf("%s minimal 8 karakter dan harus mengandung setidaknya satu huruf besar, satu huruf kecil, dan satu angka.", fieldInMsg)
+		case "id_plate":
+			// message = fmt.Sprintf("%s is not a valid license plate, e.g. B 1234 XYZ.", fieldInMsg)
+			message = fmt.Sprintf("%s bukan nomor polisi yang valid, contoh B 1234 XYZ.", fieldInMsg)
+		case "id_phone":
+			// message = fmt.Sprintf("%s is not a valid Indonesian phone number.", fieldInMsg)
+			message = fmt.Sprintf("%s bukan nomor telepon Indonesia yang valid.", fieldInMsg)
 		case "exist":
 			// message = "resource is not exist."
 			message = "sumber data tidak ditemukan."
@@ -116,6 +123,12 @@ func errorValidationHandler[T any](err error, payload *T) (int, map[string][]str
 				// message = fmt.Sprintf("%s must not have more than %s items.", fieldInMsg, err.Param())
 				message = fmt.Sprintf("%s harus tidak lebih dari %s item.", fieldInMsg, err.Param())
 			}
+		case "len":
+			// message = fmt.Sprintf("%s must be %s characters long.", fieldInMsg, err.Param())
+			message = fmt.Sprintf("%s harus %s karakter.", fieldInMsg, err.Param())
+		case "alphanum":
+			// message = fmt.Sprintf("%s must only contain letters and numbers.", fieldInMsg)
+			message = fmt.Sprintf("%s hanya boleh berisi huruf dan angka.", fieldInMsg)
 		case "gt":
 			// message = fmt.Sprintf("%s must be greater than %s.", fieldInMsg, err.Param())
 			message = fmt.Sprintf("%s harus lebih dari %s.", fieldInMsg, err.Param())
@@ -164,6 +177,11 @@ func errorValidationHandler[T any](err error, payload *T) (int, map[string][]str
 
 			// message = fmt.Sprintf("%s must be equal to %s.", fieldInMsg, eqFieldName)
 			message = fmt.Sprintf("%s harus sama dengan %s.", fieldInMsg, eqFieldName)
+		case "nefield":
+			neFieldTag, _ := reflect.TypeOf(payload).Elem().FieldByName(err.Param())
+			neFieldName := strings.ReplaceAll(neFieldTag.Tag.Get("json"), "_", " ")
+
+			message = fmt.Sprintf("%s tidak boleh sama dengan %s.", fieldInMsg, neFieldName)
 		case "oneof":
 			// message = fmt.Sprintf("%s must be one of %s.", fieldInMsg, err.Param())
 			// message = fmt.Sprintf("%s harus salah satu dari %s.", fieldInMsg, err.Param())
@@ -187,3 +205,44 @@ func errorValidationHandler[T any](err error, payload *T) (int, map[string][]str
 
 	return code, errorMessages
 }
+
+// withoutEmbedded drops the parts of the namespace that name an embedded
+// struct, so a field promoted from it is reported as if declared on the
+// payload ("from" instead of "Request.from").
+func withoutEmbedded(t reflect.Type, structParts, parts []string) []string {
+	if len(structParts) != len(parts) {
+		return parts
+	}
+
+	res := []string{parts[0]}
+	for i := 1; i < len(structParts); i++ {
+		for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+			t = t.Elem()
+		}
+
+		name := structParts[i]
+		if idx := strings.Index(name, "["); idx >= 0 {
+			name = name[:idx]
+		}
+
+		var f reflect.StructField
+		ok := false
+		if t != nil && t.Kind() == reflect.Struct {
+			f, ok = t.FieldByName(name)
+		}
+
+		if ok && f.Anonymous && i < len(structParts)-1 {
+			t = f.Type
+			continue
+		}
+
+		res = append(res, parts[i])
+		if ok {
+			t = f.Type
+		} else {
+			t = nil
+		}
+	}
+
+	return res
+}
@@ -1,6 +1,9 @@
 package pkg
 
-import "strings"
+import (
+	"strings"
+	"unicode"
+)
 
 func SanitizeKeyword(keyword string) string {
 	keyword = strings.ReplaceAll(keyword, "'", "''")  // handle single quote
@@ -24,3 +27,23 @@ func FormatKeywords(keyword string) string {
 	}
 	return strings.Join(keywords, " | ")
 }
+
+// PrefixQuery builds a to_tsquery query matching the words of keyword by
+// prefix, any of them or with all every one of them. The words are the runs
+// of letters and digits, the way the parser splits the documents, so quotes,
+// backslashes and the query operators are dropped instead of breaking it.
+func PrefixQuery(keyword string, all bool) string {
+	words := strings.FieldsFunc(keyword, func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+
+	op := " | "
+	if all {
+		op = " & "
+	}
+
+	return strings.Join(words, op)
+}
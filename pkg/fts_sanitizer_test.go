@@ -0,0 +1,26 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixQuery(t *testing.T) {
+	cases := []struct {
+		keyword string
+		any     string
+		all     string
+	}{
+		{"avanza", "avanza:*", "avanza:*"},
+		{"o'brien", "o:* | brien:*", "o:* & brien:*"},
+		{`c:\ a&b|!x`, "c:* | a:* | b:* | x:*", "c:* & a:* & b:* & x:*"},
+		{"(grand) <new> xenia*", "grand:* | new:* | xenia:*", "grand:* & new:* & xenia:*"},
+		{"'&|!:", "", ""},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.any, PrefixQuery(c.keyword, false), c.keyword)
+		assert.Equal(t, c.all, PrefixQuery(c.keyword, true), c.keyword)
+	}
+}
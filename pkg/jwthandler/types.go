@@ -9,6 +9,9 @@ import (
 type CustomClaims struct {
 	UserId string `json:"user_id"`
 	Role   string `json:"role"`
+	// TokenVersion is the token version of the user when the token was
+	// issued, a password change bumps it
+	TokenVersion int `json:"ver"`
 	jwt.RegisteredClaims
 }
 
@@ -21,6 +24,7 @@ type CostumClaimsWs struct {
 type CostumClaimsPayload struct {
 	UserId          string    `json:"user_id"`
 	Role            string    `json:"role"`
+	TokenVersion    int       `json:"token_version"`
 	TokenExpiration time.Time `json:"token_expiration"`
 }
 
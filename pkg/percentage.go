@@ -0,0 +1,13 @@
+package pkg
+
+import "math"
+
+// Percentage returns part of whole as a percentage rounded to two decimals, 0
+// when there is nothing to divide.
+func Percentage(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+
+	return math.Round(float64(part)*10000/float64(whole)) / 100
+}
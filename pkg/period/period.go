@@ -0,0 +1,245 @@
+// Package period resolves the shared reporting period parameters (from, to,
+// granularity, timezone and comparison) into day bounds and buckets.
+package period
+
+import (
+	"codebase-app/pkg/errmsg"
+	"time"
+)
+
+const (
+	Day     = "day"
+	Week    = "week"
+	Month   = "month"
+	Quarter = "quarter"
+
+	DateFormat      = "2006-01-02"
+	DefaultTimezone = "Asia/Makassar"
+
+	// MaxBuckets caps the number of buckets a single request may produce.
+	MaxBuckets = 1000
+)
+
+// Today is the current date in DefaultTimezone for the queries, the dates the
+// modules keep (promotions, price lists, quotes) change at midnight there.
+const Today = `(NOW() AT TIME ZONE '` + DefaultTimezone + `')::date`
+
+// Now is the current time in DefaultTimezone.
+func Now() time.Time {
+	loc, err := time.LoadLocation(DefaultTimezone)
+	if err != nil {
+		loc = time.Local
+	}
+
+	return time.Now().In(loc)
+}
+
+// Request holds the period query parameters, embed it in a request struct.
+type Request struct {
+	From        string `query:"from" json:"from" validate:"omitempty,datetime=2006-01-02"`
+	To          string `query:"to" json:"to" validate:"omitempty,datetime=2006-01-02"`
+	Granularity string `query:"granularity" json:"granularity" validate:"omitempty,oneof=day week month quarter"`
+	Timezone    string `query:"timezone" json:"timezone" validate:"omitempty,timezone"`
+	// Compare adds the values of the previous period of the same length
+	Compare bool `query:"compare" json:"compare"`
+}
+
+// Default fills what the request leaves empty.
+type Default struct {
+	Granularity string
+	// Months before the current month From starts at, 0 starts at the first
+	// day of the current month.
+	Months int
+	// Days before today From starts at, used instead of Months when set.
+	Days int
+}
+
+// Period is a resolved request, From and To are midnights in Location and
+// both days are included.
+type Period struct {
+	From        time.Time
+	To          time.Time
+	Granularity string
+	Location    *time.Location
+	Compare     bool
+}
+
+// Resolve validates the request and fills the missing values from def.
+func (r Request) Resolve(def Default) (Period, error) {
+	var (
+		p    Period
+		errs = errmsg.NewCustomErrors(400)
+	)
+
+	timezone := r.Timezone
+	if timezone == "" {
+		timezone = DefaultTimezone
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return p, errs.Add("timezone", "timezone tidak valid")
+	}
+
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	p.Location = loc
+	p.Compare = r.Compare
+	p.Granularity = r.Granularity
+	if p.Granularity == "" {
+		p.Granularity = def.Granularity
+	}
+	if p.Granularity == "" {
+		p.Granularity = Day
+	}
+
+	switch {
+	case r.From != "":
+		if p.From, err = time.ParseInLocation(DateFormat, r.From, loc); err != nil {
+			errs.Add("from", "format tanggal tidak valid")
+		}
+	case def.Days > 0:
+		p.From = today.AddDate(0, 0, -def.Days)
+	default:
+		p.From = time.Date(today.Year(), today.Month()-time.Month(def.Months), 1, 0, 0, 0, 0, loc)
+	}
+
+	p.To = today
+	if r.To != "" {
+		if p.To, err = time.ParseInLocation(DateFormat, r.To, loc); err != nil {
+			errs.Add("to", "format tanggal tidak valid")
+		}
+	}
+
+	if errs.HasErrors() {
+		return p, errs
+	}
+
+	if p.From.After(p.To) {
+		return p, errs.
+			Add("from", "tanggal awal tidak boleh lebih besar dari tanggal akhir").
+			Add("to", "tanggal akhir tidak boleh lebih kecil dari tanggal awal")
+	}
+
+	if n := len(p.Buckets()); n > MaxBuckets {
+		return p, errs.Add("granularity", "rentang tanggal terlalu panjang untuk granularity ini")
+	}
+
+	return p, nil
+}
+
+// MonthOf returns the period covering a whole 2006-01 month.
+func MonthOf(month string, loc *time.Location) (Period, error) {
+	start, err := time.ParseInLocation("2006-01", month, loc)
+	if err != nil {
+		return Period{}, errmsg.NewCustomErrors(400).Add("month", "format bulan tidak valid")
+	}
+
+	return Period{
+		From:        start,
+		To:          start.AddDate(0, 1, -1),
+		Granularity: Month,
+		Location:    loc,
+	}, nil
+}
+
+// End returns the midnight after the last day, the exclusive upper bound.
+func (p Period) End() time.Time {
+	return p.To.AddDate(0, 0, 1)
+}
+
+// FromDate and EndDate are the bounds formatted for date columns, EndDate is
+// exclusive.
+func (p Period) FromDate() string { return p.From.Format(DateFormat) }
+func (p Period) EndDate() string  { return p.End().Format(DateFormat) }
+
+// Days returns the number of days in the period.
+func (p Period) Days() int {
+	y1, m1, d1 := p.From.Date()
+	y2, m2, d2 := p.To.Date()
+	from := time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC)
+
+	return int(to.Sub(from).Hours()/24) + 1
+}
+
+// Previous returns the period of the same length right before p. Whole
+// months shift by months so a month is compared with the previous month.
+func (p Period) Previous() Period {
+	prev := p
+	prev.Compare = false
+
+	if p.From.Day() == 1 && p.End().Day() == 1 {
+		months := monthsBetween(p.From, p.End())
+		prev.From = p.From.AddDate(0, -months, 0)
+		prev.To = p.From.AddDate(0, 0, -1)
+		return prev
+	}
+
+	prev.To = p.From.AddDate(0, 0, -1)
+	prev.From = prev.To.AddDate(0, 0, -(p.Days() - 1))
+	return prev
+}
+
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+}
+
+// Truncate returns the start of the bucket t belongs to, weeks start on
+// Monday like postgres date_trunc.
+func Truncate(granularity string, t time.Time) time.Time {
+	y, m, d := t.Date()
+	loc := t.Location()
+
+	switch granularity {
+	case Week:
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, loc)
+	case Month:
+		return time.Date(y, m, 1, 0, 0, 0, 0, loc)
+	case Quarter:
+		return time.Date(y, m-(m-1)%3, 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, loc)
+	}
+}
+
+// Next returns the start of the bucket following the one starting at t.
+func Next(granularity string, t time.Time) time.Time {
+	switch granularity {
+	case Week:
+		return t.AddDate(0, 0, 7)
+	case Month:
+		return t.AddDate(0, 1, 0)
+	case Quarter:
+		return t.AddDate(0, 3, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+type Bucket struct {
+	Key   string    // start of the bucket in DateFormat
+	Start time.Time // may be before the period From for the first bucket
+	End   time.Time // exclusive
+}
+
+// Buckets returns every bucket touching the period, in ascending order.
+func (p Period) Buckets() []Bucket {
+	buckets := make([]Bucket, 0)
+
+	for start := Truncate(p.Granularity, p.From); !start.After(p.To); start = Next(p.Granularity, start) {
+		buckets = append(buckets, Bucket{
+			Key:   start.Format(DateFormat),
+			Start: start,
+			End:   Next(p.Granularity, start),
+		})
+
+		if len(buckets) > MaxBuckets {
+			break
+		}
+	}
+
+	return buckets
+}
@@ -0,0 +1,68 @@
+package period
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+	p, err := Request{From: "2024-10-01", To: "2024-10-16", Granularity: Week}.Resolve(Default{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Asia/Makassar", p.Location.String())
+	assert.Equal(t, 16, p.Days())
+	assert.Equal(t, "2024-10-17", p.EndDate())
+
+	_, err = Request{From: "2024-10-16", To: "2024-10-01"}.Resolve(Default{})
+	assert.Error(t, err)
+
+	_, err = Request{Timezone: "Mars/Olympus"}.Resolve(Default{})
+	assert.Error(t, err)
+
+	_, err = Request{From: "2000-01-01", To: "2024-10-01", Granularity: Day}.Resolve(Default{})
+	assert.Error(t, err)
+}
+
+func TestBuckets(t *testing.T) {
+	loc := time.UTC
+	p := Period{
+		From:        time.Date(2024, 10, 2, 0, 0, 0, 0, loc),
+		To:          time.Date(2024, 10, 16, 0, 0, 0, 0, loc),
+		Granularity: Week,
+		Location:    loc,
+	}
+
+	var keys []string
+	for _, b := range p.Buckets() {
+		keys = append(keys, b.Key)
+	}
+	assert.Equal(t, []string{"2024-09-30", "2024-10-07", "2024-10-14"}, keys)
+
+	p.Granularity = Quarter
+	p.To = time.Date(2025, 1, 1, 0, 0, 0, 0, loc)
+	keys = nil
+	for _, b := range p.Buckets() {
+		keys = append(keys, b.Key)
+	}
+	assert.Equal(t, []string{"2024-10-01", "2025-01-01"}, keys)
+}
+
+func TestPrevious(t *testing.T) {
+	loc := time.UTC
+
+	month, err := MonthOf("2024-03", loc)
+	assert.NoError(t, err)
+	prev := month.Previous()
+	assert.Equal(t, "2024-02-01", prev.FromDate())
+	assert.Equal(t, "2024-02-29", prev.To.Format(DateFormat))
+
+	days := Period{
+		From:     time.Date(2024, 10, 10, 0, 0, 0, 0, loc),
+		To:       time.Date(2024, 10, 16, 0, 0, 0, 0, loc),
+		Location: loc,
+	}
+	prev = days.Previous()
+	assert.Equal(t, "2024-10-03", prev.FromDate())
+	assert.Equal(t, "2024-10-09", prev.To.Format(DateFormat))
+}
@@ -42,3 +42,17 @@ func GenerateSignedURL(link string, expiration time.Duration) (l SignedURL, err
 
 	return l, nil
 }
+
+// VerifySignedURL checks the signature of a link signed by GenerateSignedURL,
+// the link is given without the expires and signature parameters.
+func VerifySignedURL(link string, expires int64, signature string) bool {
+	if time.Now().UTC().Unix() > expires {
+		return false
+	}
+
+	h := hmac.New(sha256.New, []byte(config.Envs.Guard.JwtPrivateKey))
+	h.Write([]byte(fmt.Sprintf("%s%d", link, expires)))
+	expected := hex.EncodeToString(h.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
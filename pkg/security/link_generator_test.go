@@ -0,0 +1,33 @@
+package security
+
+import (
+	"codebase-app/internal/infrastructure/config"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifySignedURL(t *testing.T) {
+	config.Envs = &config.Config{}
+	config.Envs.Guard.JwtPrivateKey = "secret"
+
+	link := "http://localhost/api/promotions/01J9Z/redirect?user_id=01J9Y"
+	signed, err := GenerateSignedURL(link, time.Hour)
+	assert.NoError(t, err)
+
+	u, err := url.Parse(signed.Link)
+	assert.NoError(t, err)
+	expires, err := strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+	assert.NoError(t, err)
+
+	assert.True(t, VerifySignedURL(link, expires, u.Query().Get("signature")))
+	assert.False(t, VerifySignedURL(link+"0", expires, signed.Signature))
+	assert.False(t, VerifySignedURL(link, expires+1, signed.Signature))
+
+	expired, err := GenerateSignedURL(link, -time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, VerifySignedURL(link, expired.Expires, expired.Signature))
+}
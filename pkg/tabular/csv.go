@@ -0,0 +1,53 @@
+package tabular
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// utf8BOM makes Excel open the file as UTF-8.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+type csvWriter struct {
+	w    *csv.Writer
+	cols []Column
+	opt  Options
+}
+
+func newCSVWriter(w io.Writer, columns []Column, opt Options) (*csvWriter, error) {
+	if _, err := w.Write(utf8BOM); err != nil {
+		return nil, err
+	}
+
+	c := &csvWriter{w: csv.NewWriter(w), cols: columns, opt: opt}
+
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Header(opt.Locale)
+	}
+
+	if err := c.w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *csvWriter) WriteRow(values []any) error {
+	row := formatRow(c.cols, values, c.opt)
+
+	record := make([]string, len(row))
+	for i, v := range row {
+		if v != nil {
+			record[i] = fmt.Sprint(v)
+		}
+	}
+
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
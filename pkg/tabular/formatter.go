@@ -0,0 +1,45 @@
+package tabular
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateTime formats a time in the location of the export.
+func DateTime(v any, opt Options) any {
+	t, ok := v.(time.Time)
+	if !ok {
+		return v
+	}
+
+	return t.In(opt.location()).Format("2006-01-02 15:04:05")
+}
+
+// Date formats a time as a date in the location of the export.
+func Date(v any, opt Options) any {
+	t, ok := v.(time.Time)
+	if !ok {
+		return v
+	}
+
+	return t.In(opt.location()).Format("2006-01-02")
+}
+
+// Labels maps raw values to a label per locale, unknown values are kept.
+func Labels(labels map[string]map[string]string) Formatter {
+	return func(v any, opt Options) any {
+		perLocale, ok := labels[fmt.Sprint(v)]
+		if !ok {
+			return v
+		}
+
+		if l, ok := perLocale[opt.Locale]; ok {
+			return l
+		}
+		if l, ok := perLocale[LocaleID]; ok {
+			return l
+		}
+
+		return v
+	}
+}
@@ -0,0 +1,36 @@
+package tabular
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+type ndjsonWriter struct {
+	buf  *bufio.Writer
+	enc  *json.Encoder
+	cols []Column
+	opt  Options
+}
+
+func newNDJSONWriter(w io.Writer, columns []Column, opt Options) *ndjsonWriter {
+	buf := bufio.NewWriter(w)
+
+	return &ndjsonWriter{buf: buf, enc: json.NewEncoder(buf), cols: columns, opt: opt}
+}
+
+// WriteRow writes one json object per line keyed by the column keys.
+func (n *ndjsonWriter) WriteRow(values []any) error {
+	row := formatRow(n.cols, values, n.opt)
+
+	obj := make(map[string]any, len(row))
+	for i, c := range n.cols {
+		obj[c.Key] = row[i]
+	}
+
+	return n.enc.Encode(obj)
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.buf.Flush()
+}
@@ -0,0 +1,236 @@
+// Package tabular renders rows of a report into tabular formats (xlsx, csv
+// and newline-delimited json). A report declares its columns once and every
+// format is rendered from the same declaration.
+package tabular
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	FormatXLSX   = "xlsx"
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+const (
+	LocaleID = "id"
+	LocaleEN = "en"
+)
+
+var ErrUnsupportedFormat = errors.New("tabular: unsupported format")
+
+// Formatter converts a raw row value into the value written to the output.
+type Formatter func(v any, opt Options) any
+
+type Column struct {
+	Key     string            // key used by the ndjson format
+	Headers map[string]string // header per locale
+	Width   float64           // xlsx column width, 0 uses the default
+	Format  Formatter
+}
+
+// Header returns the header of the column in the given locale, it falls back
+// to Indonesian and then to the key.
+func (c Column) Header(locale string) string {
+	if h, ok := c.Headers[locale]; ok {
+		return h
+	}
+	if h, ok := c.Headers[LocaleID]; ok {
+		return h
+	}
+
+	return c.Key
+}
+
+type Options struct {
+	Locale   string
+	Location *time.Location
+	Preamble [][]any // rows written above the header, xlsx only
+}
+
+func (o Options) location() *time.Location {
+	if o.Location == nil {
+		return time.UTC
+	}
+
+	return o.Location
+}
+
+// Writer writes rows in column order, Close must be called to flush the output.
+type Writer interface {
+	WriteRow(values []any) error
+	Close() error
+}
+
+// Table is a report whose rows are already in memory.
+type Table struct {
+	Slug    string
+	Columns []Column
+	Rows    [][]any
+}
+
+func (t Table) Write(w io.Writer, format string, opt Options) error {
+	tw, err := NewWriter(w, format, t.Columns, opt)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range t.Rows {
+		if err := tw.WriteRow(row); err != nil {
+			return err
+		}
+	}
+
+	return tw.Close()
+}
+
+func NewWriter(w io.Writer, format string, columns []Column, opt Options) (Writer, error) {
+	if opt.Locale == "" {
+		opt.Locale = LocaleID
+	}
+
+	switch format {
+	case FormatXLSX:
+		return newXLSXWriter(w, columns, opt)
+	case FormatCSV:
+		return newCSVWriter(w, columns, opt)
+	case FormatNDJSON:
+		return newNDJSONWriter(w, columns, opt), nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// Negotiate picks the output format, an explicit format parameter wins over
+// the Accept header. Without either the format defaults to xlsx.
+func Negotiate(format, accept string) (string, error) {
+	if format != "" {
+		switch strings.ToLower(format) {
+		case FormatXLSX:
+			return FormatXLSX, nil
+		case FormatCSV:
+			return FormatCSV, nil
+		case FormatNDJSON, "jsonl", "json":
+			return FormatNDJSON, nil
+		default:
+			return "", ErrUnsupportedFormat
+		}
+	}
+
+	for _, part := range strings.Split(accept, ",") {
+		mime := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		switch mime {
+		case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+			return FormatXLSX, nil
+		case "text/csv":
+			return FormatCSV, nil
+		case "application/x-ndjson", "application/jsonl":
+			return FormatNDJSON, nil
+		}
+	}
+
+	return FormatXLSX, nil
+}
+
+// Requested tells whether the request asks for a tabular format rather than
+// the json response, through the format parameter or the Accept header.
+func Requested(format, accept string) bool {
+	if format != "" {
+		return true
+	}
+
+	for _, part := range strings.Split(accept, ",") {
+		switch strings.TrimSpace(strings.SplitN(part, ";", 2)[0]) {
+		case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "text/csv", "application/x-ndjson", "application/jsonl":
+			return true
+		}
+	}
+
+	return false
+}
+
+// NegotiateLocale picks the header locale from an explicit lang parameter or
+// the Accept-Language header.
+func NegotiateLocale(lang, acceptLanguage string) string {
+	if lang == "" {
+		lang = strings.SplitN(acceptLanguage, ",", 2)[0]
+	}
+
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if strings.HasPrefix(lang, LocaleEN) {
+		return LocaleEN
+	}
+
+	return LocaleID
+}
+
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+}
+
+func Extension(format string) string {
+	switch format {
+	case FormatCSV:
+		return "csv"
+	case FormatNDJSON:
+		return "ndjson"
+	default:
+		return "xlsx"
+	}
+}
+
+// formatRow applies the column formatters and dereferences nullable values.
+func formatRow(columns []Column, values []any, opt Options) []any {
+	row := make([]any, len(columns))
+	for i := range columns {
+		if i >= len(values) {
+			break
+		}
+
+		v := deref(values[i])
+		if columns[i].Format != nil && v != nil {
+			v = columns[i].Format(v, opt)
+		}
+		row[i] = v
+	}
+
+	return row
+}
+
+func deref(v any) any {
+	switch val := v.(type) {
+	case *string:
+		if val == nil {
+			return nil
+		}
+		return *val
+	case *int:
+		if val == nil {
+			return nil
+		}
+		return *val
+	case *float64:
+		if val == nil {
+			return nil
+		}
+		return *val
+	case *time.Time:
+		if val == nil {
+			return nil
+		}
+		return *val
+	default:
+		return v
+	}
+}
@@ -0,0 +1,136 @@
+package tabular
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+var testColumns = []Column{
+	{Key: "name", Headers: map[string]string{LocaleID: "Nama", LocaleEN: "Name"}},
+	{Key: "status", Headers: map[string]string{LocaleID: "Status"}, Format: Labels(map[string]map[string]string{
+		"wip": {LocaleID: "Pengerjaan", LocaleEN: "In progress"},
+	})},
+	{Key: "created_at", Headers: map[string]string{LocaleID: "Tanggal", LocaleEN: "Date"}, Format: Date},
+}
+
+func writeAll(t *testing.T, format, locale string) string {
+	t.Helper()
+
+	var (
+		buf  bytes.Buffer
+		name = "Budi"
+		loc  = time.FixedZone("WITA", 8*3600)
+		at   = time.Date(2024, 10, 1, 20, 0, 0, 0, time.UTC)
+	)
+
+	w, err := NewWriter(&buf, format, testColumns, Options{Locale: locale, Location: loc})
+	assert.NoError(t, err)
+	assert.NoError(t, w.WriteRow([]any{&name, "wip", at}))
+	assert.NoError(t, w.WriteRow([]any{(*string)(nil), "offered", at}))
+	assert.NoError(t, w.Close())
+
+	return buf.String()
+}
+
+func TestCSVWriter(t *testing.T) {
+	out := writeAll(t, FormatCSV, LocaleEN)
+
+	assert.True(t, strings.HasPrefix(out, "\xEF\xBB\xBF"), "csv must start with a UTF-8 BOM")
+	assert.Equal(t, "Name,Status,Date\nBudi,In progress,2024-10-02\n,offered,2024-10-02\n", strings.TrimPrefix(out, "\xEF\xBB\xBF"))
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	out := writeAll(t, FormatNDJSON, LocaleID)
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	assert.Len(t, lines, 2)
+	assert.JSONEq(t, `{"name":"Budi","status":"Pengerjaan","created_at":"2024-10-02"}`, lines[0])
+	assert.JSONEq(t, `{"name":null,"status":"offered","created_at":"2024-10-02"}`, lines[1])
+}
+
+func TestXLSXWriter(t *testing.T) {
+	out := writeAll(t, FormatXLSX, LocaleID)
+
+	// xlsx files are zip archives
+	assert.True(t, strings.HasPrefix(out, "PK"))
+}
+
+func TestXLSXWriterDecimal(t *testing.T) {
+	var (
+		buf     bytes.Buffer
+		columns = []Column{{Key: "revenue", Headers: map[string]string{LocaleID: "Revenue"}}}
+	)
+
+	w, err := NewWriter(&buf, FormatXLSX, columns, Options{Locale: LocaleID})
+	assert.NoError(t, err)
+	assert.NoError(t, w.WriteRow([]any{decimal.RequireFromString("1250000.50")}))
+	assert.NoError(t, w.Close())
+
+	f, err := excelize.OpenReader(&buf)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	// the number cells have no type, the text ones are strings
+	typ, err := f.GetCellType(sheetName, "A2")
+	assert.NoError(t, err)
+	assert.Equal(t, excelize.CellTypeUnset, typ)
+
+	v, err := f.GetCellValue(sheetName, "A2")
+	assert.NoError(t, err)
+	assert.Equal(t, "1250000.5", v)
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		format, accept, want string
+		wantErr              bool
+	}{
+		{"", "", FormatXLSX, false},
+		{"csv", "application/x-ndjson", FormatCSV, false},
+		{"", "text/csv;q=0.9, */*", FormatCSV, false},
+		{"", "application/x-ndjson", FormatNDJSON, false},
+		{"pdf", "", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := Negotiate(tt.format, tt.accept)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrUnsupportedFormat)
+			continue
+		}
+
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestRequested(t *testing.T) {
+	assert.True(t, Requested("csv", ""))
+	assert.True(t, Requested("", "text/csv;q=0.9, */*"))
+	assert.False(t, Requested("", "application/json, */*"))
+	assert.False(t, Requested("", ""))
+}
+
+func TestTableWrite(t *testing.T) {
+	var (
+		buf   bytes.Buffer
+		name  = "Budi"
+		table = Table{Slug: "test", Columns: testColumns[:2], Rows: [][]any{{&name, "wip"}}}
+	)
+
+	assert.NoError(t, table.Write(&buf, FormatCSV, Options{Locale: LocaleEN}))
+	assert.Equal(t, "\ufeffName,Status\nBudi,In progress\n", buf.String())
+}
+
+func TestNegotiateLocale(t *testing.T) {
+	assert.Equal(t, LocaleEN, NegotiateLocale("", "en-US,en;q=0.9"))
+	assert.Equal(t, LocaleID, NegotiateLocale("", "id-ID"))
+	assert.Equal(t, LocaleEN, NegotiateLocale("en", "id-ID"))
+	assert.Equal(t, LocaleID, NegotiateLocale("", ""))
+}
@@ -0,0 +1,118 @@
+package tabular
+
+import (
+	"io"
+
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+const sheetName = "Sheet1"
+
+type xlsxWriter struct {
+	out  io.Writer
+	file *excelize.File
+	sw   *excelize.StreamWriter
+	cols []Column
+	opt  Options
+	row  int
+}
+
+func newXLSXWriter(w io.Writer, columns []Column, opt Options) (*xlsxWriter, error) {
+	f := excelize.NewFile()
+
+	sw, err := f.NewStreamWriter(sheetName)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	bold, err := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true},
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+	})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	// column widths must be set before any row is written
+	for i, c := range columns {
+		width := c.Width
+		if width == 0 {
+			width = 21
+		}
+
+		if err := sw.SetColWidth(i+1, i+1, width); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	x := &xlsxWriter{out: w, file: f, sw: sw, cols: columns, opt: opt}
+
+	for _, p := range opt.Preamble {
+		if err := x.setRow(styled(p, bold)); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	if len(opt.Preamble) > 0 {
+		x.row++ // blank line between the preamble and the table
+	}
+
+	header := make([]any, len(columns))
+	for i, c := range columns {
+		header[i] = c.Header(opt.Locale)
+	}
+
+	if err := x.setRow(styled(header, bold)); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return x, nil
+}
+
+func styled(values []any, style int) []any {
+	cells := make([]any, len(values))
+	for i, v := range values {
+		cells[i] = excelize.Cell{StyleID: style, Value: v}
+	}
+
+	return cells
+}
+
+func (x *xlsxWriter) setRow(values []any) error {
+	x.row++
+
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+
+	return x.sw.SetRow(cell, values)
+}
+
+func (x *xlsxWriter) WriteRow(values []any) error {
+	row := formatRow(x.cols, values, x.opt)
+	for i, v := range row {
+		// excelize writes the types it does not know as text, the numbers of
+		// a sheet are floats anyway
+		if d, ok := v.(decimal.Decimal); ok {
+			row[i] = d.InexactFloat64()
+		}
+	}
+
+	return x.setRow(row)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+
+	if err := x.sw.Flush(); err != nil {
+		return err
+	}
+
+	return x.file.Write(x.out)
+}
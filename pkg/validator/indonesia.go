@@ -0,0 +1,104 @@
+package validator
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	// platePattern is a plate without spaces, the region (1-2 letters), the
+	// number (1-4 digits, no leading zero) and the suffix (0-3 letters).
+	platePattern = regexp.MustCompile(`^([A-Z]{1,2})([1-9][0-9]{0,3})([A-Z]{0,3})$`)
+
+	// phonePattern is an Indonesian number in E.164, mobile or landline.
+	phonePattern = regexp.MustCompile(`^\+62[1-9][0-9]{7,11}$`)
+
+	plateSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "\t", "")
+)
+
+// Plate is an Indonesian license plate, "B 1234 XYZ" is the region "B", the
+// number "1234" and the suffix "XYZ".
+type Plate struct {
+	Region string
+	Number string
+	Suffix string
+}
+
+// String is the canonical plate, the parts upper cased and separated by one
+// space.
+func (p Plate) String() string {
+	if p.Suffix == "" {
+		return p.Region + " " + p.Number
+	}
+
+	return p.Region + " " + p.Number + " " + p.Suffix
+}
+
+// ParsePlate reads a plate written with or without spaces, dashes or dots
+// and in any case, "b-1234-xyz", "B1234XYZ" and "b 1234 xyz" are the same.
+func ParsePlate(s string) (Plate, bool) {
+	m := platePattern.FindStringSubmatch(strings.ToUpper(plateSeparators.Replace(s)))
+	if m == nil {
+		return Plate{}, false
+	}
+
+	return Plate{Region: m[1], Number: m[2], Suffix: m[3]}, true
+}
+
+// FormatPlate returns the canonical plate, a plate that can not be parsed
+// is upper cased with its inner spaces collapsed.
+func FormatPlate(s string) string {
+	if p, ok := ParsePlate(s); ok {
+		return p.String()
+	}
+
+	return strings.ToUpper(strings.Join(strings.Fields(s), " "))
+}
+
+// FormatPhone keeps the digits of a phone number and writes an Indonesian
+// number in E.164, "0812-3456-789", "62812 3456 789" and "+62 812 3456 789"
+// all become "+628123456789". Other numbers keep their digits and leading
+// plus.
+func FormatPhone(s string) string {
+	s = strings.TrimSpace(s)
+	plus := strings.HasPrefix(s, "+")
+
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, s)
+
+	switch {
+	case digits == "":
+		return s
+	case strings.HasPrefix(digits, "62"):
+		return "+" + digits
+	case strings.HasPrefix(digits, "0") && !plus:
+		return "+62" + digits[1:]
+	case strings.HasPrefix(digits, "8") && !plus:
+		return "+62" + digits
+	case plus:
+		return "+" + digits
+	}
+
+	return digits
+}
+
+// IsPhone reports whether s is an Indonesian phone number once formatted.
+func IsPhone(s string) bool {
+	return phonePattern.MatchString(FormatPhone(s))
+}
+
+func isIdPlate(fl validator.FieldLevel) bool {
+	_, ok := ParsePlate(fl.Field().String())
+	return ok
+}
+
+func isIdPhone(fl validator.FieldLevel) bool {
+	return IsPhone(fl.Field().String())
+}
@@ -0,0 +1,42 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePlate(t *testing.T) {
+	for _, plate := range []string{"B 1234 XYZ", "b1234xyz", " b-1234-xyz ", "B.1234.XYZ"} {
+		p, ok := ParsePlate(plate)
+		assert.True(t, ok, plate)
+		assert.Equal(t, "B 1234 XYZ", p.String(), plate)
+	}
+
+	p, ok := ParsePlate("dd 7")
+	assert.True(t, ok)
+	assert.Equal(t, Plate{Region: "DD", Number: "7"}, p)
+	assert.Equal(t, "DD 7", p.String())
+
+	for _, plate := range []string{"", "1234", "B 0123 XY", "B 12345 XY", "ABC 123", "B 123 XYZW"} {
+		_, ok := ParsePlate(plate)
+		assert.False(t, ok, plate)
+	}
+}
+
+func TestFormatPlate(t *testing.T) {
+	assert.Equal(t, "DD 1234 AB", FormatPlate("dd1234ab"))
+	assert.Equal(t, "CD 12 34 56", FormatPlate(" cd  12 34 56 "))
+}
+
+func TestFormatPhone(t *testing.T) {
+	for _, phone := range []string{"0812-3456-789", "62812 3456 789", "+62 812 3456 789", "8123456789"} {
+		assert.Equal(t, "+628123456789", FormatPhone(phone), phone)
+		assert.True(t, IsPhone(phone), phone)
+	}
+
+	assert.Equal(t, "+6591234567", FormatPhone("+65 9123 4567"))
+	assert.False(t, IsPhone("+65 9123 4567"))
+	assert.False(t, IsPhone("0812"))
+	assert.True(t, IsPhone("(021) 5550123"))
+}
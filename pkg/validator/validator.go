@@ -61,6 +61,12 @@ func NewValidator() *Validator {
 	if err := v.RegisterValidation("strong_password", isStrongPassword); err != nil {
 		log.Fatal().Err(err).Msg("Error while registering strong_password validator")
 	}
+	if err := v.RegisterValidation("id_plate", isIdPlate); err != nil {
+		log.Fatal().Err(err).Msg("Error while registering id_plate validator")
+	}
+	if err := v.RegisterValidation("id_phone", isIdPhone); err != nil {
+		log.Fatal().Err(err).Msg("Error while registering id_phone validator")
+	}
 	if err := v.RegisterValidation("exist", isExist); err != nil {
 		log.Fatal().Err(err).Msg("Error while registering exist validator")
 	}
@@ -116,6 +122,32 @@ func isStrongPassword(fl validator.FieldLevel) bool {
 	return hasUppercase && hasLowercase && hasNumber
 }
 
+// softDeletableTables are the tables with a deleted_at column, a table given
+// the column by a migration is added here, the list is checked against the
+// migrations by the tests.
+var softDeletableTables = map[string]bool{
+	"areas":                        true,
+	"branches":                     true,
+	"clients":                      true,
+	"potencies":                    true,
+	"promotions":                   true,
+	"report_subscriptions":         true,
+	"roles":                        true,
+	"tiers":                        true,
+	"users":                        true,
+	"vehicle_types":                true,
+	"vehicles":                     true,
+	"wac_follow_up_logs":           true,
+	"walk_around_check_conditions": true,
+	"walk_around_checks":           true,
+}
+
+func isSoftDeletable(table string) bool {
+	return softDeletableTables[table]
+}
+
+// isExist checks the value exists in table.column, soft deleted rows do not
+// count as existing.
 func isExist(fl validator.FieldLevel) bool {
 	db := adapter.Adapters.DigihubPostgres
 	fieldValue := fl.Field().String()
@@ -135,6 +167,9 @@ func isExist(fl validator.FieldLevel) bool {
 
 	// Check if the value exists in the column
 	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1", column, table, column)
+	if isSoftDeletable(table) {
+		query += " AND deleted_at IS NULL"
+	}
 	result := make(map[string]any)
 
 	err := db.QueryRowx(query, fieldValue).MapScan(result)
@@ -0,0 +1,87 @@
+package validator
+
+import (
+	"codebase-app/internal/adapter"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	createTable     = regexp.MustCompile(`(?is)CREATE TABLE (?:IF NOT EXISTS )?(\w+)\s*\((.*?)\);`)
+	addDeletedAt    = regexp.MustCompile(`(?i)ALTER TABLE (\w+) ADD COLUMN (?:IF NOT EXISTS )?deleted_at\b`)
+	deletedAtColumn = regexp.MustCompile(`(?im)^\s*deleted_at\s`)
+)
+
+func TestSoftDeletableTablesMatchMigrations(t *testing.T) {
+	files, err := filepath.Glob("../../db/migrations/*.up.sql")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no migrations found: %v", err)
+	}
+
+	tables := make(map[string]bool)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, m := range createTable.FindAllStringSubmatch(string(content), -1) {
+			if deletedAtColumn.MatchString(m[2]) {
+				tables[m[1]] = true
+			}
+		}
+		for _, m := range addDeletedAt.FindAllStringSubmatch(string(content), -1) {
+			tables[m[1]] = true
+		}
+	}
+
+	assert.Equal(t, keys(tables), keys(softDeletableTables))
+}
+
+func keys(m map[string]bool) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+func TestExistSkipsSoftDeletedRows(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	adapter.Adapters = &adapter.Adapter{DigihubPostgres: sqlx.NewDb(db, "postgres")}
+
+	type request struct {
+		ClientId    string `json:"client_id" validate:"exist=clients.id"`
+		PriceListId string `json:"price_list_id" validate:"exist=trade_in_price_lists.id"`
+	}
+
+	// only the whitelisted tables filter the deleted rows, the others have
+	// no deleted_at column
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM clients WHERE id = $1 AND deleted_at IS NULL")).
+		WithArgs("client").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM trade_in_price_lists WHERE id = $1") + "$").
+		WithArgs("list").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("list"))
+
+	err = NewValidator().Validate(request{ClientId: "client", PriceListId: "list"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "client_id")
+		assert.NotContains(t, err.Error(), "price_list_id")
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}